@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// linkStore is the part of the storage the link commands work with
+type linkStore interface {
+	LookupLink(ctx context.Context, id string) (domain.LinkRecord, error)
+	TakedownLink(ctx context.Context, id, reason string) error
+	RestoreLink(ctx context.Context, id string) error
+	GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+	Stats(ctx context.Context) (domain.InstanceStats, error)
+}
+
+// auditRecorder is the audit log the link commands record the changes of operators in
+type auditRecorder interface {
+	Record(ctx context.Context, entries ...domain.AuditEntry) error
+}
+
+// usageError reports a command called with wrong arguments
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// Link states shown by lookup
+const (
+	stateActive    = "active"
+	stateDeleted   = "deleted"
+	stateTakenDown = "taken down"
+)
+
+type cli struct {
+	store linkStore
+	audit auditRecorder
+	cfg   *config.Config
+	out   *printer
+	now   func() time.Time
+}
+
+func (c *cli) run(ctx context.Context, args []string) error {
+	if c.now == nil {
+		c.now = time.Now
+	}
+
+	switch args[0] {
+	case "lookup":
+		return c.lookup(ctx, args[1:])
+	case "takedown":
+		return c.takedown(ctx, args[1:])
+	case "restore":
+		return c.restore(ctx, args[1:])
+	case "list":
+		return c.list(ctx, args[1:])
+	case "stats":
+		return c.stats(ctx, args[1:])
+	case "purge-deleted":
+		return c.purgeDeleted(ctx, args[1:])
+	default:
+		return usageErrorf("unknown command %q", args[0])
+	}
+}
+
+func (c *cli) lookup(ctx context.Context, args []string) error {
+	fs := newFlagSet("lookup")
+	id, err := parseID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	link, err := c.store.LookupLink(ctx, id)
+	if err != nil {
+		return linkError(id, err)
+	}
+	link.ShortURL = c.cfg.ShortURL(link.Domain, link.ID)
+
+	deletedAt := ""
+	if link.DeletedAt != nil {
+		deletedAt = link.DeletedAt.Format(time.RFC3339)
+	}
+
+	// the owner is left out of links in API responses but is what operators look for
+	result := struct {
+		domain.LinkRecord
+		UserID int `json:"user_id"`
+	}{LinkRecord: link, UserID: link.UserID}
+
+	return c.out.print(result, nil, [][]string{
+		{"ID", link.ID},
+		{"Short URL", link.ShortURL},
+		{"Original URL", link.OriginalURL},
+		{"User", strconv.Itoa(link.UserID)},
+		{"Workspace", link.Workspace},
+		{"Title", link.Title},
+		{"Tags", strings.Join(link.Tags, ", ")},
+		{"State", linkState(link)},
+		{"Deleted at", deletedAt},
+		{"Takedown reason", link.TakedownReason},
+	})
+}
+
+func (c *cli) takedown(ctx context.Context, args []string) error {
+	fs := newFlagSet("takedown")
+	reason := fs.String("reason", "", "Why the link is taken down, required")
+	id, err := parseID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	*reason = strings.TrimSpace(*reason)
+	if *reason == "" {
+		return usageErrorf("takedown needs a --reason")
+	}
+
+	before, err := c.store.LookupLink(ctx, id)
+	if err != nil {
+		return linkError(id, err)
+	}
+
+	if err := c.store.TakedownLink(ctx, id, *reason); err != nil {
+		return linkError(id, err)
+	}
+
+	after := before
+	after.Deleted, after.TakedownReason = true, *reason
+	if err := c.record(ctx, domain.AuditTakedown, before, after); err != nil {
+		return err
+	}
+
+	return c.printResult(id, stateTakenDown)
+}
+
+func (c *cli) restore(ctx context.Context, args []string) error {
+	fs := newFlagSet("restore")
+	id, err := parseID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	before, err := c.store.LookupLink(ctx, id)
+	if err != nil {
+		return linkError(id, err)
+	}
+
+	if err := c.store.RestoreLink(ctx, id); err != nil {
+		return linkError(id, err)
+	}
+
+	after := before
+	after.Deleted, after.TakedownReason = false, ""
+	if err := c.record(ctx, domain.AuditRestore, before, after); err != nil {
+		return err
+	}
+
+	return c.printResult(id, stateActive)
+}
+
+// record appends the change of an operator to the audit log. Operators have no user ID nor address, so the entry
+// has neither
+func (c *cli) record(ctx context.Context, action string, before, after domain.LinkRecord) error {
+	entry := domain.AuditEntry{
+		Action: action,
+		LinkID: before.ID,
+		Before: auditState(before),
+		After:  auditState(after),
+	}
+	if err := c.audit.Record(ctx, entry); err != nil {
+		return fmt.Errorf("link %s changed but the audit log entry was not recorded: %w", before.ID, err)
+	}
+	return nil
+}
+
+func auditState(link domain.LinkRecord) *domain.AuditState {
+	state := domain.NewAuditState(link.Link)
+	state.Deleted = link.Deleted
+	state.TakedownReason = link.TakedownReason
+	return state
+}
+
+func (c *cli) printResult(id, state string) error {
+	result := struct {
+		ID    string `json:"id"`
+		State string `json:"state"`
+	}{ID: id, State: state}
+
+	return c.out.print(result, nil, [][]string{{id, state}})
+}
+
+func (c *cli) list(ctx context.Context, args []string) error {
+	fs := newFlagSet("list")
+	userID := fs.Int("user", 0, "ID of the user whose links are listed, required")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageErrorf("list takes no arguments")
+	}
+	if *userID == 0 {
+		return usageErrorf("list needs a --user")
+	}
+
+	links, err := c.store.GetUserURLs(ctx, *userID, domain.LinkFilter{})
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(links))
+	for i := range links {
+		links[i].ShortURL = c.cfg.ShortURL(links[i].Domain, links[i].ID)
+		rows = append(rows, []string{links[i].ID, links[i].ShortURL, links[i].OriginalURL, links[i].Title, strings.Join(links[i].Tags, ", ")})
+	}
+	if links == nil {
+		links = []domain.Link{}
+	}
+
+	return c.out.print(links, []string{"ID", "SHORT URL", "ORIGINAL URL", "TITLE", "TAGS"}, rows)
+}
+
+func (c *cli) stats(ctx context.Context, args []string) error {
+	fs := newFlagSet("stats")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageErrorf("stats takes no arguments")
+	}
+
+	stats, err := c.store.Stats(ctx)
+	if err != nil {
+		return err
+	}
+
+	count := func(n int64) string { return strconv.FormatInt(n, 10) }
+	return c.out.print(stats, nil, [][]string{
+		{"Links", count(stats.Links)},
+		{"Deleted", count(stats.Deleted)},
+		{"Taken down", count(stats.TakenDown)},
+		{"Users with links", count(stats.Users)},
+		{"Accounts", count(stats.Accounts)},
+		{"Workspaces", count(stats.Workspaces)},
+	})
+}
+
+func (c *cli) purgeDeleted(ctx context.Context, args []string) error {
+	fs := newFlagSet("purge-deleted")
+	olderThan := fs.String("older-than", "30d", "Minimum time since deletion, e.g. 30d or 12h")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageErrorf("purge-deleted takes no arguments")
+	}
+
+	age, err := parseAge(*olderThan)
+	if err != nil {
+		return usageErrorf("--older-than: %v", err)
+	}
+
+	deletedBefore := c.now().Add(-age)
+	purged, err := c.store.PurgeDeleted(ctx, deletedBefore)
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Purged        int64     `json:"purged"`
+		DeletedBefore time.Time `json:"deleted_before"`
+	}{Purged: purged, DeletedBefore: deletedBefore.UTC()}
+
+	return c.out.print(result, nil, [][]string{
+		{"Purged", strconv.FormatInt(purged, 10)},
+		{"Deleted before", result.DeletedBefore.Format(time.RFC3339)},
+	})
+}
+
+func linkState(link domain.LinkRecord) string {
+	switch {
+	case link.TakedownReason != "":
+		return stateTakenDown
+	case link.Deleted:
+		return stateDeleted
+	default:
+		return stateActive
+	}
+}
+
+func linkError(id string, err error) error {
+	if errors.Is(err, appErrors.ErrNotFound) {
+		return fmt.Errorf("link %s not found", id)
+	}
+	return err
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses flags given before, between or after the positional arguments and returns the latter
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usageErrorf("%s: %v", fs.Name(), err)
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// parseID parses the flags of a command taking a single link ID
+func parseID(fs *flag.FlagSet, args []string) (string, error) {
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return "", err
+	}
+	if len(rest) != 1 || rest[0] == "" {
+		return "", usageErrorf("%s needs exactly one link ID", fs.Name())
+	}
+	return rest[0], nil
+}
+
+// parseAge parses a duration that may also be given in days, e.g. 30d
+func parseAge(s string) (time.Duration, error) {
+	var (
+		age time.Duration
+		err error
+	)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		age, err = time.ParseDuration(s)
+	}
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return age, nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+type fakeStore struct {
+	links         map[string]domain.LinkRecord
+	deletedBefore time.Time
+}
+
+func (s *fakeStore) LookupLink(ctx context.Context, id string) (domain.LinkRecord, error) {
+	link, ok := s.links[id]
+	if !ok {
+		return domain.LinkRecord{}, appErrors.ErrNotFound
+	}
+	return link, nil
+}
+
+func (s *fakeStore) TakedownLink(ctx context.Context, id, reason string) error {
+	link, ok := s.links[id]
+	if !ok {
+		return appErrors.ErrNotFound
+	}
+	link.Deleted, link.TakedownReason = true, reason
+	s.links[id] = link
+	return nil
+}
+
+func (s *fakeStore) RestoreLink(ctx context.Context, id string) error {
+	link, ok := s.links[id]
+	if !ok {
+		return appErrors.ErrNotFound
+	}
+	link.Deleted, link.DeletedAt, link.TakedownReason = false, nil, ""
+	s.links[id] = link
+	return nil
+}
+
+func (s *fakeStore) GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error) {
+	var links []domain.Link
+	for _, link := range s.links {
+		if link.UserID == userID {
+			links = append(links, link.Link)
+		}
+	}
+	return links, nil
+}
+
+func (s *fakeStore) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	s.deletedBefore = deletedBefore
+	return 3, nil
+}
+
+func (s *fakeStore) Stats(ctx context.Context) (domain.InstanceStats, error) {
+	return domain.InstanceStats{Links: 10, Deleted: 2, TakenDown: 1, Users: 4, Accounts: 3, Workspaces: 1}, nil
+}
+
+type fakeAudit struct {
+	entries []domain.AuditEntry
+}
+
+func (a *fakeAudit) Record(ctx context.Context, entries ...domain.AuditEntry) error {
+	a.entries = append(a.entries, entries...)
+	return nil
+}
+
+func TestCLI(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{links: map[string]domain.LinkRecord{
+		"abc": {Link: domain.Link{ID: "abc", OriginalURL: "https://example.com", UserID: 7, LinkMeta: domain.LinkMeta{Title: "Example"}}},
+	}}
+	audit := &fakeAudit{}
+	cfg := &config.Config{BaseURL: "http://localhost:8080"}
+
+	exec := func(t *testing.T, format string, args ...string) (string, error) {
+		var out bytes.Buffer
+		c := &cli{store: store, audit: audit, cfg: cfg, out: newPrinter(&out, format), now: func() time.Time { return now }}
+		err := c.run(context.Background(), args)
+		return out.String(), err
+	}
+
+	t.Run("lookup", func(t *testing.T) {
+		out, err := exec(t, formatJSON, "lookup", "abc")
+		require.NoError(t, err)
+
+		var link map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &link))
+		require.Equal(t, "http://localhost:8080/abc", link["short_url"])
+		require.Equal(t, float64(7), link["user_id"])
+		require.Equal(t, false, link["deleted"])
+
+		out, err = exec(t, formatTable, "lookup", "abc")
+		require.NoError(t, err)
+		require.Contains(t, out, "State            active\n")
+
+		_, err = exec(t, formatTable, "lookup", "missing")
+		require.EqualError(t, err, "link missing not found")
+	})
+
+	t.Run("takedown and restore", func(t *testing.T) {
+		var usageErr *usageError
+		_, err := exec(t, formatTable, "takedown", "abc")
+		require.True(t, errors.As(err, &usageErr))
+
+		out, err := exec(t, formatTable, "takedown", "abc", "--reason", "phishing")
+		require.NoError(t, err)
+		require.Equal(t, "abc  taken down\n", out)
+		require.Equal(t, "phishing", store.links["abc"].TakedownReason)
+
+		out, err = exec(t, formatTable, "lookup", "abc")
+		require.NoError(t, err)
+		require.Contains(t, out, "State            taken down\n")
+		require.Contains(t, out, "Takedown reason  phishing\n")
+
+		out, err = exec(t, formatJSON, "restore", "abc")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":"abc","state":"active"}`, out)
+		require.False(t, store.links["abc"].Deleted)
+
+		active := domain.AuditState{OriginalURL: "https://example.com", Title: "Example"}
+		takenDown := domain.AuditState{OriginalURL: "https://example.com", Title: "Example", Deleted: true, TakedownReason: "phishing"}
+		require.Equal(t, []domain.AuditEntry{
+			{Action: domain.AuditTakedown, LinkID: "abc", Before: &active, After: &takenDown},
+			{Action: domain.AuditRestore, LinkID: "abc", Before: &takenDown, After: &active},
+		}, audit.entries)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		out, err := exec(t, formatTable, "list", "--user", "7")
+		require.NoError(t, err)
+		require.Equal(t, "ID   SHORT URL                  ORIGINAL URL         TITLE    TAGS\n"+
+			"abc  http://localhost:8080/abc  https://example.com  Example  \n", out)
+
+		out, err = exec(t, formatJSON, "list", "--user=8")
+		require.NoError(t, err)
+		require.JSONEq(t, `[]`, out)
+
+		var usageErr *usageError
+		_, err = exec(t, formatTable, "list")
+		require.True(t, errors.As(err, &usageErr))
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		out, err := exec(t, formatJSON, "stats")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"links":10,"deleted":2,"taken_down":1,"users":4,"accounts":3,"workspaces":1}`, out)
+	})
+
+	t.Run("purge-deleted", func(t *testing.T) {
+		out, err := exec(t, formatJSON, "purge-deleted", "--older-than", "7d")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"purged":3,"deleted_before":"2024-05-24T12:00:00Z"}`, out)
+		require.Equal(t, now.Add(-7*24*time.Hour), store.deletedBefore)
+
+		_, err = exec(t, formatJSON, "purge-deleted")
+		require.NoError(t, err)
+		require.Equal(t, now.Add(-30*24*time.Hour), store.deletedBefore)
+
+		var usageErr *usageError
+		_, err = exec(t, formatJSON, "purge-deleted", "--older-than", "-1d")
+		require.True(t, errors.As(err, &usageErr))
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		var usageErr *usageError
+		_, err := exec(t, formatTable, "frobnicate")
+		require.True(t, errors.As(err, &usageErr))
+	})
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: "12h", want: 12 * time.Hour},
+		{in: "90m", want: 90 * time.Minute},
+		{in: "0d", err: true},
+		{in: "d", err: true},
+		{in: "week", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseAge(tt.in)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Command shortctl operates a shortener instance: it looks up, takes down and restores links, lists the links of
+// a user, shows instance statistics, purges deleted links and migrates the database. It reads the same configuration
+// as the shortener and works with its PostgreSQL storage.
+//
+// Usage:
+//
+//	shortctl [-d dsn] [-o table|json] <command> [arguments]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/repository"
+	"github.com/Te8va/shortURL/internal/app/service"
+)
+
+const usage = `Usage: shortctl [flags] <command> [arguments]
+
+Commands:
+  lookup <id>                          show a link, including deleted ones
+  takedown <id> --reason <text>        delete a link on behalf of the operators
+  restore <id>                         undo the deletion or takedown of a link
+  list --user <id>                     list the personal links of a user
+  stats                                count links, users and workspaces
+  purge-deleted [--older-than 30d]     remove links deleted longer ago than the given age
+  migrate up|down [--steps 1]|version  apply, revert or show database migrations
+
+The shortener's flags and environment variables (DATABASE_DSN, BASE_URL, ...) configure the instance.
+
+Flags:
+`
+
+func main() {
+	format := flag.String("o", formatTable, "Output format: table or json")
+	migrationsDir := flag.String("migrations", "migrations", "Directory of the database migrations")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+
+	log.SetFlags(0)
+	log.SetPrefix("shortctl: ")
+
+	cfg := config.NewConfig()
+	if err := run(cfg, *format, *migrationsDir, flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(cfg *config.Config, format, migrationsDir string, args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return errors.New("no command given")
+	}
+	if format != formatTable && format != formatJSON {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	if cfg.DatabaseDSN == "" {
+		return errors.New("no PostgreSQL storage configured, set DATABASE_DSN or -d")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	if args[0] == "migrate" {
+		err = runMigrate(cfg.DatabaseDSN, migrationsDir, os.Stdout, args[1:])
+	} else {
+		err = runLinkCommand(ctx, cfg, format, args)
+	}
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		flag.Usage()
+	}
+	return err
+}
+
+func runLinkCommand(ctx context.Context, cfg *config.Config, format string, args []string) error {
+	pool, err := repository.GetPgxPool(ctx, cfg.DatabaseDSN)
+	if err != nil {
+		return fmt.Errorf("connect to PostgreSQL: %w", err)
+	}
+	defer pool.Close()
+
+	repo, err := repository.NewURLRepository(pool)
+	if err != nil {
+		return err
+	}
+
+	audit, err := repository.NewAuditRepository(pool)
+	if err != nil {
+		return err
+	}
+
+	c := &cli{
+		store: repo,
+		audit: service.NewAuditService(audit, repo),
+		cfg:   cfg,
+		out:   newPrinter(os.Stdout, format),
+	}
+	return c.run(ctx, args)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang-migrate/migrate/v4"
+	// drivers of the migrations and of the database they are applied to
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+// runMigrate applies all migrations, reverts the last --steps of them or prints the current version
+func runMigrate(dsn, dir string, out io.Writer, args []string) error {
+	fs := newFlagSet("migrate")
+	steps := fs.Int("steps", 1, "How many migrations down reverts")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageErrorf("migrate needs one of up, down or version")
+	}
+	if *steps < 1 {
+		return usageErrorf("--steps must be positive")
+	}
+
+	var apply func(m *migrate.Migrate) error
+	switch rest[0] {
+	case "up":
+		apply = (*migrate.Migrate).Up
+	case "down":
+		apply = func(m *migrate.Migrate) error { return m.Steps(-*steps) }
+	case "version":
+	default:
+		return usageErrorf("unknown migrate command %q", rest[0])
+	}
+
+	m, err := migrate.New("file://"+dir, dsn)
+	if err != nil {
+		return fmt.Errorf("initialize migrations: %w", err)
+	}
+	defer func() {
+		_, _ = m.Close()
+	}()
+
+	if apply != nil {
+		if err := apply(m); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("migrate %s: %w", rest[0], err)
+		}
+	}
+
+	return printVersion(out, m)
+}
+
+func printVersion(out io.Writer, m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		_, err = fmt.Fprintln(out, "no migrations applied")
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("read migration version: %w", err)
+	}
+
+	if dirty {
+		_, err = fmt.Fprintf(out, "version %d (dirty: a migration failed halfway and needs fixing by hand)\n", version)
+	} else {
+		_, err = fmt.Fprintf(out, "version %d\n", version)
+	}
+	return err
+}
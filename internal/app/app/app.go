@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -13,25 +15,61 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+	"github.com/Te8va/shortURL/internal/app/policy"
 	"github.com/Te8va/shortURL/internal/app/repository"
 	"github.com/Te8va/shortURL/internal/app/router"
 	"github.com/Te8va/shortURL/internal/app/service"
 )
 
+// clickFlushInterval is how often variant clicks counted by the JSON file storage are written to the file
+const clickFlushInterval = 10 * time.Second
+
 // App represents the core application structure
 type App struct {
-	cfg     *config.Config
-	logger  *zap.SugaredLogger
-	saver   service.URLSaverServ
-	getter  service.URLGetterServ
-	pinger  service.PingerServ
-	deleter service.URLDeleteServ
-	server  *http.Server
+	cfg        *config.Config
+	logger     *zap.SugaredLogger
+	saver      service.URLSaverServ
+	getter     service.URLGetterServ
+	pinger     service.PingerServ
+	deleter    service.URLDeleteServ
+	updater    service.URLUpdaterServ
+	variants   service.URLVariantServ
+	rules      service.URLRuleServ
+	query      service.URLQueryServ
+	auth       service.AuthServ
+	keys       service.APIKeyServ
+	webhooks   *service.WebhookService
+	checker    *service.LinkChecker
+	purger     *service.Purger
+	jobs       *service.JobService
+	pages      *service.PageFetcher
+	spaces     service.WorkspaceServ
+	moderation service.ModerationServ
+	audit      service.AuditServ
+	files      *repository.JSONRepository
+	tokens     *middleware.TokenManager
+	policy     *policy.Policy
+	server     *http.Server
 }
 
 // NewApp creates a new App instance
 func NewApp() (*App, error) {
 	cfg := config.NewConfig()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	tokens, err := middleware.NewTokenManagerFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPolicy, err := policy.New(cfg.BaseURL, cfg.URLPolicyFile, cfg.ShortDomains...)
+	if err != nil {
+		return nil, err
+	}
 
 	logger, err := zap.NewDevelopment()
 	if err != nil {
@@ -48,6 +86,8 @@ func NewApp() (*App, error) {
 	app := &App{
 		cfg:    cfg,
 		logger: sugar,
+		tokens: tokens,
+		policy: urlPolicy,
 	}
 
 	if err := app.initStorage(); err != nil {
@@ -89,15 +129,77 @@ func (a *App) initPostgresStorage(ctx context.Context) error {
 		a.logger.Fatalw("Failed to create Postgres connection pool", "error", err)
 	}
 
-	repo, err := repository.NewURLRepository(pool, a.cfg)
+	repo, err := repository.NewURLRepository(pool)
 	if err != nil {
 		a.logger.Fatalw("Failed to initialize Postgres repository", "error", err)
 	}
 
+	// BASE_URL is not known to migrations, so links they left on its host are moved here
+	moved, left, err := repo.AdoptBaseDomain(ctx, a.cfg.BaseDomain())
+	if err != nil {
+		a.logger.Fatalw("Failed to move links to the BASE_URL domain", "error", err)
+	}
+	if moved > 0 {
+		a.logger.Infow("Moved links to the BASE_URL domain", "count", moved)
+	}
+	if len(left) > 0 {
+		a.logger.Warnw("Links left on the BASE_URL host because their URL is already shortened on BASE_URL, they do not resolve until merged by hand",
+			"host", a.cfg.BaseDomain(), "ids", left)
+	}
+
+	users, err := repository.NewUserRepository(pool)
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize Postgres user repository", "error", err)
+	}
+
+	keys, err := repository.NewAPIKeyRepository(pool)
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize Postgres API key repository", "error", err)
+	}
+
+	webhooks, err := repository.NewWebhookRepository(pool)
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize Postgres webhook repository", "error", err)
+	}
+
+	workspaces, err := repository.NewWorkspaceRepository(pool)
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize Postgres workspace repository", "error", err)
+	}
+
+	moderation, err := repository.NewModerationRepository(pool)
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize Postgres moderation repository", "error", err)
+	}
+
+	audit, err := repository.NewAuditRepository(pool)
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize Postgres audit repository", "error", err)
+	}
+
+	jobs, err := repository.NewJobRepository(pool)
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize Postgres job repository", "error", err)
+	}
+
 	a.saver = repo
 	a.getter = repo
 	a.pinger = repo
 	a.deleter = repo
+	a.updater = repo
+	a.variants = repo
+	a.rules = repo
+	a.query = repo
+	a.auth = service.NewAuthService(users, repo)
+	a.keys = service.NewAPIKeyService(keys)
+	a.webhooks = service.NewWebhookService(webhooks, repo, a.webhookOptions())
+	a.startLinkChecker(repo)
+	a.startPurger(repo)
+	a.audit = service.NewAuditService(audit, repo)
+	a.startJobs(jobs, repo)
+	a.startPageFetcher(repo)
+	a.spaces = service.NewWorkspaceService(workspaces, repo)
+	a.moderation = service.NewModerationService(moderation, repo)
 
 	return nil
 }
@@ -105,27 +207,235 @@ func (a *App) initPostgresStorage(ctx context.Context) error {
 func (a *App) initFileStorage() error {
 	a.logger.Infoln("Using JSON file as storage:", a.cfg.FileStoragePath)
 
-	storage, err := repository.NewJSONRepository(a.cfg.FileStoragePath, a.cfg)
+	storage, err := repository.NewJSONRepository(a.cfg.FileStoragePath)
 	if err != nil {
 		a.logger.Fatalw("Failed to initialize JSON repository", "error", err)
 	}
 
+	users, err := repository.NewUserStore(sidecarFilePath(a.cfg.FileStoragePath, "users"))
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize JSON user store", "error", err)
+	}
+
+	keys, err := repository.NewAPIKeyStore(sidecarFilePath(a.cfg.FileStoragePath, "apikeys"))
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize JSON API key store", "error", err)
+	}
+
+	webhooks, err := repository.NewWebhookStore(sidecarFilePath(a.cfg.FileStoragePath, "webhooks"))
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize JSON webhook store", "error", err)
+	}
+
+	workspaces, err := repository.NewWorkspaceStore(sidecarFilePath(a.cfg.FileStoragePath, "workspaces"))
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize JSON workspace store", "error", err)
+	}
+	storage.UseWorkspaces(workspaces)
+	storage.StartFlushing(clickFlushInterval)
+	a.files = storage
+
+	moderation, err := repository.NewModerationStore(sidecarFilePath(a.cfg.FileStoragePath, "moderation"))
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize JSON moderation store", "error", err)
+	}
+
+	audit, err := repository.NewAuditStore(sidecarFilePath(a.cfg.FileStoragePath, "audit"))
+	if err != nil {
+		a.logger.Fatalw("Failed to initialize JSON audit store", "error", err)
+	}
+
 	a.saver = storage
 	a.getter = storage
+	a.updater = storage
+	a.variants = storage
+	a.rules = storage
+	a.query = storage
+	a.auth = service.NewAuthService(users, storage)
+	a.keys = service.NewAPIKeyService(keys)
+	a.webhooks = service.NewWebhookService(webhooks, storage, a.webhookOptions())
+	a.startLinkChecker(storage)
+	a.startPageFetcher(storage)
+	a.spaces = service.NewWorkspaceService(workspaces, storage)
+	a.moderation = service.NewModerationService(moderation, storage)
+	a.audit = service.NewAuditService(audit, storage)
 	return nil
 }
 
 func (a *App) initMemoryStorage() error {
 	a.logger.Infoln("Using in-memory storage")
-	storage := repository.NewMemoryRepository(a.cfg)
+	storage := repository.NewMemoryRepository()
+
+	users, err := repository.NewUserStore("")
+	if err != nil {
+		return err
+	}
+
+	keys, err := repository.NewAPIKeyStore("")
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := repository.NewWebhookStore("")
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := repository.NewWorkspaceStore("")
+	if err != nil {
+		return err
+	}
+	storage.UseWorkspaces(workspaces)
+
+	moderation, err := repository.NewModerationStore("")
+	if err != nil {
+		return err
+	}
+
+	audit, err := repository.NewAuditStore("")
+	if err != nil {
+		return err
+	}
 
 	a.saver = storage
 	a.getter = storage
+	a.updater = storage
+	a.variants = storage
+	a.rules = storage
+	a.query = storage
+	a.auth = service.NewAuthService(users, storage)
+	a.keys = service.NewAPIKeyService(keys)
+	a.webhooks = service.NewWebhookService(webhooks, storage, a.webhookOptions())
+	a.startLinkChecker(storage)
+	a.startPageFetcher(storage)
+	a.spaces = service.NewWorkspaceService(workspaces, storage)
+	a.moderation = service.NewModerationService(moderation, storage)
+	a.audit = service.NewAuditService(audit, storage)
 	return nil
 }
 
+func (a *App) webhookOptions() service.WebhookOptions {
+	return service.WebhookOptions{
+		MaxAttempts: a.cfg.WebhookAttempts,
+		Backoff:     a.cfg.WebhookBackoff,
+		Timeout:     a.cfg.WebhookTimeout,
+		ShortURL:    a.cfg.ShortURL,
+		Policy:      a.policy,
+	}
+}
+
+// startLinkChecker checks the original URLs of links in the background unless LINK_CHECK_INTERVAL is 0
+func (a *App) startLinkChecker(links service.LinkHealthStorage) {
+	if a.cfg.LinkCheckInterval <= 0 {
+		return
+	}
+
+	a.checker = service.NewLinkChecker(links, service.LinkCheckOptions{
+		Interval:  a.cfg.LinkCheckInterval,
+		Workers:   a.cfg.LinkCheckWorkers,
+		HostDelay: a.cfg.LinkCheckDelay,
+		Timeout:   a.cfg.LinkCheckTimeout,
+		Policy:    a.policy,
+	})
+	a.checker.Start()
+}
+
+// startPurger removes deleted links for good once they are older than DELETED_RETENTION unless it is 0
+func (a *App) startPurger(links service.DeletedLinkStorage) {
+	if a.cfg.DeletedRetention <= 0 {
+		return
+	}
+
+	a.purger = service.NewPurger(links, service.PurgeOptions{
+		Retention: a.cfg.DeletedRetention,
+		Interval:  a.cfg.PurgeInterval,
+	})
+	a.purger.Start()
+}
+
+// startJobs runs bulk deletions in the background and keeps their outcome for JOB_RETENTION
+func (a *App) startJobs(jobs service.JobStorage, links service.LinkDeleter) {
+	a.jobs = service.NewJobService(jobs, links, service.JobOptions{
+		Retention: a.cfg.JobRetention,
+		Resumed:   a.resumedDeletion,
+	})
+	a.jobs.Start()
+}
+
+// resumedDeletion publishes and records the links deleted by a job resumed after a restart, the way the handler
+// that submitted it would have. The address of the request is gone by then
+func (a *App) resumedDeletion(job domain.Job, links []domain.Link) {
+	entries := make([]domain.AuditEntry, 0, len(links))
+	for _, link := range links {
+		a.webhooks.Publish(domain.LinkEvent{Type: domain.EventLinkDeleted, UserID: job.UserID, ID: link.ID})
+
+		before := domain.NewAuditState(link)
+		after := *before
+		after.Deleted = true
+		entries = append(entries, domain.AuditEntry{
+			ActorID: job.UserID,
+			Action:  domain.AuditDelete,
+			LinkID:  link.ID,
+			Before:  before,
+			After:   &after,
+		})
+	}
+
+	if err := a.audit.Record(context.Background(), entries...); err != nil {
+		a.logger.Errorw("Failed to record audit log entries of a resumed job", "job", job.ID, "error", err)
+	}
+}
+
+// startPageFetcher fetches the destination pages of new links in the background unless PAGE_FETCH_TIMEOUT is 0
+func (a *App) startPageFetcher(pages service.PageMetaStorage) {
+	if a.cfg.PageFetchTimeout <= 0 {
+		return
+	}
+
+	a.pages = service.NewPageFetcher(pages, service.PageFetchOptions{
+		Policy:   a.policy,
+		Timeout:  a.cfg.PageFetchTimeout,
+		MaxBytes: a.cfg.PageFetchMaxBytes,
+	})
+}
+
+// sidecarFilePath derives the path of an auxiliary file kept next to the URL storage file, e.g. storage.json -> storage.users.json
+func sidecarFilePath(storagePath, name string) string {
+	ext := filepath.Ext(storagePath)
+	return strings.TrimSuffix(storagePath, ext) + "." + name + ext
+}
+
 func (a *App) initServer() {
-	handler := router.NewRouter(a.cfg, a.saver, a.getter, a.pinger, a.deleter)
+	var pages service.PageFetchServ
+	if a.pages != nil {
+		pages = a.pages
+	}
+
+	var jobs service.JobServ
+	if a.jobs != nil {
+		jobs = a.jobs
+	}
+
+	handler := router.NewRouter(a.cfg, router.Deps{
+		Tokens:     a.tokens,
+		Saver:      a.saver,
+		Getter:     a.getter,
+		Pinger:     a.pinger,
+		Deleter:    a.deleter,
+		Updater:    a.updater,
+		Auth:       a.auth,
+		Keys:       a.keys,
+		Policy:     a.policy,
+		Webhooks:   a.webhooks,
+		Workspaces: a.spaces,
+		Variants:   a.variants,
+		Rules:      a.rules,
+		Query:      a.query,
+		Pages:      pages,
+		Moderation: a.moderation,
+		Audit:      a.audit,
+		Jobs:       jobs,
+	})
 
 	a.server = &http.Server{
 		Addr:    a.cfg.ServerAddress,
@@ -169,6 +479,26 @@ func (a *App) Run() error {
 		a.logger.Fatalw("Server shutdown failed", "error", err)
 	}
 
+	// jobs finishing now still publish events, so they stop before webhooks
+	if a.jobs != nil {
+		a.jobs.Close()
+	}
+	a.webhooks.Close()
+	if a.checker != nil {
+		a.checker.Close()
+	}
+	if a.purger != nil {
+		a.purger.Close()
+	}
+	if a.pages != nil {
+		a.pages.Close()
+	}
+	if a.files != nil {
+		if err := a.files.Close(); err != nil {
+			a.logger.Errorw("Failed to write variant clicks", "error", err)
+		}
+	}
+
 	var wg sync.WaitGroup
 	waitGroupChan := make(chan struct{})
 	go func() {
@@ -11,6 +11,9 @@ import (
 	"github.com/golang/mock/gomock"
 
 	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+	"github.com/Te8va/shortURL/internal/app/policy"
 	"github.com/Te8va/shortURL/internal/app/router"
 	"github.com/Te8va/shortURL/internal/app/service/mocks"
 )
@@ -28,14 +31,25 @@ func initBenchmarkRouter(b *testing.B) {
 		mockGetter := mocks.NewMockURLGetterServ(ctrl)
 		mockDeleter := mocks.NewMockURLDeleteServ(ctrl)
 
-		mockSaver.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).Return("shortURL", nil).AnyTimes()
-		mockSaver.EXPECT().SaveBatch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
-		mockGetter.EXPECT().Get(gomock.Any(), gomock.Any()).Return("https://example.com", true, true).AnyTimes()
-		mockGetter.EXPECT().GetUserURLs(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
-		mockDeleter.EXPECT().DeleteUserURLs(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		mockSaver.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("shortURL", nil).AnyTimes()
+		mockSaver.EXPECT().SaveBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+		mockGetter.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.Redirect{OriginalURL: "https://example.com"}, true, true).AnyTimes()
+		mockGetter.EXPECT().GetUserURLs(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+		mockDeleter.EXPECT().DeleteUserURLs(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 
 		cfg := config.NewConfig()
-		r = router.NewRouter(cfg, mockSaver, mockGetter, nil, mockDeleter)
+		tokens := middleware.NewTokenManager(middleware.NewHMACKey(cfg.JWTKeyID, cfg.JWTKey), cfg.TokenTTL, false)
+		urlPolicy, err := policy.New(cfg.BaseURL, "")
+		if err != nil {
+			b.Fatal(err)
+		}
+		r = router.NewRouter(cfg, router.Deps{
+			Tokens:  tokens,
+			Saver:   mockSaver,
+			Getter:  mockGetter,
+			Deleter: mockDeleter,
+			Policy:  urlPolicy,
+		})
 	})
 }
 
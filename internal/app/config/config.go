@@ -2,35 +2,255 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
 
 // Config holds application configuration parameters
 type Config struct {
-	ServerAddress    string `env:"SERVER_ADDRESS" envDefault:"localhost:8080"`
-	BaseURL          string `env:"BASE_URL" envDefault:"http://localhost:8080"`
-	FileStoragePath  string `env:"FILE_STORAGE_PATH"`
-	PostgresUser     string `env:"POSTGRES_USER"         envDefault:"shortURL"`
-	PostgresPassword string `env:"POSTGRES_PASSWORD"     envDefault:"shortURL"`
-	PostgresDB       string `env:"POSTGRES_DB"     envDefault:"shortURL"`
-	PostgresPort     int    `env:"POSTGRES_PORT"         envDefault:"5432"`
-	DatabaseDSN      string `env:"DATABASE_DSN"`
-	JWTKey           string `env:"JWT_KEY"               envDefault:"supermegasecret"`
-	EnableHTTPS      bool
+	ServerAddress     string        `env:"SERVER_ADDRESS" envDefault:"localhost:8080"`
+	BaseURL           string        `env:"BASE_URL" envDefault:"http://localhost:8080"`
+	ShortDomains      []string      `env:"SHORT_DOMAINS"         envSeparator:","`
+	ShortDomainOwners []string      `env:"SHORT_DOMAIN_OWNERS"   envSeparator:","` // domain=userID pairs
+	FileStoragePath   string        `env:"FILE_STORAGE_PATH"`
+	PostgresUser      string        `env:"POSTGRES_USER"         envDefault:"shortURL"`
+	PostgresPassword  string        `env:"POSTGRES_PASSWORD"     envDefault:"shortURL"`
+	PostgresDB        string        `env:"POSTGRES_DB"     envDefault:"shortURL"`
+	PostgresPort      int           `env:"POSTGRES_PORT"         envDefault:"5432"`
+	DatabaseDSN       string        `env:"DATABASE_DSN"`
+	JWTKey            string        `env:"JWT_KEY"               envDefault:"supermegasecret"`
+	JWTKeyID          string        `env:"JWT_KEY_ID"            envDefault:"default"`
+	JWTPrivateKeyFile string        `env:"JWT_PRIVATE_KEY_FILE"`
+	JWTPreviousKeys   []string      `env:"JWT_PREVIOUS_KEYS"     envSeparator:","`
+	TokenTTL          time.Duration `env:"TOKEN_TTL"             envDefault:"720h"`
+	DevMode           bool          `env:"DEV_MODE"`
+	RateLimitShorten  RateLimit     `env:"RATE_LIMIT_SHORTEN"`
+	RateLimitBatch    RateLimit     `env:"RATE_LIMIT_BATCH"`
+	RateLimitRedirect RateLimit     `env:"RATE_LIMIT_REDIRECT"`
+	RateLimitDelete   RateLimit     `env:"RATE_LIMIT_DELETE"`
+	RateLimitReport   RateLimit     `env:"RATE_LIMIT_REPORT"`
+	RateLimitAuth     RateLimit     `env:"RATE_LIMIT_AUTH"       envDefault:"10/m"` // on by default against password guessing
+	TrustProxyHeaders bool          `env:"TRUST_PROXY_HEADERS"`
+	URLPolicyFile     string        `env:"URL_POLICY_FILE"`
+	ValidateRequests  bool          `env:"VALIDATE_REQUESTS"`
+	WebhookAttempts   int           `env:"WEBHOOK_MAX_ATTEMPTS"  envDefault:"5"`
+	WebhookBackoff    time.Duration `env:"WEBHOOK_BACKOFF"       envDefault:"1s"`
+	WebhookTimeout    time.Duration `env:"WEBHOOK_TIMEOUT"       envDefault:"10s"`
+	LinkCheckInterval time.Duration `env:"LINK_CHECK_INTERVAL"   envDefault:"24h"`
+	LinkCheckWorkers  int           `env:"LINK_CHECK_WORKERS"    envDefault:"8"`
+	LinkCheckDelay    time.Duration `env:"LINK_CHECK_HOST_DELAY" envDefault:"1s"`
+	LinkCheckTimeout  time.Duration `env:"LINK_CHECK_TIMEOUT"    envDefault:"10s"`
+	PageFetchTimeout  time.Duration `env:"PAGE_FETCH_TIMEOUT"    envDefault:"5s"`
+	PageFetchMaxBytes int64         `env:"PAGE_FETCH_MAX_BYTES"  envDefault:"524288"`
+	AdminUserIDs      []int         `env:"ADMIN_USER_IDS"        envSeparator:","`
+	DeletedRetention  time.Duration `env:"DELETED_RETENTION"     envDefault:"720h"` // PostgreSQL only, like deleting links
+	PurgeInterval     time.Duration `env:"PURGE_INTERVAL"        envDefault:"1h"`   // PostgreSQL only
+	JobRetention      time.Duration `env:"JOB_RETENTION"         envDefault:"24h"`  // PostgreSQL only
+	StreamMaxLines    int           `env:"STREAM_MAX_LINES"      envDefault:"10000"`
+	EnableHTTPS       bool
+}
+
+// RateLimit is a token bucket limit written as "<requests>/<period>", e.g. "60/m" or "5/10s".
+// Up to Requests may be made in a burst, after which the bucket refills evenly over Period. The zero value disables limiting
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled reports whether the limit should be enforced
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// UnmarshalText parses a limit in the "<requests>/<period>" form. The period is a duration or one of s, m, h
+func (l *RateLimit) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if s == "" || s == "0" {
+		*l = RateLimit{}
+		return nil
+	}
+
+	reqPart, periodPart, ok := strings.Cut(s, "/")
+	if !ok {
+		return fmt.Errorf("config: rate limit %q must look like 60/m", s)
+	}
+
+	requests, err := strconv.Atoi(reqPart)
+	if err != nil || requests < 0 {
+		return fmt.Errorf("config: rate limit %q has an invalid request count", s)
+	}
+
+	switch periodPart {
+	case "s", "m", "h":
+		periodPart = "1" + periodPart
+	}
+	period, err := time.ParseDuration(periodPart)
+	if err != nil || period <= 0 {
+		return fmt.Errorf("config: rate limit %q has an invalid period", s)
+	}
+
+	*l = RateLimit{Requests: requests, Period: period}
+	return nil
+}
+
+// DefaultJWTKey is the built-in JWT secret, only accepted in dev mode
+const DefaultJWTKey = "supermegasecret"
+
+// Validate reports configuration that is unsafe to run with
+func (c *Config) Validate() error {
+	if c.JWTPrivateKeyFile == "" && c.JWTKey == DefaultJWTKey && !c.DevMode {
+		return errors.New("config: JWT_KEY is not set; refusing to start with the default secret outside dev mode (-dev)")
+	}
+	if c.JWTKeyID == "" {
+		return errors.New("config: JWT_KEY_ID must not be empty")
+	}
+	if c.TokenTTL <= 0 {
+		return errors.New("config: TOKEN_TTL must be positive")
+	}
+	for _, d := range c.ShortDomains {
+		if _, _, err := c.parseDomain(d); err != nil {
+			return fmt.Errorf("config: SHORT_DOMAINS: %w", err)
+		}
+	}
+	for _, o := range c.ShortDomainOwners {
+		host, _, err := c.parseOwner(o)
+		if err != nil {
+			return fmt.Errorf("config: SHORT_DOMAIN_OWNERS: %w", err)
+		}
+		if d, ok := c.Domain(host); !ok || d == "" {
+			return fmt.Errorf("config: SHORT_DOMAIN_OWNERS: %q is not one of SHORT_DOMAINS", host)
+		}
+	}
+	return nil
+}
+
+// BaseDomain returns the canonical host of BaseURL, links on it are stored with the empty domain
+func (c *Config) BaseDomain() string {
+	host, _, err := c.parseDomain(c.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// Domain returns the form links on host are stored with: "" for the host of BaseURL, so that its links follow BaseURL
+// when it changes, and the canonical host for ShortDomains. ok is false if host is not registered
+func (c *Config) Domain(host string) (string, bool) {
+	host = normalizeDomain(host)
+	if host == "" {
+		return "", false
+	}
+	if base, _, err := c.parseDomain(c.BaseURL); err == nil && base == host {
+		return "", true
+	}
+	for _, d := range c.ShortDomains {
+		if h, _, err := c.parseDomain(d); err == nil && h == host {
+			return host, true
+		}
+	}
+	return "", false
+}
+
+// ResolveDomain returns the stored form of host, falling back to the BaseURL domain for unknown hosts
+func (c *Config) ResolveDomain(host string) string {
+	d, _ := c.Domain(host)
+	return d
+}
+
+// DomainAllowed reports whether the user may create links on domain, given in its stored form. Domains without
+// owners in ShortDomainOwners are open to every user, the BaseURL domain always is
+func (c *Config) DomainAllowed(domain string, userID int) bool {
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return true
+	}
+
+	owned := false
+	for _, o := range c.ShortDomainOwners {
+		host, owner, err := c.parseOwner(o)
+		if err != nil || host != domain {
+			continue
+		}
+		if owner == userID {
+			return true
+		}
+		owned = true
+	}
+	return !owned
+}
+
+// ShortURL composes the short link for id on domain. The BaseURL domain "" and unknown domains are served from BaseURL
+func (c *Config) ShortURL(domain, id string) string {
+	base := strings.TrimSuffix(c.BaseURL, "/")
+	for _, d := range c.ShortDomains {
+		if host, b, err := c.parseDomain(d); err == nil && host != "" && host == normalizeDomain(domain) {
+			base = b
+			break
+		}
+	}
+	return base + "/" + id
+}
+
+// parseDomain accepts a host or a base URL, hosts are served with the scheme of BaseURL
+func (c *Config) parseDomain(raw string) (string, string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		scheme := "http"
+		if base, err := url.Parse(c.BaseURL); err == nil && base.Scheme != "" {
+			scheme = base.Scheme
+		}
+		raw = scheme + "://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", "", fmt.Errorf("invalid short domain %q", raw)
+	}
+
+	return normalizeDomain(u.Host), u.Scheme + "://" + u.Host, nil
+}
+
+// parseOwner splits a domain=userID pair of ShortDomainOwners into the canonical host and the user ID
+func (c *Config) parseOwner(raw string) (string, int, error) {
+	domain, id, ok := strings.Cut(strings.TrimSpace(raw), "=")
+	if !ok {
+		return "", 0, fmt.Errorf("domain owner %q must look like go.example.com=7", raw)
+	}
+
+	userID, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil || userID <= 0 {
+		return "", 0, fmt.Errorf("domain owner %q has an invalid user ID", raw)
+	}
+
+	host, _, err := c.parseDomain(domain)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, userID, nil
+}
+
+func normalizeDomain(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
 }
 
 // ConfigFile describes JSON configuration file format
 type ConfigFile struct {
-	ServerAddress   string `json:"server_address"`
-	BaseURL         string `json:"base_url"`
-	FileStoragePath string `json:"file_storage_path"`
-	DatabaseDSN     string `json:"database_dsn"`
-	EnableHTTPS     bool   `json:"enable_https"`
+	ServerAddress     string   `json:"server_address"`
+	BaseURL           string   `json:"base_url"`
+	FileStoragePath   string   `json:"file_storage_path"`
+	DatabaseDSN       string   `json:"database_dsn"`
+	EnableHTTPS       bool     `json:"enable_https"`
+	ShortDomains      []string `json:"short_domains"`
+	ShortDomainOwners []string `json:"short_domain_owners"`
 }
 
 func loadFromFile(path string) (*ConfigFile, error) {
@@ -62,6 +282,7 @@ func NewConfig() *Config {
 	httpsFlag := flag.Bool("s", false, "Enable HTTPS")
 	configPathFlag := flag.String("c", "", "Path to config file (JSON)")
 	configPathFlagLong := flag.String("config", "", "Path to config file (JSON)")
+	devFlag := flag.Bool("dev", false, "Development mode: allow the default JWT secret")
 
 	flag.Parse()
 
@@ -81,6 +302,12 @@ func NewConfig() *Config {
 			cfg.FileStoragePath = cfgFile.FileStoragePath
 			cfg.DatabaseDSN = cfgFile.DatabaseDSN
 			cfg.EnableHTTPS = cfgFile.EnableHTTPS
+			if len(cfgFile.ShortDomains) > 0 {
+				cfg.ShortDomains = cfgFile.ShortDomains
+			}
+			if len(cfgFile.ShortDomainOwners) > 0 {
+				cfg.ShortDomainOwners = cfgFile.ShortDomainOwners
+			}
 		}
 	}
 
@@ -100,6 +327,10 @@ func NewConfig() *Config {
 		cfg.DatabaseDSN = *databaseDSNFlag
 	}
 
+	if *devFlag {
+		cfg.DevMode = true
+	}
+
 	cfg.EnableHTTPS = *httpsFlag
 	if secureEnv, exists := os.LookupEnv("ENABLE_HTTPS"); exists || !cfg.EnableHTTPS {
 		if val, err := strconv.ParseBool(secureEnv); err == nil {
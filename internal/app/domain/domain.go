@@ -1,8 +1,15 @@
 package domain
 
-// ShortenRequest represents request to URL.
+import (
+	"encoding/json"
+	"time"
+)
+
+// ShortenRequest represents request to URL. Domain picks one of the registered short domains.
 type ShortenRequest struct {
-	URL string `json:"url"`
+	URL    string `json:"url"`
+	Domain string `json:"domain,omitempty"`
+	LinkMeta
 }
 
 // ShortenResponse represents response containing userID .
@@ -10,7 +17,532 @@ type ShortenResponse struct {
 	Result string `json:"result"`
 }
 
+// Link represents a stored short link. Only the bare ID and the domain are persisted, ShortURL is composed when read.
+type Link struct {
+	ID          string `json:"id"`
+	Domain      string `json:"domain"`
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+	UserID      int    `json:"-"`
+	LinkMeta
+	Health *LinkHealth `json:"health,omitempty"`
+	Page   *PageMeta   `json:"page,omitempty"`
+}
+
+// LinkRecord is a link as operators see it, including whether and when it was deleted and why it was taken down.
+type LinkRecord struct {
+	Link
+	Deleted        bool       `json:"deleted"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	TakedownReason string     `json:"takedown_reason,omitempty"`
+}
+
+// Outcomes of restoring a deleted link.
+const (
+	RestoreRestored   = "restored"
+	RestoreNotFound   = "not_found"
+	RestoreNotDeleted = "not_deleted"
+	RestoreExpired    = "expired"
+	RestoreConflict   = "conflict"
+)
+
+// RestoreResult reports what happened to one of the links a user asked to restore. Links that do not exist, belong to
+// someone else or were taken down by an operator are all not found; conflict means the URL was shortened again.
+type RestoreResult struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	ShortURL string `json:"short_url,omitempty"`
+}
+
+// Job types.
+const (
+	JobDelete = "delete"
+)
+
+// Job states. A job is queued until a worker picks it up and ends up done or, if it could not run to the end, failed.
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Outcomes of deleting a link in a job.
+const (
+	JobResultDeleted  = "deleted"
+	JobResultNotFound = "not_found"
+	JobResultNotOwned = "not_owned"
+)
+
+// Job is a bulk operation a user requested and that runs in the background.
+type Job struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	State      string      `json:"state"`
+	UserID     int         `json:"-"`
+	IDs        []string    `json:"ids"`
+	Results    []JobResult `json:"results,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+}
+
+// JobResult reports what a job did to one of the links it was given.
+type JobResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// InstanceStats counts the links and users of a shortener instance. Deleted links include those taken down.
+type InstanceStats struct {
+	Links      int64 `json:"links"`
+	Deleted    int64 `json:"deleted"`
+	TakenDown  int64 `json:"taken_down"`
+	Users      int64 `json:"users"`
+	Accounts   int64 `json:"accounts"`
+	Workspaces int64 `json:"workspaces"`
+}
+
+// PageMeta is what the destination page of a link tells about itself, fetched in the background after the link
+// is created.
+type PageMeta struct {
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Image       string    `json:"image,omitempty"`
+	Favicon     string    `json:"favicon,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// LinkMeta holds the workspace a link belongs to and the title, notes and tags the user organises it with.
+// Links without a workspace are personal links of the user who created them.
+type LinkMeta struct {
+	Workspace string   `json:"workspace,omitempty"`
+	Title     string   `json:"title,omitempty"`
+	Notes     string   `json:"notes,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// LinkUpdate represents a partial update of link metadata. Fields left out are kept.
+type LinkUpdate struct {
+	Title *string   `json:"title"`
+	Notes *string   `json:"notes"`
+	Tags  *[]string `json:"tags"`
+}
+
+// LinkFilter narrows down the user's links to those carrying Tag, matching every word of Query and, if Health is
+// set, in that health state. If Workspace is set, the links of that workspace are listed instead of the user's
+// personal links.
+type LinkFilter struct {
+	Workspace string
+	Tag       string
+	Query     string
+	Health    string
+}
+
+// Health states links can be filtered by.
+const (
+	HealthOK        = "ok"
+	HealthBroken    = "broken"
+	HealthUnchecked = "unchecked"
+)
+
+// LinkHealth is the outcome of the last check of the original URL of a link. Status is the status code of the final
+// response, Redirects the URLs it was redirected through and Error why no response was received.
+type LinkHealth struct {
+	Status    int       `json:"status,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Redirects []string  `json:"redirects,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Broken reports whether the original URL could not be reached or answered with an error
+func (h LinkHealth) Broken() bool {
+	return h.Error != "" || h.Status >= 400
+}
+
+// HealthState returns the health state of a link with the given last check, which is nil for unchecked links
+func HealthState(h *LinkHealth) string {
+	switch {
+	case h == nil:
+		return HealthUnchecked
+	case h.Broken():
+		return HealthBroken
+	default:
+		return HealthOK
+	}
+}
+
+// Variant is one of the destinations of an A/B link. Visitors are split across the variants of a link in proportion
+// to their weights, and Clicks counts the redirects to each variant.
+type Variant struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+	Clicks int64  `json:"clicks"`
+}
+
+// Platforms a redirect rule can match, detected from the User-Agent of the visitor.
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWindows = "windows"
+	PlatformMacOS   = "macos"
+	PlatformLinux   = "linux"
+	PlatformOther   = "other"
+)
+
+// RedirectRule sends the visitors of a link matching all of its conditions to URL. Rules of a link are evaluated in
+// order and the first matching one wins, visitors matching none go to the link's own destination. Languages match the
+// preferred language of the Accept-Language header, "pt" also matching "pt-BR". TimeFrom and TimeTo limit the rule to
+// a time of day as "HH:MM" in Timezone, UTC by default, and wrap around midnight if TimeTo is earlier than TimeFrom.
+type RedirectRule struct {
+	Platforms []string `json:"platforms,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+	TimeFrom  string   `json:"time_from,omitempty"`
+	TimeTo    string   `json:"time_to,omitempty"`
+	Timezone  string   `json:"timezone,omitempty"`
+	URL       string   `json:"url"`
+}
+
+// Redirect is what following a short link needs, looked up together with the link: its original URL, its redirect
+// rules and its query string options.
+type Redirect struct {
+	OriginalURL string
+	Rules       []RedirectRule
+	Query       QueryOptions
+}
+
+// Ways of resolving a forwarded query parameter that the destination of a link already has.
+const (
+	QueryConflictKeep     = "keep"
+	QueryConflictOverride = "override"
+	QueryConflictAppend   = "append"
+)
+
+// UTM holds the campaign parameters added to the destination of a link.
+type UTM struct {
+	Source   string `json:"utm_source,omitempty"`
+	Medium   string `json:"utm_medium,omitempty"`
+	Campaign string `json:"utm_campaign,omitempty"`
+	Term     string `json:"utm_term,omitempty"`
+	Content  string `json:"utm_content,omitempty"`
+}
+
+// QueryOptions controls the query string of the redirects of a link. UTM parameters are added unless the destination
+// already sets them. With Forward the query string of the short link is passed on to the destination, and Conflict
+// decides what happens to keys the destination already has: keep its value, the default, override it with the
+// forwarded one, or append the forwarded values.
+type QueryOptions struct {
+	UTM      UTM    `json:"utm"`
+	Forward  bool   `json:"forward"`
+	Conflict string `json:"conflict,omitempty"`
+}
+
+// AuthRequest represents credentials sent to register or log in.
+type AuthRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// AuthResponse represents the account the client is authenticated as.
+type AuthResponse struct {
+	UserID int `json:"user_id"`
+}
+
+// User represents a registered user account.
+type User struct {
+	ID           int       `json:"id"`
+	Login        string    `json:"login"`
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// APIKeyRequest represents a request to create an API key.
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// APIKey represents a personal API key. Only the hash of the secret is stored.
+type APIKey struct {
+	ID        string     `json:"id"`
+	UserID    int        `json:"-"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Hash      string     `json:"-"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyResponse represents a newly created API key together with its secret, which is shown only once.
+type APIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// API key scopes. A key without scopes may be used for every operation.
+const (
+	ScopeShorten = "shorten"
+	ScopeRead    = "read"
+	ScopeDelete  = "delete"
+)
+
+// WebhookRequest represents a request to subscribe to link events.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+}
+
+// Webhook represents a subscription that receives signed link events. A webhook without events receives all of them.
+type Webhook struct {
+	ID        string    `json:"id"`
+	UserID    int       `json:"-"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookResponse represents a newly created webhook together with its signing secret, which is shown only once.
+type WebhookResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// LinkEvent describes something that happened to a short link.
+type LinkEvent struct {
+	Type        string `json:"-"`
+	UserID      int    `json:"-"`
+	ID          string `json:"id"`
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url,omitempty"`
+}
+
+// WebhookPayload represents the JSON body delivered to webhooks.
+type WebhookPayload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      LinkEvent `json:"data"`
+}
+
+// WebhookDeadLetter represents a delivery that failed after every retry.
+type WebhookDeadLetter struct {
+	ID         string          `json:"id"`
+	WebhookID  string          `json:"webhook_id"`
+	DeliveryID string          `json:"delivery_id"`
+	UserID     int             `json:"-"`
+	Event      string          `json:"event"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	LastError  string          `json:"last_error"`
+	FailedAt   time.Time       `json:"failed_at"`
+}
+
+// WorkspaceRequest represents a request to create a workspace.
+type WorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// Workspace represents a group of users sharing links. Role is the role of the user the workspace is listed for.
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MemberRequest represents a request to add a user to a workspace or change their role.
+type MemberRequest struct {
+	Role string `json:"role"`
+}
+
+// WorkspaceMember represents the membership of a registered user in a workspace.
+type WorkspaceMember struct {
+	WorkspaceID string    `json:"-"`
+	UserID      int       `json:"user_id"`
+	Role        string    `json:"role"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// Workspace roles. Owners manage members, editors also create, move and delete links, viewers only list them.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Link event types delivered to webhooks.
+const (
+	EventLinkCreated = "link.created"
+	EventLinkClicked = "link.clicked"
+	EventLinkDeleted = "link.deleted"
+)
+
+// Reasons abuse reports can be filed for.
+const (
+	ReportPhishing = "phishing"
+	ReportMalware  = "malware"
+	ReportSpam     = "spam"
+	ReportOther    = "other"
+)
+
+// AbuseReportRequest represents a report of a short link filed by a visitor.
+type AbuseReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details,omitempty"`
+}
+
+// AbuseReport represents a report of a short link waiting for a moderator until ResolvedAt is set. The address of the
+// reporter is kept for spotting floods of reports but never shown.
+type AbuseReport struct {
+	ID         string     `json:"id"`
+	LinkID     string     `json:"link_id"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details,omitempty"`
+	ReporterIP string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Moderation queues: links with reports waiting for review and links disabled by moderators.
+const (
+	QueuePending  = "pending"
+	QueueDisabled = "disabled"
+)
+
+// ModerationCase summarises the reports of a link for moderators. Reasons lists the distinct reasons of its pending
+// reports.
+type ModerationCase struct {
+	LinkID          string     `json:"link_id"`
+	Domain          string     `json:"-"`
+	ShortURL        string     `json:"short_url"`
+	OriginalURL     string     `json:"original_url"`
+	UserID          int        `json:"user_id"`
+	Disabled        bool       `json:"disabled"`
+	PendingReports  int        `json:"pending_reports"`
+	Reasons         []string   `json:"reasons"`
+	FirstReportedAt *time.Time `json:"first_reported_at,omitempty"`
+	LastReportedAt  *time.Time `json:"last_reported_at,omitempty"`
+}
+
+// ModerationReview is everything a moderator needs to decide on a link: its case, all of its reports and the
+// decisions taken on it before, newest first.
+type ModerationReview struct {
+	ModerationCase
+	Reports []AbuseReport      `json:"reports"`
+	Actions []ModerationAction `json:"actions"`
+}
+
+// Moderator actions. Disabling a link stops its redirects, clearing it lets it redirect again. Both resolve its
+// pending reports.
+const (
+	ModerationDisable = "disable"
+	ModerationClear   = "clear"
+)
+
+// ModerationRequest represents a moderator's decision on a link.
+type ModerationRequest struct {
+	Action string `json:"action"`
+	Note   string `json:"note,omitempty"`
+}
+
+// ModerationAction is an entry of the audit trail of moderator decisions.
+type ModerationAction struct {
+	ID          string    `json:"id"`
+	LinkID      string    `json:"link_id"`
+	ModeratorID int       `json:"moderator_id"`
+	Action      string    `json:"action"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Audited actions on links.
+const (
+	AuditCreate      = "create"
+	AuditBatchCreate = "batch_create"
+	AuditUpdate      = "update"
+	AuditDelete      = "delete"
+	AuditRestore     = "restore"
+	AuditVariants    = "set_variants"
+	AuditRules       = "set_rules"
+	AuditQuery       = "set_query"
+	AuditTransfer    = "transfer"
+	AuditModerate    = "moderate"
+	AuditTakedown    = "takedown"
+)
+
+// AuditState is the state of a link before or after an audited change. Variants, Rules, Query, Disabled and
+// TakedownReason are only filled in by the changes of them.
+type AuditState struct {
+	OriginalURL    string         `json:"original_url"`
+	Workspace      string         `json:"workspace,omitempty"`
+	Title          string         `json:"title,omitempty"`
+	Notes          string         `json:"notes,omitempty"`
+	Tags           []string       `json:"tags,omitempty"`
+	Deleted        bool           `json:"deleted,omitempty"`
+	Variants       []Variant      `json:"variants,omitempty"`
+	Rules          []RedirectRule `json:"rules,omitempty"`
+	Query          *QueryOptions  `json:"query,omitempty"`
+	Disabled       bool           `json:"disabled,omitempty"`
+	TakedownReason string         `json:"takedown_reason,omitempty"`
+}
+
+// NewAuditState returns the audited state of the link.
+func NewAuditState(link Link) *AuditState {
+	return &AuditState{
+		OriginalURL: link.OriginalURL,
+		Workspace:   link.Workspace,
+		Title:       link.Title,
+		Notes:       link.Notes,
+		Tags:        link.Tags,
+	}
+}
+
+// AuditEntry records who changed a link, from where and how. Before is nil for created links.
+type AuditEntry struct {
+	ID        string      `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	ActorID   int         `json:"actor_id"`
+	IP        string      `json:"ip"`
+	Action    string      `json:"action"`
+	LinkID    string      `json:"link_id"`
+	Before    *AuditState `json:"before,omitempty"`
+	After     *AuditState `json:"after,omitempty"`
+}
+
+// AuditFilter narrows down the audit log. Zero fields match every entry, Limit 0 lists all of them.
+type AuditFilter struct {
+	ActorID int
+	LinkID  string
+	Action  string
+	IP      string
+	From    time.Time
+	To      time.Time
+	Limit   int
+}
+
+// Match reports whether the entry passes the filter, ignoring Limit.
+func (f AuditFilter) Match(entry AuditEntry) bool {
+	return (f.ActorID == 0 || entry.ActorID == f.ActorID) &&
+		(f.LinkID == "" || entry.LinkID == f.LinkID) &&
+		(f.Action == "" || entry.Action == f.Action) &&
+		(f.IP == "" || entry.IP == f.IP) &&
+		(f.From.IsZero() || !entry.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || entry.CreatedAt.Before(f.To))
+}
+
+// FirstRegisteredUserID is the lowest ID given to registered accounts; anonymous IDs are always below it.
+const FirstRegisteredUserID = 1000000
+
 type contextKey string
 
 // UserIDKey is the key used to store the user ID in context
 const UserIDKey contextKey = "userID"
+
+// RegisteredKey is the key used to store in context whether the user ID belongs to a registered account
+const RegisteredKey contextKey = "registered"
+
+// ScopesKey is the key used to store the scopes of the API key in context. It is absent for cookie sessions
+const ScopesKey contextKey = "scopes"
@@ -6,8 +6,34 @@ import "errors"
 var (
 	// ErrURLExists indicates that the given URL already exists
 	ErrURLExists = errors.New("URL уже существует")
+	// ErrTakenDown indicates that the URL belongs to a link taken down by an operator and can not be shortened again
+	ErrTakenDown = errors.New("URL заблокирован оператором")
 	// ErrDeleted indicates that the resource has been deleted
 	ErrDeleted = errors.New("удалено")
 	// ErrNotFound indicates that the specified URL was not found
 	ErrNotFound = errors.New("URL не найден")
+	// ErrUserExists indicates that the login is already taken
+	ErrUserExists = errors.New("пользователь уже существует")
+	// ErrInvalidCredentials indicates that the login or password is wrong
+	ErrInvalidCredentials = errors.New("неверный логин или пароль")
+	// ErrWeakCredentials indicates that the login is empty or the password is too short or too long
+	ErrWeakCredentials = errors.New("логин не может быть пустым, а пароль должен содержать от 8 до 72 байт")
+	// ErrInvalidAPIKey indicates that the API key is unknown or revoked
+	ErrInvalidAPIKey = errors.New("недействительный API-ключ")
+	// ErrInvalidScope indicates that an unknown API key scope was requested
+	ErrInvalidScope = errors.New("неизвестная область действия API-ключа")
+	// ErrInvalidWebhook indicates that the webhook URL is not an absolute http(s) URL or an unknown event was requested
+	ErrInvalidWebhook = errors.New("некорректный URL или события вебхука")
+	// ErrInvalidWorkspace indicates that the workspace name or member role is invalid or the member is not a registered user
+	ErrInvalidWorkspace = errors.New("некорректное название рабочего пространства, роль или участник")
+	// ErrForbidden indicates that the user's workspace role does not allow the operation
+	ErrForbidden = errors.New("недостаточно прав в рабочем пространстве")
+	// ErrLastOwner indicates that the operation would leave a workspace without an owner
+	ErrLastOwner = errors.New("в рабочем пространстве должен остаться владелец")
+	// ErrInvalidReport indicates that the abuse report has an unknown reason or its details are too long
+	ErrInvalidReport = errors.New("некорректная причина или описание жалобы")
+	// ErrInvalidModeration indicates that an unknown moderator action was requested
+	ErrInvalidModeration = errors.New("некорректное действие модератора")
+	// ErrInvalidAuditFilter indicates that the audit log was queried with an unknown action, an empty time range or a too large limit
+	ErrInvalidAuditFilter = errors.New("некорректный фильтр журнала аудита")
 )
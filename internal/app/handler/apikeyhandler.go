@@ -0,0 +1,110 @@
+// package handler contains handlers for managing personal API keys.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+// APIKeyManager defines an interface for creating, listing and revoking API keys.
+//
+//go:generate mockgen -source=apikeyhandler.go -destination=mocks/api_key_mock.gen.go -package=mocks
+type APIKeyManager interface {
+	CreateAPIKey(ctx context.Context, userID int, name string, scopes []string) (domain.APIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, userID int) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID int, id string) error
+}
+
+// APIKeyHandler handles requests for managing API keys.
+type APIKeyHandler struct {
+	keys APIKeyManager
+}
+
+// NewAPIKeyHandler creates a new instance of APIKeyHandler.
+func NewAPIKeyHandler(keys APIKeyManager) *APIKeyHandler {
+	return &APIKeyHandler{keys: keys}
+}
+
+// CreateAPIKeyHandler processes requests to create an API key. The plain key is only returned in this response.
+func (u *APIKeyHandler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var req domain.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	key, err := u.keys.CreateAPIKey(r.Context(), userID, req.Name, req.Scopes)
+	if errors.Is(err, appErrors.ErrInvalidScope) {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidScope)
+		return
+	} else if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(key); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ListAPIKeysHandler processes requests to list the user's API keys.
+func (u *APIKeyHandler) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	keys, err := u.keys.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	if keys == nil {
+		keys = []domain.APIKey{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(keys); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// RevokeAPIKeyHandler processes requests to revoke one of the user's API keys.
+func (u *APIKeyHandler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	err := u.keys.RevokeAPIKey(r.Context(), userID, chi.URLParam(r, "id"))
+	if errors.Is(err, appErrors.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeAPIKeyNotFound)
+		return
+	} else if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,174 @@
+// package handler contains handlers for searching and exporting the audit log of link changes.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+const contentTypeNDJSON = "application/x-ndjson"
+
+// AuditRecorder defines an interface for recording changes of links in the audit log.
+//
+//go:generate mockgen -source=audithandler.go -destination=mocks/audit_mock.gen.go -package=mocks
+type AuditRecorder interface {
+	Snapshot(ctx context.Context, linkID string) (*domain.AuditState, error)
+	Record(ctx context.Context, entries ...domain.AuditEntry) error
+}
+
+// AuditLog defines an interface for searching and exporting the audit log.
+type AuditLog interface {
+	Query(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	Export(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// auditEntry describes a change of a link made by the request.
+func auditEntry(r *http.Request, trustProxy bool, action, linkID string, before, after *domain.AuditState) domain.AuditEntry {
+	actorID, _ := r.Context().Value(domain.UserIDKey).(int)
+	return domain.AuditEntry{
+		ActorID: actorID,
+		IP:      middleware.ClientIP(r, trustProxy),
+		Action:  action,
+		LinkID:  linkID,
+		Before:  before,
+		After:   after,
+	}
+}
+
+// record appends the entries to the audit log if one is configured. Failures are logged, the change itself is done.
+func record(ctx context.Context, audit AuditRecorder, entries ...domain.AuditEntry) {
+	if audit == nil || len(entries) == 0 {
+		return
+	}
+	if err := audit.Record(ctx, entries...); err != nil {
+		log.Println("Failed to record audit log entries:", err)
+	}
+}
+
+// snapshot returns the state of the link before a change if the audit log is configured.
+func snapshot(ctx context.Context, audit AuditRecorder, linkID string) *domain.AuditState {
+	if audit == nil {
+		return nil
+	}
+	state, err := audit.Snapshot(ctx, linkID)
+	if err != nil {
+		log.Println("Failed to snapshot link for the audit log:", err)
+	}
+	return state
+}
+
+// changed returns a copy of the state before a change with the change applied, nil if that state is not known.
+func changed(before *domain.AuditState, change func(after *domain.AuditState)) *domain.AuditState {
+	if before == nil {
+		return nil
+	}
+	after := *before
+	change(&after)
+	return &after
+}
+
+// AuditHandler handles requests of administrators for the audit log.
+type AuditHandler struct {
+	audit AuditLog
+}
+
+// NewAuditHandler creates a new instance of AuditHandler.
+func NewAuditHandler(audit AuditLog) *AuditHandler {
+	return &AuditHandler{audit: audit}
+}
+
+// QueryHandler processes requests to search the audit log, newest entries first. The actor, link, action and ip
+// query parameters narrow it down, from and to bound the time range and limit caps the number of entries.
+func (u *AuditHandler) QueryHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter).WithDetail(err.Error()).Write(w)
+		return
+	}
+
+	entries, err := u.audit.Query(r.Context(), filter)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	if entries == nil {
+		entries = []domain.AuditEntry{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ExportHandler processes requests to download the whole audit log, or the part matching the same filters as
+// QueryHandler, as newline-delimited JSON.
+func (u *AuditHandler) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter).WithDetail(err.Error()).Write(w)
+		return
+	}
+
+	entries, err := u.audit.Export(r.Context(), filter)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set(contentType, contentTypeNDJSON)
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			log.Println("Failed to write response:", err)
+			return
+		}
+	}
+}
+
+// parseAuditFilter reads the filters of the audit log from the query string.
+func parseAuditFilter(r *http.Request) (domain.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := domain.AuditFilter{
+		LinkID: q.Get("link"),
+		Action: q.Get("action"),
+		IP:     q.Get("ip"),
+	}
+
+	var err error
+	if v := q.Get("actor"); v != "" {
+		if filter.ActorID, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("actor must be a user ID")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("limit must be a number")
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("from must be an RFC 3339 time")
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("to must be an RFC 3339 time")
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,52 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/handler"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+)
+
+type mockAuth struct{}
+
+func (m mockAuth) Register(ctx context.Context, login, password string) (int, error) {
+	return 1000001, nil
+}
+
+func (m mockAuth) Login(ctx context.Context, login, password string) (int, error) {
+	return 1000001, nil
+}
+
+func (m mockAuth) ClaimURLs(ctx context.Context, fromUserID, toUserID int) error {
+	return nil
+}
+
+func ExampleAuthHandler_RegisterHandler() {
+	h := handler.NewAuthHandler(mockAuth{}, middleware.NewTokenManager(middleware.NewHMACKey("example", "secret"), time.Hour, false))
+	r := chi.NewRouter()
+	r.Post("/api/auth/register", h.RegisterHandler)
+
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	body := bytes.NewBufferString(`{"login":"alice","password":"password123"}`)
+	resp, err := http.Post(ts.URL+"/api/auth/register", "application/json", body)
+	if err != nil {
+		fmt.Println("request failed:", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	fmt.Println(resp.StatusCode)
+	fmt.Println(len(resp.Cookies()) > 0)
+	// Output:
+	// 201
+	// true
+}
@@ -0,0 +1,97 @@
+// package handler contains handlers for registering, logging in and logging out users.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+// UserAuthenticator defines an interface for registering and authenticating users.
+//
+//go:generate mockgen -source=authhandler.go -destination=mocks/user_auth_mock.gen.go -package=mocks
+type UserAuthenticator interface {
+	Register(ctx context.Context, login, password string) (int, error)
+	Login(ctx context.Context, login, password string) (int, error)
+	ClaimURLs(ctx context.Context, fromUserID, toUserID int) error
+}
+
+// AuthHandler handles requests for user accounts.
+type AuthHandler struct {
+	auth   UserAuthenticator
+	tokens *middleware.TokenManager
+}
+
+// NewAuthHandler creates a new instance of AuthHandler.
+func NewAuthHandler(auth UserAuthenticator, tokens *middleware.TokenManager) *AuthHandler {
+	return &AuthHandler{auth: auth, tokens: tokens}
+}
+
+// RegisterHandler processes requests to create an account. Links of the current anonymous user are moved to it.
+func (u *AuthHandler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.AuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	userID, err := u.auth.Register(r.Context(), req.Login, req.Password)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	u.signIn(w, r, userID, http.StatusCreated)
+}
+
+// LoginHandler processes requests to log in. Links of the current anonymous user are moved to the account.
+func (u *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.AuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	userID, err := u.auth.Login(r.Context(), req.Login, req.Password)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	u.signIn(w, r, userID, http.StatusOK)
+}
+
+// LogoutHandler processes requests to log out. The next request falls back to an anonymous user.
+func (u *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	u.tokens.ClearCookie(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (u *AuthHandler) signIn(w http.ResponseWriter, r *http.Request, userID int, status int) {
+	anonymousID, ok := r.Context().Value(domain.UserIDKey).(int)
+	registered, _ := r.Context().Value(domain.RegisteredKey).(bool)
+	if ok && !registered {
+		if err := u.auth.ClaimURLs(r.Context(), anonymousID, userID); err != nil {
+			problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+			return
+		}
+	}
+
+	tokenString, err := u.tokens.Issue(userID, true)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+	u.tokens.SetCookie(w, tokenString)
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(domain.AuthResponse{UserID: userID}); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
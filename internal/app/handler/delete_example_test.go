@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -17,13 +18,23 @@ import (
 
 type mockDeleter struct{}
 
-func (m mockDeleter) DeleteUserURLs(ctx context.Context, ids []string, userID int) error {
-	return nil
+func (m mockDeleter) DeleteUserURLs(ctx context.Context, ids []string, userID int) ([]domain.Link, error) {
+	return nil, nil
+}
+
+func (m mockDeleter) RestoreUserURLs(ctx context.Context, ids []string, userID int, deletedAfter time.Time) ([]domain.RestoreResult, []domain.Link, error) {
+	return nil, nil, nil
+}
+
+type mockJobs struct{}
+
+func (m mockJobs) SubmitDelete(ctx context.Context, userID int, ids []string, deleted func(links []domain.Link)) (domain.Job, error) {
+	return domain.Job{ID: "j1", Type: domain.JobDelete, State: domain.JobQueued, UserID: userID, IDs: ids}, nil
 }
 
 func ExampleDeleteHandler_DeleteUserURLsHandler() {
 	cfg := &config.Config{BaseURL: "http://localhost:8080"}
-	h := handler.NewDeleteHandler(mockDeleter{}, cfg)
+	h := handler.NewDeleteHandler(mockDeleter{}, cfg, handler.DeleteOptions{Jobs: mockJobs{}})
 
 	r := chi.NewRouter()
 	r.Delete("/user/urls", func(w http.ResponseWriter, r *http.Request) {
@@ -4,62 +4,157 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/Te8va/shortURL/internal/app/config"
 	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/problem"
 )
 
-// URLDelete defines an interface for deleting user URLs
+// URLDelete defines an interface for deleting and restoring user URLs
 //
 //go:generate mockgen -source=deletehandler.go -destination=mocks/url_delete_mock.gen.go -package=mocks
 type URLDelete interface {
-	DeleteUserURLs(ctx context.Context, ids []string, userID int) error
+	DeleteUserURLs(ctx context.Context, ids []string, userID int) ([]domain.Link, error)
+	RestoreUserURLs(ctx context.Context, ids []string, userID int, deletedAfter time.Time) ([]domain.RestoreResult, []domain.Link, error)
 }
 
-// DeleteHandler handles requests for deleting user URLs
+// DeleteJobSubmitter defines an interface for running deletions of user URLs as background jobs
+type DeleteJobSubmitter interface {
+	SubmitDelete(ctx context.Context, userID int, ids []string, deleted func(links []domain.Link)) (domain.Job, error)
+}
+
+// DeleteHandler handles requests for deleting and restoring user URLs
 type DeleteHandler struct {
 	deleter URLDelete
 	cfg     *config.Config
+	events  LinkEventPublisher
+	audit   AuditRecorder
+	jobs    DeleteJobSubmitter
+}
+
+// DeleteOptions holds the optional collaborators of DeleteHandler. Any of them may be nil, without Jobs deletions
+// answer 501.
+type DeleteOptions struct {
+	Events LinkEventPublisher
+	Audit  AuditRecorder
+	Jobs   DeleteJobSubmitter
 }
 
 // NewDeleteHandler creates a new instance of DeleteHandler.
-func NewDeleteHandler(deleter URLDelete, cfg *config.Config) *DeleteHandler {
-	return &DeleteHandler{deleter: deleter, cfg: cfg}
+func NewDeleteHandler(deleter URLDelete, cfg *config.Config, opts DeleteOptions) *DeleteHandler {
+	return &DeleteHandler{deleter: deleter, cfg: cfg, events: opts.Events, audit: opts.Audit, jobs: opts.Jobs}
 }
 
-// DeleteUserURLsHandler processes requests to delete user URLs.
+// DeleteUserURLsHandler processes requests to delete user URLs. The deletion runs in the background, the response
+// points to the job that reports its outcome.
 func (u *DeleteHandler) DeleteUserURLsHandler(w http.ResponseWriter, r *http.Request) {
+	if u.jobs == nil {
+		problem.Write(w, r, http.StatusNotImplemented, problem.CodeNotImplemented)
+		return
+	}
+
 	userID, ok := r.Context().Value(domain.UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
 		return
 	}
 
 	var ids []string
 	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
 		return
 	}
 
 	if len(ids) == 0 {
-		http.Error(w, "Empty list of URLs", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeEmptyBatch)
 		return
 	}
 
-	var fullURLs []string
-	for _, id := range ids {
-		fullURLs = append(fullURLs, fmt.Sprintf("%s/%s", u.cfg.BaseURL, id))
-	}
+	// the entry is described while the request is still around, the links are filled in once they are deleted
+	base := auditEntry(r, u.cfg.TrustProxyHeaders, domain.AuditDelete, "", nil, nil)
+	deleted := func(links []domain.Link) {
+		entries := make([]domain.AuditEntry, 0, len(links))
+		for _, link := range links {
+			publish(u.events, domain.LinkEvent{Type: domain.EventLinkDeleted, UserID: userID, ID: link.ID})
 
-	go func(fullURLs []string, userID int) {
-		err := u.deleter.DeleteUserURLs(context.Background(), fullURLs, userID)
-		if err != nil {
-			http.Error(w, "Failed to delete URL", http.StatusBadRequest)
-			return
+			entry := base
+			entry.LinkID = link.ID
+			entry.Before = domain.NewAuditState(link)
+			after := *entry.Before
+			after.Deleted = true
+			entry.After = &after
+			entries = append(entries, entry)
 		}
-	}(fullURLs, userID)
+		record(context.Background(), u.audit, entries...)
+	}
+
+	job, err := u.jobs.SubmitDelete(r.Context(), userID, ids, deleted)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
 
+	w.Header().Set(contentType, contentTypeApp)
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
 	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// RestoreUserURLsHandler processes requests to undelete user URLs that were deleted within DELETED_RETENTION. The
+// response reports the outcome for every ID.
+func (u *DeleteHandler) RestoreUserURLsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var ids []string
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	if len(ids) == 0 {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeEmptyBatch)
+		return
+	}
+
+	var deletedAfter time.Time
+	if u.cfg.DeletedRetention > 0 {
+		deletedAfter = time.Now().Add(-u.cfg.DeletedRetention)
+	}
+
+	results, links, err := u.deleter.RestoreUserURLs(r.Context(), ids, userID, deletedAfter)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	shortURLs := make(map[string]string, len(links))
+	entries := make([]domain.AuditEntry, 0, len(links))
+	for _, link := range links {
+		shortURLs[link.ID] = u.cfg.ShortURL(link.Domain, link.ID)
+
+		after := domain.NewAuditState(link)
+		before := *after
+		before.Deleted = true
+		entries = append(entries, auditEntry(r, u.cfg.TrustProxyHeaders, domain.AuditRestore, link.ID, &before, after))
+	}
+	record(r.Context(), u.audit, entries...)
+
+	for i := range results {
+		results[i].ShortURL = shortURLs[results[i].ID]
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		log.Println("Failed to write response:", err)
+	}
 }
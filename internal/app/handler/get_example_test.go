@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 
 	"github.com/go-chi/chi/v5"
 
@@ -17,16 +16,16 @@ import (
 
 type mockGetter struct{}
 
-func (m mockGetter) Get(ctx context.Context, id string) (string, bool, bool) {
-	if strings.HasSuffix(id, "/abc123") {
-		return "https://example.com", true, false
+func (m mockGetter) Get(ctx context.Context, host, id string) (domain.Redirect, bool, bool) {
+	if id == "abc123" {
+		return domain.Redirect{OriginalURL: "https://example.com"}, true, false
 	}
-	return "", false, false
+	return domain.Redirect{}, false, false
 }
 
-func (m mockGetter) GetUserURLs(ctx context.Context, userID int) ([]map[string]string, error) {
-	return []map[string]string{
-		{"short_url": fmt.Sprintf("%s/%s", "http://example.test", "abc123"), "original_url": "https://example.com"},
+func (m mockGetter) GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error) {
+	return []domain.Link{
+		{ID: "abc123", Domain: "example.test", OriginalURL: "https://example.com"},
 	}, nil
 }
 
@@ -36,7 +35,7 @@ func ExampleGetterHandler_GetHandler() {
 	defer ts.Close()
 
 	cfg := &config.Config{BaseURL: ts.URL}
-	h := handler.NewGetterHandler(mockGetter{}, cfg)
+	h := handler.NewGetterHandler(mockGetter{}, cfg, handler.GetterOptions{})
 
 	r.Get("/{id}", h.GetHandler)
 
@@ -64,8 +63,8 @@ func ExampleGetterHandler_GetUserURLsHandler() {
 	ts := httptest.NewServer(r)
 	defer ts.Close()
 
-	cfg := &config.Config{BaseURL: ts.URL}
-	h := handler.NewGetterHandler(mockGetter{}, cfg)
+	cfg := &config.Config{BaseURL: ts.URL, ShortDomains: []string{"http://example.test"}}
+	h := handler.NewGetterHandler(mockGetter{}, cfg, handler.GetterOptions{})
 
 	r.Get("/user/urls", func(w http.ResponseWriter, r *http.Request) {
 		ctx := context.WithValue(r.Context(), domain.UserIDKey, 1)
@@ -5,78 +5,186 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Te8va/shortURL/internal/app/config"
 	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/problem"
 )
 
 // URLGetter defines an interface for retrieving URLs.
 //
 //go:generate mockgen -source=gethandler.go -destination=mocks/url_getter_mock.gen.go -package=mocks
 type URLGetter interface {
-	Get(ctx context.Context, id string) (string, bool, bool)
-	GetUserURLs(ctx context.Context, userID int) ([]map[string]string, error)
+	Get(ctx context.Context, host, id string) (domain.Redirect, bool, bool)
+	GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error)
+}
+
+// userURL represents one of the user's links in the list of their URLs.
+type userURL struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+	domain.LinkMeta
+	Health *domain.LinkHealth `json:"health,omitempty"`
+	Page   *domain.PageMeta   `json:"page,omitempty"`
+}
+
+func newUserURL(cfg *config.Config, link domain.Link) userURL {
+	return userURL{ShortURL: cfg.ShortURL(link.Domain, link.ID), OriginalURL: link.OriginalURL, LinkMeta: link.LinkMeta, Health: link.Health, Page: link.Page}
 }
 
 // GetterHandler handles requests for retrieving URLs.
 type GetterHandler struct {
-	getter URLGetter
-	cfg    *config.Config
+	getter     URLGetter
+	cfg        *config.Config
+	events     LinkEventPublisher
+	workspaces WorkspaceAuthorizer
+	variants   VariantRouter
+	moderation ModerationChecker
+}
+
+// GetterOptions holds the optional collaborators of GetterHandler, any of them may be nil. Workspace links can not be
+// listed without Workspaces and traffic is not split across variants without Variants. Links disabled by moderators are not
+// redirected unless Moderation is nil.
+type GetterOptions struct {
+	Events     LinkEventPublisher
+	Workspaces WorkspaceAuthorizer
+	Variants   VariantRouter
+	Moderation ModerationChecker
 }
 
 // NewGetterHandler creates a new instance of GetterHandler.
-func NewGetterHandler(getter URLGetter, cfg *config.Config) *GetterHandler {
-	return &GetterHandler{getter: getter, cfg: cfg}
+func NewGetterHandler(getter URLGetter, cfg *config.Config, opts GetterOptions) *GetterHandler {
+	return &GetterHandler{getter: getter, cfg: cfg, events: opts.Events, workspaces: opts.Workspaces, variants: opts.Variants, moderation: opts.Moderation}
 }
 
 // GetHandler processes request to redirect to the original URL by short ID.
 func (u *GetterHandler) GetHandler(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimPrefix(r.URL.Path, "/")
 	if id == "" {
-		http.Error(w, "Missing or invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidID)
 		return
 	}
 
-	id = fmt.Sprintf("%s/%s", u.cfg.BaseURL, id)
-	originalURL, exists, isDeleted := u.getter.Get(r.Context(), id)
+	host := u.cfg.ResolveDomain(r.Host)
+	redirect, exists, isDeleted := u.getter.Get(r.Context(), host, id)
 	if !exists {
-		http.Error(w, "URL not found", http.StatusNotFound)
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound)
 		return
 	}
 
 	if isDeleted {
-		http.Error(w, "URL has been deleted", http.StatusGone)
+		problem.Write(w, r, http.StatusGone, problem.CodeGone)
+		return
+	}
+
+	if linkDisabled(r, u.moderation, id) {
+		writeDisabled(w, r, id)
 		return
 	}
 
-	log.Printf("Redirecting ID %s to URL: %s", id, originalURL)
+	originalURL := withQuery(r, id, u.destination(w, r, id, redirect), redirect.Query)
+
+	log.Printf("Redirecting ID %s on %s to URL: %s", id, host, originalURL)
+	publish(u.events, domain.LinkEvent{Type: domain.EventLinkClicked, ID: id, ShortURL: u.cfg.ShortURL(host, id), OriginalURL: originalURL})
 	w.Header().Set("Location", originalURL)
 	w.WriteHeader(http.StatusTemporaryRedirect)
 }
 
-// GetUserURLsHandler a request to retrieve all URLs created user.
+// destination returns the URL of the first redirect rule the visitor matches, or else the URL of the variant the
+// visitor is assigned to if the link splits its traffic, otherwise the original URL. The redirect is counted for
+// the variant.
+func (u *GetterHandler) destination(w http.ResponseWriter, r *http.Request, id string, redirect domain.Redirect) string {
+	if url, ok := matchRules(redirect.Rules, r, time.Now()); ok {
+		return url
+	}
+
+	originalURL := redirect.OriginalURL
+	if u.variants == nil {
+		return originalURL
+	}
+
+	variants, err := u.variants.GetVariants(r.Context(), id)
+	if err != nil {
+		log.Println("Failed to get link variants:", err)
+		return originalURL
+	}
+	if len(variants) == 0 {
+		return originalURL
+	}
+
+	i := pickVariant(variants, id, visitorKey(w, r, u.cfg.TrustProxyHeaders, u.cfg.EnableHTTPS))
+	if err := u.variants.CountVariantClick(r.Context(), id, i); err != nil {
+		log.Println("Failed to count variant click:", err)
+	}
+	return variants[i].URL
+}
+
+// withQuery merges the UTM parameters of the link and, if it forwards them, the query parameters of the request into
+// the destination. A malformed query string is not forwarded, and the destination is used as is if it can not be
+// merged at all.
+func withQuery(r *http.Request, id, destination string, opts domain.QueryOptions) string {
+	merged, err := mergeQuery(destination, opts, r.URL.RawQuery)
+	if err != nil && opts.Forward {
+		log.Printf("Not forwarding query string of ID %s: %v", id, err)
+		opts.Forward = false
+		merged, err = mergeQuery(destination, opts, "")
+	}
+	if err != nil {
+		log.Printf("Failed to merge query string of ID %s: %v", id, err)
+		return destination
+	}
+	return merged
+}
+
+// GetUserURLsHandler a request to retrieve all URLs created user. The tag and q query parameters narrow them down
+// to links carrying the tag and whose title, original URL or notes contain words starting with every word of q.
+// With the workspace query parameter the links of that workspace are listed instead of the user's personal ones,
+// and the health parameter keeps the links whose last check found them ok, broken or that are unchecked.
 func (u *GetterHandler) GetUserURLsHandler(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(domain.UserIDKey).(int)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
 		return
 	}
 
-	urls, err := u.getter.GetUserURLs(r.Context(), userID)
+	filter := domain.LinkFilter{
+		Workspace: r.URL.Query().Get("workspace"),
+		Tag:       r.URL.Query().Get("tag"),
+		Query:     r.URL.Query().Get("q"),
+		Health:    r.URL.Query().Get("health"),
+	}
+	switch filter.Health {
+	case "", domain.HealthOK, domain.HealthBroken, domain.HealthUnchecked:
+	default:
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter).
+			WithDetail("health must be any of ok, broken, unchecked").
+			Write(w)
+		return
+	}
+	if !authorizeWorkspace(w, r, u.workspaces, userID, filter.Workspace, domain.RoleViewer) {
+		return
+	}
+
+	links, err := u.getter.GetUserURLs(r.Context(), userID, filter)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
 		return
 	}
 
-	if urls == nil {
+	if len(links) == 0 {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
+	urls := make([]userURL, 0, len(links))
+	for _, link := range links {
+		urls = append(urls, newUserURL(u.cfg, link))
+	}
+
 	w.Header().Set(contentType, contentTypeApp)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(urls)
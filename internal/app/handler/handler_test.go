@@ -1,18 +1,29 @@
 package handler
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Te8va/shortURL/internal/app/config"
 	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
 	"github.com/Te8va/shortURL/internal/app/handler/mocks"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+	"github.com/Te8va/shortURL/internal/app/policy"
+	"github.com/Te8va/shortURL/internal/app/problem"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
@@ -27,10 +38,14 @@ func setupTestHandler(t *testing.T) (*gomock.Controller, *mocks.MockURLSaver, *m
 	testCfg := &config.Config{
 		BaseURL:       "http://localhost:8080",
 		ServerAddress: "localhost:8080",
+		ShortDomains:  []string{"https://go.example.com"},
 	}
 
-	saveHandler := NewSaveHandler(mockSaver)
-	getterHandler := NewGetterHandler(mockGetter, testCfg)
+	urlPolicy, err := policy.New(testCfg.BaseURL, "", testCfg.ShortDomains...)
+	require.NoError(t, err)
+
+	saveHandler := NewSaveHandler(mockSaver, urlPolicy, testCfg, SaveOptions{})
+	getterHandler := NewGetterHandler(mockGetter, testCfg, GetterOptions{})
 	pingHandler := NewPingHandler(mockPinger)
 
 	return ctrl, mockSaver, mockGetter, mockPinger, saveHandler, getterHandler, pingHandler
@@ -51,7 +66,7 @@ func TestPostHandler(t *testing.T) {
 			name:        "valid URL",
 			contentType: "text/plain",
 			body:        "http://example.com",
-			mockReturn:  "http://localhost:8080/shortID",
+			mockReturn:  "shortID",
 			wantCode:    http.StatusCreated,
 		},
 		{
@@ -66,12 +81,24 @@ func TestPostHandler(t *testing.T) {
 			body:        "",
 			wantCode:    http.StatusBadRequest,
 		},
+		{
+			name:        "javascript scheme",
+			contentType: "text/plain",
+			body:        "javascript:alert(1)",
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:        "loopback host",
+			contentType: "text/plain",
+			body:        "http://127.0.0.1/admin",
+			wantCode:    http.StatusBadRequest,
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			if testCase.wantCode == http.StatusCreated {
-				mockSaver.EXPECT().Save(gomock.Any(), gomock.Any(), testCase.body).Return(testCase.mockReturn, nil).Times(1)
+				mockSaver.EXPECT().Save(gomock.Any(), gomock.Any(), "", testCase.body, domain.LinkMeta{}).Return(testCase.mockReturn, nil).Times(1)
 			}
 
 			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(testCase.body))
@@ -93,17 +120,18 @@ func TestGetHandler(t *testing.T) {
 	ctrl, _, mockGetter, _, _, getterHandler, _ := setupTestHandler(t)
 	defer ctrl.Finish()
 
-	baseURL := "http://localhost:8080"
 	testID := "testID"
-	fullURL := fmt.Sprintf("%s/%s", baseURL, testID)
 	testURL := "http://example.com"
 
-	mockGetter.EXPECT().Get(gomock.Any(), fullURL).Return(testURL, true, false).AnyTimes()
-	mockGetter.EXPECT().Get(gomock.Any(), fmt.Sprintf("%s/%s", baseURL, "deletedID")).Return("", true, true).AnyTimes()
-	mockGetter.EXPECT().Get(gomock.Any(), fmt.Sprintf("%s/%s", baseURL, "invalidID")).Return("", false, false).AnyTimes()
+	mockGetter.EXPECT().Get(gomock.Any(), "", testID).Return(domain.Redirect{OriginalURL: testURL}, true, false).AnyTimes()
+	mockGetter.EXPECT().Get(gomock.Any(), "", "deletedID").Return(domain.Redirect{}, true, true).AnyTimes()
+	mockGetter.EXPECT().Get(gomock.Any(), "", "invalidID").Return(domain.Redirect{}, false, false).AnyTimes()
+	mockGetter.EXPECT().Get(gomock.Any(), "go.example.com", "goID").Return(domain.Redirect{OriginalURL: testURL}, true, false).AnyTimes()
+	mockGetter.EXPECT().Get(gomock.Any(), "go.example.com", testID).Return(domain.Redirect{}, false, false).AnyTimes()
 
 	testCases := []struct {
 		name      string
+		host      string
 		requestID string
 		wantCode  int
 		wantURL   string
@@ -119,12 +147,33 @@ func TestGetHandler(t *testing.T) {
 			requestID: "invalidID",
 			wantCode:  http.StatusNotFound,
 		},
+		{
+			name:      "custom domain",
+			host:      "go.example.com",
+			requestID: "goID",
+			wantCode:  http.StatusTemporaryRedirect,
+			wantURL:   testURL,
+		},
+		{
+			name:      "ID of another domain",
+			host:      "go.example.com",
+			requestID: testID,
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "unknown host falls back to base URL",
+			host:      "unknown.example.org",
+			requestID: testID,
+			wantCode:  http.StatusTemporaryRedirect,
+			wantURL:   testURL,
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			req, err := http.NewRequest(http.MethodGet, "/"+testCase.requestID, nil)
 			require.NoError(t, err)
+			req.Host = testCase.host
 
 			w := httptest.NewRecorder()
 			getterHandler.GetHandler(w, req)
@@ -148,14 +197,66 @@ func TestPostHandlerJSON(t *testing.T) {
 		mockReturn  string
 		mockErr     error
 		wantCode    int
+		wantRule    string
+		wantHost    string
+		wantMeta    domain.LinkMeta
+		wantResult  string
+		wantProblem problem.Code
 	}{
 		{
 			name:        "valid JSON",
 			contentType: "application/json",
 			body:        domain.ShortenRequest{URL: "http://example.com"},
-			mockReturn:  "http://localhost:8080/shortID",
+			mockReturn:  "shortID",
 			mockErr:     nil,
 			wantCode:    http.StatusCreated,
+			wantHost:    "",
+			wantResult:  "http://localhost:8080/shortID",
+		},
+		{
+			name:        "custom domain",
+			contentType: "application/json",
+			body:        domain.ShortenRequest{URL: "http://example.com", Domain: "GO.example.com"},
+			mockReturn:  "shortID",
+			wantCode:    http.StatusCreated,
+			wantHost:    "go.example.com",
+			wantResult:  "https://go.example.com/shortID",
+		},
+		{
+			name:        "with metadata",
+			contentType: "application/json",
+			body: domain.ShortenRequest{
+				URL:      "http://example.com",
+				LinkMeta: domain.LinkMeta{Title: " Docs ", Notes: "API reference", Tags: []string{"Docs", "api", "docs", " "}},
+			},
+			mockReturn: "shortID",
+			wantCode:   http.StatusCreated,
+			wantMeta:   domain.LinkMeta{Title: "Docs", Notes: "API reference", Tags: []string{"docs", "api"}},
+			wantResult: "http://localhost:8080/shortID",
+		},
+		{
+			name:        "too many tags",
+			contentType: "application/json",
+			body: domain.ShortenRequest{
+				URL:      "http://example.com",
+				LinkMeta: domain.LinkMeta{Tags: strings.Split("a,b,c,d,e,f,g,h,i,j,k,l,m,n,o,p,q,r,s,t,u", ",")},
+			},
+			wantCode:    http.StatusBadRequest,
+			wantProblem: problem.CodeInvalidMetadata,
+		},
+		{
+			name:        "unknown domain",
+			contentType: "application/json",
+			body:        domain.ShortenRequest{URL: "http://example.com", Domain: "evil.example.org"},
+			wantCode:    http.StatusBadRequest,
+			wantProblem: problem.CodeUnknownDomain,
+		},
+		{
+			name:        "link back to custom domain",
+			contentType: "application/json",
+			body:        domain.ShortenRequest{URL: "https://go.example.com/abc"},
+			wantCode:    http.StatusBadRequest,
+			wantRule:    "self_reference",
 		},
 		{
 			name:        "invalid content type",
@@ -168,6 +269,22 @@ func TestPostHandlerJSON(t *testing.T) {
 			contentType: "application/json",
 			body:        domain.ShortenRequest{URL: "invalid-url"},
 			wantCode:    http.StatusBadRequest,
+			wantRule:    "format",
+		},
+		{
+			name:        "self reference",
+			contentType: "application/json",
+			body:        domain.ShortenRequest{URL: "http://localhost:8080/abc"},
+			wantCode:    http.StatusBadRequest,
+			wantRule:    "self_reference",
+		},
+		{
+			name:        "taken down URL",
+			contentType: "application/json",
+			body:        domain.ShortenRequest{URL: "http://example.com"},
+			mockErr:     appErrors.ErrTakenDown,
+			wantCode:    http.StatusBadRequest,
+			wantProblem: problem.CodeURLRejected,
 		},
 	}
 
@@ -175,8 +292,8 @@ func TestPostHandlerJSON(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			bodyBytes, _ := json.Marshal(testCase.body)
 
-			if testCase.wantCode == http.StatusCreated {
-				mockSaver.EXPECT().Save(gomock.Any(), gomock.Any(), testCase.body.URL).Return(testCase.mockReturn, testCase.mockErr).Times(1)
+			if testCase.wantCode == http.StatusCreated || testCase.mockErr != nil {
+				mockSaver.EXPECT().Save(gomock.Any(), gomock.Any(), testCase.wantHost, testCase.body.URL, testCase.wantMeta).Return(testCase.mockReturn, testCase.mockErr).Times(1)
 			}
 
 			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyBytes))
@@ -187,11 +304,95 @@ func TestPostHandlerJSON(t *testing.T) {
 			saveHandler.PostHandlerJSON(w, req)
 
 			require.Equal(t, testCase.wantCode, w.Code)
+			if testCase.wantRule != "" {
+				var resp map[string]interface{}
+				require.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, string(problem.CodeURLRejected), resp["code"])
+				require.Equal(t, testCase.wantRule, resp["rule"])
+			}
+			if testCase.wantProblem != "" {
+				var resp map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, string(testCase.wantProblem), resp["code"])
+			}
 			if testCase.wantCode == http.StatusCreated {
-				require.Contains(t, w.Body.String(), "http://localhost:8080/shortID")
+				var resp domain.ShortenResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, testCase.wantResult, resp.Result)
+			}
+		})
+	}
+}
+
+func TestPostHandlerJSON_DomainOwners(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCfg := &config.Config{
+		BaseURL:           "http://localhost:8080",
+		ShortDomains:      []string{"go.example.com", "l.example.org"},
+		ShortDomainOwners: []string{"go.example.com=7", "GO.example.com.=8"},
+		JWTKeyID:          "default",
+		TokenTTL:          time.Hour,
+		DevMode:           true,
+	}
+	require.NoError(t, testCfg.Validate())
+
+	urlPolicy, err := policy.New(testCfg.BaseURL, "", testCfg.ShortDomains...)
+	require.NoError(t, err)
+	mockSaver := mocks.NewMockURLSaver(ctrl)
+	saveHandler := NewSaveHandler(mockSaver, urlPolicy, testCfg, SaveOptions{})
+
+	testCases := []struct {
+		name     string
+		userID   int
+		host     string
+		domain   string
+		wantHost string
+		wantCode int
+	}{
+		{name: "owner", userID: 7, domain: "go.example.com", wantHost: "go.example.com", wantCode: http.StatusCreated},
+		{name: "second owner", userID: 8, host: "go.example.com", wantHost: "go.example.com", wantCode: http.StatusCreated},
+		{name: "other user", userID: 9, domain: "go.example.com", wantCode: http.StatusForbidden},
+		{name: "other user on the owned host", userID: 9, host: "go.example.com", wantCode: http.StatusForbidden},
+		{name: "domain without owners", userID: 9, domain: "l.example.org", wantHost: "l.example.org", wantCode: http.StatusCreated},
+		{name: "base domain", userID: 9, wantHost: "", wantCode: http.StatusCreated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.wantCode == http.StatusCreated {
+				mockSaver.EXPECT().Save(gomock.Any(), tc.userID, tc.wantHost, "http://example.com", domain.LinkMeta{}).Return("shortID", nil)
+			}
+
+			body, err := json.Marshal(domain.ShortenRequest{URL: "http://example.com", Domain: tc.domain})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/api/shorten", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.host != "" {
+				req.Host = tc.host
+			}
+			req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, tc.userID))
+
+			w := httptest.NewRecorder()
+			saveHandler.PostHandlerJSON(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code, w.Body.String())
+			if tc.wantCode == http.StatusForbidden {
+				var resp map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, string(problem.CodeDomainForbidden), resp["code"])
+				require.Equal(t, "go.example.com", resp["domain"])
 			}
 		})
 	}
+
+	// owners must be given for one of SHORT_DOMAINS
+	testCfg.ShortDomainOwners = []string{"localhost:8080=7"}
+	require.Error(t, testCfg.Validate())
+	testCfg.ShortDomainOwners = []string{"go.example.com=me"}
+	require.Error(t, testCfg.Validate())
 }
 
 func TestPingHandler(t *testing.T) {
@@ -213,9 +414,9 @@ func TestDeleteUserURLsHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockDeleter := mocks.NewMockURLDelete(ctrl)
+	mockJobs := mocks.NewMockDeleteJobSubmitter(ctrl)
 	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
-	deleteHandler := NewDeleteHandler(mockDeleter, testCfg)
+	deleteHandler := NewDeleteHandler(mocks.NewMockURLDelete(ctrl), testCfg, DeleteOptions{Jobs: mockJobs})
 
 	testCases := []struct {
 		name       string
@@ -272,45 +473,44 @@ func TestDeleteUserURLsHandler(t *testing.T) {
 			if tc.expectCall {
 				var ids []string
 				_ = json.Unmarshal(bodyBytes, &ids)
-				var full []string
-				for _, id := range ids {
-					full = append(full, fmt.Sprintf("%s/%s", testCfg.BaseURL, id))
-				}
-
-				done := make(chan struct{})
-				mockDeleter.EXPECT().
-					DeleteUserURLs(gomock.Any(), full, tc.userID.(int)).
-					DoAndReturn(func(ctx context.Context, ids []string, userID int) error {
-						close(done)
-						return nil
-					}).Times(1)
 
-				w := httptest.NewRecorder()
-				deleteHandler.DeleteUserURLsHandler(w, req)
-
-				<-done
-				require.Equal(t, tc.wantCode, w.Code)
-			} else {
-				w := httptest.NewRecorder()
-				deleteHandler.DeleteUserURLsHandler(w, req)
-				require.Equal(t, tc.wantCode, w.Code)
+				mockJobs.EXPECT().
+					SubmitDelete(gomock.Any(), tc.userID.(int), ids, gomock.Any()).
+					Return(domain.Job{ID: "j1", Type: domain.JobDelete, State: domain.JobQueued}, nil).Times(1)
 			}
+
+			w := httptest.NewRecorder()
+			deleteHandler.DeleteUserURLsHandler(w, req)
+			require.Equal(t, tc.wantCode, w.Code)
 		})
 	}
 
+	t.Run("without jobs", func(t *testing.T) {
+		handler := NewDeleteHandler(mocks.NewMockURLDelete(ctrl), testCfg, DeleteOptions{})
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/user/urls", bytes.NewBufferString(`["abc123"]`))
+		req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 42))
+		w := httptest.NewRecorder()
+		handler.DeleteUserURLsHandler(w, req)
+
+		require.Equal(t, http.StatusNotImplemented, w.Code)
+		require.Contains(t, w.Body.String(), `"code":"not_implemented"`)
+	})
 }
 func TestGetUserURLsHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockGetter := mocks.NewMockURLGetter(ctrl)
-	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
-	handler := NewGetterHandler(mockGetter, testCfg)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080", ShortDomains: []string{"go.example.com"}}
+	handler := NewGetterHandler(mockGetter, testCfg, GetterOptions{})
 
 	testCases := []struct {
 		name       string
 		userID     interface{}
-		mockResult []map[string]string
+		query      string
+		wantFilter domain.LinkFilter
+		mockResult []domain.Link
 		mockErr    error
 		wantCode   int
 		wantBody   []map[string]string
@@ -318,18 +518,37 @@ func TestGetUserURLsHandler(t *testing.T) {
 		{
 			name:   "authorized with URLs",
 			userID: 123,
-			mockResult: []map[string]string{
+			mockResult: []domain.Link{
+				{ID: "abc", OriginalURL: "http://example.com"},
+				{ID: "xyz", Domain: "go.example.com", OriginalURL: "http://example.org"},
+			},
+			mockErr:  nil,
+			wantCode: http.StatusOK,
+			wantBody: []map[string]string{
 				{
 					"short_url":    "http://localhost:8080/abc",
 					"original_url": "http://example.com",
 				},
+				{
+					"short_url":    "http://go.example.com/xyz",
+					"original_url": "http://example.org",
+				},
+			},
+		},
+		{
+			name:       "search",
+			userID:     123,
+			query:      "?tag=docs&q=api+reference",
+			wantFilter: domain.LinkFilter{Tag: "docs", Query: "api reference"},
+			mockResult: []domain.Link{
+				{ID: "abc", OriginalURL: "http://example.com", LinkMeta: domain.LinkMeta{Title: "API reference"}},
 			},
-			mockErr:  nil,
 			wantCode: http.StatusOK,
 			wantBody: []map[string]string{
 				{
 					"short_url":    "http://localhost:8080/abc",
 					"original_url": "http://example.com",
+					"title":        "API reference",
 				},
 			},
 		},
@@ -355,14 +574,14 @@ func TestGetUserURLsHandler(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls"+tc.query, nil)
 			if tc.userID != nil {
 				req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, tc.userID))
 			}
 
 			if tc.userID != nil {
 				mockGetter.EXPECT().
-					GetUserURLs(gomock.Any(), tc.userID.(int)).
+					GetUserURLs(gomock.Any(), tc.userID.(int), tc.wantFilter).
 					Return(tc.mockResult, tc.mockErr).
 					Times(1)
 			}
@@ -381,3 +600,2218 @@ func TestGetUserURLsHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUserURLsHandler_Health(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGetter := mocks.NewMockURLGetter(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	handler := NewGetterHandler(mockGetter, testCfg, GetterOptions{})
+
+	checkedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockGetter.EXPECT().GetUserURLs(gomock.Any(), 123, domain.LinkFilter{Health: domain.HealthBroken}).Return([]domain.Link{
+		{ID: "abc", OriginalURL: "http://example.com/old", Health: &domain.LinkHealth{Status: 404, Redirects: []string{"http://example.com/new"}, CheckedAt: checkedAt}},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls?health=broken", nil)
+	req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 123))
+	w := httptest.NewRecorder()
+	handler.GetUserURLsHandler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `[{"short_url":"http://localhost:8080/abc","original_url":"http://example.com/old",
+		"health":{"status":404,"redirects":["http://example.com/new"],"checked_at":"2024-05-01T12:00:00Z"}}]`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/user/urls?health=dead", nil)
+	req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 123))
+	w = httptest.NewRecorder()
+	handler.GetUserURLsHandler(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"code":"invalid_parameter"`)
+}
+
+func TestUpdateLinkHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mocks.NewMockURLUpdater(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	updateHandler := NewUpdateHandler(mockUpdater, testCfg, nil)
+
+	r := chi.NewRouter()
+	r.Patch("/urls/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if userID := r.Header.Get("X-Test-User"); userID != "" {
+			r = r.WithContext(context.WithValue(r.Context(), domain.UserIDKey, 7))
+		}
+		updateHandler.UpdateLinkHandler(w, r)
+	})
+
+	title := "Docs"
+	tags := []string{"docs", "api"}
+	notes := ""
+
+	testCases := []struct {
+		name        string
+		id          string
+		body        string
+		anonymous   bool
+		mockSetup   func()
+		wantCode    int
+		wantProblem problem.Code
+		wantBody    string
+	}{
+		{
+			name: "update title and tags",
+			id:   "abc",
+			body: `{"title":" Docs ","tags":["Docs","api","docs"]}`,
+			mockSetup: func() {
+				mockUpdater.EXPECT().
+					UpdateLink(gomock.Any(), 7, "abc", domain.LinkUpdate{Title: &title, Tags: &tags}).
+					Return(domain.Link{ID: "abc", OriginalURL: "https://example.com", LinkMeta: domain.LinkMeta{Title: title, Tags: tags}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"short_url":"http://localhost:8080/abc","original_url":"https://example.com","title":"Docs","tags":["docs","api"]}`,
+		},
+		{
+			name: "clear notes",
+			id:   "abc",
+			body: `{"notes":""}`,
+			mockSetup: func() {
+				mockUpdater.EXPECT().
+					UpdateLink(gomock.Any(), 7, "abc", domain.LinkUpdate{Notes: &notes}).
+					Return(domain.Link{ID: "abc", OriginalURL: "https://example.com"}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"short_url":"http://localhost:8080/abc","original_url":"https://example.com"}`,
+		},
+		{
+			name: "someone else's link",
+			id:   "foreign",
+			body: `{"title":"Docs"}`,
+			mockSetup: func() {
+				mockUpdater.EXPECT().
+					UpdateLink(gomock.Any(), 7, "foreign", gomock.Any()).
+					Return(domain.Link{}, appErrors.ErrNotFound)
+			},
+			wantCode:    http.StatusNotFound,
+			wantProblem: problem.CodeNotFound,
+		},
+		{
+			name:        "title too long",
+			id:          "abc",
+			body:        `{"title":"` + strings.Repeat("a", maxTitleLength+1) + `"}`,
+			wantCode:    http.StatusBadRequest,
+			wantProblem: problem.CodeInvalidMetadata,
+		},
+		{
+			name:        "invalid JSON",
+			id:          "abc",
+			body:        `{"title":`,
+			wantCode:    http.StatusBadRequest,
+			wantProblem: problem.CodeInvalidJSON,
+		},
+		{
+			name:        "unauthorized",
+			id:          "abc",
+			body:        `{"title":"Docs"}`,
+			anonymous:   true,
+			wantCode:    http.StatusUnauthorized,
+			wantProblem: problem.CodeUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockSetup != nil {
+				tc.mockSetup()
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/urls/"+tc.id, strings.NewReader(tc.body))
+			if !tc.anonymous {
+				req.Header.Set("X-Test-User", "7")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantBody != "" {
+				require.JSONEq(t, tc.wantBody, w.Body.String())
+			}
+			if tc.wantProblem != "" {
+				var resp map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, string(tc.wantProblem), resp["code"])
+			}
+		})
+	}
+}
+
+func TestQRHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGetter := mocks.NewMockURLGetter(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	mockChecker := mocks.NewMockModerationChecker(ctrl)
+	qrHandler := NewQRHandler(mockGetter, testCfg, mockChecker)
+
+	mockGetter.EXPECT().Get(gomock.Any(), "", "abc").Return(domain.Redirect{OriginalURL: "http://example.com"}, true, false).AnyTimes()
+	mockGetter.EXPECT().Get(gomock.Any(), "", "gone").Return(domain.Redirect{}, true, true).AnyTimes()
+	mockGetter.EXPECT().Get(gomock.Any(), "", "missing").Return(domain.Redirect{}, false, false).AnyTimes()
+	mockGetter.EXPECT().Get(gomock.Any(), "", "bad").Return(domain.Redirect{OriginalURL: "http://example.com"}, true, false).AnyTimes()
+	mockChecker.EXPECT().Disabled(gomock.Any(), "abc").Return(false, nil).AnyTimes()
+	mockChecker.EXPECT().Disabled(gomock.Any(), "bad").Return(true, nil).AnyTimes()
+
+	r := chi.NewRouter()
+	r.Get("/{id}/qr", qrHandler.QRHandler)
+
+	testCases := []struct {
+		name            string
+		target          string
+		ifNoneMatch     string
+		wantCode        int
+		wantContentType string
+	}{
+		{
+			name:            "default png",
+			target:          "/abc/qr",
+			wantCode:        http.StatusOK,
+			wantContentType: "image/png",
+		},
+		{
+			name:            "svg with options",
+			target:          "/abc/qr?size=512&format=svg&ecc=H&margin=2&fg=%23112233&bg=fff",
+			wantCode:        http.StatusOK,
+			wantContentType: "image/svg+xml",
+		},
+		{
+			name:     "invalid size",
+			target:   "/abc/qr?size=10",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid format",
+			target:   "/abc/qr?format=gif",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid colour",
+			target:   "/abc/qr?fg=blue",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			target:   "/missing/qr",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "deleted",
+			target:   "/gone/qr",
+			wantCode: http.StatusGone,
+		},
+		{
+			name:     "disabled by moderators",
+			target:   "/bad/qr",
+			wantCode: http.StatusUnavailableForLegalReasons,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantCode == http.StatusOK {
+				require.Equal(t, tc.wantContentType, w.Header().Get("Content-Type"))
+				require.NotEmpty(t, w.Header().Get("ETag"))
+				require.NotEmpty(t, w.Body.Bytes())
+			}
+		})
+	}
+
+	t.Run("etag revalidation", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/abc/qr?size=300", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+
+		req = httptest.NewRequest(http.MethodGet, "/abc/qr?size=300", nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.Bytes())
+
+		req = httptest.NewRequest(http.MethodGet, "/abc/qr?size=301", nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestETagMatches(t *testing.T) {
+	const etag = `"0123abcd"`
+
+	testCases := []struct {
+		name   string
+		values []string
+		want   bool
+	}{
+		{name: "exact", values: []string{`"0123abcd"`}, want: true},
+		{name: "other tag", values: []string{`"ffff"`}, want: false},
+		{name: "list", values: []string{`"ffff", "0123abcd"`}, want: true},
+		{name: "list without spaces", values: []string{`"ffff","0123abcd"`}, want: true},
+		{name: "list without match", values: []string{`"ffff", "eeee"`}, want: false},
+		{name: "repeated header", values: []string{`"ffff"`, `"0123abcd"`}, want: true},
+		{name: "any", values: []string{`*`}, want: true},
+		{name: "weak", values: []string{`W/"0123abcd"`}, want: true},
+		{name: "weak in list", values: []string{`"ffff", W/"0123abcd"`}, want: true},
+		{name: "comma inside tag", values: []string{`"ff,ff", "0123abcd"`}, want: true},
+		{name: "unquoted", values: []string{`0123abcd`}, want: false},
+		{name: "empty", values: nil, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, etagMatches(tc.values, etag))
+		})
+	}
+}
+
+func TestAuthHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mocks.NewMockUserAuthenticator(ctrl)
+	tokens := middleware.NewTokenManager(middleware.NewHMACKey("test", "test_secret"), time.Hour, false)
+	authHandler := NewAuthHandler(mockAuth, tokens)
+
+	testCases := []struct {
+		name       string
+		handler    http.HandlerFunc
+		body       string
+		registered bool
+		mockSetup  func()
+		wantCode   int
+		wantCookie bool
+	}{
+		{
+			name:    "register claims anonymous links",
+			handler: authHandler.RegisterHandler,
+			body:    `{"login":"alice","password":"password123"}`,
+			mockSetup: func() {
+				mockAuth.EXPECT().Register(gomock.Any(), "alice", "password123").Return(1000001, nil)
+				mockAuth.EXPECT().ClaimURLs(gomock.Any(), 42, 1000001).Return(nil)
+			},
+			wantCode:   http.StatusCreated,
+			wantCookie: true,
+		},
+		{
+			name:    "register taken login",
+			handler: authHandler.RegisterHandler,
+			body:    `{"login":"alice","password":"password123"}`,
+			mockSetup: func() {
+				mockAuth.EXPECT().Register(gomock.Any(), "alice", "password123").Return(0, appErrors.ErrUserExists)
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:    "register weak password",
+			handler: authHandler.RegisterHandler,
+			body:    `{"login":"alice","password":"123"}`,
+			mockSetup: func() {
+				mockAuth.EXPECT().Register(gomock.Any(), "alice", "123").Return(0, appErrors.ErrWeakCredentials)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:       "login as registered user does not claim",
+			handler:    authHandler.LoginHandler,
+			body:       `{"login":"alice","password":"password123"}`,
+			registered: true,
+			mockSetup: func() {
+				mockAuth.EXPECT().Login(gomock.Any(), "alice", "password123").Return(1000001, nil)
+			},
+			wantCode:   http.StatusOK,
+			wantCookie: true,
+		},
+		{
+			name:    "login wrong password",
+			handler: authHandler.LoginHandler,
+			body:    `{"login":"alice","password":"nope"}`,
+			mockSetup: func() {
+				mockAuth.EXPECT().Login(gomock.Any(), "alice", "nope").Return(0, appErrors.ErrInvalidCredentials)
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:      "invalid JSON",
+			handler:   authHandler.LoginHandler,
+			body:      `{`,
+			mockSetup: func() {},
+			wantCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth", bytes.NewBufferString(tc.body))
+			ctx := context.WithValue(req.Context(), domain.UserIDKey, 42)
+			ctx = context.WithValue(ctx, domain.RegisteredKey, tc.registered)
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			tc.handler(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantCookie {
+				var resp domain.AuthResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, 1000001, resp.UserID)
+				require.Contains(t, w.Header().Get("Set-Cookie"), "auth=")
+			}
+		})
+	}
+
+	t.Run("logout clears cookie", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+		w := httptest.NewRecorder()
+		authHandler.LogoutHandler(w, req)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		require.Equal(t, -1, cookies[0].MaxAge)
+	})
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeys := mocks.NewMockAPIKeyManager(ctrl)
+	apiKeyHandler := NewAPIKeyHandler(mockKeys)
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), domain.UserIDKey, 7)))
+		})
+	})
+	r.Get("/keys", apiKeyHandler.ListAPIKeysHandler)
+	r.Post("/keys", apiKeyHandler.CreateAPIKeyHandler)
+	r.Delete("/keys/{id}", apiKeyHandler.RevokeAPIKeyHandler)
+
+	testCases := []struct {
+		name      string
+		method    string
+		target    string
+		body      string
+		mockSetup func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			target: "/keys",
+			body:   `{"name":"ci","scopes":["shorten"]}`,
+			mockSetup: func() {
+				mockKeys.EXPECT().CreateAPIKey(gomock.Any(), 7, "ci", []string{"shorten"}).
+					Return(domain.APIKeyResponse{APIKey: domain.APIKey{ID: "k1", Name: "ci"}, Key: "sk_secret"}, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `"key":"sk_secret"`,
+		},
+		{
+			name:   "create with unknown scope",
+			method: http.MethodPost,
+			target: "/keys",
+			body:   `{"name":"ci","scopes":["admin"]}`,
+			mockSetup: func() {
+				mockKeys.EXPECT().CreateAPIKey(gomock.Any(), 7, "ci", []string{"admin"}).
+					Return(domain.APIKeyResponse{}, appErrors.ErrInvalidScope)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "list hides secrets",
+			method: http.MethodGet,
+			target: "/keys",
+			mockSetup: func() {
+				mockKeys.EXPECT().ListAPIKeys(gomock.Any(), 7).
+					Return([]domain.APIKey{{ID: "k1", Name: "ci", Hash: "deadbeef", Prefix: "sk_12345678"}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"prefix":"sk_12345678"`,
+		},
+		{
+			name:   "revoke",
+			method: http.MethodDelete,
+			target: "/keys/k1",
+			mockSetup: func() {
+				mockKeys.EXPECT().RevokeAPIKey(gomock.Any(), 7, "k1").Return(nil)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:   "revoke unknown",
+			method: http.MethodDelete,
+			target: "/keys/k2",
+			mockSetup: func() {
+				mockKeys.EXPECT().RevokeAPIKey(gomock.Any(), 7, "k2").Return(appErrors.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(tc.method, tc.target, bytes.NewBufferString(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantBody != "" {
+				require.Contains(t, w.Body.String(), tc.wantBody)
+			}
+			require.NotContains(t, w.Body.String(), "deadbeef")
+		})
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHooks := mocks.NewMockWebhookManager(ctrl)
+	webhookHandler := NewWebhookHandler(mockHooks)
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), domain.UserIDKey, 7)))
+		})
+	})
+	r.Get("/webhooks", webhookHandler.ListWebhooksHandler)
+	r.Post("/webhooks", webhookHandler.CreateWebhookHandler)
+	r.Delete("/webhooks/{id}", webhookHandler.DeleteWebhookHandler)
+	r.Get("/webhooks/dead-letters", webhookHandler.ListDeadLettersHandler)
+
+	testCases := []struct {
+		name      string
+		method    string
+		target    string
+		body      string
+		mockSetup func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			target: "/webhooks",
+			body:   `{"url":"https://hooks.example.com","events":["link.created"]}`,
+			mockSetup: func() {
+				mockHooks.EXPECT().CreateWebhook(gomock.Any(), 7, "https://hooks.example.com", []string{domain.EventLinkCreated}).
+					Return(domain.WebhookResponse{Webhook: domain.Webhook{ID: "h1"}, Secret: "whsec_secret"}, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `"secret":"whsec_secret"`,
+		},
+		{
+			name:   "create with unknown event",
+			method: http.MethodPost,
+			target: "/webhooks",
+			body:   `{"url":"https://hooks.example.com","events":["link.renamed"]}`,
+			mockSetup: func() {
+				mockHooks.EXPECT().CreateWebhook(gomock.Any(), 7, "https://hooks.example.com", []string{"link.renamed"}).
+					Return(domain.WebhookResponse{}, appErrors.ErrInvalidWebhook)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_webhook"`,
+		},
+		{
+			name:   "create for a private host",
+			method: http.MethodPost,
+			target: "/webhooks",
+			body:   `{"url":"http://169.254.169.254/latest"}`,
+			mockSetup: func() {
+				mockHooks.EXPECT().CreateWebhook(gomock.Any(), 7, "http://169.254.169.254/latest", gomock.Any()).
+					Return(domain.WebhookResponse{}, fmt.Errorf("%w: %w", appErrors.ErrInvalidWebhook,
+						&policy.Violation{Rule: policy.RulePrivateHost, Message: "host is private"}))
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `"rule":"private_host"`,
+		},
+		{
+			name:   "list hides secrets",
+			method: http.MethodGet,
+			target: "/webhooks",
+			mockSetup: func() {
+				mockHooks.EXPECT().ListWebhooks(gomock.Any(), 7).
+					Return([]domain.Webhook{{ID: "h1", URL: "https://hooks.example.com", Secret: "deadbeef"}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"url":"https://hooks.example.com"`,
+		},
+		{
+			name:   "delete unknown",
+			method: http.MethodDelete,
+			target: "/webhooks/h2",
+			mockSetup: func() {
+				mockHooks.EXPECT().DeleteWebhook(gomock.Any(), 7, "h2").Return(appErrors.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `"code":"webhook_not_found"`,
+		},
+		{
+			name:   "dead letters",
+			method: http.MethodGet,
+			target: "/webhooks/dead-letters",
+			mockSetup: func() {
+				mockHooks.EXPECT().ListDeadLetters(gomock.Any(), 7).
+					Return([]domain.WebhookDeadLetter{{ID: "d1", WebhookID: "h1", Event: domain.EventLinkClicked, Payload: []byte(`{}`), Attempts: 5}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"attempts":5`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(tc.method, tc.target, bytes.NewBufferString(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantBody != "" {
+				require.Contains(t, w.Body.String(), tc.wantBody)
+			}
+			require.NotContains(t, w.Body.String(), "deadbeef")
+		})
+	}
+}
+
+func TestLinkEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSaver := mocks.NewMockURLSaver(ctrl)
+	mockGetter := mocks.NewMockURLGetter(ctrl)
+	mockEvents := mocks.NewMockLinkEventPublisher(ctrl)
+
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	urlPolicy, err := policy.New(testCfg.BaseURL, "")
+	require.NoError(t, err)
+
+	saveHandler := NewSaveHandler(mockSaver, urlPolicy, testCfg, SaveOptions{Events: mockEvents})
+	getterHandler := NewGetterHandler(mockGetter, testCfg, GetterOptions{Events: mockEvents})
+
+	t.Run("created", func(t *testing.T) {
+		mockSaver.EXPECT().Save(gomock.Any(), 7, "", "https://example.com", domain.LinkMeta{}).Return("abc", nil)
+		mockEvents.EXPECT().Publish(domain.LinkEvent{
+			Type:        domain.EventLinkCreated,
+			UserID:      7,
+			ID:          "abc",
+			ShortURL:    "http://localhost:8080/abc",
+			OriginalURL: "https://example.com",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten", bytes.NewBufferString(`{"url":"https://example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 7))
+		w := httptest.NewRecorder()
+		saveHandler.PostHandlerJSON(w, req)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("not published for existing URL", func(t *testing.T) {
+		mockSaver.EXPECT().Save(gomock.Any(), 7, "", "https://example.com", domain.LinkMeta{}).Return("abc", appErrors.ErrURLExists)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten", bytes.NewBufferString(`{"url":"https://example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 7))
+		w := httptest.NewRecorder()
+		saveHandler.PostHandlerJSON(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("clicked", func(t *testing.T) {
+		mockGetter.EXPECT().Get(gomock.Any(), "", "abc").Return(domain.Redirect{OriginalURL: "https://example.com"}, true, false)
+		mockEvents.EXPECT().Publish(domain.LinkEvent{
+			Type:        domain.EventLinkClicked,
+			ID:          "abc",
+			ShortURL:    "http://localhost:8080/abc",
+			OriginalURL: "https://example.com",
+		})
+
+		w := httptest.NewRecorder()
+		getterHandler.GetHandler(w, httptest.NewRequest(http.MethodGet, "/abc", nil))
+
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	})
+}
+
+func TestPageQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSaver := mocks.NewMockURLSaver(ctrl)
+	mockPages := mocks.NewMockPageQueue(ctrl)
+
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	urlPolicy, err := policy.New(testCfg.BaseURL, "")
+	require.NoError(t, err)
+
+	saveHandler := NewSaveHandler(mockSaver, urlPolicy, testCfg, SaveOptions{Pages: mockPages})
+
+	t.Run("queued for new links", func(t *testing.T) {
+		mockSaver.EXPECT().Save(gomock.Any(), 7, "", "https://example.com", domain.LinkMeta{}).Return("abc", nil)
+		mockSaver.EXPECT().Save(gomock.Any(), 7, "", "https://example.org", domain.LinkMeta{}).Return("def", nil)
+		mockPages.EXPECT().Enqueue("abc", "https://example.com")
+		mockPages.EXPECT().Enqueue("def", "https://example.org")
+
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten/batch", bytes.NewBufferString(
+			`[{"correlation_id":"1","original_url":"https://example.com"},{"correlation_id":"2","original_url":"https://example.org"}]`))
+		req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 7))
+		w := httptest.NewRecorder()
+		saveHandler.PostHandlerBatch(w, req)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("not queued for existing URL", func(t *testing.T) {
+		mockSaver.EXPECT().Save(gomock.Any(), 7, "", "https://example.com", domain.LinkMeta{}).Return("abc", appErrors.ErrURLExists)
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("https://example.com"))
+		req.Header.Set("Content-Type", "text/plain")
+		req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 7))
+		w := httptest.NewRecorder()
+		saveHandler.PostHandler(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestWorkspaceHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWorkspaces := mocks.NewMockWorkspaceManager(ctrl)
+	mockSaver := mocks.NewMockURLSaver(ctrl)
+	mockGetter := mocks.NewMockURLGetter(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	urlPolicy, err := policy.New(testCfg.BaseURL, "")
+	require.NoError(t, err)
+
+	workspaceHandler := NewWorkspaceHandler(mockWorkspaces, &config.Config{}, nil)
+	saveHandler := NewSaveHandler(mockSaver, urlPolicy, testCfg, SaveOptions{Workspaces: mockWorkspaces})
+	getHandler := NewGetterHandler(mockGetter, testCfg, GetterOptions{Workspaces: mockWorkspaces})
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), domain.UserIDKey, 7)))
+		})
+	})
+	r.Get("/workspaces", workspaceHandler.ListWorkspacesHandler)
+	r.Post("/workspaces", workspaceHandler.CreateWorkspaceHandler)
+	r.Get("/workspaces/{id}/members", workspaceHandler.ListMembersHandler)
+	r.Put("/workspaces/{id}/members/{userID}", workspaceHandler.SetMemberHandler)
+	r.Delete("/workspaces/{id}/members/{userID}", workspaceHandler.RemoveMemberHandler)
+	r.Post("/workspaces/{id}/urls", workspaceHandler.TransferLinksHandler)
+	r.Post("/shorten", saveHandler.PostHandlerJSON)
+	r.Get("/urls", getHandler.GetUserURLsHandler)
+
+	testCases := []struct {
+		name      string
+		method    string
+		target    string
+		body      string
+		mockSetup func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			target: "/workspaces",
+			body:   `{"name":"Marketing"}`,
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().CreateWorkspace(gomock.Any(), 7, "Marketing").
+					Return(domain.Workspace{ID: "ws1", Name: "Marketing", Role: domain.RoleOwner}, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `"role":"owner"`,
+		},
+		{
+			name:   "create without name",
+			method: http.MethodPost,
+			target: "/workspaces",
+			body:   `{"name":""}`,
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().CreateWorkspace(gomock.Any(), 7, "").Return(domain.Workspace{}, appErrors.ErrInvalidWorkspace)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_workspace"`,
+		},
+		{
+			name:   "list",
+			method: http.MethodGet,
+			target: "/workspaces",
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().ListWorkspaces(gomock.Any(), 7).Return(nil, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[]`,
+		},
+		{
+			name:   "members of a foreign workspace",
+			method: http.MethodGet,
+			target: "/workspaces/ws2/members",
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().ListMembers(gomock.Any(), 7, "ws2").Return(nil, appErrors.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `"code":"not_found"`,
+		},
+		{
+			name:   "invite",
+			method: http.MethodPut,
+			target: "/workspaces/ws1/members/1000001",
+			body:   `{"role":"editor"}`,
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().SetMember(gomock.Any(), 7, "ws1", 1000001, domain.RoleEditor).
+					Return(domain.WorkspaceMember{WorkspaceID: "ws1", UserID: 1000001, Role: domain.RoleEditor}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"user_id":1000001`,
+		},
+		{
+			name:     "invite with invalid user ID",
+			method:   http.MethodPut,
+			target:   "/workspaces/ws1/members/alice",
+			body:     `{"role":"editor"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_parameter"`,
+		},
+		{
+			name:   "last owner leaves",
+			method: http.MethodDelete,
+			target: "/workspaces/ws1/members/7",
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().RemoveMember(gomock.Any(), 7, "ws1", 7).Return(appErrors.ErrLastOwner)
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `"code":"last_owner"`,
+		},
+		{
+			name:   "transfer as viewer",
+			method: http.MethodPost,
+			target: "/workspaces/ws1/urls",
+			body:   `["abc"]`,
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().TransferLinks(gomock.Any(), 7, "ws1", []string{"abc"}).Return(appErrors.ErrForbidden)
+			},
+			wantCode: http.StatusForbidden,
+			wantBody: `"code":"forbidden"`,
+		},
+		{
+			name:     "transfer nothing",
+			method:   http.MethodPost,
+			target:   "/workspaces/ws1/urls",
+			body:     `[]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"empty_batch"`,
+		},
+		{
+			name:   "shorten into workspace",
+			method: http.MethodPost,
+			target: "/shorten",
+			body:   `{"url":"https://example.com","workspace":" ws1 "}`,
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().Authorize(gomock.Any(), 7, "ws1", domain.RoleEditor).Return(nil)
+				mockSaver.EXPECT().Save(gomock.Any(), 7, "", "https://example.com", domain.LinkMeta{Workspace: "ws1"}).Return("abc", nil)
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `"result":"http://localhost:8080/abc"`,
+		},
+		{
+			name:   "shorten into workspace as viewer",
+			method: http.MethodPost,
+			target: "/shorten",
+			body:   `{"url":"https://example.com","workspace":"ws1"}`,
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().Authorize(gomock.Any(), 7, "ws1", domain.RoleEditor).Return(appErrors.ErrForbidden)
+			},
+			wantCode: http.StatusForbidden,
+			wantBody: `"workspace":"ws1"`,
+		},
+		{
+			name:   "list workspace links",
+			method: http.MethodGet,
+			target: "/urls?workspace=ws1",
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().Authorize(gomock.Any(), 7, "ws1", domain.RoleViewer).Return(nil)
+				mockGetter.EXPECT().GetUserURLs(gomock.Any(), 7, domain.LinkFilter{Workspace: "ws1"}).
+					Return([]domain.Link{{ID: "abc", OriginalURL: "https://example.com", UserID: 9, LinkMeta: domain.LinkMeta{Workspace: "ws1"}}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"workspace":"ws1"`,
+		},
+		{
+			name:   "list links of a foreign workspace",
+			method: http.MethodGet,
+			target: "/urls?workspace=ws2",
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().Authorize(gomock.Any(), 7, "ws2", domain.RoleViewer).Return(appErrors.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `"code":"not_found"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockSetup != nil {
+				tc.mockSetup()
+			}
+
+			req := httptest.NewRequest(tc.method, tc.target, bytes.NewBufferString(tc.body))
+			req.Header.Set(contentType, contentTypeApp)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantBody != "" {
+				require.Contains(t, w.Body.String(), tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestModerationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModeration := mocks.NewMockLinkModerator(ctrl)
+	mockChecker := mocks.NewMockModerationChecker(ctrl)
+	mockGetter := mocks.NewMockURLGetter(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+
+	moderationHandler := NewModerationHandler(mockModeration, testCfg, nil)
+	getHandler := NewGetterHandler(mockGetter, testCfg, GetterOptions{Moderation: mockChecker})
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), domain.UserIDKey, domain.FirstRegisteredUserID)))
+		})
+	})
+	r.Get("/{id}", getHandler.GetHandler)
+	r.Post("/{id}/report", moderationHandler.ReportHandler)
+	r.Get("/admin/moderation", moderationHandler.QueueHandler)
+	r.Get("/admin/moderation/{id}", moderationHandler.ReviewHandler)
+	r.Post("/admin/moderation/{id}", moderationHandler.ModerateHandler)
+	r.Get("/admin/moderation-actions", moderationHandler.ActionsHandler)
+
+	testCases := []struct {
+		name      string
+		method    string
+		target    string
+		accept    string
+		body      string
+		mockSetup func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "report",
+			method: http.MethodPost,
+			target: "/abc/report",
+			body:   `{"reason":"phishing","details":"fake bank login"}`,
+			mockSetup: func() {
+				mockModeration.EXPECT().Report(gomock.Any(), "abc", "192.0.2.1",
+					domain.AbuseReportRequest{Reason: domain.ReportPhishing, Details: "fake bank login"}).
+					Return(domain.AbuseReport{ID: "r1", LinkID: "abc", Reason: domain.ReportPhishing, ReporterIP: "192.0.2.1"}, nil)
+			},
+			wantCode: http.StatusAccepted,
+			wantBody: `"link_id":"abc"`,
+		},
+		{
+			name:   "report with unknown reason",
+			method: http.MethodPost,
+			target: "/abc/report",
+			body:   `{"reason":"boring"}`,
+			mockSetup: func() {
+				mockModeration.EXPECT().Report(gomock.Any(), "abc", gomock.Any(), gomock.Any()).
+					Return(domain.AbuseReport{}, appErrors.ErrInvalidReport)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_report"`,
+		},
+		{
+			name:     "report with invalid JSON",
+			method:   http.MethodPost,
+			target:   "/abc/report",
+			body:     `{`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_json"`,
+		},
+		{
+			name:   "pending queue",
+			method: http.MethodGet,
+			target: "/admin/moderation",
+			mockSetup: func() {
+				mockModeration.EXPECT().Queue(gomock.Any(), domain.QueuePending).
+					Return([]domain.ModerationCase{{LinkID: "abc", PendingReports: 2, Reasons: []string{domain.ReportSpam}}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"short_url":"http://localhost:8080/abc"`,
+		},
+		{
+			name:   "empty disabled queue",
+			method: http.MethodGet,
+			target: "/admin/moderation?queue=disabled",
+			mockSetup: func() {
+				mockModeration.EXPECT().Queue(gomock.Any(), domain.QueueDisabled).Return(nil, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[]`,
+		},
+		{
+			name:     "unknown queue",
+			method:   http.MethodGet,
+			target:   "/admin/moderation?queue=all",
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_parameter"`,
+		},
+		{
+			name:   "review",
+			method: http.MethodGet,
+			target: "/admin/moderation/abc",
+			mockSetup: func() {
+				mockModeration.EXPECT().Review(gomock.Any(), "abc").Return(domain.ModerationReview{
+					ModerationCase: domain.ModerationCase{LinkID: "abc", Reasons: []string{}},
+					Reports:        []domain.AbuseReport{{ID: "r1", LinkID: "abc", Reason: domain.ReportSpam, ReporterIP: "192.0.2.1"}},
+					Actions:        []domain.ModerationAction{},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"reports":[{"id":"r1"`,
+		},
+		{
+			name:   "disable",
+			method: http.MethodPost,
+			target: "/admin/moderation/abc",
+			body:   `{"action":"disable","note":"confirmed phishing"}`,
+			mockSetup: func() {
+				mockModeration.EXPECT().Moderate(gomock.Any(), domain.FirstRegisteredUserID, "abc",
+					domain.ModerationRequest{Action: domain.ModerationDisable, Note: "confirmed phishing"}).
+					Return(domain.ModerationAction{ID: "m1", LinkID: "abc", ModeratorID: domain.FirstRegisteredUserID, Action: domain.ModerationDisable}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"action":"disable"`,
+		},
+		{
+			name:   "unknown action",
+			method: http.MethodPost,
+			target: "/admin/moderation/abc",
+			body:   `{"action":"delete"}`,
+			mockSetup: func() {
+				mockModeration.EXPECT().Moderate(gomock.Any(), gomock.Any(), "abc", gomock.Any()).
+					Return(domain.ModerationAction{}, appErrors.ErrInvalidModeration)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_moderation"`,
+		},
+		{
+			name:   "actions of a link",
+			method: http.MethodGet,
+			target: "/admin/moderation-actions?link=abc",
+			mockSetup: func() {
+				mockModeration.EXPECT().Actions(gomock.Any(), "abc").Return(nil, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[]`,
+		},
+		{
+			name:   "redirect of a disabled link",
+			method: http.MethodGet,
+			target: "/abc",
+			mockSetup: func() {
+				mockGetter.EXPECT().Get(gomock.Any(), gomock.Any(), "abc").Return(domain.Redirect{OriginalURL: "https://example.com"}, true, false)
+				mockChecker.EXPECT().Disabled(gomock.Any(), "abc").Return(true, nil)
+			},
+			wantCode: http.StatusUnavailableForLegalReasons,
+			wantBody: `"code":"link_disabled"`,
+		},
+		{
+			name:   "browser following a disabled link",
+			method: http.MethodGet,
+			target: "/abc",
+			accept: "text/html,application/xhtml+xml",
+			mockSetup: func() {
+				mockGetter.EXPECT().Get(gomock.Any(), gomock.Any(), "abc").Return(domain.Redirect{OriginalURL: "https://example.com"}, true, false)
+				mockChecker.EXPECT().Disabled(gomock.Any(), "abc").Return(true, nil)
+			},
+			wantCode: http.StatusUnavailableForLegalReasons,
+			wantBody: `This link has been disabled`,
+		},
+		{
+			name:   "redirect of a cleared link",
+			method: http.MethodGet,
+			target: "/abc",
+			mockSetup: func() {
+				mockGetter.EXPECT().Get(gomock.Any(), gomock.Any(), "abc").Return(domain.Redirect{OriginalURL: "https://example.com"}, true, false)
+				mockChecker.EXPECT().Disabled(gomock.Any(), "abc").Return(false, nil)
+			},
+			wantCode: http.StatusTemporaryRedirect,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockSetup != nil {
+				tc.mockSetup()
+			}
+
+			req := httptest.NewRequest(tc.method, tc.target, bytes.NewBufferString(tc.body))
+			req.Header.Set(contentType, contentTypeApp)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantBody != "" {
+				require.Contains(t, w.Body.String(), tc.wantBody)
+			}
+			require.NotContains(t, w.Body.String(), "192.0.2.1")
+		})
+	}
+}
+
+func TestPickVariant(t *testing.T) {
+	variants := []domain.Variant{{URL: "https://a.example.com", Weight: 3}, {URL: "https://b.example.com", Weight: 1}}
+
+	counts := make([]int, len(variants))
+	for i := 0; i < 4000; i++ {
+		visitor := fmt.Sprintf("ip:10.0.%d.%d", i/256, i%256)
+		picked := pickVariant(variants, "abc", visitor)
+		require.Equal(t, picked, pickVariant(variants, "abc", visitor), "visitors must stay on their variant")
+		counts[picked]++
+	}
+
+	require.InDelta(t, 3000, counts[0], 200)
+	require.InDelta(t, 1000, counts[1], 200)
+}
+
+func TestVariants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGetter := mocks.NewMockURLGetter(ctrl)
+	mockRouter := mocks.NewMockVariantRouter(ctrl)
+	mockVariants := mocks.NewMockURLVariants(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	urlPolicy, err := policy.New(testCfg.BaseURL, "")
+	require.NoError(t, err)
+
+	getHandler := NewGetterHandler(mockGetter, testCfg, GetterOptions{Variants: mockRouter})
+	variantHandler := NewVariantHandler(mockVariants, urlPolicy, testCfg, nil)
+
+	variants := []domain.Variant{{URL: "https://a.example.com", Weight: 1}, {URL: "https://b.example.com", Weight: 1}}
+
+	t.Run("redirect is sticky per visitor", func(t *testing.T) {
+		// the auth cookie is only set on the first visit, the variant must not change once it comes back
+		tokens := middleware.NewTokenManager(middleware.NewHMACKey("test", "secret"), time.Hour, false)
+		r := chi.NewRouter()
+		r.Use(middleware.AuthMiddleware(tokens, nil))
+		r.Get("/{id}", getHandler.GetHandler)
+		ts := httptest.NewServer(r)
+		defer ts.Close()
+
+		for i := 0; i < 20; i++ {
+			id := fmt.Sprintf("ab%d", i)
+			mockGetter.EXPECT().Get(gomock.Any(), "", id).Return(domain.Redirect{OriginalURL: "https://example.com"}, true, false).Times(2)
+			mockRouter.EXPECT().GetVariants(gomock.Any(), id).Return(variants, nil).Times(2)
+			mockRouter.EXPECT().CountVariantClick(gomock.Any(), id, gomock.Any()).Return(nil).Times(2)
+
+			jar, err := cookiejar.New(nil)
+			require.NoError(t, err)
+			client := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+			var targets []string
+			for visit := 0; visit < 2; visit++ {
+				resp, err := client.Get(ts.URL + "/" + id)
+				require.NoError(t, err)
+				resp.Body.Close()
+				require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+				targets = append(targets, resp.Header.Get("Location"))
+			}
+			require.Equal(t, targets[0], targets[1], "visitors must stay on their variant")
+		}
+	})
+
+	t.Run("visitor cookie picks the variant", func(t *testing.T) {
+		mockGetter.EXPECT().Get(gomock.Any(), "", "abc").Return(domain.Redirect{OriginalURL: "https://example.com"}, true, false)
+		mockRouter.EXPECT().GetVariants(gomock.Any(), "abc").Return(variants, nil)
+		mockRouter.EXPECT().CountVariantClick(gomock.Any(), "abc", gomock.Any()).Return(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+		req.AddCookie(&http.Cookie{Name: visitorCookieName, Value: "v7"})
+		w := httptest.NewRecorder()
+		getHandler.GetHandler(w, req)
+
+		require.Equal(t, variants[pickVariant(variants, "abc", "v7")].URL, w.Header().Get("Location"))
+		require.Empty(t, w.Result().Cookies(), "a visitor with a cookie keeps it")
+	})
+
+	t.Run("plain link", func(t *testing.T) {
+		mockGetter.EXPECT().Get(gomock.Any(), "", "plain").Return(domain.Redirect{OriginalURL: "https://example.com"}, true, false)
+		mockRouter.EXPECT().GetVariants(gomock.Any(), "plain").Return(nil, nil)
+
+		w := httptest.NewRecorder()
+		getHandler.GetHandler(w, httptest.NewRequest(http.MethodGet, "/plain", nil))
+
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+		require.Equal(t, "https://example.com", w.Header().Get("Location"))
+	})
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), domain.UserIDKey, 7)))
+		})
+	})
+	r.Get("/urls/{id}/variants", variantHandler.ListVariantsHandler)
+	r.Put("/urls/{id}/variants", variantHandler.SetVariantsHandler)
+
+	testCases := []struct {
+		name      string
+		method    string
+		body      string
+		mockSetup func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "set",
+			method: http.MethodPut,
+			body:   `[{"url":"https://a.example.com","weight":1,"clicks":99},{"url":"https://b.example.com","weight":1}]`,
+			mockSetup: func() {
+				mockVariants.EXPECT().SetVariants(gomock.Any(), 7, "abc", variants).
+					Return([]domain.Variant{{URL: "https://a.example.com", Weight: 1, Clicks: 5}, {URL: "https://b.example.com", Weight: 1}}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"clicks":5`,
+		},
+		{
+			name:     "zero weight",
+			method:   http.MethodPut,
+			body:     `[{"url":"https://a.example.com","weight":0}]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_variants"`,
+		},
+		{
+			name:     "rejected URL",
+			method:   http.MethodPut,
+			body:     `[{"url":"https://a.example.com","weight":1},{"url":"ftp://b.example.com","weight":1}]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"variant":1`,
+		},
+		{
+			name:   "list someone else's link",
+			method: http.MethodGet,
+			mockSetup: func() {
+				mockVariants.EXPECT().UserVariants(gomock.Any(), 7, "abc").Return(nil, appErrors.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `"code":"not_found"`,
+		},
+		{
+			name:   "list plain link",
+			method: http.MethodGet,
+			mockSetup: func() {
+				mockVariants.EXPECT().UserVariants(gomock.Any(), 7, "abc").Return(nil, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockSetup != nil {
+				tc.mockSetup()
+			}
+
+			req := httptest.NewRequest(tc.method, "/urls/abc/variants", bytes.NewBufferString(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			require.Contains(t, w.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestMatchRules(t *testing.T) {
+	const (
+		iPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+		android = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+		mac     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Safari/605.1.15"
+	)
+
+	rules := []domain.RedirectRule{
+		{Platforms: []string{domain.PlatformIOS}, URL: "https://apps.apple.com/app/id1"},
+		{Platforms: []string{domain.PlatformAndroid}, URL: "https://play.google.com/store/apps/details?id=app"},
+		{Languages: []string{"pt"}, URL: "https://example.com/pt"},
+		{TimeFrom: "22:00", TimeTo: "06:00", URL: "https://example.com/night"},
+	}
+	noon := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		userAgent string
+		language  string
+		now       time.Time
+		wantURL   string
+	}{
+		{name: "iOS", userAgent: iPhone, language: "pt-BR", now: noon, wantURL: "https://apps.apple.com/app/id1"},
+		{name: "Android", userAgent: android, now: noon, wantURL: "https://play.google.com/store/apps/details?id=app"},
+		{name: "macOS is not iOS", userAgent: mac, now: noon},
+		{name: "regional language", userAgent: mac, language: "en;q=0.5, pt-BR", now: noon, wantURL: "https://example.com/pt"},
+		{name: "preferred language only", userAgent: mac, language: "en, pt;q=0.8", now: noon},
+		{name: "night before midnight", userAgent: mac, now: noon.Add(11 * time.Hour), wantURL: "https://example.com/night"},
+		{name: "night after midnight", userAgent: mac, now: noon.Add(17 * time.Hour), wantURL: "https://example.com/night"},
+		{name: "window end is exclusive", userAgent: mac, now: noon.Add(18 * time.Hour)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+			req.Header.Set("User-Agent", tc.userAgent)
+			if tc.language != "" {
+				req.Header.Set("Accept-Language", tc.language)
+			}
+
+			url, ok := matchRules(rules, req, tc.now)
+			require.Equal(t, tc.wantURL != "", ok)
+			require.Equal(t, tc.wantURL, url)
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGetter := mocks.NewMockURLGetter(ctrl)
+	mockVariants := mocks.NewMockVariantRouter(ctrl)
+	mockRules := mocks.NewMockURLRules(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	urlPolicy, err := policy.New(testCfg.BaseURL, "")
+	require.NoError(t, err)
+
+	getHandler := NewGetterHandler(mockGetter, testCfg, GetterOptions{Variants: mockVariants})
+	ruleHandler := NewRuleHandler(mockRules, urlPolicy, testCfg, nil)
+
+	rules := []domain.RedirectRule{{Platforms: []string{domain.PlatformAndroid}, URL: "https://play.google.com/store/apps/details?id=app"}}
+
+	t.Run("matching rule wins over variants", func(t *testing.T) {
+		mockGetter.EXPECT().Get(gomock.Any(), "", "abc").Return(domain.Redirect{OriginalURL: "https://example.com", Rules: rules}, true, false)
+
+		req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Linux; Android 14)")
+		w := httptest.NewRecorder()
+		getHandler.GetHandler(w, req)
+
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+		require.Equal(t, rules[0].URL, w.Header().Get("Location"))
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		mockGetter.EXPECT().Get(gomock.Any(), "", "abc").Return(domain.Redirect{OriginalURL: "https://example.com", Rules: rules}, true, false)
+		mockVariants.EXPECT().GetVariants(gomock.Any(), "abc").Return(nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64)")
+		w := httptest.NewRecorder()
+		getHandler.GetHandler(w, req)
+
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+		require.Equal(t, "https://example.com", w.Header().Get("Location"))
+	})
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), domain.UserIDKey, 7)))
+		})
+	})
+	r.Get("/urls/{id}/rules", ruleHandler.ListRulesHandler)
+	r.Put("/urls/{id}/rules", ruleHandler.SetRulesHandler)
+
+	testCases := []struct {
+		name      string
+		method    string
+		body      string
+		mockSetup func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "set",
+			method: http.MethodPut,
+			body:   `[{"platforms":["iOS"],"url":"https://apps.apple.com/app/id1"},{"languages":["pt-br"],"time_from":"09:00","time_to":"18:00","url":"https://example.com/pt"}]`,
+			mockSetup: func() {
+				mockRules.EXPECT().SetRules(gomock.Any(), 7, "abc", []domain.RedirectRule{
+					{Platforms: []string{domain.PlatformIOS}, URL: "https://apps.apple.com/app/id1"},
+					{Languages: []string{"pt-BR"}, TimeFrom: "09:00", TimeTo: "18:00", URL: "https://example.com/pt"},
+				}).DoAndReturn(func(ctx context.Context, userID int, id string, rules []domain.RedirectRule) ([]domain.RedirectRule, error) {
+					return rules, nil
+				})
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"platforms":["ios"]`,
+		},
+		{
+			name:     "no conditions",
+			method:   http.MethodPut,
+			body:     `[{"url":"https://example.com"}]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_rules"`,
+		},
+		{
+			name:     "unknown platform",
+			method:   http.MethodPut,
+			body:     `[{"platforms":["ios"],"url":"https://example.com"},{"platforms":["symbian"],"url":"https://example.com"}]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"index":1`,
+		},
+		{
+			name:     "half a time window",
+			method:   http.MethodPut,
+			body:     `[{"time_from":"09:00","url":"https://example.com"}]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_rules"`,
+		},
+		{
+			name:     "rejected URL",
+			method:   http.MethodPut,
+			body:     `[{"platforms":["ios"],"url":"ftp://example.com"}]`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"url_rejected"`,
+		},
+		{
+			name:   "someone else's link",
+			method: http.MethodPut,
+			body:   `[]`,
+			mockSetup: func() {
+				mockRules.EXPECT().SetRules(gomock.Any(), 7, "abc", []domain.RedirectRule{}).Return(nil, appErrors.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `"code":"not_found"`,
+		},
+		{
+			name:   "list",
+			method: http.MethodGet,
+			mockSetup: func() {
+				mockRules.EXPECT().UserRules(gomock.Any(), 7, "abc").Return(rules, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"platforms":["android"]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockSetup != nil {
+				tc.mockSetup()
+			}
+
+			req := httptest.NewRequest(tc.method, "/urls/abc/rules", bytes.NewBufferString(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			require.Contains(t, w.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestMergeQuery(t *testing.T) {
+	utm := domain.UTM{Source: "newsletter", Medium: "email"}
+
+	testCases := []struct {
+		name        string
+		destination string
+		opts        domain.QueryOptions
+		incoming    string
+		want        string
+		wantErr     bool
+	}{
+		{name: "nothing to merge", destination: "https://example.com/p?b=2&a=1", incoming: "ref=x", want: "https://example.com/p?b=2&a=1"},
+		{name: "utm", destination: "https://example.com/p#top", opts: domain.QueryOptions{UTM: utm}, want: "https://example.com/p?utm_medium=email&utm_source=newsletter#top"},
+		{name: "destination utm wins", destination: "https://example.com/?utm_source=ads", opts: domain.QueryOptions{UTM: utm}, want: "https://example.com/?utm_medium=email&utm_source=ads"},
+		{name: "forward", destination: "https://example.com/", opts: domain.QueryOptions{Forward: true}, incoming: "ref=x", want: "https://example.com/?ref=x"},
+		{name: "keep on conflict", destination: "https://example.com/?ref=a", opts: domain.QueryOptions{Forward: true}, incoming: "ref=x&b=1", want: "https://example.com/?b=1&ref=a"},
+		{name: "override on conflict", destination: "https://example.com/?ref=a", opts: domain.QueryOptions{Forward: true, Conflict: domain.QueryConflictOverride}, incoming: "ref=x", want: "https://example.com/?ref=x"},
+		{name: "append on conflict", destination: "https://example.com/?ref=a", opts: domain.QueryOptions{Forward: true, Conflict: domain.QueryConflictAppend}, incoming: "ref=x", want: "https://example.com/?ref=a&ref=x"},
+		{name: "forwarded utm overrides defaults", destination: "https://example.com/", opts: domain.QueryOptions{UTM: utm, Forward: true, Conflict: domain.QueryConflictOverride}, incoming: "utm_source=tw", want: "https://example.com/?utm_medium=email&utm_source=tw"},
+		{name: "malformed incoming", destination: "https://example.com/", opts: domain.QueryOptions{Forward: true}, incoming: "ref=%zz", wantErr: true},
+		{name: "malformed destination", destination: "https://example.com/?a=%zz", opts: domain.QueryOptions{UTM: utm}, wantErr: true},
+		{name: "too long", destination: "https://example.com/", opts: domain.QueryOptions{Forward: true}, incoming: "a=" + strings.Repeat("x", maxMergedURLLength), wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := mergeQuery(tc.destination, tc.opts, tc.incoming)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestQueryOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGetter := mocks.NewMockURLGetter(ctrl)
+	mockQuery := mocks.NewMockURLQueryOptions(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+
+	getHandler := NewGetterHandler(mockGetter, testCfg, GetterOptions{})
+	queryHandler := NewQueryHandler(mockQuery, testCfg, nil)
+
+	opts := domain.QueryOptions{UTM: domain.UTM{Campaign: "spring"}, Forward: true}
+
+	t.Run("redirect merges query", func(t *testing.T) {
+		mockGetter.EXPECT().Get(gomock.Any(), "", "abc").Return(domain.Redirect{OriginalURL: "https://example.com/?ref=a", Query: opts}, true, false)
+
+		w := httptest.NewRecorder()
+		getHandler.GetHandler(w, httptest.NewRequest(http.MethodGet, "/abc?ref=x&page=2", nil))
+
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+		require.Equal(t, "https://example.com/?page=2&ref=a&utm_campaign=spring", w.Header().Get("Location"))
+	})
+
+	t.Run("malformed query is not forwarded", func(t *testing.T) {
+		mockGetter.EXPECT().Get(gomock.Any(), "", "abc").Return(domain.Redirect{OriginalURL: "https://example.com/", Query: opts}, true, false)
+
+		req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+		req.URL.RawQuery = "ref=%zz"
+		w := httptest.NewRecorder()
+		getHandler.GetHandler(w, req)
+
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+		require.Equal(t, "https://example.com/?utm_campaign=spring", w.Header().Get("Location"))
+	})
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), domain.UserIDKey, 7)))
+		})
+	})
+	r.Get("/urls/{id}/query", queryHandler.GetQueryOptionsHandler)
+	r.Put("/urls/{id}/query", queryHandler.SetQueryOptionsHandler)
+
+	testCases := []struct {
+		name      string
+		method    string
+		body      string
+		mockSetup func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "set",
+			method: http.MethodPut,
+			body:   `{"utm":{"utm_source":" newsletter "},"forward":true,"conflict":"append"}`,
+			mockSetup: func() {
+				want := domain.QueryOptions{UTM: domain.UTM{Source: "newsletter"}, Forward: true, Conflict: domain.QueryConflictAppend}
+				mockQuery.EXPECT().SetQueryOptions(gomock.Any(), 7, "abc", want).Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"utm_source":"newsletter"`,
+		},
+		{
+			name:     "unknown conflict mode",
+			method:   http.MethodPut,
+			body:     `{"forward":true,"conflict":"merge"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"field":"conflict"`,
+		},
+		{
+			name:     "conflict without forwarding",
+			method:   http.MethodPut,
+			body:     `{"conflict":"override"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_query_options"`,
+		},
+		{
+			name:     "control characters",
+			method:   http.MethodPut,
+			body:     `{"utm":{"utm_term":"a\nb"}}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `"field":"utm_term"`,
+		},
+		{
+			name:   "someone else's link",
+			method: http.MethodGet,
+			mockSetup: func() {
+				mockQuery.EXPECT().UserQueryOptions(gomock.Any(), 7, "abc").Return(domain.QueryOptions{}, appErrors.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: `"code":"not_found"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockSetup != nil {
+				tc.mockSetup()
+			}
+
+			req := httptest.NewRequest(tc.method, "/urls/abc/query", bytes.NewBufferString(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			require.Contains(t, w.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestAuditHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAudit := mocks.NewMockAuditLog(ctrl)
+	auditHandler := NewAuditHandler(mockAudit)
+
+	r := chi.NewRouter()
+	r.Get("/admin/audit", auditHandler.QueryHandler)
+	r.Get("/admin/audit/export", auditHandler.ExportHandler)
+
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	entries := []domain.AuditEntry{
+		{ID: "e2", CreatedAt: at.Add(time.Hour), ActorID: 1000000, Action: domain.AuditUpdate, LinkID: "abc"},
+		{ID: "e1", CreatedAt: at, ActorID: 1000000, Action: domain.AuditCreate, LinkID: "abc"},
+	}
+
+	testCases := []struct {
+		name      string
+		target    string
+		mockSetup func()
+		wantCode  int
+		wantType  string
+		wantBody  string
+	}{
+		{
+			name:   "query",
+			target: "/admin/audit?actor=1000000&link=abc&from=2024-05-01T00:00:00Z&limit=10",
+			mockSetup: func() {
+				mockAudit.EXPECT().Query(gomock.Any(), domain.AuditFilter{
+					ActorID: 1000000, LinkID: "abc", From: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Limit: 10,
+				}).Return(entries, nil)
+			},
+			wantCode: http.StatusOK,
+			wantType: contentTypeApp,
+			wantBody: `"id":"e2"`,
+		},
+		{
+			name:   "empty query",
+			target: "/admin/audit?action=delete",
+			mockSetup: func() {
+				mockAudit.EXPECT().Query(gomock.Any(), domain.AuditFilter{Action: domain.AuditDelete}).Return(nil, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[]`,
+		},
+		{
+			name:     "invalid time",
+			target:   "/admin/audit?from=yesterday",
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_parameter"`,
+		},
+		{
+			name:   "invalid filter",
+			target: "/admin/audit?limit=5000",
+			mockSetup: func() {
+				mockAudit.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, appErrors.ErrInvalidAuditFilter)
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_parameter"`,
+		},
+		{
+			name:   "export",
+			target: "/admin/audit/export?link=abc",
+			mockSetup: func() {
+				mockAudit.EXPECT().Export(gomock.Any(), domain.AuditFilter{LinkID: "abc"}).Return(entries, nil)
+			},
+			wantCode: http.StatusOK,
+			wantType: contentTypeNDJSON,
+			wantBody: "\"id\":\"e2\"",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockSetup != nil {
+				tc.mockSetup()
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantType != "" {
+				require.Equal(t, tc.wantType, w.Header().Get(contentType))
+			}
+			require.Contains(t, w.Body.String(), tc.wantBody)
+		})
+	}
+
+	t.Run("export writes one entry per line", func(t *testing.T) {
+		mockAudit.EXPECT().Export(gomock.Any(), domain.AuditFilter{}).Return(entries, nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit/export", nil))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		var entry domain.AuditEntry
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+		require.Equal(t, "e1", entry.ID)
+	})
+}
+
+func TestUpdateLinkHandler_RecordsAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mocks.NewMockURLUpdater(ctrl)
+	mockAudit := mocks.NewMockAuditRecorder(ctrl)
+	updateHandler := NewUpdateHandler(mockUpdater, &config.Config{BaseURL: "http://localhost:8080"}, mockAudit)
+
+	before := &domain.AuditState{OriginalURL: "https://example.com"}
+	mockAudit.EXPECT().Snapshot(gomock.Any(), "abc").Return(before, nil)
+	mockUpdater.EXPECT().UpdateLink(gomock.Any(), 7, "abc", gomock.Any()).
+		Return(domain.Link{ID: "abc", OriginalURL: "https://example.com", LinkMeta: domain.LinkMeta{Title: "Example"}}, nil)
+	mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, entries ...domain.AuditEntry) error {
+			require.Len(t, entries, 1)
+			require.Equal(t, domain.AuditUpdate, entries[0].Action)
+			require.Equal(t, 7, entries[0].ActorID)
+			require.Equal(t, "192.0.2.1", entries[0].IP)
+			require.Equal(t, before, entries[0].Before)
+			require.Equal(t, "Example", entries[0].After.Title)
+			return nil
+		})
+
+	r := chi.NewRouter()
+	r.Patch("/user/urls/{id}", updateHandler.UpdateLinkHandler)
+
+	req := httptest.NewRequest(http.MethodPatch, "/user/urls/abc", bytes.NewBufferString(`{"title":"Example"}`))
+	req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 7))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestSettingsHandlers_RecordAudit checks that changing how a link redirects, moving it into a workspace and
+// moderating it are recorded with the link before and after the change
+func TestSettingsHandlers_RecordAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	urlPolicy, err := policy.New(testCfg.BaseURL, "")
+	require.NoError(t, err)
+
+	mockAudit := mocks.NewMockAuditRecorder(ctrl)
+	mockVariants := mocks.NewMockURLVariants(ctrl)
+	mockRules := mocks.NewMockURLRules(ctrl)
+	mockQuery := mocks.NewMockURLQueryOptions(ctrl)
+	mockWorkspaces := mocks.NewMockWorkspaceManager(ctrl)
+	mockModeration := mocks.NewMockLinkModerator(ctrl)
+
+	r := chi.NewRouter()
+	r.Put("/urls/{id}/variants", NewVariantHandler(mockVariants, urlPolicy, testCfg, mockAudit).SetVariantsHandler)
+	r.Put("/urls/{id}/rules", NewRuleHandler(mockRules, urlPolicy, testCfg, mockAudit).SetRulesHandler)
+	r.Put("/urls/{id}/query", NewQueryHandler(mockQuery, testCfg, mockAudit).SetQueryOptionsHandler)
+	r.Post("/workspaces/{id}/urls", NewWorkspaceHandler(mockWorkspaces, testCfg, mockAudit).TransferLinksHandler)
+	r.Post("/moderation/{id}", NewModerationHandler(mockModeration, testCfg, mockAudit).ModerateHandler)
+
+	variants := []domain.Variant{{URL: "https://a.example.com", Weight: 1}}
+	rules := []domain.RedirectRule{{Platforms: []string{domain.PlatformIOS}, URL: "https://apps.apple.com/app/id1"}}
+	opts := domain.QueryOptions{UTM: domain.UTM{Source: "newsletter"}}
+
+	testCases := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		mockSetup  func()
+		wantAction string
+		wantBefore domain.AuditState
+		wantAfter  domain.AuditState
+	}{
+		{
+			name:   "variants",
+			method: http.MethodPut,
+			target: "/urls/abc/variants",
+			body:   `[{"url":"https://a.example.com","weight":1}]`,
+			mockSetup: func() {
+				mockVariants.EXPECT().UserVariants(gomock.Any(), 7, "abc").Return(nil, nil)
+				mockVariants.EXPECT().SetVariants(gomock.Any(), 7, "abc", variants).Return(variants, nil)
+			},
+			wantAction: domain.AuditVariants,
+			wantBefore: domain.AuditState{OriginalURL: "https://example.com"},
+			wantAfter:  domain.AuditState{OriginalURL: "https://example.com", Variants: variants},
+		},
+		{
+			name:   "rules",
+			method: http.MethodPut,
+			target: "/urls/abc/rules",
+			body:   `[{"platforms":["ios"],"url":"https://apps.apple.com/app/id1"}]`,
+			mockSetup: func() {
+				mockRules.EXPECT().UserRules(gomock.Any(), 7, "abc").Return(rules, nil)
+				mockRules.EXPECT().SetRules(gomock.Any(), 7, "abc", rules).Return(rules, nil)
+			},
+			wantAction: domain.AuditRules,
+			wantBefore: domain.AuditState{OriginalURL: "https://example.com", Rules: rules},
+			wantAfter:  domain.AuditState{OriginalURL: "https://example.com", Rules: rules},
+		},
+		{
+			name:   "query options",
+			method: http.MethodPut,
+			target: "/urls/abc/query",
+			body:   `{"utm":{"utm_source":"newsletter"}}`,
+			mockSetup: func() {
+				mockQuery.EXPECT().UserQueryOptions(gomock.Any(), 7, "abc").Return(domain.QueryOptions{}, nil)
+				mockQuery.EXPECT().SetQueryOptions(gomock.Any(), 7, "abc", gomock.Any()).Return(opts, nil)
+			},
+			wantAction: domain.AuditQuery,
+			wantBefore: domain.AuditState{OriginalURL: "https://example.com", Query: &domain.QueryOptions{}},
+			wantAfter:  domain.AuditState{OriginalURL: "https://example.com", Query: &opts},
+		},
+		{
+			name:   "transfer",
+			method: http.MethodPost,
+			target: "/workspaces/ws1/urls",
+			body:   `["abc"]`,
+			mockSetup: func() {
+				mockWorkspaces.EXPECT().TransferLinks(gomock.Any(), 7, "ws1", []string{"abc"}).Return(nil)
+			},
+			wantAction: domain.AuditTransfer,
+			wantBefore: domain.AuditState{OriginalURL: "https://example.com"},
+			wantAfter:  domain.AuditState{OriginalURL: "https://example.com", Workspace: "ws1"},
+		},
+		{
+			name:   "moderation",
+			method: http.MethodPost,
+			target: "/moderation/abc",
+			body:   `{"action":"disable"}`,
+			mockSetup: func() {
+				mockModeration.EXPECT().Disabled(gomock.Any(), "abc").Return(false, nil)
+				mockModeration.EXPECT().Moderate(gomock.Any(), 7, "abc", domain.ModerationRequest{Action: domain.ModerationDisable}).
+					Return(domain.ModerationAction{LinkID: "abc", Action: domain.ModerationDisable}, nil)
+			},
+			wantAction: domain.AuditModerate,
+			wantBefore: domain.AuditState{OriginalURL: "https://example.com"},
+			wantAfter:  domain.AuditState{OriginalURL: "https://example.com", Disabled: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockAudit.EXPECT().Snapshot(gomock.Any(), "abc").Return(&domain.AuditState{OriginalURL: "https://example.com"}, nil)
+			tc.mockSetup()
+			mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, entries ...domain.AuditEntry) error {
+					require.Len(t, entries, 1)
+					require.Equal(t, tc.wantAction, entries[0].Action)
+					require.Equal(t, "abc", entries[0].LinkID)
+					require.Equal(t, 7, entries[0].ActorID)
+					require.Equal(t, &tc.wantBefore, entries[0].Before)
+					require.Equal(t, &tc.wantAfter, entries[0].After)
+					return nil
+				})
+
+			req := httptest.NewRequest(tc.method, tc.target, bytes.NewBufferString(tc.body))
+			req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 7))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Less(t, w.Code, 300, w.Body.String())
+		})
+	}
+}
+
+func TestRestoreUserURLsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDeleter := mocks.NewMockURLDelete(ctrl)
+	mockAudit := mocks.NewMockAuditRecorder(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080", DeletedRetention: 24 * time.Hour}
+	deleteHandler := NewDeleteHandler(mockDeleter, testCfg, DeleteOptions{Audit: mockAudit})
+
+	testCases := []struct {
+		name      string
+		body      string
+		userID    any
+		mockSetup func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "restore",
+			body:   `["abc123","old","mine"]`,
+			userID: 42,
+			mockSetup: func() {
+				mockDeleter.EXPECT().RestoreUserURLs(gomock.Any(), []string{"abc123", "old", "mine"}, 42, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, ids []string, userID int, deletedAfter time.Time) ([]domain.RestoreResult, []domain.Link, error) {
+						require.WithinDuration(t, time.Now().Add(-24*time.Hour), deletedAfter, time.Minute)
+						return []domain.RestoreResult{
+							{ID: "abc123", Status: domain.RestoreRestored},
+							{ID: "old", Status: domain.RestoreExpired},
+							{ID: "mine", Status: domain.RestoreNotFound},
+						}, []domain.Link{{ID: "abc123", OriginalURL: "https://example.com", UserID: 42}}, nil
+					})
+				mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, entries ...domain.AuditEntry) error {
+						require.Len(t, entries, 1)
+						require.Equal(t, domain.AuditRestore, entries[0].Action)
+						require.True(t, entries[0].Before.Deleted)
+						require.False(t, entries[0].After.Deleted)
+						return nil
+					})
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[{"id":"abc123","status":"restored","short_url":"http://localhost:8080/abc123"},{"id":"old","status":"expired"},{"id":"mine","status":"not_found"}]`,
+		},
+		{
+			name:     "unauthorized",
+			body:     `["abc123"]`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "empty list",
+			body:     `[]`,
+			userID:   42,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid JSON",
+			body:     `{`,
+			userID:   42,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockSetup != nil {
+				tc.mockSetup()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/urls/restore", bytes.NewBufferString(tc.body))
+			if tc.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, tc.userID))
+			}
+			w := httptest.NewRecorder()
+			deleteHandler.RestoreUserURLsHandler(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantBody != "" {
+				require.JSONEq(t, tc.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeleteUserURLsHandler_Job(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobs := mocks.NewMockDeleteJobSubmitter(ctrl)
+	mockAudit := mocks.NewMockAuditRecorder(ctrl)
+	deleteHandler := NewDeleteHandler(mocks.NewMockURLDelete(ctrl), &config.Config{BaseURL: "http://localhost:8080"}, DeleteOptions{Audit: mockAudit, Jobs: mockJobs})
+
+	var deleted func(links []domain.Link)
+	mockJobs.EXPECT().SubmitDelete(gomock.Any(), 42, []string{"abc123", "def456"}, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, userID int, ids []string, fn func(links []domain.Link)) (domain.Job, error) {
+			deleted = fn
+			return domain.Job{ID: "j1", Type: domain.JobDelete, State: domain.JobQueued, UserID: userID, IDs: ids}, nil
+		})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/user/urls", bytes.NewBufferString(`["abc123","def456"]`))
+	req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 42))
+	w := httptest.NewRecorder()
+	deleteHandler.DeleteUserURLsHandler(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.Equal(t, "/api/jobs/j1", w.Header().Get("Location"))
+	require.Contains(t, w.Body.String(), `"state":"queued"`)
+
+	mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, entries ...domain.AuditEntry) error {
+			require.Len(t, entries, 1)
+			require.Equal(t, "abc123", entries[0].LinkID)
+			require.True(t, entries[0].After.Deleted)
+			return nil
+		})
+	deleted([]domain.Link{{ID: "abc123", OriginalURL: "https://example.com", UserID: 42}})
+}
+
+func TestJobHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobs := mocks.NewMockJobGetter(ctrl)
+	jobHandler := NewJobHandler(mockJobs)
+
+	r := chi.NewRouter()
+	r.Get("/api/jobs/{id}", jobHandler.GetJobHandler)
+
+	testCases := []struct {
+		name      string
+		target    string
+		userID    any
+		mockSetup func()
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "done",
+			target: "/api/jobs/j1",
+			userID: 42,
+			mockSetup: func() {
+				mockJobs.EXPECT().Job(gomock.Any(), 42, "j1").Return(domain.Job{
+					ID: "j1", Type: domain.JobDelete, State: domain.JobDone, UserID: 42, IDs: []string{"abc123"},
+					Results: []domain.JobResult{{ID: "abc123", Status: domain.JobResultNotOwned}},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"results":[{"id":"abc123","status":"not_owned"}]`,
+		},
+		{
+			name:   "someone else's job",
+			target: "/api/jobs/j2",
+			userID: 42,
+			mockSetup: func() {
+				mockJobs.EXPECT().Job(gomock.Any(), 42, "j2").Return(domain.Job{}, appErrors.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "unauthorized",
+			target:   "/api/jobs/j1",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mockSetup != nil {
+				tc.mockSetup()
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, tc.userID))
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			require.Contains(t, w.Body.String(), tc.wantBody)
+			require.NotContains(t, w.Body.String(), "user_id")
+		})
+	}
+}
+
+func TestPostHandlerStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSaver := mocks.NewMockURLSaver(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080", StreamMaxLines: 3}
+	urlPolicy, err := policy.New(testCfg.BaseURL, "")
+	require.NoError(t, err)
+
+	saveHandler := NewSaveHandler(mockSaver, urlPolicy, testCfg, SaveOptions{})
+
+	stream := func(t *testing.T, body string) []StreamResponse {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten/stream", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, 7))
+		w := httptest.NewRecorder()
+		saveHandler.PostHandlerStream(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, contentTypeNDJSON, w.Header().Get(contentType))
+
+		var results []StreamResponse
+		for _, line := range strings.Split(strings.TrimSpace(w.Body.String()), "\n") {
+			var res struct {
+				StreamResponse
+				Error *struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(line), &res))
+			if res.Error != nil {
+				res.StreamResponse.Error = &problem.Problem{Code: problem.Code(res.Error.Code)}
+			}
+			results = append(results, res.StreamResponse)
+		}
+		return results
+	}
+
+	t.Run("saved in a batch", func(t *testing.T) {
+		mockSaver.EXPECT().SaveBatch(gomock.Any(), 7, "", map[string]string{"1": "https://example.com", "3": "https://example.org"}).
+			Return(map[string]string{"1": "abc", "3": "def"}, nil)
+
+		results := stream(t, "{\"correlation_id\":\"a\",\"original_url\":\"https://example.com\"}\n"+
+			"not json\n\n"+
+			"{\"correlation_id\":\"c\",\"original_url\":\"https://example.org\"}\n")
+
+		require.Len(t, results, 3)
+		require.Equal(t, 2, results[0].Line)
+		require.Equal(t, problem.CodeInvalidJSON, results[0].Error.Code)
+		require.Equal(t, StreamResponse{Line: 1, CorrelationID: "a", ShortURL: "http://localhost:8080/abc"}, results[1])
+		require.Equal(t, StreamResponse{Line: 3, CorrelationID: "c", ShortURL: "http://localhost:8080/def"}, results[2])
+	})
+
+	t.Run("rejected and existing URLs", func(t *testing.T) {
+		mockSaver.EXPECT().SaveBatch(gomock.Any(), 7, "", gomock.Any()).Return(nil, errors.New("duplicate key"))
+		mockSaver.EXPECT().Save(gomock.Any(), 7, "", "https://example.com", domain.LinkMeta{}).Return("abc", appErrors.ErrURLExists)
+		mockSaver.EXPECT().Save(gomock.Any(), 7, "", "https://example.org", domain.LinkMeta{}).Return("def", nil)
+
+		results := stream(t, `{"correlation_id":"a","original_url":"https://example.com"}
+{"correlation_id":"b","original_url":"javascript:alert(1)"}
+{"correlation_id":"c","original_url":"https://example.org"}`)
+
+		require.Len(t, results, 3)
+		require.Equal(t, "b", results[0].CorrelationID)
+		require.Equal(t, problem.CodeURLRejected, results[0].Error.Code)
+		require.Equal(t, "http://localhost:8080/abc", results[1].ShortURL)
+		require.Equal(t, problem.CodeURLExists, results[1].Error.Code)
+		require.Equal(t, StreamResponse{Line: 3, CorrelationID: "c", ShortURL: "http://localhost:8080/def"}, results[2])
+	})
+
+	t.Run("too many lines", func(t *testing.T) {
+		mockSaver.EXPECT().SaveBatch(gomock.Any(), 7, "", gomock.Len(3)).
+			Return(map[string]string{"1": "a1", "2": "a2", "3": "a3"}, nil)
+
+		line := `{"original_url":"https://example.com"}` + "\n"
+		results := stream(t, strings.Repeat(line, 5))
+
+		require.Len(t, results, 4)
+		require.Equal(t, 4, results[3].Line)
+		require.Equal(t, problem.CodeTooManyLines, results[3].Error.Code)
+	})
+
+	t.Run("empty stream", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten/stream", bytes.NewBufferString("\n\n"))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		w := httptest.NewRecorder()
+		saveHandler.PostHandlerStream(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), `"code":"empty_batch"`)
+	})
+
+	t.Run("wrong content type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten/stream", bytes.NewBufferString(`[]`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		saveHandler.PostHandlerStream(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), `"code":"invalid_content_type"`)
+	})
+}
+
+func TestPostHandlerStream_AnswersWhileReading(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSaver := mocks.NewMockURLSaver(ctrl)
+	testCfg := &config.Config{BaseURL: "http://localhost:8080"}
+	urlPolicy, err := policy.New(testCfg.BaseURL, "")
+	require.NoError(t, err)
+
+	saveHandler := NewSaveHandler(mockSaver, urlPolicy, testCfg, SaveOptions{})
+	ts := httptest.NewServer(http.HandlerFunc(saveHandler.PostHandlerStream))
+	defer ts.Close()
+
+	body, input := io.Pipe()
+	req, err := http.NewRequest(http.MethodPost, ts.URL, body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	respc := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			respc <- nil
+			return
+		}
+		respc <- resp
+	}()
+
+	// the answer to an invalid line is sent before the stream ends
+	_, err = input.Write([]byte("not json\n"))
+	require.NoError(t, err)
+
+	resp := <-respc
+	require.NotNil(t, resp)
+	defer resp.Body.Close()
+
+	answers := bufio.NewReader(resp.Body)
+	first, err := answers.ReadString('\n')
+	require.NoError(t, err)
+	require.Contains(t, first, `"code":"invalid_json"`)
+
+	mockSaver.EXPECT().SaveBatch(gomock.Any(), gomock.Any(), "", map[string]string{"2": "https://example.com"}).
+		Return(map[string]string{"2": "abc"}, nil)
+	_, err = input.Write([]byte(`{"original_url":"https://example.com"}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, input.Close())
+
+	second, err := answers.ReadString('\n')
+	require.NoError(t, err)
+	require.Contains(t, second, `"short_url":"http://localhost:8080/abc"`)
+}
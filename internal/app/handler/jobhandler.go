@@ -0,0 +1,52 @@
+// package handler contains handlers for following background jobs.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+// JobGetter defines an interface for looking up the background jobs of a user.
+//
+//go:generate mockgen -source=jobhandler.go -destination=mocks/job_mock.gen.go -package=mocks
+type JobGetter interface {
+	Job(ctx context.Context, userID int, id string) (domain.Job, error)
+}
+
+// JobHandler handles requests for the state of background jobs.
+type JobHandler struct {
+	jobs JobGetter
+}
+
+// NewJobHandler creates a new instance of JobHandler.
+func NewJobHandler(jobs JobGetter) *JobHandler {
+	return &JobHandler{jobs: jobs}
+}
+
+// GetJobHandler processes requests for the state and per-link results of one of the user's jobs.
+func (u *JobHandler) GetJobHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	job, err := u.jobs.Job(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: jobhandler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockJobGetter is a mock of JobGetter interface.
+type MockJobGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobGetterMockRecorder
+}
+
+// MockJobGetterMockRecorder is the mock recorder for MockJobGetter.
+type MockJobGetterMockRecorder struct {
+	mock *MockJobGetter
+}
+
+// NewMockJobGetter creates a new mock instance.
+func NewMockJobGetter(ctrl *gomock.Controller) *MockJobGetter {
+	mock := &MockJobGetter{ctrl: ctrl}
+	mock.recorder = &MockJobGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobGetter) EXPECT() *MockJobGetterMockRecorder {
+	return m.recorder
+}
+
+// Job mocks base method.
+func (m *MockJobGetter) Job(ctx context.Context, userID int, id string) (domain.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Job", ctx, userID, id)
+	ret0, _ := ret[0].(domain.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Job indicates an expected call of Job.
+func (mr *MockJobGetterMockRecorder) Job(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Job", reflect.TypeOf((*MockJobGetter)(nil).Job), ctx, userID, id)
+}
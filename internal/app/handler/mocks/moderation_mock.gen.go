@@ -0,0 +1,202 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: moderationhandler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAbuseReporter is a mock of AbuseReporter interface.
+type MockAbuseReporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAbuseReporterMockRecorder
+}
+
+// MockAbuseReporterMockRecorder is the mock recorder for MockAbuseReporter.
+type MockAbuseReporterMockRecorder struct {
+	mock *MockAbuseReporter
+}
+
+// NewMockAbuseReporter creates a new mock instance.
+func NewMockAbuseReporter(ctrl *gomock.Controller) *MockAbuseReporter {
+	mock := &MockAbuseReporter{ctrl: ctrl}
+	mock.recorder = &MockAbuseReporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAbuseReporter) EXPECT() *MockAbuseReporterMockRecorder {
+	return m.recorder
+}
+
+// Report mocks base method.
+func (m *MockAbuseReporter) Report(ctx context.Context, linkID, reporterIP string, req domain.AbuseReportRequest) (domain.AbuseReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", ctx, linkID, reporterIP, req)
+	ret0, _ := ret[0].(domain.AbuseReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockAbuseReporterMockRecorder) Report(ctx, linkID, reporterIP, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockAbuseReporter)(nil).Report), ctx, linkID, reporterIP, req)
+}
+
+// MockLinkModerator is a mock of LinkModerator interface.
+type MockLinkModerator struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinkModeratorMockRecorder
+}
+
+// MockLinkModeratorMockRecorder is the mock recorder for MockLinkModerator.
+type MockLinkModeratorMockRecorder struct {
+	mock *MockLinkModerator
+}
+
+// NewMockLinkModerator creates a new mock instance.
+func NewMockLinkModerator(ctrl *gomock.Controller) *MockLinkModerator {
+	mock := &MockLinkModerator{ctrl: ctrl}
+	mock.recorder = &MockLinkModeratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinkModerator) EXPECT() *MockLinkModeratorMockRecorder {
+	return m.recorder
+}
+
+// Actions mocks base method.
+func (m *MockLinkModerator) Actions(ctx context.Context, linkID string) ([]domain.ModerationAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Actions", ctx, linkID)
+	ret0, _ := ret[0].([]domain.ModerationAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Actions indicates an expected call of Actions.
+func (mr *MockLinkModeratorMockRecorder) Actions(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Actions", reflect.TypeOf((*MockLinkModerator)(nil).Actions), ctx, linkID)
+}
+
+// Disabled mocks base method.
+func (m *MockLinkModerator) Disabled(ctx context.Context, linkID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disabled", ctx, linkID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Disabled indicates an expected call of Disabled.
+func (mr *MockLinkModeratorMockRecorder) Disabled(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disabled", reflect.TypeOf((*MockLinkModerator)(nil).Disabled), ctx, linkID)
+}
+
+// Moderate mocks base method.
+func (m *MockLinkModerator) Moderate(ctx context.Context, moderatorID int, linkID string, req domain.ModerationRequest) (domain.ModerationAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Moderate", ctx, moderatorID, linkID, req)
+	ret0, _ := ret[0].(domain.ModerationAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Moderate indicates an expected call of Moderate.
+func (mr *MockLinkModeratorMockRecorder) Moderate(ctx, moderatorID, linkID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Moderate", reflect.TypeOf((*MockLinkModerator)(nil).Moderate), ctx, moderatorID, linkID, req)
+}
+
+// Queue mocks base method.
+func (m *MockLinkModerator) Queue(ctx context.Context, queue string) ([]domain.ModerationCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Queue", ctx, queue)
+	ret0, _ := ret[0].([]domain.ModerationCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Queue indicates an expected call of Queue.
+func (mr *MockLinkModeratorMockRecorder) Queue(ctx, queue interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queue", reflect.TypeOf((*MockLinkModerator)(nil).Queue), ctx, queue)
+}
+
+// Report mocks base method.
+func (m *MockLinkModerator) Report(ctx context.Context, linkID, reporterIP string, req domain.AbuseReportRequest) (domain.AbuseReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", ctx, linkID, reporterIP, req)
+	ret0, _ := ret[0].(domain.AbuseReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockLinkModeratorMockRecorder) Report(ctx, linkID, reporterIP, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockLinkModerator)(nil).Report), ctx, linkID, reporterIP, req)
+}
+
+// Review mocks base method.
+func (m *MockLinkModerator) Review(ctx context.Context, linkID string) (domain.ModerationReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Review", ctx, linkID)
+	ret0, _ := ret[0].(domain.ModerationReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Review indicates an expected call of Review.
+func (mr *MockLinkModeratorMockRecorder) Review(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Review", reflect.TypeOf((*MockLinkModerator)(nil).Review), ctx, linkID)
+}
+
+// MockModerationChecker is a mock of ModerationChecker interface.
+type MockModerationChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationCheckerMockRecorder
+}
+
+// MockModerationCheckerMockRecorder is the mock recorder for MockModerationChecker.
+type MockModerationCheckerMockRecorder struct {
+	mock *MockModerationChecker
+}
+
+// NewMockModerationChecker creates a new mock instance.
+func NewMockModerationChecker(ctrl *gomock.Controller) *MockModerationChecker {
+	mock := &MockModerationChecker{ctrl: ctrl}
+	mock.recorder = &MockModerationCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationChecker) EXPECT() *MockModerationCheckerMockRecorder {
+	return m.recorder
+}
+
+// Disabled mocks base method.
+func (m *MockModerationChecker) Disabled(ctx context.Context, linkID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disabled", ctx, linkID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Disabled indicates an expected call of Disabled.
+func (mr *MockModerationCheckerMockRecorder) Disabled(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disabled", reflect.TypeOf((*MockModerationChecker)(nil).Disabled), ctx, linkID)
+}
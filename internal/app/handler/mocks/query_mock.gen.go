@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: queryhandler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockURLQueryOptions is a mock of URLQueryOptions interface.
+type MockURLQueryOptions struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLQueryOptionsMockRecorder
+}
+
+// MockURLQueryOptionsMockRecorder is the mock recorder for MockURLQueryOptions.
+type MockURLQueryOptionsMockRecorder struct {
+	mock *MockURLQueryOptions
+}
+
+// NewMockURLQueryOptions creates a new mock instance.
+func NewMockURLQueryOptions(ctrl *gomock.Controller) *MockURLQueryOptions {
+	mock := &MockURLQueryOptions{ctrl: ctrl}
+	mock.recorder = &MockURLQueryOptionsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLQueryOptions) EXPECT() *MockURLQueryOptionsMockRecorder {
+	return m.recorder
+}
+
+// SetQueryOptions mocks base method.
+func (m *MockURLQueryOptions) SetQueryOptions(ctx context.Context, userID int, id string, opts domain.QueryOptions) (domain.QueryOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQueryOptions", ctx, userID, id, opts)
+	ret0, _ := ret[0].(domain.QueryOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetQueryOptions indicates an expected call of SetQueryOptions.
+func (mr *MockURLQueryOptionsMockRecorder) SetQueryOptions(ctx, userID, id, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQueryOptions", reflect.TypeOf((*MockURLQueryOptions)(nil).SetQueryOptions), ctx, userID, id, opts)
+}
+
+// UserQueryOptions mocks base method.
+func (m *MockURLQueryOptions) UserQueryOptions(ctx context.Context, userID int, id string) (domain.QueryOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserQueryOptions", ctx, userID, id)
+	ret0, _ := ret[0].(domain.QueryOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserQueryOptions indicates an expected call of UserQueryOptions.
+func (mr *MockURLQueryOptionsMockRecorder) UserQueryOptions(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserQueryOptions", reflect.TypeOf((*MockURLQueryOptions)(nil).UserQueryOptions), ctx, userID, id)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rulehandler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockURLRules is a mock of URLRules interface.
+type MockURLRules struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLRulesMockRecorder
+}
+
+// MockURLRulesMockRecorder is the mock recorder for MockURLRules.
+type MockURLRulesMockRecorder struct {
+	mock *MockURLRules
+}
+
+// NewMockURLRules creates a new mock instance.
+func NewMockURLRules(ctrl *gomock.Controller) *MockURLRules {
+	mock := &MockURLRules{ctrl: ctrl}
+	mock.recorder = &MockURLRulesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLRules) EXPECT() *MockURLRulesMockRecorder {
+	return m.recorder
+}
+
+// SetRules mocks base method.
+func (m *MockURLRules) SetRules(ctx context.Context, userID int, id string, rules []domain.RedirectRule) ([]domain.RedirectRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRules", ctx, userID, id, rules)
+	ret0, _ := ret[0].([]domain.RedirectRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRules indicates an expected call of SetRules.
+func (mr *MockURLRulesMockRecorder) SetRules(ctx, userID, id, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRules", reflect.TypeOf((*MockURLRules)(nil).SetRules), ctx, userID, id, rules)
+}
+
+// UserRules mocks base method.
+func (m *MockURLRules) UserRules(ctx context.Context, userID int, id string) ([]domain.RedirectRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserRules", ctx, userID, id)
+	ret0, _ := ret[0].([]domain.RedirectRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserRules indicates an expected call of UserRules.
+func (mr *MockURLRulesMockRecorder) UserRules(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRules", reflect.TypeOf((*MockURLRules)(nil).UserRules), ctx, userID, id)
+}
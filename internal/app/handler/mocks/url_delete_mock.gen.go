@@ -7,7 +7,9 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	domain "github.com/Te8va/shortURL/internal/app/domain"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,11 +37,12 @@ func (m *MockURLDelete) EXPECT() *MockURLDeleteMockRecorder {
 }
 
 // DeleteUserURLs mocks base method.
-func (m *MockURLDelete) DeleteUserURLs(ctx context.Context, ids []string, userID int) error {
+func (m *MockURLDelete) DeleteUserURLs(ctx context.Context, ids []string, userID int) ([]domain.Link, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteUserURLs", ctx, ids, userID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteUserURLs indicates an expected call of DeleteUserURLs.
@@ -47,3 +50,57 @@ func (mr *MockURLDeleteMockRecorder) DeleteUserURLs(ctx, ids, userID interface{}
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserURLs", reflect.TypeOf((*MockURLDelete)(nil).DeleteUserURLs), ctx, ids, userID)
 }
+
+// RestoreUserURLs mocks base method.
+func (m *MockURLDelete) RestoreUserURLs(ctx context.Context, ids []string, userID int, deletedAfter time.Time) ([]domain.RestoreResult, []domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUserURLs", ctx, ids, userID, deletedAfter)
+	ret0, _ := ret[0].([]domain.RestoreResult)
+	ret1, _ := ret[1].([]domain.Link)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RestoreUserURLs indicates an expected call of RestoreUserURLs.
+func (mr *MockURLDeleteMockRecorder) RestoreUserURLs(ctx, ids, userID, deletedAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUserURLs", reflect.TypeOf((*MockURLDelete)(nil).RestoreUserURLs), ctx, ids, userID, deletedAfter)
+}
+
+// MockDeleteJobSubmitter is a mock of DeleteJobSubmitter interface.
+type MockDeleteJobSubmitter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeleteJobSubmitterMockRecorder
+}
+
+// MockDeleteJobSubmitterMockRecorder is the mock recorder for MockDeleteJobSubmitter.
+type MockDeleteJobSubmitterMockRecorder struct {
+	mock *MockDeleteJobSubmitter
+}
+
+// NewMockDeleteJobSubmitter creates a new mock instance.
+func NewMockDeleteJobSubmitter(ctrl *gomock.Controller) *MockDeleteJobSubmitter {
+	mock := &MockDeleteJobSubmitter{ctrl: ctrl}
+	mock.recorder = &MockDeleteJobSubmitterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeleteJobSubmitter) EXPECT() *MockDeleteJobSubmitterMockRecorder {
+	return m.recorder
+}
+
+// SubmitDelete mocks base method.
+func (m *MockDeleteJobSubmitter) SubmitDelete(ctx context.Context, userID int, ids []string, deleted func([]domain.Link)) (domain.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitDelete", ctx, userID, ids, deleted)
+	ret0, _ := ret[0].(domain.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitDelete indicates an expected call of SubmitDelete.
+func (mr *MockDeleteJobSubmitterMockRecorder) SubmitDelete(ctx, userID, ids, deleted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitDelete", reflect.TypeOf((*MockDeleteJobSubmitter)(nil).SubmitDelete), ctx, userID, ids, deleted)
+}
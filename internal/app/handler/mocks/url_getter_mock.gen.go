@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	domain "github.com/Te8va/shortURL/internal/app/domain"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,32 +36,32 @@ func (m *MockURLGetter) EXPECT() *MockURLGetterMockRecorder {
 }
 
 // Get mocks base method.
-func (m *MockURLGetter) Get(ctx context.Context, id string) (string, bool, bool) {
+func (m *MockURLGetter) Get(ctx context.Context, host, id string) (domain.Redirect, bool, bool) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, id)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "Get", ctx, host, id)
+	ret0, _ := ret[0].(domain.Redirect)
 	ret1, _ := ret[1].(bool)
 	ret2, _ := ret[2].(bool)
 	return ret0, ret1, ret2
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockURLGetterMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+func (mr *MockURLGetterMockRecorder) Get(ctx, host, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockURLGetter)(nil).Get), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockURLGetter)(nil).Get), ctx, host, id)
 }
 
 // GetUserURLs mocks base method.
-func (m *MockURLGetter) GetUserURLs(ctx context.Context, userID int) ([]map[string]string, error) {
+func (m *MockURLGetter) GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserURLs", ctx, userID)
-	ret0, _ := ret[0].([]map[string]string)
+	ret := m.ctrl.Call(m, "GetUserURLs", ctx, userID, filter)
+	ret0, _ := ret[0].([]domain.Link)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserURLs indicates an expected call of GetUserURLs.
-func (mr *MockURLGetterMockRecorder) GetUserURLs(ctx, userID interface{}) *gomock.Call {
+func (mr *MockURLGetterMockRecorder) GetUserURLs(ctx, userID, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLs", reflect.TypeOf((*MockURLGetter)(nil).GetUserURLs), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLs", reflect.TypeOf((*MockURLGetter)(nil).GetUserURLs), ctx, userID, filter)
 }
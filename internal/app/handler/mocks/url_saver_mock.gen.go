@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	domain "github.com/Te8va/shortURL/internal/app/domain"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,31 +36,103 @@ func (m *MockURLSaver) EXPECT() *MockURLSaverMockRecorder {
 }
 
 // Save mocks base method.
-func (m *MockURLSaver) Save(ctx context.Context, userID int, url string) (string, error) {
+func (m *MockURLSaver) Save(ctx context.Context, userID int, host, url string, meta domain.LinkMeta) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", ctx, userID, url)
+	ret := m.ctrl.Call(m, "Save", ctx, userID, host, url, meta)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockURLSaverMockRecorder) Save(ctx, userID, url interface{}) *gomock.Call {
+func (mr *MockURLSaverMockRecorder) Save(ctx, userID, host, url, meta interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockURLSaver)(nil).Save), ctx, userID, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockURLSaver)(nil).Save), ctx, userID, host, url, meta)
 }
 
 // SaveBatch mocks base method.
-func (m *MockURLSaver) SaveBatch(ctx context.Context, userID int, urls map[string]string) (map[string]string, error) {
+func (m *MockURLSaver) SaveBatch(ctx context.Context, userID int, host string, urls map[string]string) (map[string]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveBatch", ctx, userID, urls)
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, userID, host, urls)
 	ret0, _ := ret[0].(map[string]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveBatch indicates an expected call of SaveBatch.
-func (mr *MockURLSaverMockRecorder) SaveBatch(ctx, userID, urls interface{}) *gomock.Call {
+func (mr *MockURLSaverMockRecorder) SaveBatch(ctx, userID, host, urls interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockURLSaver)(nil).SaveBatch), ctx, userID, urls)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockURLSaver)(nil).SaveBatch), ctx, userID, host, urls)
+}
+
+// MockURLChecker is a mock of URLChecker interface.
+type MockURLChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLCheckerMockRecorder
+}
+
+// MockURLCheckerMockRecorder is the mock recorder for MockURLChecker.
+type MockURLCheckerMockRecorder struct {
+	mock *MockURLChecker
+}
+
+// NewMockURLChecker creates a new mock instance.
+func NewMockURLChecker(ctrl *gomock.Controller) *MockURLChecker {
+	mock := &MockURLChecker{ctrl: ctrl}
+	mock.recorder = &MockURLCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLChecker) EXPECT() *MockURLCheckerMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockURLChecker) Check(rawURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", rawURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockURLCheckerMockRecorder) Check(rawURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockURLChecker)(nil).Check), rawURL)
+}
+
+// MockPageQueue is a mock of PageQueue interface.
+type MockPageQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockPageQueueMockRecorder
+}
+
+// MockPageQueueMockRecorder is the mock recorder for MockPageQueue.
+type MockPageQueueMockRecorder struct {
+	mock *MockPageQueue
+}
+
+// NewMockPageQueue creates a new mock instance.
+func NewMockPageQueue(ctrl *gomock.Controller) *MockPageQueue {
+	mock := &MockPageQueue{ctrl: ctrl}
+	mock.recorder = &MockPageQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPageQueue) EXPECT() *MockPageQueueMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockPageQueue) Enqueue(id, url string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Enqueue", id, url)
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockPageQueueMockRecorder) Enqueue(id, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockPageQueue)(nil).Enqueue), id, url)
 }
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: updatehandler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockURLUpdater is a mock of URLUpdater interface.
+type MockURLUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLUpdaterMockRecorder
+}
+
+// MockURLUpdaterMockRecorder is the mock recorder for MockURLUpdater.
+type MockURLUpdaterMockRecorder struct {
+	mock *MockURLUpdater
+}
+
+// NewMockURLUpdater creates a new mock instance.
+func NewMockURLUpdater(ctrl *gomock.Controller) *MockURLUpdater {
+	mock := &MockURLUpdater{ctrl: ctrl}
+	mock.recorder = &MockURLUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLUpdater) EXPECT() *MockURLUpdaterMockRecorder {
+	return m.recorder
+}
+
+// UpdateLink mocks base method.
+func (m *MockURLUpdater) UpdateLink(ctx context.Context, userID int, id string, update domain.LinkUpdate) (domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLink", ctx, userID, id, update)
+	ret0, _ := ret[0].(domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLink indicates an expected call of UpdateLink.
+func (mr *MockURLUpdaterMockRecorder) UpdateLink(ctx, userID, id, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLink", reflect.TypeOf((*MockURLUpdater)(nil).UpdateLink), ctx, userID, id, update)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: authhandler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserAuthenticator is a mock of UserAuthenticator interface.
+type MockUserAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserAuthenticatorMockRecorder
+}
+
+// MockUserAuthenticatorMockRecorder is the mock recorder for MockUserAuthenticator.
+type MockUserAuthenticatorMockRecorder struct {
+	mock *MockUserAuthenticator
+}
+
+// NewMockUserAuthenticator creates a new mock instance.
+func NewMockUserAuthenticator(ctrl *gomock.Controller) *MockUserAuthenticator {
+	mock := &MockUserAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockUserAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserAuthenticator) EXPECT() *MockUserAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// ClaimURLs mocks base method.
+func (m *MockUserAuthenticator) ClaimURLs(ctx context.Context, fromUserID, toUserID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimURLs", ctx, fromUserID, toUserID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimURLs indicates an expected call of ClaimURLs.
+func (mr *MockUserAuthenticatorMockRecorder) ClaimURLs(ctx, fromUserID, toUserID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimURLs", reflect.TypeOf((*MockUserAuthenticator)(nil).ClaimURLs), ctx, fromUserID, toUserID)
+}
+
+// Login mocks base method.
+func (m *MockUserAuthenticator) Login(ctx context.Context, login, password string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, login, password)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockUserAuthenticatorMockRecorder) Login(ctx, login, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserAuthenticator)(nil).Login), ctx, login, password)
+}
+
+// Register mocks base method.
+func (m *MockUserAuthenticator) Register(ctx context.Context, login, password string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, login, password)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockUserAuthenticatorMockRecorder) Register(ctx, login, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserAuthenticator)(nil).Register), ctx, login, password)
+}
@@ -0,0 +1,118 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: varianthandler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVariantRouter is a mock of VariantRouter interface.
+type MockVariantRouter struct {
+	ctrl     *gomock.Controller
+	recorder *MockVariantRouterMockRecorder
+}
+
+// MockVariantRouterMockRecorder is the mock recorder for MockVariantRouter.
+type MockVariantRouterMockRecorder struct {
+	mock *MockVariantRouter
+}
+
+// NewMockVariantRouter creates a new mock instance.
+func NewMockVariantRouter(ctrl *gomock.Controller) *MockVariantRouter {
+	mock := &MockVariantRouter{ctrl: ctrl}
+	mock.recorder = &MockVariantRouterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVariantRouter) EXPECT() *MockVariantRouterMockRecorder {
+	return m.recorder
+}
+
+// CountVariantClick mocks base method.
+func (m *MockVariantRouter) CountVariantClick(ctx context.Context, id string, variant int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountVariantClick", ctx, id, variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CountVariantClick indicates an expected call of CountVariantClick.
+func (mr *MockVariantRouterMockRecorder) CountVariantClick(ctx, id, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountVariantClick", reflect.TypeOf((*MockVariantRouter)(nil).CountVariantClick), ctx, id, variant)
+}
+
+// GetVariants mocks base method.
+func (m *MockVariantRouter) GetVariants(ctx context.Context, id string) ([]domain.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariants", ctx, id)
+	ret0, _ := ret[0].([]domain.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariants indicates an expected call of GetVariants.
+func (mr *MockVariantRouterMockRecorder) GetVariants(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariants", reflect.TypeOf((*MockVariantRouter)(nil).GetVariants), ctx, id)
+}
+
+// MockURLVariants is a mock of URLVariants interface.
+type MockURLVariants struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLVariantsMockRecorder
+}
+
+// MockURLVariantsMockRecorder is the mock recorder for MockURLVariants.
+type MockURLVariantsMockRecorder struct {
+	mock *MockURLVariants
+}
+
+// NewMockURLVariants creates a new mock instance.
+func NewMockURLVariants(ctrl *gomock.Controller) *MockURLVariants {
+	mock := &MockURLVariants{ctrl: ctrl}
+	mock.recorder = &MockURLVariantsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLVariants) EXPECT() *MockURLVariantsMockRecorder {
+	return m.recorder
+}
+
+// SetVariants mocks base method.
+func (m *MockURLVariants) SetVariants(ctx context.Context, userID int, id string, variants []domain.Variant) ([]domain.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVariants", ctx, userID, id, variants)
+	ret0, _ := ret[0].([]domain.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVariants indicates an expected call of SetVariants.
+func (mr *MockURLVariantsMockRecorder) SetVariants(ctx, userID, id, variants interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVariants", reflect.TypeOf((*MockURLVariants)(nil).SetVariants), ctx, userID, id, variants)
+}
+
+// UserVariants mocks base method.
+func (m *MockURLVariants) UserVariants(ctx context.Context, userID int, id string) ([]domain.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserVariants", ctx, userID, id)
+	ret0, _ := ret[0].([]domain.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserVariants indicates an expected call of UserVariants.
+func (mr *MockURLVariantsMockRecorder) UserVariants(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserVariants", reflect.TypeOf((*MockURLVariants)(nil).UserVariants), ctx, userID, id)
+}
@@ -0,0 +1,175 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: workspacehandler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWorkspaceAuthorizer is a mock of WorkspaceAuthorizer interface.
+type MockWorkspaceAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceAuthorizerMockRecorder
+}
+
+// MockWorkspaceAuthorizerMockRecorder is the mock recorder for MockWorkspaceAuthorizer.
+type MockWorkspaceAuthorizerMockRecorder struct {
+	mock *MockWorkspaceAuthorizer
+}
+
+// NewMockWorkspaceAuthorizer creates a new mock instance.
+func NewMockWorkspaceAuthorizer(ctrl *gomock.Controller) *MockWorkspaceAuthorizer {
+	mock := &MockWorkspaceAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceAuthorizer) EXPECT() *MockWorkspaceAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockWorkspaceAuthorizer) Authorize(ctx context.Context, userID int, workspaceID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, userID, workspaceID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockWorkspaceAuthorizerMockRecorder) Authorize(ctx, userID, workspaceID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockWorkspaceAuthorizer)(nil).Authorize), ctx, userID, workspaceID, role)
+}
+
+// MockWorkspaceManager is a mock of WorkspaceManager interface.
+type MockWorkspaceManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceManagerMockRecorder
+}
+
+// MockWorkspaceManagerMockRecorder is the mock recorder for MockWorkspaceManager.
+type MockWorkspaceManagerMockRecorder struct {
+	mock *MockWorkspaceManager
+}
+
+// NewMockWorkspaceManager creates a new mock instance.
+func NewMockWorkspaceManager(ctrl *gomock.Controller) *MockWorkspaceManager {
+	mock := &MockWorkspaceManager{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceManager) EXPECT() *MockWorkspaceManagerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockWorkspaceManager) Authorize(ctx context.Context, userID int, workspaceID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, userID, workspaceID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockWorkspaceManagerMockRecorder) Authorize(ctx, userID, workspaceID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockWorkspaceManager)(nil).Authorize), ctx, userID, workspaceID, role)
+}
+
+// CreateWorkspace mocks base method.
+func (m *MockWorkspaceManager) CreateWorkspace(ctx context.Context, userID int, name string) (domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", ctx, userID, name)
+	ret0, _ := ret[0].(domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MockWorkspaceManagerMockRecorder) CreateWorkspace(ctx, userID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockWorkspaceManager)(nil).CreateWorkspace), ctx, userID, name)
+}
+
+// ListMembers mocks base method.
+func (m *MockWorkspaceManager) ListMembers(ctx context.Context, userID int, workspaceID string) ([]domain.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, userID, workspaceID)
+	ret0, _ := ret[0].([]domain.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockWorkspaceManagerMockRecorder) ListMembers(ctx, userID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockWorkspaceManager)(nil).ListMembers), ctx, userID, workspaceID)
+}
+
+// ListWorkspaces mocks base method.
+func (m *MockWorkspaceManager) ListWorkspaces(ctx context.Context, userID int) ([]domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaces", ctx, userID)
+	ret0, _ := ret[0].([]domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaces indicates an expected call of ListWorkspaces.
+func (mr *MockWorkspaceManagerMockRecorder) ListWorkspaces(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaces", reflect.TypeOf((*MockWorkspaceManager)(nil).ListWorkspaces), ctx, userID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockWorkspaceManager) RemoveMember(ctx context.Context, userID int, workspaceID string, memberID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, userID, workspaceID, memberID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockWorkspaceManagerMockRecorder) RemoveMember(ctx, userID, workspaceID, memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockWorkspaceManager)(nil).RemoveMember), ctx, userID, workspaceID, memberID)
+}
+
+// SetMember mocks base method.
+func (m *MockWorkspaceManager) SetMember(ctx context.Context, userID int, workspaceID string, memberID int, role string) (domain.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", ctx, userID, workspaceID, memberID, role)
+	ret0, _ := ret[0].(domain.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockWorkspaceManagerMockRecorder) SetMember(ctx, userID, workspaceID, memberID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockWorkspaceManager)(nil).SetMember), ctx, userID, workspaceID, memberID, role)
+}
+
+// TransferLinks mocks base method.
+func (m *MockWorkspaceManager) TransferLinks(ctx context.Context, userID int, workspaceID string, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferLinks", ctx, userID, workspaceID, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferLinks indicates an expected call of TransferLinks.
+func (mr *MockWorkspaceManagerMockRecorder) TransferLinks(ctx, userID, workspaceID, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferLinks", reflect.TypeOf((*MockWorkspaceManager)(nil).TransferLinks), ctx, userID, workspaceID, ids)
+}
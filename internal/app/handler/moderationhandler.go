@@ -0,0 +1,226 @@
+// package handler contains handlers for reporting abusive links and for the moderators reviewing the reports.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+// AbuseReporter defines an interface for reporting abusive links.
+//
+//go:generate mockgen -source=moderationhandler.go -destination=mocks/moderation_mock.gen.go -package=mocks
+type AbuseReporter interface {
+	Report(ctx context.Context, linkID, reporterIP string, req domain.AbuseReportRequest) (domain.AbuseReport, error)
+}
+
+// LinkModerator defines an interface for reviewing reported links and disabling or clearing them.
+type LinkModerator interface {
+	AbuseReporter
+	Queue(ctx context.Context, queue string) ([]domain.ModerationCase, error)
+	Review(ctx context.Context, linkID string) (domain.ModerationReview, error)
+	ModerationChecker
+	Moderate(ctx context.Context, moderatorID int, linkID string, req domain.ModerationRequest) (domain.ModerationAction, error)
+	Actions(ctx context.Context, linkID string) ([]domain.ModerationAction, error)
+}
+
+// ModerationChecker defines an interface for checking whether moderators disabled a link.
+type ModerationChecker interface {
+	Disabled(ctx context.Context, linkID string) (bool, error)
+}
+
+// disabledPage is shown to browsers following a disabled link instead of redirecting them.
+var disabledPage = template.Must(template.New("disabled").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Link disabled</title>
+</head>
+<body>
+<h1>This link has been disabled</h1>
+<p>The short link <code>{{.}}</code> was reported as abusive and disabled by our moderators, so you are not being redirected to its destination.</p>
+</body>
+</html>
+`))
+
+// linkDisabled reports whether moderators disabled the link, always false if checker is nil. A failed check lets the
+// link through
+func linkDisabled(r *http.Request, checker ModerationChecker, id string) bool {
+	if checker == nil {
+		return false
+	}
+
+	disabled, err := checker.Disabled(r.Context(), id)
+	if err != nil {
+		log.Println("Failed to check whether link is disabled:", err)
+		return false
+	}
+	return disabled
+}
+
+// writeDisabled answers a request for a disabled link with a warning page for browsers and a problem otherwise.
+func writeDisabled(w http.ResponseWriter, r *http.Request, id string) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		problem.New(r, http.StatusUnavailableForLegalReasons, problem.CodeLinkDisabled).With("id", id).Write(w)
+		return
+	}
+
+	w.Header().Set(contentType, "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusUnavailableForLegalReasons)
+	if err := disabledPage.Execute(w, id); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ModerationHandler handles abuse reports and the moderation of reported links.
+type ModerationHandler struct {
+	moderation LinkModerator
+	cfg        *config.Config
+	audit      AuditRecorder
+}
+
+// NewModerationHandler creates a new instance of ModerationHandler. audit may be nil.
+func NewModerationHandler(moderation LinkModerator, cfg *config.Config, audit AuditRecorder) *ModerationHandler {
+	return &ModerationHandler{moderation: moderation, cfg: cfg, audit: audit}
+}
+
+// ReportHandler processes requests of visitors reporting a link as abusive. No authentication is needed.
+func (u *ModerationHandler) ReportHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.AbuseReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	report, err := u.moderation.Report(r.Context(), chi.URLParam(r, "id"), middleware.ClientIP(r, u.cfg.TrustProxyHeaders), req)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// QueueHandler processes requests of moderators to list the reported links, or the disabled ones with queue=disabled.
+func (u *ModerationHandler) QueueHandler(w http.ResponseWriter, r *http.Request) {
+	queue := r.URL.Query().Get("queue")
+	switch queue {
+	case "":
+		queue = domain.QueuePending
+	case domain.QueuePending, domain.QueueDisabled:
+	default:
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter).
+			WithDetail("queue must be any of pending, disabled").
+			Write(w)
+		return
+	}
+
+	cases, err := u.moderation.Queue(r.Context(), queue)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	for i := range cases {
+		cases[i].ShortURL = u.cfg.ShortURL(cases[i].Domain, cases[i].LinkID)
+	}
+	if cases == nil {
+		cases = []domain.ModerationCase{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(cases); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ReviewHandler processes requests of moderators to see a link together with its reports and past decisions.
+func (u *ModerationHandler) ReviewHandler(w http.ResponseWriter, r *http.Request) {
+	review, err := u.moderation.Review(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+	review.ShortURL = u.cfg.ShortURL(review.Domain, review.LinkID)
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ModerateHandler processes requests of moderators to disable or clear a link, resolving its pending reports.
+func (u *ModerationHandler) ModerateHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var req domain.ModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	before := snapshot(r.Context(), u.audit, id)
+	if before != nil {
+		var err error
+		if before.Disabled, err = u.moderation.Disabled(r.Context(), id); err != nil {
+			log.Println("Failed to check whether link is disabled for the audit log:", err)
+		}
+	}
+
+	action, err := u.moderation.Moderate(r.Context(), userID, id, req)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	after := changed(before, func(after *domain.AuditState) { after.Disabled = action.Action == domain.ModerationDisable })
+	record(r.Context(), u.audit, auditEntry(r, u.cfg.TrustProxyHeaders, domain.AuditModerate, id, before, after))
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(action); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ActionsHandler processes requests of moderators to read the audit trail of decisions, narrowed down to one link
+// with the link query parameter.
+func (u *ModerationHandler) ActionsHandler(w http.ResponseWriter, r *http.Request) {
+	actions, err := u.moderation.Actions(r.Context(), r.URL.Query().Get("link"))
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	if actions == nil {
+		actions = []domain.ModerationAction{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(actions); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
@@ -4,6 +4,8 @@ package handler
 import (
 	"context"
 	"net/http"
+
+	"github.com/Te8va/shortURL/internal/app/problem"
 )
 
 // Pinger defines an interface for health check for database connection.
@@ -27,7 +29,7 @@ func NewPingHandler(pinger Pinger) *PingHandler {
 func (u *PingHandler) PingHandler(w http.ResponseWriter, r *http.Request) {
 	err := u.pinger.PingPg(r.Context())
 	if err != nil {
-		http.Error(w, "Database connection failed", http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeStorageUnavailable)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
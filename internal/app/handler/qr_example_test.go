@@ -0,0 +1,36 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/handler"
+)
+
+func ExampleQRHandler_QRHandler() {
+	r := chi.NewRouter()
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	cfg := &config.Config{BaseURL: ts.URL}
+	h := handler.NewQRHandler(mockGetter{}, cfg, nil)
+
+	r.Get("/{id}/qr", h.QRHandler)
+
+	resp, err := http.Get(ts.URL + "/abc123/qr?size=128&format=svg")
+	if err != nil {
+		fmt.Println("request failed:", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	fmt.Println(resp.StatusCode)
+	fmt.Println(resp.Header.Get("Content-Type"))
+	// Output:
+	// 200
+	// image/svg+xml
+}
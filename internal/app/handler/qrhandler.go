@@ -0,0 +1,220 @@
+// package handler contains logic for rendering QR codes of short links.
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/problem"
+	"github.com/Te8va/shortURL/internal/app/qrcode"
+)
+
+const (
+	qrDefaultSize   = 256
+	qrMinSize       = 64
+	qrMaxSize       = 2048
+	qrDefaultMargin = 4
+	qrMaxMargin     = 16
+	qrFormatPNG     = "png"
+	qrFormatSVG     = "svg"
+	contentTypePNG  = "image/png"
+	contentTypeSVG  = "image/svg+xml"
+)
+
+// QRHandler handles requests for QR codes of short links.
+type QRHandler struct {
+	getter     URLGetter
+	cfg        *config.Config
+	moderation ModerationChecker
+}
+
+// NewQRHandler creates a new instance of QRHandler. QR codes of links disabled by moderators are refused unless
+// moderation is nil.
+func NewQRHandler(getter URLGetter, cfg *config.Config, moderation ModerationChecker) *QRHandler {
+	return &QRHandler{getter: getter, cfg: cfg, moderation: moderation}
+}
+
+type qrParams struct {
+	size   int
+	format string
+	level  qrcode.Level
+	margin int
+	fg     color.RGBA
+	bg     color.RGBA
+}
+
+// QRHandler renders a QR code pointing at the full short URL of the given ID.
+func (u *QRHandler) QRHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidID)
+		return
+	}
+
+	params, err := parseQRParams(r)
+	if err != nil {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter).WithDetail(err.Error()).Write(w)
+		return
+	}
+
+	host := u.cfg.ResolveDomain(r.Host)
+	_, exists, isDeleted := u.getter.Get(r.Context(), host, id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound)
+		return
+	}
+
+	if isDeleted {
+		problem.Write(w, r, http.StatusGone, problem.CodeGone)
+		return
+	}
+
+	if linkDisabled(r, u.moderation, id) {
+		writeDisabled(w, r, id)
+		return
+	}
+
+	shortURL := u.cfg.ShortURL(host, id)
+	etag := qrETag(shortURL, params)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if etagMatches(r.Header.Values("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	code, err := qrcode.Encode([]byte(shortURL), params.level)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	opts := qrcode.RenderOptions{
+		Size:       params.size,
+		Margin:     params.margin,
+		Foreground: params.fg,
+		Background: params.bg,
+	}
+
+	var buf bytes.Buffer
+	if params.format == qrFormatSVG {
+		err = code.WriteSVG(&buf, opts)
+		w.Header().Set(contentType, contentTypeSVG)
+	} else {
+		err = code.WritePNG(&buf, opts)
+		w.Header().Set(contentType, contentTypePNG)
+	}
+	if err != nil {
+		w.Header().Del(contentType)
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+func parseQRParams(r *http.Request) (qrParams, error) {
+	q := r.URL.Query()
+	params := qrParams{
+		size:   qrDefaultSize,
+		format: qrFormatPNG,
+		level:  qrcode.LevelM,
+		margin: qrDefaultMargin,
+		fg:     color.RGBA{A: 0xff},
+		bg:     color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+
+	if v := q.Get("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < qrMinSize || size > qrMaxSize {
+			return params, fmt.Errorf("size must be an integer between %d and %d", qrMinSize, qrMaxSize)
+		}
+		params.size = size
+	}
+
+	if v := q.Get("format"); v != "" {
+		if v != qrFormatPNG && v != qrFormatSVG {
+			return params, fmt.Errorf("format must be png or svg")
+		}
+		params.format = v
+	}
+
+	if v := q.Get("ecc"); v != "" {
+		level, err := qrcode.ParseLevel(v)
+		if err != nil {
+			return params, fmt.Errorf("ecc must be one of L, M, Q, H")
+		}
+		params.level = level
+	}
+
+	if v := q.Get("margin"); v != "" {
+		margin, err := strconv.Atoi(v)
+		if err != nil || margin < 0 || margin > qrMaxMargin {
+			return params, fmt.Errorf("margin must be an integer between 0 and %d", qrMaxMargin)
+		}
+		params.margin = margin
+	}
+
+	if v := q.Get("fg"); v != "" {
+		fg, err := qrcode.ParseColor(v)
+		if err != nil {
+			return params, fmt.Errorf("fg must be a hex colour")
+		}
+		params.fg = fg
+	}
+
+	if v := q.Get("bg"); v != "" {
+		bg, err := qrcode.ParseColor(v)
+		if err != nil {
+			return params, fmt.Errorf("bg must be a hex colour")
+		}
+		params.bg = bg
+	}
+
+	return params, nil
+}
+
+func qrETag(shortURL string, p qrParams) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s|%s|%d|%v|%v", shortURL, p.size, p.format, p.level, p.margin, p.fg, p.bg)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether If-None-Match values match etag. Lists, "*" and weak validators are accepted and
+// compared weakly as RFC 9110 requires for If-None-Match
+func etagMatches(values []string, etag string) bool {
+	for _, v := range values {
+		for v = strings.TrimLeft(v, " \t,"); v != ""; v = strings.TrimLeft(v, " \t,") {
+			if v[0] == '*' {
+				return true
+			}
+
+			tag := strings.TrimPrefix(v, "W/")
+			if tag == "" || tag[0] != '"' {
+				// not an entity tag, the rest of the header cannot be parsed
+				break
+			}
+			end := strings.IndexByte(tag[1:], '"')
+			if end < 0 {
+				break
+			}
+			if tag[:end+2] == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+			v = tag[end+2:]
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+// package handler contains handlers for managing the UTM parameters and query string forwarding of links.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+const (
+	maxUTMLength       = 200
+	maxMergedURLLength = 8192
+)
+
+// URLQueryOptions defines an interface for managing the query string options of the user's links.
+//
+//go:generate mockgen -source=queryhandler.go -destination=mocks/query_mock.gen.go -package=mocks
+type URLQueryOptions interface {
+	UserQueryOptions(ctx context.Context, userID int, id string) (domain.QueryOptions, error)
+	SetQueryOptions(ctx context.Context, userID int, id string, opts domain.QueryOptions) (domain.QueryOptions, error)
+}
+
+// QueryHandler handles requests for managing the query string options of links.
+type QueryHandler struct {
+	query URLQueryOptions
+	cfg   *config.Config
+	audit AuditRecorder
+}
+
+// NewQueryHandler creates a new instance of QueryHandler. audit may be nil.
+func NewQueryHandler(query URLQueryOptions, cfg *config.Config, audit AuditRecorder) *QueryHandler {
+	return &QueryHandler{query: query, cfg: cfg, audit: audit}
+}
+
+// GetQueryOptionsHandler processes requests to get the query string options of one of the user's links.
+func (u *QueryHandler) GetQueryOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	opts, err := u.query.UserQueryOptions(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	writeQueryOptions(w, opts)
+}
+
+// SetQueryOptionsHandler processes requests to set the default UTM parameters of one of the user's links and
+// whether its query string is forwarded to the destination.
+func (u *QueryHandler) SetQueryOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var opts domain.QueryOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	if field, err := normalizeQueryOptions(&opts); err != nil {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidQuery).
+			WithDetail(err.Error()).
+			With("field", field).
+			Write(w)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	before := snapshot(r.Context(), u.audit, id)
+	if before != nil {
+		if current, err := u.query.UserQueryOptions(r.Context(), userID, id); err != nil {
+			log.Println("Failed to snapshot link query options for the audit log:", err)
+		} else {
+			before.Query = &current
+		}
+	}
+
+	stored, err := u.query.SetQueryOptions(r.Context(), userID, id, opts)
+	if errors.Is(err, appErrors.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound)
+		return
+	} else if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	after := changed(before, func(after *domain.AuditState) { after.Query = &stored })
+	record(r.Context(), u.audit, auditEntry(r, u.cfg.TrustProxyHeaders, domain.AuditQuery, id, before, after))
+
+	writeQueryOptions(w, stored)
+}
+
+func writeQueryOptions(w http.ResponseWriter, opts domain.QueryOptions) {
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(opts); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// normalizeQueryOptions trims the UTM parameters and checks them and the conflict mode, returning the name of the
+// offending field on error.
+func normalizeQueryOptions(opts *domain.QueryOptions) (string, error) {
+	params := []struct {
+		name  string
+		value *string
+	}{
+		{"utm_source", &opts.UTM.Source},
+		{"utm_medium", &opts.UTM.Medium},
+		{"utm_campaign", &opts.UTM.Campaign},
+		{"utm_term", &opts.UTM.Term},
+		{"utm_content", &opts.UTM.Content},
+	}
+
+	for _, param := range params {
+		*param.value = strings.TrimSpace(*param.value)
+		if utf8.RuneCountInString(*param.value) > maxUTMLength {
+			return param.name, fmt.Errorf("%s must be at most %d characters", param.name, maxUTMLength)
+		}
+		if strings.IndexFunc(*param.value, unicode.IsControl) >= 0 {
+			return param.name, fmt.Errorf("%s must not contain control characters", param.name)
+		}
+	}
+
+	switch opts.Conflict {
+	case "", domain.QueryConflictKeep, domain.QueryConflictOverride, domain.QueryConflictAppend:
+	default:
+		return "conflict", errors.New("conflict must be any of keep, override, append")
+	}
+	if opts.Conflict != "" && !opts.Forward {
+		return "conflict", errors.New("conflict only applies when the query string is forwarded")
+	}
+
+	return "", nil
+}
+
+// utmValues returns the UTM parameters that are set, keyed by their query parameter names.
+func utmValues(utm domain.UTM) url.Values {
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"utm_source":   utm.Source,
+		"utm_medium":   utm.Medium,
+		"utm_campaign": utm.Campaign,
+		"utm_term":     utm.Term,
+		"utm_content":  utm.Content,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values
+}
+
+// mergeQuery adds the UTM parameters of the link to the destination, unless it already sets them, and the incoming
+// query string if the link forwards it. Keys of the incoming query string the destination already has are resolved
+// by the conflict mode of the link. The destination is returned as is if there is nothing to merge.
+func mergeQuery(destination string, opts domain.QueryOptions, incoming string) (string, error) {
+	utm := utmValues(opts.UTM)
+	if !opts.Forward {
+		incoming = ""
+	}
+	if len(utm) == 0 && incoming == "" {
+		return destination, nil
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", fmt.Errorf("destination is not a valid URL: %w", err)
+	}
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", fmt.Errorf("destination has a malformed query string: %w", err)
+	}
+
+	for key, value := range utm {
+		if !values.Has(key) {
+			values[key] = value
+		}
+	}
+
+	if incoming != "" {
+		forwarded, err := url.ParseQuery(incoming)
+		if err != nil {
+			return "", fmt.Errorf("malformed query string: %w", err)
+		}
+
+		for key, value := range forwarded {
+			switch {
+			case !values.Has(key):
+				values[key] = value
+			case opts.Conflict == domain.QueryConflictOverride:
+				values[key] = value
+			case opts.Conflict == domain.QueryConflictAppend:
+				values[key] = append(values[key], value...)
+			}
+		}
+	}
+
+	u.RawQuery = values.Encode()
+	merged := u.String()
+	if len(merged) > maxMergedURLLength {
+		return "", fmt.Errorf("merged URL is longer than %d bytes", maxMergedURLLength)
+	}
+	return merged, nil
+}
@@ -0,0 +1,312 @@
+// package handler contains handlers for managing the conditional redirect rules of links and evaluating them.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/text/language"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/policy"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+const (
+	maxRules       = 20
+	ruleTimeLayout = "15:04"
+)
+
+// rulePlatforms holds the platforms redirect rules can match on
+var rulePlatforms = map[string]bool{
+	domain.PlatformIOS:     true,
+	domain.PlatformAndroid: true,
+	domain.PlatformWindows: true,
+	domain.PlatformMacOS:   true,
+	domain.PlatformLinux:   true,
+	domain.PlatformOther:   true,
+}
+
+// URLRules defines an interface for managing the redirect rules of the user's links.
+//
+//go:generate mockgen -source=rulehandler.go -destination=mocks/rule_mock.gen.go -package=mocks
+type URLRules interface {
+	UserRules(ctx context.Context, userID int, id string) ([]domain.RedirectRule, error)
+	SetRules(ctx context.Context, userID int, id string, rules []domain.RedirectRule) ([]domain.RedirectRule, error)
+}
+
+// RuleHandler handles requests for managing conditional redirect rules.
+type RuleHandler struct {
+	rules   URLRules
+	checker URLChecker
+	cfg     *config.Config
+	audit   AuditRecorder
+}
+
+// NewRuleHandler creates a new instance of RuleHandler. audit may be nil.
+func NewRuleHandler(rules URLRules, checker URLChecker, cfg *config.Config, audit AuditRecorder) *RuleHandler {
+	return &RuleHandler{rules: rules, checker: checker, cfg: cfg, audit: audit}
+}
+
+// ListRulesHandler processes requests to list the redirect rules of one of the user's links.
+func (u *RuleHandler) ListRulesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	rules, err := u.rules.UserRules(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	writeRules(w, rules)
+}
+
+// SetRulesHandler processes requests to replace the redirect rules of one of the user's links.
+// An empty list sends every visitor to the link's own destination again.
+func (u *RuleHandler) SetRulesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var rules []domain.RedirectRule
+	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	if len(rules) > maxRules {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidRules).
+			WithDetail(fmt.Sprintf("a link can have at most %d rules", maxRules)).
+			Write(w)
+		return
+	}
+
+	for i := range rules {
+		if err := normalizeRule(&rules[i]); err != nil {
+			problem.New(r, http.StatusBadRequest, problem.CodeInvalidRules).
+				WithDetail(err.Error()).
+				With("index", i).
+				Write(w)
+			return
+		}
+
+		if err := u.checker.Check(rules[i].URL); err != nil {
+			var violation *policy.Violation
+			if !errors.As(err, &violation) {
+				problem.WriteError(w, r, err)
+				return
+			}
+			problem.New(r, http.StatusBadRequest, problem.CodeURLRejected).
+				WithDetail(violation.Message).
+				With("rule", violation.Rule).
+				With("index", i).
+				Write(w)
+			return
+		}
+	}
+
+	id := chi.URLParam(r, "id")
+	before := snapshot(r.Context(), u.audit, id)
+	if before != nil {
+		var err error
+		if before.Rules, err = u.rules.UserRules(r.Context(), userID, id); err != nil {
+			log.Println("Failed to snapshot link redirect rules for the audit log:", err)
+		}
+	}
+
+	stored, err := u.rules.SetRules(r.Context(), userID, id, rules)
+	if errors.Is(err, appErrors.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound)
+		return
+	} else if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	after := changed(before, func(after *domain.AuditState) { after.Rules = stored })
+	record(r.Context(), u.audit, auditEntry(r, u.cfg.TrustProxyHeaders, domain.AuditRules, id, before, after))
+
+	writeRules(w, stored)
+}
+
+func writeRules(w http.ResponseWriter, rules []domain.RedirectRule) {
+	if rules == nil {
+		rules = []domain.RedirectRule{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(rules); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// normalizeRule checks the conditions of a rule and brings platforms and languages to their canonical form.
+// A rule needs at least one condition, otherwise it would shadow the link's own destination.
+func normalizeRule(rule *domain.RedirectRule) error {
+	for i, platform := range rule.Platforms {
+		platform = strings.ToLower(strings.TrimSpace(platform))
+		if !rulePlatforms[platform] {
+			return fmt.Errorf("unknown platform %q, platforms must be any of ios, android, windows, macos, linux, other", platform)
+		}
+		rule.Platforms[i] = platform
+	}
+
+	for i, lang := range rule.Languages {
+		tag, err := language.Parse(strings.TrimSpace(lang))
+		if err != nil {
+			return fmt.Errorf("invalid language %q", lang)
+		}
+		rule.Languages[i] = tag.String()
+	}
+
+	if (rule.TimeFrom == "") != (rule.TimeTo == "") {
+		return errors.New("time_from and time_to must be given together")
+	}
+	if rule.TimeFrom != "" {
+		from, errFrom := time.Parse(ruleTimeLayout, rule.TimeFrom)
+		to, errTo := time.Parse(ruleTimeLayout, rule.TimeTo)
+		if errFrom != nil || errTo != nil {
+			return errors.New("time_from and time_to must be times of day as HH:MM")
+		}
+		if from.Equal(to) {
+			return errors.New("time_from and time_to must differ")
+		}
+	}
+	if rule.Timezone != "" {
+		if rule.TimeFrom == "" {
+			return errors.New("timezone requires time_from and time_to")
+		}
+		if _, err := time.LoadLocation(rule.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", rule.Timezone)
+		}
+	}
+
+	if len(rule.Platforms) == 0 && len(rule.Languages) == 0 && rule.TimeFrom == "" {
+		return errors.New("a rule needs at least one condition")
+	}
+	return nil
+}
+
+// matchRules returns the URL of the first rule the request matches at the given time.
+func matchRules(rules []domain.RedirectRule, r *http.Request, now time.Time) (string, bool) {
+	platform := detectPlatform(r.UserAgent())
+	lang := preferredLanguage(r.Header.Get("Accept-Language"))
+
+	for _, rule := range rules {
+		if matchRule(rule, platform, lang, now) {
+			return rule.URL, true
+		}
+	}
+	return "", false
+}
+
+func matchRule(rule domain.RedirectRule, platform, lang string, now time.Time) bool {
+	if len(rule.Platforms) > 0 && !contains(rule.Platforms, platform) {
+		return false
+	}
+
+	if len(rule.Languages) > 0 {
+		matched := false
+		for _, want := range rule.Languages {
+			want = strings.ToLower(want)
+			if lang == want || strings.HasPrefix(lang, want+"-") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if rule.TimeFrom != "" {
+		return inTimeWindow(rule, now)
+	}
+	return true
+}
+
+// inTimeWindow reports whether the time of day falls into the window of the rule, which includes its start
+// and excludes its end.
+func inTimeWindow(rule domain.RedirectRule, now time.Time) bool {
+	from, errFrom := time.Parse(ruleTimeLayout, rule.TimeFrom)
+	to, errTo := time.Parse(ruleTimeLayout, rule.TimeTo)
+	if errFrom != nil || errTo != nil {
+		return false
+	}
+
+	loc := time.UTC
+	if rule.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(rule.Timezone); err != nil {
+			log.Println("Failed to load rule timezone:", err)
+			return false
+		}
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	start := from.Hour()*60 + from.Minute()
+	end := to.Hour()*60 + to.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// detectPlatform tells the platform of the visitor by their User-Agent. iOS and Android are checked first since
+// their browsers also claim to run on macOS and Linux.
+func detectPlatform(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "iPod"):
+		return domain.PlatformIOS
+	case strings.Contains(userAgent, "Android"):
+		return domain.PlatformAndroid
+	case strings.Contains(userAgent, "Windows"):
+		return domain.PlatformWindows
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		return domain.PlatformMacOS
+	case strings.Contains(userAgent, "Linux"), strings.Contains(userAgent, "X11"):
+		return domain.PlatformLinux
+	default:
+		return domain.PlatformOther
+	}
+}
+
+// preferredLanguage returns the lowercased language the visitor prefers most, empty if they send none.
+func preferredLanguage(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return ""
+	}
+	return strings.ToLower(tags[0].String())
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -10,25 +10,31 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/Te8va/shortURL/internal/app/config"
 	"github.com/Te8va/shortURL/internal/app/domain"
 	"github.com/Te8va/shortURL/internal/app/handler"
+	"github.com/Te8va/shortURL/internal/app/policy"
 )
 
 type mockSaver struct{}
 
-func (m mockSaver) Save(ctx context.Context, userID int, url string) (string, error) {
-	return "http://short.ly/abc123", nil
+func (m mockSaver) Save(ctx context.Context, userID int, host, url string, meta domain.LinkMeta) (string, error) {
+	return "abc123", nil
 }
-func (m mockSaver) SaveBatch(ctx context.Context, userID int, urls map[string]string) (map[string]string, error) {
+func (m mockSaver) SaveBatch(ctx context.Context, userID int, host string, urls map[string]string) (map[string]string, error) {
 	res := make(map[string]string)
 	for k := range urls {
-		res[k] = "http://short.ly/" + k
+		res[k] = k
 	}
 	return res, nil
 }
 
+var exampleCfg = &config.Config{BaseURL: "http://short.ly"}
+
+var examplePolicy, _ = policy.New(exampleCfg.BaseURL, "")
+
 func ExampleSaveHandler_PostHandler() {
-	h := handler.NewSaveHandler(mockSaver{})
+	h := handler.NewSaveHandler(mockSaver{}, examplePolicy, exampleCfg, handler.SaveOptions{})
 	r := chi.NewRouter()
 	r.Post("/", h.PostHandler)
 
@@ -50,7 +56,7 @@ func ExampleSaveHandler_PostHandler() {
 }
 
 func ExampleSaveHandler_PostHandlerJSON() {
-	h := handler.NewSaveHandler(mockSaver{})
+	h := handler.NewSaveHandler(mockSaver{}, examplePolicy, exampleCfg, handler.SaveOptions{})
 	r := chi.NewRouter()
 	r.Post("/api/shorten", h.PostHandlerJSON)
 
@@ -75,7 +81,7 @@ func ExampleSaveHandler_PostHandlerJSON() {
 }
 
 func ExampleSaveHandler_PostHandlerBatch() {
-	h := handler.NewSaveHandler(mockSaver{})
+	h := handler.NewSaveHandler(mockSaver{}, examplePolicy, exampleCfg, handler.SaveOptions{})
 	r := chi.NewRouter()
 	r.Post("/api/shorten/batch", h.PostHandlerBatch)
 
@@ -6,13 +6,16 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"log"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
+	"github.com/Te8va/shortURL/internal/app/config"
 	"github.com/Te8va/shortURL/internal/app/domain"
 	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/policy"
+	"github.com/Te8va/shortURL/internal/app/problem"
 )
 
 const (
@@ -26,24 +29,121 @@ const (
 //
 //go:generate mockgen -source=savehandler.go -destination=mocks/url_saver_mock.gen.go -package=mocks
 type URLSaver interface {
-	Save(ctx context.Context, userID int, url string) (string, error)
-	SaveBatch(ctx context.Context, userID int, urls map[string]string) (map[string]string, error)
+	Save(ctx context.Context, userID int, host, url string, meta domain.LinkMeta) (string, error)
+	SaveBatch(ctx context.Context, userID int, host string, urls map[string]string) (map[string]string, error)
+}
+
+// URLChecker defines an interface for validating destination URLs against the URL policy.
+type URLChecker interface {
+	Check(rawURL string) error
+}
+
+// PageQueue defines an interface for queueing the metadata of the destination page of a new link to be fetched.
+type PageQueue interface {
+	Enqueue(id, url string)
 }
 
 // SaveHandler handles requests for saving URLs.
 type SaveHandler struct {
-	saver URLSaver
+	saver      URLSaver
+	checker    URLChecker
+	cfg        *config.Config
+	events     LinkEventPublisher
+	workspaces WorkspaceAuthorizer
+	pages      PageQueue
+	audit      AuditRecorder
+}
+
+// SaveOptions holds the optional collaborators of SaveHandler. Any of them may be nil, links can not be created in
+// workspaces if Workspaces is nil.
+type SaveOptions struct {
+	Events     LinkEventPublisher
+	Workspaces WorkspaceAuthorizer
+	Pages      PageQueue
+	Audit      AuditRecorder
 }
 
 // NewSaveHandler creates a new instance of SaveHandler.
-func NewSaveHandler(saver URLSaver) *SaveHandler {
-	return &SaveHandler{saver: saver}
+func NewSaveHandler(saver URLSaver, checker URLChecker, cfg *config.Config, opts SaveOptions) *SaveHandler {
+	return &SaveHandler{saver: saver, checker: checker, cfg: cfg, events: opts.Events, workspaces: opts.Workspaces, pages: opts.Pages, audit: opts.Audit}
+}
+
+// created publishes the creation of a link and queues fetching its destination page. It returns the audit log entry
+// of the creation.
+func (u *SaveHandler) created(r *http.Request, action string, userID int, id, shortURL, originalURL string, meta domain.LinkMeta) domain.AuditEntry {
+	publish(u.events, domain.LinkEvent{Type: domain.EventLinkCreated, UserID: userID, ID: id, ShortURL: shortURL, OriginalURL: originalURL})
+	if u.pages != nil {
+		u.pages.Enqueue(id, originalURL)
+	}
+
+	after := domain.NewAuditState(domain.Link{OriginalURL: originalURL, LinkMeta: meta})
+	return auditEntry(r, u.cfg.TrustProxyHeaders, action, id, nil, after)
+}
+
+// linkDomain picks the domain a new link is created on: the requested one if given, otherwise the one the request came in on.
+// It writes a rejection response if the requested domain is not registered.
+func (u *SaveHandler) linkDomain(w http.ResponseWriter, r *http.Request, requested string) (string, bool) {
+	host, p := u.resolveLinkDomain(r, requested)
+	if p != nil {
+		p.Write(w)
+		return "", false
+	}
+	return host, true
+}
+
+// resolveLinkDomain is linkDomain returning the rejection instead of writing it. Domains with owners are only
+// available to them.
+func (u *SaveHandler) resolveLinkDomain(r *http.Request, requested string) (string, *problem.Problem) {
+	host := u.cfg.ResolveDomain(r.Host)
+	if requested != "" {
+		var ok bool
+		host, ok = u.cfg.Domain(requested)
+		if !ok {
+			return "", problem.New(r, http.StatusBadRequest, problem.CodeUnknownDomain).With("domain", requested)
+		}
+	}
+
+	userID, _ := r.Context().Value(domain.UserIDKey).(int)
+	if !u.cfg.DomainAllowed(host, userID) {
+		return "", problem.New(r, http.StatusForbidden, problem.CodeDomainForbidden).With("domain", host)
+	}
+	return host, nil
+}
+
+// checkURL validates the URL and writes a rejection response if it breaks the policy.
+func (u *SaveHandler) checkURL(w http.ResponseWriter, r *http.Request, rawURL, correlationID string) bool {
+	if p := u.urlProblem(r, rawURL, correlationID); p != nil {
+		p.Write(w)
+		return false
+	}
+	return true
+}
+
+// urlProblem is checkURL returning the rejection instead of writing it.
+func (u *SaveHandler) urlProblem(r *http.Request, rawURL, correlationID string) *problem.Problem {
+	err := u.checker.Check(rawURL)
+	if err == nil {
+		return nil
+	}
+
+	var violation *policy.Violation
+	if !errors.As(err, &violation) {
+		return problem.FromError(r, err)
+	}
+
+	p := problem.New(r, http.StatusBadRequest, problem.CodeURLRejected).
+		WithDetail(violation.Message).
+		With("rule", violation.Rule)
+	if correlationID != "" {
+		p.With("correlation_id", correlationID)
+	}
+	return p
 }
 
 // PostHandler processes requests to save URL.
 func (u *SaveHandler) PostHandler(w http.ResponseWriter, r *http.Request) {
 	if !strings.HasPrefix(r.Header.Get(contentType), contentTypeText) {
-		http.Error(w, "Content-Type must be text/plain", http.StatusBadRequest)
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidContentType).WithDetail("Content-Type must be text/plain").Write(w)
 		return
 	}
 
@@ -51,43 +151,54 @@ func (u *SaveHandler) PostHandler(w http.ResponseWriter, r *http.Request) {
 
 	originalURLBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidBody)
 		return
 	}
 
 	originalURL := string(originalURLBytes)
 	if originalURL == "" {
-		http.Error(w, "Empty URL", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeEmptyURL)
 		return
 	}
 
-	_, err = url.ParseRequestURI(originalURL)
-	if err != nil {
-		http.Error(w, "Invalid URL format", http.StatusBadRequest)
+	if !u.checkURL(w, r, originalURL, "") {
+		return
+	}
+
+	host, ok := u.linkDomain(w, r, r.URL.Query().Get("domain"))
+	if !ok {
+		return
+	}
+
+	meta := domain.LinkMeta{Workspace: strings.TrimSpace(r.URL.Query().Get("workspace"))}
+	if !authorizeWorkspace(w, r, u.workspaces, userID, meta.Workspace, domain.RoleEditor) {
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	id, err := u.saver.Save(ctx, userID, originalURL)
+	id, err := u.saver.Save(ctx, userID, host, originalURL, meta)
 	if err != nil {
 		if !errors.Is(err, appErrors.ErrURLExists) {
-			http.Error(w, "Failed to save URL", http.StatusBadRequest)
+			problem.WriteError(w, r, err)
 			return
 		}
 		w.Header().Set(contentType, contentTypeText)
 		w.WriteHeader(http.StatusConflict)
-		if _, err := w.Write([]byte(id)); err != nil {
-			http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		if _, err := w.Write([]byte(u.cfg.ShortURL(host, id))); err != nil {
+			log.Println("Failed to write response:", err)
 		}
 		return
 	}
 
+	shortURL := u.cfg.ShortURL(host, id)
+	record(r.Context(), u.audit, u.created(r, domain.AuditCreate, userID, id, shortURL, originalURL, meta))
+
 	w.Header().Set(contentType, contentTypeText)
 	w.WriteHeader(http.StatusCreated)
-	if _, err := w.Write([]byte(id)); err != nil {
-		http.Error(w, "Failed to write response", http.StatusBadRequest)
+	if _, err := w.Write([]byte(shortURL)); err != nil {
+		log.Println("Failed to write response:", err)
 		return
 	}
 }
@@ -95,7 +206,7 @@ func (u *SaveHandler) PostHandler(w http.ResponseWriter, r *http.Request) {
 // PostHandlerJSON processes JSON-formatted POST requests to save URL.
 func (u *SaveHandler) PostHandlerJSON(w http.ResponseWriter, r *http.Request) {
 	if !strings.HasPrefix(r.Header.Get(contentType), contentTypeApp) {
-		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidContentType).WithDetail("Content-Type must be application/json").Write(w)
 		return
 	}
 
@@ -103,37 +214,54 @@ func (u *SaveHandler) PostHandlerJSON(w http.ResponseWriter, r *http.Request) {
 
 	var req domain.ShortenRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	if !u.checkURL(w, r, req.URL, "") {
+		return
+	}
+
+	host, ok := u.linkDomain(w, r, req.Domain)
+	if !ok {
+		return
+	}
+
+	meta, err := normalizeMeta(req.LinkMeta)
+	if err != nil {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidMetadata).WithDetail(err.Error()).Write(w)
 		return
 	}
 
-	if _, err := url.ParseRequestURI(req.URL); err != nil {
-		http.Error(w, "Invalid URL format", http.StatusBadRequest)
+	if !authorizeWorkspace(w, r, u.workspaces, userID, meta.Workspace, domain.RoleEditor) {
 		return
 	}
 
-	id, err := u.saver.Save(r.Context(), userID, req.URL)
+	id, err := u.saver.Save(r.Context(), userID, host, req.URL, meta)
 
 	if errors.Is(err, appErrors.ErrURLExists) {
-		resp := domain.ShortenResponse{Result: id}
+		resp := domain.ShortenResponse{Result: u.cfg.ShortURL(host, id)}
 		w.Header().Set(contentType, contentTypeApp)
 		w.WriteHeader(http.StatusConflict)
 		if err := json.NewEncoder(w).Encode(resp); err != nil {
-			http.Error(w, "Failed to write response", http.StatusInternalServerError)
+			log.Println("Failed to write response:", err)
 			return
 		}
 		return
 	} else if err != nil {
-		http.Error(w, "Failed to save URL", http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
 		return
 	}
 
-	resp := domain.ShortenResponse{Result: id}
+	shortURL := u.cfg.ShortURL(host, id)
+	record(r.Context(), u.audit, u.created(r, domain.AuditCreate, userID, id, shortURL, req.URL, meta))
+
+	resp := domain.ShortenResponse{Result: shortURL}
 
 	w.Header().Set(contentType, contentTypeApp)
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		log.Println("Failed to write response:", err)
 		return
 	}
 }
@@ -142,6 +270,8 @@ func (u *SaveHandler) PostHandlerJSON(w http.ResponseWriter, r *http.Request) {
 type BatchRequest struct {
 	CorrelationID string `json:"correlation_id"`
 	OriginalURL   string `json:"original_url"`
+	Domain        string `json:"domain,omitempty"`
+	domain.LinkMeta
 }
 
 // BatchResponse represents a shortened URL response for a single batch item.
@@ -156,24 +286,58 @@ func (u *SaveHandler) PostHandlerBatch(w http.ResponseWriter, r *http.Request) {
 
 	var batchReq []BatchRequest
 	if err := json.NewDecoder(r.Body).Decode(&batchReq); err != nil {
-		http.Error(w, "Некорректный JSON", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
 		return
 	}
 
 	if len(batchReq) == 0 {
-		http.Error(w, "Пустой список URL", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeEmptyBatch)
 		return
 	}
 
+	hosts := make([]string, len(batchReq))
+	metas := make([]domain.LinkMeta, len(batchReq))
+	authorized := make(map[string]bool)
+	for i, req := range batchReq {
+		if !u.checkURL(w, r, req.OriginalURL, req.CorrelationID) {
+			return
+		}
+		host, ok := u.linkDomain(w, r, req.Domain)
+		if !ok {
+			return
+		}
+		meta, err := normalizeMeta(req.LinkMeta)
+		if err != nil {
+			problem.New(r, http.StatusBadRequest, problem.CodeInvalidMetadata).
+				WithDetail(err.Error()).
+				With("correlation_id", req.CorrelationID).
+				Write(w)
+			return
+		}
+		if !authorized[meta.Workspace] {
+			if !authorizeWorkspace(w, r, u.workspaces, userID, meta.Workspace, domain.RoleEditor) {
+				return
+			}
+			authorized[meta.Workspace] = true
+		}
+		hosts[i] = host
+		metas[i] = meta
+	}
+
 	urlMap := make(map[string]string)
-	for _, req := range batchReq {
-		id, err := u.saver.Save(r.Context(), userID, req.OriginalURL)
+	entries := make([]domain.AuditEntry, 0, len(batchReq))
+	for i, req := range batchReq {
+		id, err := u.saver.Save(r.Context(), userID, hosts[i], req.OriginalURL, metas[i])
 		if err != nil {
-			http.Error(w, "Ошибка сохранения URL", http.StatusInternalServerError)
+			record(r.Context(), u.audit, entries...)
+			problem.FromError(r, err).With("correlation_id", req.CorrelationID).Write(w)
 			return
 		}
-		urlMap[req.CorrelationID] = id
+		shortURL := u.cfg.ShortURL(hosts[i], id)
+		urlMap[req.CorrelationID] = shortURL
+		entries = append(entries, u.created(r, domain.AuditBatchCreate, userID, id, shortURL, req.OriginalURL, metas[i]))
 	}
+	record(r.Context(), u.audit, entries...)
 
 	var batchResp []BatchResponse
 	for _, req := range batchReq {
@@ -186,6 +350,6 @@ func (u *SaveHandler) PostHandlerBatch(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set(contentType, contentTypeApp)
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(batchResp); err != nil {
-		http.Error(w, "Ошибка записи ответа", http.StatusInternalServerError)
+		log.Println("Failed to write response:", err)
 	}
 }
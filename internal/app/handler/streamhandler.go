@@ -0,0 +1,210 @@
+// package handler contains the handler for shortening a stream of URLs.
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+const (
+	// streamChunkSize is how many lines of a stream are saved with one SaveBatch call
+	streamChunkSize = 100
+	// maxStreamLineBytes bounds a single line of a stream
+	maxStreamLineBytes = 64 << 10
+)
+
+// StreamRequest represents a line of a stream of URLs to shorten
+type StreamRequest struct {
+	CorrelationID string `json:"correlation_id"`
+	OriginalURL   string `json:"original_url"`
+	Domain        string `json:"domain,omitempty"`
+}
+
+// StreamResponse represents the outcome of a line of a stream, lines are counted from 1 skipping blank ones.
+// Failed lines carry a problem document, a URL that was already shortened also carries its short URL.
+type StreamResponse struct {
+	Line          int              `json:"line"`
+	CorrelationID string           `json:"correlation_id,omitempty"`
+	ShortURL      string           `json:"short_url,omitempty"`
+	Error         *problem.Problem `json:"error,omitempty"`
+}
+
+// streamLine is a valid line of a stream waiting to be saved
+type streamLine struct {
+	line int
+	req  StreamRequest
+	host string
+}
+
+// PostHandlerStream processes newline-delimited JSON streams of URLs to shorten and answers with a line per input
+// line. Lines are saved in chunks and the results of a chunk are sent before more of the stream is read, so a client
+// that does not read the answers stops being read from. Streams longer than STREAM_MAX_LINES are cut off.
+func (u *SaveHandler) PostHandlerStream(w http.ResponseWriter, r *http.Request) {
+	if media, _, err := mime.ParseMediaType(r.Header.Get(contentType)); err != nil || media != contentTypeNDJSON {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidContentType).WithDetail("Content-Type must be application/x-ndjson").Write(w)
+		return
+	}
+
+	userID, _ := r.Context().Value(domain.UserIDKey).(int)
+
+	rc := http.NewResponseController(w)
+	// HTTP/1 stops reading the request once the response starts unless asked not to, HTTP/2 always can
+	_ = rc.EnableFullDuplex()
+
+	w.Header().Set(contentType, contentTypeNDJSON)
+	enc := json.NewEncoder(w)
+	written := false
+	send := func(results ...StreamResponse) bool {
+		for _, res := range results {
+			if err := enc.Encode(res); err != nil {
+				log.Println("Failed to write response:", err)
+				return false
+			}
+			written = true
+		}
+		if err := rc.Flush(); err != nil {
+			log.Println("Failed to flush response:", err)
+			return false
+		}
+		return true
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxStreamLineBytes)
+
+	chunk := make([]streamLine, 0, streamChunkSize)
+	line := 0
+	tooMany := false
+	for scanner.Scan() {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		line++
+		if u.cfg.StreamMaxLines > 0 && line > u.cfg.StreamMaxLines {
+			tooMany = true
+			break
+		}
+
+		var req StreamRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			if !send(StreamResponse{Line: line, Error: problem.New(r, http.StatusBadRequest, problem.CodeInvalidJSON)}) {
+				return
+			}
+			continue
+		}
+
+		host, p := u.resolveLinkDomain(r, req.Domain)
+		if p == nil {
+			p = u.urlProblem(r, req.OriginalURL, req.CorrelationID)
+		}
+		if p != nil {
+			if !send(StreamResponse{Line: line, CorrelationID: req.CorrelationID, Error: p}) {
+				return
+			}
+			continue
+		}
+
+		chunk = append(chunk, streamLine{line: line, req: req, host: host})
+		if len(chunk) == streamChunkSize {
+			if !send(u.saveStreamChunk(r, userID, chunk)...) {
+				return
+			}
+			chunk = chunk[:0]
+		}
+	}
+
+	if len(chunk) > 0 && !send(u.saveStreamChunk(r, userID, chunk)...) {
+		return
+	}
+
+	switch err := scanner.Err(); {
+	case tooMany:
+		send(StreamResponse{Line: line, Error: problem.New(r, http.StatusRequestEntityTooLarge, problem.CodeTooManyLines).
+			WithDetail(fmt.Sprintf("at most %d lines are accepted", u.cfg.StreamMaxLines))})
+	case errors.Is(err, bufio.ErrTooLong):
+		send(StreamResponse{Line: line + 1, Error: problem.New(r, http.StatusBadRequest, problem.CodeInvalidBody).
+			WithDetail(fmt.Sprintf("lines must not be longer than %d bytes", maxStreamLineBytes))})
+	case err != nil:
+		send(StreamResponse{Line: line + 1, Error: problem.New(r, http.StatusBadRequest, problem.CodeInvalidBody)})
+	case !written:
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeEmptyBatch)
+	}
+}
+
+// saveStreamChunk saves the lines with a SaveBatch call per domain and returns their results in the order of the stream
+func (u *SaveHandler) saveStreamChunk(r *http.Request, userID int, chunk []streamLine) []StreamResponse {
+	var hosts []string
+	byHost := make(map[string][]streamLine)
+	for _, l := range chunk {
+		if _, ok := byHost[l.host]; !ok {
+			hosts = append(hosts, l.host)
+		}
+		byHost[l.host] = append(byHost[l.host], l)
+	}
+
+	results := make([]StreamResponse, 0, len(chunk))
+	entries := make([]domain.AuditEntry, 0, len(chunk))
+	for _, host := range hosts {
+		lines := byHost[host]
+
+		// keyed by line, correlation IDs may repeat
+		urls := make(map[string]string, len(lines))
+		for _, l := range lines {
+			urls[strconv.Itoa(l.line)] = l.req.OriginalURL
+		}
+
+		ids, err := u.saver.SaveBatch(r.Context(), userID, host, urls)
+		if err != nil {
+			// a single URL that was already shortened fails the whole batch, saving the lines one by one tells them apart
+			for _, l := range lines {
+				results = append(results, u.saveStreamLine(r, userID, l, &entries))
+			}
+			continue
+		}
+
+		for _, l := range lines {
+			id := ids[strconv.Itoa(l.line)]
+			shortURL := u.cfg.ShortURL(host, id)
+			entries = append(entries, u.created(r, domain.AuditBatchCreate, userID, id, shortURL, l.req.OriginalURL, domain.LinkMeta{}))
+			results = append(results, StreamResponse{Line: l.line, CorrelationID: l.req.CorrelationID, ShortURL: shortURL})
+		}
+	}
+	record(r.Context(), u.audit, entries...)
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Line < results[j].Line
+	})
+	return results
+}
+
+// saveStreamLine saves a single line of a stream, adding the audit log entry of the new link to entries
+func (u *SaveHandler) saveStreamLine(r *http.Request, userID int, l streamLine, entries *[]domain.AuditEntry) StreamResponse {
+	res := StreamResponse{Line: l.line, CorrelationID: l.req.CorrelationID}
+
+	id, err := u.saver.Save(r.Context(), userID, l.host, l.req.OriginalURL, domain.LinkMeta{})
+	if errors.Is(err, appErrors.ErrURLExists) {
+		res.ShortURL = u.cfg.ShortURL(l.host, id)
+		res.Error = problem.FromError(r, err)
+		return res
+	} else if err != nil {
+		res.Error = problem.FromError(r, err)
+		return res
+	}
+
+	res.ShortURL = u.cfg.ShortURL(l.host, id)
+	*entries = append(*entries, u.created(r, domain.AuditBatchCreate, userID, id, res.ShortURL, l.req.OriginalURL, domain.LinkMeta{}))
+	return res
+}
@@ -0,0 +1,159 @@
+// package handler contains logic for updating the title, notes and tags of user links.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+const (
+	maxTitleLength = 200
+	maxNotesLength = 2000
+	maxTags        = 20
+	maxTagLength   = 50
+)
+
+// URLUpdater defines an interface for updating link metadata.
+//
+//go:generate mockgen -source=updatehandler.go -destination=mocks/url_updater_mock.gen.go -package=mocks
+type URLUpdater interface {
+	UpdateLink(ctx context.Context, userID int, id string, update domain.LinkUpdate) (domain.Link, error)
+}
+
+// UpdateHandler handles requests for updating link metadata.
+type UpdateHandler struct {
+	updater URLUpdater
+	cfg     *config.Config
+	audit   AuditRecorder
+}
+
+// NewUpdateHandler creates a new instance of UpdateHandler. audit may be nil.
+func NewUpdateHandler(updater URLUpdater, cfg *config.Config, audit AuditRecorder) *UpdateHandler {
+	return &UpdateHandler{updater: updater, cfg: cfg, audit: audit}
+}
+
+// UpdateLinkHandler processes requests to change the title, notes or tags of one of the user's links.
+func (u *UpdateHandler) UpdateLinkHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var update domain.LinkUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	update, err := normalizeUpdate(update)
+	if err != nil {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidMetadata).WithDetail(err.Error()).Write(w)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	before := snapshot(r.Context(), u.audit, id)
+
+	link, err := u.updater.UpdateLink(r.Context(), userID, id, update)
+	if errors.Is(err, appErrors.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound)
+		return
+	} else if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	record(r.Context(), u.audit, auditEntry(r, u.cfg.TrustProxyHeaders, domain.AuditUpdate, id, before, domain.NewAuditState(link)))
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(newUserURL(u.cfg, link)); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// normalizeMeta trims the title and notes, lower-cases and deduplicates the tags and checks them against the limits.
+func normalizeMeta(meta domain.LinkMeta) (domain.LinkMeta, error) {
+	meta.Workspace = strings.TrimSpace(meta.Workspace)
+
+	meta.Title = strings.TrimSpace(meta.Title)
+	if utf8.RuneCountInString(meta.Title) > maxTitleLength {
+		return meta, fmt.Errorf("title must not be longer than %d characters", maxTitleLength)
+	}
+
+	meta.Notes = strings.TrimSpace(meta.Notes)
+	if utf8.RuneCountInString(meta.Notes) > maxNotesLength {
+		return meta, fmt.Errorf("notes must not be longer than %d characters", maxNotesLength)
+	}
+
+	if meta.Tags == nil {
+		return meta, nil
+	}
+
+	tags := make([]string, 0, len(meta.Tags))
+	seen := make(map[string]bool, len(meta.Tags))
+	for _, tag := range meta.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return meta, fmt.Errorf("tags must not be longer than %d characters", maxTagLength)
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTags {
+		return meta, fmt.Errorf("a link can have at most %d tags", maxTags)
+	}
+	meta.Tags = tags
+
+	return meta, nil
+}
+
+// normalizeUpdate applies normalizeMeta to the fields set in update.
+func normalizeUpdate(update domain.LinkUpdate) (domain.LinkUpdate, error) {
+	var meta domain.LinkMeta
+	if update.Title != nil {
+		meta.Title = *update.Title
+	}
+	if update.Notes != nil {
+		meta.Notes = *update.Notes
+	}
+	if update.Tags != nil {
+		meta.Tags = *update.Tags
+		if meta.Tags == nil {
+			meta.Tags = []string{}
+		}
+	}
+
+	meta, err := normalizeMeta(meta)
+	if err != nil {
+		return update, err
+	}
+
+	if update.Title != nil {
+		update.Title = &meta.Title
+	}
+	if update.Notes != nil {
+		update.Notes = &meta.Notes
+	}
+	if update.Tags != nil {
+		update.Tags = &meta.Tags
+	}
+	return update, nil
+}
@@ -0,0 +1,208 @@
+// package handler contains handlers for managing the destinations of A/B links and splitting traffic across them.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+	"github.com/Te8va/shortURL/internal/app/policy"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+const (
+	maxVariants      = 10
+	maxVariantWeight = 1000
+	maxVisitorKey    = 64
+	visitorCookieTTL = 365 * 24 * time.Hour
+)
+
+// visitorCookieName is the name of the cookie that keeps visitors of A/B links on their variant
+const visitorCookieName = "visitor"
+
+// VariantRouter defines an interface for looking up the destinations of a link on redirect and counting their clicks.
+//
+//go:generate mockgen -source=varianthandler.go -destination=mocks/variant_mock.gen.go -package=mocks
+type VariantRouter interface {
+	GetVariants(ctx context.Context, id string) ([]domain.Variant, error)
+	CountVariantClick(ctx context.Context, id string, variant int) error
+}
+
+// URLVariants defines an interface for managing the destinations of the user's links.
+type URLVariants interface {
+	UserVariants(ctx context.Context, userID int, id string) ([]domain.Variant, error)
+	SetVariants(ctx context.Context, userID int, id string, variants []domain.Variant) ([]domain.Variant, error)
+}
+
+// VariantHandler handles requests for managing A/B link destinations.
+type VariantHandler struct {
+	variants URLVariants
+	checker  URLChecker
+	cfg      *config.Config
+	audit    AuditRecorder
+}
+
+// NewVariantHandler creates a new instance of VariantHandler. audit may be nil.
+func NewVariantHandler(variants URLVariants, checker URLChecker, cfg *config.Config, audit AuditRecorder) *VariantHandler {
+	return &VariantHandler{variants: variants, checker: checker, cfg: cfg, audit: audit}
+}
+
+// ListVariantsHandler processes requests to list the destinations of one of the user's links with their click counts.
+func (u *VariantHandler) ListVariantsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	variants, err := u.variants.UserVariants(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	writeVariants(w, variants)
+}
+
+// SetVariantsHandler processes requests to split the traffic of one of the user's links across weighted destinations.
+// An empty list turns the link back into a plain redirect to its original URL.
+func (u *VariantHandler) SetVariantsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var variants []domain.Variant
+	if err := json.NewDecoder(r.Body).Decode(&variants); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	if len(variants) > maxVariants {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidVariants).
+			WithDetail(fmt.Sprintf("a link can have at most %d variants", maxVariants)).
+			Write(w)
+		return
+	}
+
+	for i, variant := range variants {
+		if variant.Weight < 1 || variant.Weight > maxVariantWeight {
+			problem.New(r, http.StatusBadRequest, problem.CodeInvalidVariants).
+				WithDetail(fmt.Sprintf("weights must be between 1 and %d", maxVariantWeight)).
+				With("variant", i).
+				Write(w)
+			return
+		}
+
+		if err := u.checker.Check(variant.URL); err != nil {
+			var violation *policy.Violation
+			if !errors.As(err, &violation) {
+				problem.WriteError(w, r, err)
+				return
+			}
+			problem.New(r, http.StatusBadRequest, problem.CodeURLRejected).
+				WithDetail(violation.Message).
+				With("rule", violation.Rule).
+				With("variant", i).
+				Write(w)
+			return
+		}
+		variants[i].Clicks = 0
+	}
+
+	id := chi.URLParam(r, "id")
+	before := snapshot(r.Context(), u.audit, id)
+	if before != nil {
+		var err error
+		if before.Variants, err = u.variants.UserVariants(r.Context(), userID, id); err != nil {
+			log.Println("Failed to snapshot link variants for the audit log:", err)
+		}
+	}
+
+	stored, err := u.variants.SetVariants(r.Context(), userID, id, variants)
+	if errors.Is(err, appErrors.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound)
+		return
+	} else if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	after := changed(before, func(after *domain.AuditState) { after.Variants = stored })
+	record(r.Context(), u.audit, auditEntry(r, u.cfg.TrustProxyHeaders, domain.AuditVariants, id, before, after))
+
+	writeVariants(w, stored)
+}
+
+func writeVariants(w http.ResponseWriter, variants []domain.Variant) {
+	if variants == nil {
+		variants = []domain.Variant{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(variants); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// pickVariant chooses one of the variants in proportion to their weights. The choice only depends on the link and the
+// visitor, so a visitor keeps getting the same variant as long as the variants do not change.
+func pickVariant(variants []domain.Variant, id, visitor string) int {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(id + "\x00" + visitor))
+	n := h.Sum64() % uint64(total)
+
+	for i, variant := range variants {
+		if n < uint64(variant.Weight) {
+			return i
+		}
+		n -= uint64(variant.Weight)
+	}
+	return len(variants) - 1
+}
+
+// visitorKey identifies the visitor of a redirect by its visitor cookie. Visitors without one are identified by their
+// client address, and the cookie is set to that same key so they stay on their variant once the cookie comes back,
+// whichever user they are signed in as by then.
+func visitorKey(w http.ResponseWriter, r *http.Request, trustProxy, secure bool) string {
+	if cookie, err := r.Cookie(visitorCookieName); err == nil && cookie.Value != "" && len(cookie.Value) <= maxVisitorKey {
+		return cookie.Value
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(middleware.ClientIP(r, trustProxy)))
+	key := strconv.FormatUint(h.Sum64(), 36)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     visitorCookieName,
+		Value:    key,
+		Path:     "/",
+		MaxAge:   int(visitorCookieTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return key
+}
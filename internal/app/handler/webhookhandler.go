@@ -0,0 +1,156 @@
+// package handler contains handlers for managing webhook subscriptions.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/policy"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+// WebhookManager defines an interface for creating, listing and deleting webhooks and viewing failed deliveries.
+//
+//go:generate mockgen -source=webhookhandler.go -destination=mocks/webhook_mock.gen.go -package=mocks
+type WebhookManager interface {
+	CreateWebhook(ctx context.Context, userID int, url string, events []string) (domain.WebhookResponse, error)
+	ListWebhooks(ctx context.Context, userID int) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID int, id string) error
+	ListDeadLetters(ctx context.Context, userID int) ([]domain.WebhookDeadLetter, error)
+}
+
+// LinkEventPublisher defines an interface for announcing link lifecycle events to webhooks.
+type LinkEventPublisher interface {
+	Publish(event domain.LinkEvent)
+}
+
+// publish sends the event if a publisher is configured.
+func publish(events LinkEventPublisher, event domain.LinkEvent) {
+	if events != nil {
+		events.Publish(event)
+	}
+}
+
+// WebhookHandler handles requests for managing webhooks.
+type WebhookHandler struct {
+	hooks WebhookManager
+}
+
+// NewWebhookHandler creates a new instance of WebhookHandler.
+func NewWebhookHandler(hooks WebhookManager) *WebhookHandler {
+	return &WebhookHandler{hooks: hooks}
+}
+
+// CreateWebhookHandler processes requests to subscribe to link events. The signing secret is only returned in this response.
+func (u *WebhookHandler) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var req domain.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	hook, err := u.hooks.CreateWebhook(r.Context(), userID, req.URL, req.Events)
+	var violation *policy.Violation
+	if errors.As(err, &violation) {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidWebhook).
+			WithDetail(violation.Message).
+			With("rule", violation.Rule).
+			Write(w)
+		return
+	} else if errors.Is(err, appErrors.ErrInvalidWebhook) {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidWebhook)
+		return
+	} else if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(hook); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ListWebhooksHandler processes requests to list the user's webhooks.
+func (u *WebhookHandler) ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	hooks, err := u.hooks.ListWebhooks(r.Context(), userID)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	if hooks == nil {
+		hooks = []domain.Webhook{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(hooks); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// DeleteWebhookHandler processes requests to delete one of the user's webhooks.
+func (u *WebhookHandler) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	err := u.hooks.DeleteWebhook(r.Context(), userID, chi.URLParam(r, "id"))
+	if errors.Is(err, appErrors.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeWebhookNotFound)
+		return
+	} else if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeadLettersHandler processes requests to list deliveries that failed after every retry.
+func (u *WebhookHandler) ListDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	letters, err := u.hooks.ListDeadLetters(r.Context(), userID)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	if letters == nil {
+		letters = []domain.WebhookDeadLetter{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(letters); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
@@ -0,0 +1,242 @@
+// package handler contains handlers for managing workspaces, their members and the links they share.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+// WorkspaceAuthorizer defines an interface for checking the role of a user in a workspace.
+//
+//go:generate mockgen -source=workspacehandler.go -destination=mocks/workspace_mock.gen.go -package=mocks
+type WorkspaceAuthorizer interface {
+	Authorize(ctx context.Context, userID int, workspaceID, role string) error
+}
+
+// WorkspaceManager defines an interface for managing workspaces, their members and moving links into them.
+type WorkspaceManager interface {
+	WorkspaceAuthorizer
+	CreateWorkspace(ctx context.Context, userID int, name string) (domain.Workspace, error)
+	ListWorkspaces(ctx context.Context, userID int) ([]domain.Workspace, error)
+	ListMembers(ctx context.Context, userID int, workspaceID string) ([]domain.WorkspaceMember, error)
+	SetMember(ctx context.Context, userID int, workspaceID string, memberID int, role string) (domain.WorkspaceMember, error)
+	RemoveMember(ctx context.Context, userID int, workspaceID string, memberID int) error
+	TransferLinks(ctx context.Context, userID int, workspaceID string, ids []string) error
+}
+
+// authorizeWorkspace checks that the user holds at least the role in the workspace, if one is given,
+// and writes a rejection response otherwise.
+func authorizeWorkspace(w http.ResponseWriter, r *http.Request, workspaces WorkspaceAuthorizer, userID int, workspaceID, role string) bool {
+	if workspaceID == "" {
+		return true
+	}
+	if workspaces == nil {
+		problem.New(r, http.StatusNotFound, problem.CodeNotFound).With("workspace", workspaceID).Write(w)
+		return false
+	}
+
+	err := workspaces.Authorize(r.Context(), userID, workspaceID, role)
+	if err == nil {
+		return true
+	}
+
+	p := problem.FromError(r, err)
+	if p.Status != http.StatusInternalServerError {
+		p.With("workspace", workspaceID)
+	}
+	p.Write(w)
+	return false
+}
+
+// WorkspaceHandler handles requests for managing workspaces.
+type WorkspaceHandler struct {
+	workspaces WorkspaceManager
+	cfg        *config.Config
+	audit      AuditRecorder
+}
+
+// NewWorkspaceHandler creates a new instance of WorkspaceHandler. audit may be nil.
+func NewWorkspaceHandler(workspaces WorkspaceManager, cfg *config.Config, audit AuditRecorder) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaces: workspaces, cfg: cfg, audit: audit}
+}
+
+// CreateWorkspaceHandler processes requests to create a workspace owned by the user.
+func (u *WorkspaceHandler) CreateWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var req domain.WorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	ws, err := u.workspaces.CreateWorkspace(r.Context(), userID, req.Name)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(ws); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ListWorkspacesHandler processes requests to list the workspaces the user is a member of.
+func (u *WorkspaceHandler) ListWorkspacesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	workspaces, err := u.workspaces.ListWorkspaces(r.Context(), userID)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	if workspaces == nil {
+		workspaces = []domain.Workspace{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(workspaces); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ListMembersHandler processes requests to list the members of a workspace.
+func (u *WorkspaceHandler) ListMembersHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	members, err := u.workspaces.ListMembers(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	if members == nil {
+		members = []domain.WorkspaceMember{}
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(members); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// SetMemberHandler processes requests to invite a registered user into a workspace or change their role.
+func (u *WorkspaceHandler) SetMemberHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	memberID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter).WithDetail("user ID must be a number").Write(w)
+		return
+	}
+
+	var req domain.MemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	member, err := u.workspaces.SetMember(r.Context(), userID, chi.URLParam(r, "id"), memberID, req.Role)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set(contentType, contentTypeApp)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(member); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// RemoveMemberHandler processes requests to remove a member from a workspace or to leave it.
+func (u *WorkspaceHandler) RemoveMemberHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	memberID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter).WithDetail("user ID must be a number").Write(w)
+		return
+	}
+
+	if err := u.workspaces.RemoveMember(r.Context(), userID, chi.URLParam(r, "id"), memberID); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TransferLinksHandler processes requests to move links into a workspace, transferring their ownership to it.
+func (u *WorkspaceHandler) TransferLinksHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(domain.UserIDKey).(int)
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+		return
+	}
+
+	var ids []string
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+		return
+	}
+
+	if len(ids) == 0 {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeEmptyBatch)
+		return
+	}
+
+	workspaceID := chi.URLParam(r, "id")
+	before := make([]*domain.AuditState, len(ids))
+	for i, id := range ids {
+		before[i] = snapshot(r.Context(), u.audit, id)
+	}
+
+	if err := u.workspaces.TransferLinks(r.Context(), userID, workspaceID, ids); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	entries := make([]domain.AuditEntry, 0, len(ids))
+	for i, id := range ids {
+		after := changed(before[i], func(after *domain.AuditState) { after.Workspace = workspaceID })
+		entries = append(entries, auditEntry(r, u.cfg.TrustProxyHeaders, domain.AuditTransfer, id, before[i], after))
+	}
+	record(r.Context(), u.audit, entries...)
+
+	w.WriteHeader(http.StatusNoContent)
+}
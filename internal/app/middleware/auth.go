@@ -7,87 +7,102 @@ import (
 	"log"
 	"math/big"
 	"net/http"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
 
 	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/problem"
 )
 
+// AuthCookieName is the name of the cookie carrying the JWT
+const AuthCookieName = "auth"
+
+const bearerPrefix = "Bearer "
+
+// APIKeyAuthenticator defines an interface for resolving a plain API key to the stored key
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (domain.APIKey, error)
+}
+
 // Claims defines the JWT claims containing the user ID
 type Claims struct {
-	UserID int `json:"user_id"`
+	UserID     int  `json:"user_id"`
+	Registered bool `json:"registered,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken creates a signed JWT containing a random user ID
-func GenerateToken(secretKey string) (string, int, error) {
-	id, err := rand.Int(rand.Reader, big.NewInt(1000000))
-	if err != nil {
-		return "", 0, fmt.Errorf("GenerateToken: %w", err)
-	}
-
-	claims := Claims{
-		UserID: int(id.Int64()),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secretKey))
+// newAnonymousUserID picks a random ID below the range reserved for registered accounts
+func newAnonymousUserID() (int, error) {
+	id, err := rand.Int(rand.Reader, big.NewInt(domain.FirstRegisteredUserID))
 	if err != nil {
-		return "", 0, fmt.Errorf("GenerateToken: %w", err)
+		return 0, fmt.Errorf("newAnonymousUserID: %w", err)
 	}
-
-	return tokenString, claims.UserID, nil
+	return int(id.Int64()), nil
 }
 
-func getUserIDFromCookie(r *http.Request, secretKey string) (int, bool) {
-	cookie, err := r.Cookie("auth")
+func getClaimsFromCookie(r *http.Request, tokens *TokenManager) (*Claims, bool, bool) {
+	cookie, err := r.Cookie(AuthCookieName)
 	if err != nil {
-		return 0, false
+		return nil, false, false
 	}
 
-	token, err := jwt.ParseWithClaims(cookie.Value, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
-		return []byte(secretKey), nil
-	})
-
+	claims, refresh, err := tokens.Parse(cookie.Value)
 	if err != nil {
 		log.Println("JWT parsing error:", err)
-		return 0, false
-	}
-
-	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
-		return 0, false
+		return nil, false, false
 	}
 
-	return claims.UserID, true
+	return claims, refresh, true
 }
 
-// AuthMiddleware is an HTTP middleware that ensures the request has a valid user authentication cookie. If absent, it issues a new token and sets the cookie
-func AuthMiddleware(secretKey string) func(http.Handler) http.Handler {
+// AuthMiddleware is an HTTP middleware that resolves the user either from an "Authorization: Bearer sk_..." API key or from the authentication cookie.
+// Requests with an invalid API key are rejected. If there is no valid cookie, it issues a new anonymous token and sets the cookie.
+// Valid cookies close to expiry or signed with a retired key are re-issued, so active sessions slide forward.
+// keys may be nil, in which case API keys are not accepted
+func AuthMiddleware(tokens *TokenManager, keys APIKeyAuthenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			userID, valid := getUserIDFromCookie(r, secretKey)
+			if header := r.Header.Get("Authorization"); keys != nil && strings.HasPrefix(header, bearerPrefix) {
+				key, err := keys.AuthenticateAPIKey(r.Context(), strings.TrimPrefix(header, bearerPrefix))
+				if err != nil {
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+					problem.Write(w, r, http.StatusUnauthorized, problem.CodeInvalidAPIKey)
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), domain.UserIDKey, key.UserID)
+				ctx = context.WithValue(ctx, domain.RegisteredKey, key.UserID >= domain.FirstRegisteredUserID)
+				ctx = context.WithValue(ctx, domain.ScopesKey, key.Scopes)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			claims, refresh, valid := getClaimsFromCookie(r, tokens)
 
 			if !valid {
-				tokenString, newUserID, err := GenerateToken(secretKey)
+				newUserID, err := newAnonymousUserID()
 				if err != nil {
-					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
 					return
 				}
 
-				http.SetCookie(w, &http.Cookie{
-					Name:  "auth",
-					Value: tokenString,
-					Path:  "/",
-				})
+				claims = &Claims{UserID: newUserID}
+				refresh = true
+			}
+
+			if refresh {
+				tokenString, err := tokens.Issue(claims.UserID, claims.Registered)
+				if err != nil {
+					problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+					return
+				}
 
-				userID = newUserID
+				tokens.SetCookie(w, tokenString)
 			}
 
-			ctx := context.WithValue(r.Context(), domain.UserIDKey, userID)
+			ctx := context.WithValue(r.Context(), domain.UserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, domain.RegisteredKey, claims.Registered)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
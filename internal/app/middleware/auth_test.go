@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -13,8 +14,14 @@ import (
 
 const secretKey = "test_secret"
 
+func newTestTokens() *middleware.TokenManager {
+	return middleware.NewTokenManager(middleware.NewHMACKey("test", secretKey), time.Hour, false)
+}
+
 func TestAuthMiddleware_TableDriven(t *testing.T) {
-	token, validUserID, err := middleware.GenerateToken(secretKey)
+	tokens := newTestTokens()
+	validUserID := 42
+	token, err := tokens.Issue(validUserID, false)
 	require.NoError(t, err)
 
 	tests := []struct {
@@ -57,7 +64,7 @@ func TestAuthMiddleware_TableDriven(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var capturedUserID int
 
-			handler := middleware.AuthMiddleware(secretKey)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler := middleware.AuthMiddleware(tokens, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				val := r.Context().Value(domain.UserIDKey)
 				require.NotNil(t, val)
 				capturedUserID = val.(int)
@@ -99,3 +106,32 @@ func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
 	}
 	return nil
 }
+
+func TestAuthMiddleware_RegisteredUser(t *testing.T) {
+	tokens := newTestTokens()
+	token, err := tokens.Issue(1000001, true)
+	require.NoError(t, err)
+
+	var capturedUserID int
+	var capturedRegistered bool
+	handler := middleware.AuthMiddleware(tokens, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedUserID, _ = r.Context().Value(domain.UserIDKey).(int)
+		capturedRegistered, _ = r.Context().Value(domain.RegisteredKey).(bool)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "auth", Value: token})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, 1000001, capturedUserID)
+	require.True(t, capturedRegistered)
+	require.Nil(t, findCookie(w.Result().Cookies(), "auth"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Less(t, capturedUserID, domain.FirstRegisteredUserID)
+	require.False(t, capturedRegistered)
+}
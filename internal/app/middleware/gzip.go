@@ -5,6 +5,8 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/Te8va/shortURL/internal/app/problem"
 )
 
 type gzipWriter struct {
@@ -23,7 +25,7 @@ func GzipHandle(h http.Handler) http.Handler {
 		if r.Header.Get("Content-Encoding") == "gzip" {
 			gz, err := gzip.NewReader(r.Body)
 			if err != nil {
-				http.Error(w, "Failed to decompress request", http.StatusBadRequest)
+				problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidEncoding)
 				return
 			}
 			defer gz.Close()
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+// rateLimitSweepInterval is how often idle buckets are dropped from the in-memory store
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitResult describes the state of a bucket after a request has been counted
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps token bucket state. MemoryRateLimitStore suits a single instance, a shared store lets several instances enforce one limit
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit config.RateLimit) (RateLimitResult, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+// MemoryRateLimitStore is a RateLimitStore holding buckets in process memory
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take refills the bucket for key and consumes one token if available
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit config.RateLimit) (RateLimitResult, error) {
+	capacity := float64(limit.Requests)
+	perToken := limit.Period / time.Duration(limit.Requests)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > rateLimitSweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now, period: limit.Period}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+
+	res := RateLimitResult{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((capacity - b.tokens) * float64(perToken))
+
+	return res, nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again, they are indistinguishable from new ones
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > b.period {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// RateLimiter builds rate limit middlewares for route groups sharing one store
+type RateLimiter struct {
+	store      RateLimitStore
+	trustProxy bool
+}
+
+// NewRateLimiter creates a RateLimiter. If trustProxy is set the client address is taken from X-Real-IP or X-Forwarded-For
+func NewRateLimiter(store RateLimitStore, trustProxy bool) *RateLimiter {
+	return &RateLimiter{store: store, trustProxy: trustProxy}
+}
+
+// Limit returns a middleware enforcing limit for the named route group. Registered users and API keys are limited per user ID,
+// everyone else per client IP since anonymous IDs are handed out freely. A disabled limit returns a pass-through middleware
+func (l *RateLimiter) Limit(group string, limit config.RateLimit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !limit.Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := group + ":" + l.clientKey(r)
+
+			res, err := l.store.Take(r.Context(), key, limit)
+			if err != nil {
+				Log.Warn("rate limit store failed, letting request through", zap.String("key", key), zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				problem.Write(w, r, http.StatusTooManyRequests, problem.CodeRateLimited)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	registered, _ := r.Context().Value(domain.RegisteredKey).(bool)
+	_, viaKey := r.Context().Value(domain.ScopesKey).([]string)
+	if userID, ok := r.Context().Value(domain.UserIDKey).(int); ok && (registered || viaKey) {
+		return fmt.Sprintf("user:%d", userID)
+	}
+
+	return "ip:" + ClientIP(r, l.trustProxy)
+}
+
+// ClientIP returns the address of the client. If trustProxy is set it is taken from X-Real-IP or X-Forwarded-For
+// when the proxy sent them
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,107 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+)
+
+func TestRateLimitUnmarshalText(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    config.RateLimit
+		wantErr bool
+	}{
+		{in: "60/m", want: config.RateLimit{Requests: 60, Period: time.Minute}},
+		{in: "5/10s", want: config.RateLimit{Requests: 5, Period: 10 * time.Second}},
+		{in: "", want: config.RateLimit{}},
+		{in: "0", want: config.RateLimit{}},
+		{in: "60", wantErr: true},
+		{in: "x/m", wantErr: true},
+		{in: "10/week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got config.RateLimit
+			err := got.UnmarshalText([]byte(tt.in))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRateLimiter_Limit(t *testing.T) {
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), true)
+	handler := limiter.Limit("shorten", config.RateLimit{Requests: 2, Period: time.Hour})(okHandler())
+
+	do := func(ip string, userID int, registered bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("X-Real-IP", ip)
+		ctx := context.WithValue(req.Context(), domain.UserIDKey, userID)
+		ctx = context.WithValue(ctx, domain.RegisteredKey, registered)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req.WithContext(ctx))
+		return w
+	}
+
+	w := do("10.0.0.1", 1, false)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+	// A fresh anonymous ID from the same address shares the bucket
+	require.Equal(t, http.StatusOK, do("10.0.0.1", 2, false).Code)
+	w = do("10.0.0.1", 3, false)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "1800", w.Header().Get("Retry-After"))
+	require.Equal(t, "3600", w.Header().Get("RateLimit-Reset"))
+
+	require.Equal(t, http.StatusOK, do("10.0.0.2", 4, false).Code)
+
+	// Registered users are limited per account regardless of address
+	require.Equal(t, http.StatusOK, do("10.0.0.1", 1000001, true).Code)
+	require.Equal(t, http.StatusOK, do("10.0.0.3", 1000001, true).Code)
+	require.Equal(t, http.StatusTooManyRequests, do("10.0.0.4", 1000001, true).Code)
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), false)
+	handler := limiter.Limit("redirect", config.RateLimit{})(okHandler())
+
+	for i := 0; i < 5; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/abc", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, config.RateLimit) (middleware.RateLimitResult, error) {
+	return middleware.RateLimitResult{}, errors.New("store unavailable")
+}
+
+func TestRateLimiter_StoreError(t *testing.T) {
+	limiter := middleware.NewRateLimiter(failingStore{}, false)
+	handler := limiter.Limit("delete", config.RateLimit{Requests: 1, Period: time.Minute})(okHandler())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/user/urls", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+// RequireScope is an HTTP middleware that rejects requests authenticated by an API key lacking the given scope.
+// Cookie sessions and keys created without scopes are allowed everything
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, viaKey := r.Context().Value(domain.ScopesKey).([]string)
+			if viaKey && len(scopes) > 0 && !slices.Contains(scopes, scope) {
+				problem.New(r, http.StatusForbidden, problem.CodeInsufficientScope).With("scope", scope).Write(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession is an HTTP middleware that rejects requests authenticated by an API key, e.g. for managing the keys themselves
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, viaKey := r.Context().Value(domain.ScopesKey).([]string); viaKey {
+			problem.Write(w, r, http.StatusForbidden, problem.CodeSessionRequired)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAdmin is an HTTP middleware that lets through only registered users listed as administrators
+func RequireAdmin(admins []int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(domain.UserIDKey).(int)
+			if !ok {
+				problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized)
+				return
+			}
+			if userID < domain.FirstRegisteredUserID || !slices.Contains(admins, userID) {
+				problem.Write(w, r, http.StatusForbidden, problem.CodeAdminRequired)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
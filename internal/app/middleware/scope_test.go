@@ -0,0 +1,101 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+)
+
+type fakeKeys map[string]domain.APIKey
+
+func (f fakeKeys) AuthenticateAPIKey(ctx context.Context, key string) (domain.APIKey, error) {
+	if k, ok := f[key]; ok {
+		return k, nil
+	}
+	return domain.APIKey{}, appErrors.ErrInvalidAPIKey
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	keys := fakeKeys{
+		"sk_read": {UserID: 1000001, Scopes: []string{domain.ScopeRead}},
+		"sk_all":  {UserID: 55, Scopes: []string{}},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/read", middleware.RequireScope(domain.ScopeRead)(okHandler()))
+	mux.Handle("/delete", middleware.RequireScope(domain.ScopeDelete)(okHandler()))
+	mux.Handle("/keys", middleware.RequireSession(okHandler()))
+	handler := middleware.AuthMiddleware(newTestTokens(), keys)(mux)
+
+	tests := []struct {
+		name       string
+		path       string
+		authHeader string
+		wantCode   int
+		wantCookie bool
+	}{
+		{name: "key with scope", path: "/read", authHeader: "Bearer sk_read", wantCode: http.StatusOK},
+		{name: "key without scope", path: "/delete", authHeader: "Bearer sk_read", wantCode: http.StatusForbidden},
+		{name: "unrestricted key", path: "/delete", authHeader: "Bearer sk_all", wantCode: http.StatusOK},
+		{name: "invalid key", path: "/read", authHeader: "Bearer sk_nope", wantCode: http.StatusUnauthorized},
+		{name: "key cannot manage keys", path: "/keys", authHeader: "Bearer sk_all", wantCode: http.StatusForbidden},
+		{name: "cookie session", path: "/delete", wantCode: http.StatusOK, wantCookie: true},
+		{name: "cookie session manages keys", path: "/keys", wantCode: http.StatusOK, wantCookie: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			require.Equal(t, tt.wantCookie, findCookie(w.Result().Cookies(), "auth") != nil)
+		})
+	}
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestRequireAdmin(t *testing.T) {
+	admin := domain.FirstRegisteredUserID + 1
+	handler := middleware.RequireAdmin([]int{admin, 7})(okHandler())
+
+	tests := []struct {
+		name     string
+		userID   any
+		wantCode int
+	}{
+		{name: "admin", userID: admin, wantCode: http.StatusOK},
+		{name: "other user", userID: domain.FirstRegisteredUserID, wantCode: http.StatusForbidden},
+		{name: "anonymous user listed by mistake", userID: 7, wantCode: http.StatusForbidden},
+		{name: "unauthenticated", wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/moderation", nil)
+			if tt.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), domain.UserIDKey, tt.userID))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
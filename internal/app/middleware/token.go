@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+)
+
+// ErrUnknownKeyID indicates that the token was signed with a key that is not configured
+var ErrUnknownKeyID = errors.New("unknown signing key id")
+
+// SigningKey is a key identified by the JWT "kid" header. Keys without a private part can only verify tokens
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	sign   interface{}
+	verify interface{}
+}
+
+// NewHMACKey creates an HS256 key from a shared secret
+func NewHMACKey(id, secret string) *SigningKey {
+	return &SigningKey{ID: id, Method: jwt.SigningMethodHS256, sign: []byte(secret), verify: []byte(secret)}
+}
+
+// ParsePrivateKeyPEM creates an RS256 or EdDSA signing key from a PEM encoded private key
+func ParsePrivateKeyPEM(id string, data []byte) (*SigningKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return &SigningKey{ID: id, Method: jwt.SigningMethodRS256, sign: key, verify: &key.PublicKey}, nil
+	}
+
+	edKey, err := jwt.ParseEdPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePrivateKeyPEM: key %q is neither RSA nor Ed25519", id)
+	}
+	priv, ok := edKey.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("ParsePrivateKeyPEM: key %q is neither RSA nor Ed25519", id)
+	}
+	return &SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, sign: priv, verify: priv.Public()}, nil
+}
+
+// ParsePublicKeyPEM creates a verification-only RS256 or EdDSA key from a PEM encoded public key
+func ParsePublicKeyPEM(id string, data []byte) (*SigningKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return &SigningKey{ID: id, Method: jwt.SigningMethodRS256, verify: key}, nil
+	}
+
+	edKey, err := jwt.ParseEdPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePublicKeyPEM: key %q is neither RSA nor Ed25519", id)
+	}
+	return &SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, verify: edKey}, nil
+}
+
+// TokenManager issues and verifies auth tokens. Tokens are signed with the active key and verified with any configured key, so keys can be rotated without logging users out
+type TokenManager struct {
+	active       *SigningKey
+	keys         map[string]*SigningKey
+	ttl          time.Duration
+	secureCookie bool
+}
+
+// NewTokenManager creates a TokenManager signing with active and additionally accepting tokens signed with previous keys
+func NewTokenManager(active *SigningKey, ttl time.Duration, secureCookie bool, previous ...*SigningKey) *TokenManager {
+	keys := map[string]*SigningKey{active.ID: active}
+	for _, key := range previous {
+		keys[key.ID] = key
+	}
+
+	return &TokenManager{
+		active:       active,
+		keys:         keys,
+		ttl:          ttl,
+		secureCookie: secureCookie,
+	}
+}
+
+// NewTokenManagerFromConfig builds a TokenManager from the JWT settings of the configuration
+func NewTokenManagerFromConfig(cfg *config.Config) (*TokenManager, error) {
+	active := NewHMACKey(cfg.JWTKeyID, cfg.JWTKey)
+	if cfg.JWTPrivateKeyFile != "" {
+		data, err := os.ReadFile(cfg.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("NewTokenManagerFromConfig: %w", err)
+		}
+		if active, err = ParsePrivateKeyPEM(cfg.JWTKeyID, data); err != nil {
+			return nil, fmt.Errorf("NewTokenManagerFromConfig: %w", err)
+		}
+	}
+
+	var previous []*SigningKey
+	for _, entry := range cfg.JWTPreviousKeys {
+		id, value, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || value == "" {
+			return nil, fmt.Errorf("NewTokenManagerFromConfig: previous key must be kid:secret or kid:@file.pem, got %q", entry)
+		}
+
+		if !strings.HasPrefix(value, "@") {
+			previous = append(previous, NewHMACKey(id, value))
+			continue
+		}
+
+		data, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+		if err != nil {
+			return nil, fmt.Errorf("NewTokenManagerFromConfig: %w", err)
+		}
+		key, err := ParsePublicKeyPEM(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("NewTokenManagerFromConfig: %w", err)
+		}
+		previous = append(previous, key)
+	}
+
+	return NewTokenManager(active, cfg.TokenTTL, cfg.EnableHTTPS, previous...), nil
+}
+
+// Issue creates a signed token for the user that expires after the configured TTL
+func (m *TokenManager) Issue(userID int, registered bool) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		UserID:     userID,
+		Registered: registered,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.ttl)),
+		},
+	}
+
+	token := jwt.NewWithClaims(m.active.Method, claims)
+	token.Header["kid"] = m.active.ID
+
+	tokenString, err := token.SignedString(m.active.sign)
+	if err != nil {
+		return "", fmt.Errorf("TokenManager.Issue: %w", err)
+	}
+	return tokenString, nil
+}
+
+// Parse verifies the token and reports whether it should be re-issued, which happens once half of its lifetime has passed,
+// when it was signed with a key other than the active one or when it predates expiry support
+func (m *TokenManager) Parse(tokenString string) (*Claims, bool, error) {
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(tokenString, &Claims{}, m.keyFunc)
+	if err != nil {
+		return nil, false, fmt.Errorf("TokenManager.Parse: %w", err)
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, false, fmt.Errorf("TokenManager.Parse: invalid token")
+	}
+
+	now := time.Now()
+	if claims.ExpiresAt != nil && !now.Before(claims.ExpiresAt.Time) {
+		return nil, false, fmt.Errorf("TokenManager.Parse: token expired")
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	refresh := claims.ExpiresAt == nil ||
+		claims.ExpiresAt.Time.Sub(now) < m.ttl/2 ||
+		kid != m.active.ID
+
+	return claims, refresh, nil
+}
+
+func (m *TokenManager) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, hasKid := t.Header["kid"].(string)
+
+	key, ok := m.keys[kid]
+	if !hasKid {
+		// Tokens issued before key IDs were introduced are only trusted if the active key is the shared secret
+		key, ok = m.active, m.active.Method == jwt.SigningMethodHS256
+	}
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", t.Method.Alg(), key.ID)
+	}
+
+	return key.verify, nil
+}
+
+// SetCookie writes the auth cookie carrying the token
+func (m *TokenManager) SetCookie(w http.ResponseWriter, tokenString string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     AuthCookieName,
+		Value:    tokenString,
+		Path:     "/",
+		MaxAge:   int(m.ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   m.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearCookie instructs the client to drop the auth cookie
+func (m *TokenManager) ClearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     AuthCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   m.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,177 @@
+package middleware_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+)
+
+func signHS256(t *testing.T, kid, secret string, claims middleware.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString([]byte(secret))
+	require.NoError(t, err)
+	return s
+}
+
+func claimsExpiringIn(userID int, d time.Duration) middleware.Claims {
+	return middleware.Claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(d)),
+		},
+	}
+}
+
+func TestTokenManager_Parse(t *testing.T) {
+	tokens := middleware.NewTokenManager(middleware.NewHMACKey("new", "new_secret"), time.Hour, false, middleware.NewHMACKey("old", "old_secret"))
+
+	fresh, err := tokens.Issue(7, true)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		token       string
+		wantErr     bool
+		wantRefresh bool
+	}{
+		{name: "fresh token", token: fresh},
+		{name: "past half of lifetime", token: signHS256(t, "new", "new_secret", claimsExpiringIn(7, 10*time.Minute)), wantRefresh: true},
+		{name: "expired", token: signHS256(t, "new", "new_secret", claimsExpiringIn(7, -time.Minute)), wantErr: true},
+		{name: "previous key", token: signHS256(t, "old", "old_secret", claimsExpiringIn(7, time.Hour)), wantRefresh: true},
+		{name: "unknown key id", token: signHS256(t, "gone", "old_secret", claimsExpiringIn(7, time.Hour)), wantErr: true},
+		{name: "wrong secret for key id", token: signHS256(t, "new", "old_secret", claimsExpiringIn(7, time.Hour)), wantErr: true},
+		{name: "legacy token without kid and exp", token: signHS256(t, "", "new_secret", middleware.Claims{UserID: 7}), wantRefresh: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, refresh, err := tokens.Parse(tt.token)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 7, claims.UserID)
+			require.Equal(t, tt.wantRefresh, refresh)
+		})
+	}
+}
+
+func TestTokenManager_AsymmetricKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	edPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER})
+	edPubDER, err := x509.MarshalPKIXPublicKey(edKey.Public())
+	require.NoError(t, err)
+	edPubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: edPubDER})
+
+	dir := t.TempDir()
+	rsaFile := filepath.Join(dir, "rsa.pem")
+	edPubFile := filepath.Join(dir, "ed.pub.pem")
+	require.NoError(t, os.WriteFile(rsaFile, rsaPEM, 0o600))
+	require.NoError(t, os.WriteFile(edPubFile, edPubPEM, 0o600))
+
+	edSigner, err := middleware.ParsePrivateKeyPEM("ed-1", edPEM)
+	require.NoError(t, err)
+	require.Equal(t, jwt.SigningMethodEdDSA, edSigner.Method)
+	oldTokens := middleware.NewTokenManager(edSigner, time.Hour, false)
+	edToken, err := oldTokens.Issue(1000001, true)
+	require.NoError(t, err)
+
+	cfg := &config.Config{
+		JWTKey:            config.DefaultJWTKey,
+		JWTKeyID:          "rsa-2",
+		JWTPrivateKeyFile: rsaFile,
+		JWTPreviousKeys:   []string{"ed-1:@" + edPubFile, "hs-0:legacy_secret"},
+		TokenTTL:          time.Hour,
+	}
+	tokens, err := middleware.NewTokenManagerFromConfig(cfg)
+	require.NoError(t, err)
+
+	rsaToken, err := tokens.Issue(1000002, true)
+	require.NoError(t, err)
+	claims, refresh, err := tokens.Parse(rsaToken)
+	require.NoError(t, err)
+	require.Equal(t, 1000002, claims.UserID)
+	require.False(t, refresh)
+
+	claims, refresh, err = tokens.Parse(edToken)
+	require.NoError(t, err)
+	require.Equal(t, 1000001, claims.UserID)
+	require.True(t, refresh)
+
+	_, _, err = tokens.Parse(signHS256(t, "hs-0", "legacy_secret", claimsExpiringIn(3, time.Hour)))
+	require.NoError(t, err)
+
+	// The default secret must not be usable to forge tokens once an asymmetric key is active
+	_, _, err = tokens.Parse(signHS256(t, "", config.DefaultJWTKey, claimsExpiringIn(3, time.Hour)))
+	require.Error(t, err)
+	_, _, err = tokens.Parse(signHS256(t, "rsa-2", config.DefaultJWTKey, claimsExpiringIn(3, time.Hour)))
+	require.Error(t, err)
+
+	_, err = middleware.NewTokenManagerFromConfig(&config.Config{JWTKeyID: "x", JWTKey: "s", JWTPreviousKeys: []string{"broken"}})
+	require.Error(t, err)
+}
+
+func TestAuthMiddleware_SlidingRefresh(t *testing.T) {
+	tokens := newTestTokens()
+	handler := middleware.AuthMiddleware(tokens, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(domain.UserIDKey).(int)
+		require.Equal(t, 99, userID)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: middleware.AuthCookieName, Value: signHS256(t, "test", secretKey, claimsExpiringIn(99, time.Minute))})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	cookie := findCookie(w.Result().Cookies(), middleware.AuthCookieName)
+	require.NotNil(t, cookie)
+	require.True(t, cookie.HttpOnly)
+	require.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	require.Equal(t, int(time.Hour.Seconds()), cookie.MaxAge)
+
+	claims, refresh, err := tokens.Parse(cookie.Value)
+	require.NoError(t, err)
+	require.Equal(t, 99, claims.UserID)
+	require.False(t, refresh)
+}
+
+func TestTokenManager_SecureCookie(t *testing.T) {
+	tokens := middleware.NewTokenManager(middleware.NewHMACKey("k", "s"), time.Hour, true)
+
+	w := httptest.NewRecorder()
+	tokens.SetCookie(w, "value")
+	require.True(t, w.Result().Cookies()[0].Secure)
+
+	w = httptest.NewRecorder()
+	tokens.ClearCookie(w)
+	cookie := w.Result().Cookies()[0]
+	require.Equal(t, -1, cookie.MaxAge)
+	require.True(t, cookie.Secure)
+}
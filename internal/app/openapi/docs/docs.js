@@ -0,0 +1,12 @@
+// Renders the API served by this instance with the vendored Swagger UI. The validator badge is turned off, it would
+// send the document to validator.swagger.io.
+window.onload = function () {
+  window.ui = SwaggerUIBundle({
+    url: "/api/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    validatorUrl: null,
+    presets: [SwaggerUIBundle.presets.apis],
+    layout: "BaseLayout",
+  });
+};
@@ -0,0 +1,152 @@
+// Package openapi serves the OpenAPI description of the HTTP API and validates requests against it.
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// docs holds the documentation page with Swagger UI 4.15.5 (swagger-ui-dist, Apache License 2.0), they are served
+// from the binary so the page needs no third-party origins
+//
+//go:embed docs
+var docs embed.FS
+
+// Route identifies an operation of the specification
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Spec returns the raw OpenAPI document
+func Spec() []byte {
+	return spec
+}
+
+// SpecHandler serves the OpenAPI document
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(spec); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// DocsHandler serves Swagger UI rendering the OpenAPI document
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := docs.ReadFile("docs/index.html")
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(page); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// DocsAssetHandler serves the scripts and styles of Swagger UI
+func DocsAssetHandler(w http.ResponseWriter, r *http.Request) {
+	name := path.Base(r.URL.Path)
+	if name == "index.html" {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound)
+		return
+	}
+	if _, err := fs.Stat(docs, "docs/"+name); err != nil {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound)
+		return
+	}
+
+	http.ServeFileFS(w, r, docs, "docs/"+name)
+}
+
+// Routes lists the operations described by the specification, sorted by path and method
+func Routes() ([]Route, error) {
+	doc, err := parse(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []Route
+	for path, item := range doc.Paths {
+		for method := range item {
+			routes = append(routes, Route{Method: strings.ToUpper(method), Path: path})
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes, nil
+}
+
+type document struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas    map[string]*schema    `json:"schemas"`
+		Parameters map[string]*parameter `json:"parameters"`
+	} `json:"components"`
+}
+
+type operation struct {
+	Parameters  []*parameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool                  `json:"required"`
+		Content  map[string]*mediaType `json:"content"`
+	} `json:"requestBody"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Enum       []interface{}      `json:"enum"`
+	Required   []string           `json:"required"`
+	Properties map[string]*schema `json:"properties"`
+	Items      *schema            `json:"items"`
+	AllOf      []*schema          `json:"allOf"`
+	MinItems   *int               `json:"minItems"`
+	MaxItems   *int               `json:"maxItems"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+	Pattern    string             `json:"pattern"`
+}
+
+func parse(data []byte) (*document, error) {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("openapi.parse: %w", err)
+	}
+	return &doc, nil
+}
@@ -0,0 +1,404 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+// maxValidatedBody caps how much of a request body is buffered for validation
+const maxValidatedBody = 10 << 20
+
+// FieldError describes one way in which a request does not match the specification
+type FieldError struct {
+	In      string `json:"in"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type compiledRoute struct {
+	segments []string
+	literals int
+	methods  map[string]*operation
+}
+
+// Validator checks requests against the OpenAPI document
+type Validator struct {
+	doc      *document
+	routes   []compiledRoute
+	patterns map[string]*regexp.Regexp
+}
+
+// NewValidator creates a Validator for the embedded specification
+func NewValidator() (*Validator, error) {
+	doc, err := parse(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Validator{doc: doc, patterns: make(map[string]*regexp.Regexp)}
+	for path, item := range doc.Paths {
+		route := compiledRoute{segments: strings.Split(strings.Trim(path, "/"), "/"), methods: make(map[string]*operation)}
+		for _, seg := range route.segments {
+			if !strings.HasPrefix(seg, "{") {
+				route.literals++
+			}
+		}
+		for method, op := range item {
+			route.methods[strings.ToUpper(method)] = op
+			for _, p := range op.Parameters {
+				if err := v.compilePatterns(v.resolveParameter(p).Schema); err != nil {
+					return nil, err
+				}
+			}
+		}
+		v.routes = append(v.routes, route)
+	}
+
+	// Prefer the most specific template, so /api/docs wins over /{id}/qr style matches
+	sort.Slice(v.routes, func(i, j int) bool { return v.routes[i].literals > v.routes[j].literals })
+
+	for _, s := range doc.Components.Schemas {
+		if err := v.compilePatterns(s); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Middleware rejects requests to documented operations whose query parameters or body do not match the specification.
+// Requests to unknown routes are passed through so the router can answer them
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op := v.find(r.Method, r.URL.Path)
+		if op == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		errs := v.validateQuery(op, r.URL.Query())
+
+		if op.RequestBody != nil && streamed(r.Header.Get("Content-Type")) {
+			// streams are not buffered, the handler checks them line by line as they arrive
+			errs = append(errs, v.validateMediaType(op, r.Header.Get("Content-Type"))...)
+		} else if op.RequestBody != nil {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxValidatedBody))
+			if err != nil {
+				problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidBody)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			errs = append(errs, v.validateBody(op, r.Header.Get("Content-Type"), body)...)
+		}
+
+		if len(errs) > 0 {
+			problem.New(r, http.StatusBadRequest, problem.CodeInvalidRequest).With("errors", errs).Write(w)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (v *Validator) find(method, path string) *operation {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, route := range v.routes {
+		if len(route.segments) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, seg := range route.segments {
+			if strings.HasPrefix(seg, "{") {
+				if segments[i] == "" {
+					matched = false
+					break
+				}
+				continue
+			}
+			if seg != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			if op, ok := route.methods[method]; ok {
+				return op
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateQuery(op *operation, query url.Values) []FieldError {
+	var errs []FieldError
+	for _, p := range op.Parameters {
+		p = v.resolveParameter(p)
+		if p.In != "query" {
+			continue
+		}
+
+		raw, present := query[p.Name]
+		if !present || len(raw) == 0 {
+			if p.Required {
+				errs = append(errs, FieldError{In: "query", Field: p.Name, Message: "is required"})
+			}
+			continue
+		}
+
+		value, msg := coerce(raw[0], v.resolve(p.Schema))
+		if msg == "" {
+			msg = v.check(value, p.Schema)
+		}
+		if msg != "" {
+			errs = append(errs, FieldError{In: "query", Field: p.Name, Message: msg})
+		}
+	}
+	return errs
+}
+
+// streamed reports whether the body is a stream of newline-delimited JSON
+func streamed(contentType string) bool {
+	media, _, err := mime.ParseMediaType(contentType)
+	return err == nil && media == "application/x-ndjson"
+}
+
+func (v *Validator) validateMediaType(op *operation, contentType string) []FieldError {
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		media = ""
+	}
+
+	if _, ok := op.RequestBody.Content[media]; ok {
+		return nil
+	}
+
+	allowed := make([]string, 0, len(op.RequestBody.Content))
+	for m := range op.RequestBody.Content {
+		allowed = append(allowed, m)
+	}
+	sort.Strings(allowed)
+	return []FieldError{{In: "header", Field: "Content-Type", Message: "must be one of " + strings.Join(allowed, ", ")}}
+}
+
+func (v *Validator) validateBody(op *operation, contentType string, body []byte) []FieldError {
+	if errs := v.validateMediaType(op, contentType); errs != nil {
+		return errs
+	}
+
+	media, _, _ := mime.ParseMediaType(contentType)
+	content := op.RequestBody.Content[media]
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return []FieldError{{In: "body", Message: "is required"}}
+		}
+		return nil
+	}
+
+	var value interface{}
+	if media == "application/json" {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return []FieldError{{In: "body", Message: "is not valid JSON"}}
+		}
+	} else {
+		value = string(body)
+	}
+
+	var errs []FieldError
+	v.walk(value, content.Schema, "", &errs)
+	return errs
+}
+
+// walk validates value against s, collecting errors for every field so that clients can fix them all at once
+func (v *Validator) walk(value interface{}, s *schema, pointer string, errs *[]FieldError) {
+	s = v.resolve(s)
+	if s == nil {
+		return
+	}
+
+	for _, sub := range s.AllOf {
+		v.walk(value, sub, pointer, errs)
+	}
+
+	if msg := v.check(value, s); msg != "" {
+		*errs = append(*errs, FieldError{In: "body", Field: pointer, Message: msg})
+		return
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				*errs = append(*errs, FieldError{In: "body", Field: pointer + "/" + name, Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if field, ok := val[name]; ok {
+				v.walk(field, s.Properties[name], pointer+"/"+name, errs)
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			v.walk(item, s.Items, pointer+"/"+strconv.Itoa(i), errs)
+		}
+	}
+}
+
+// check validates the constraints of s that apply to value itself, not to nested fields
+func (v *Validator) check(value interface{}, s *schema) string {
+	s = v.resolve(s)
+	if s == nil {
+		return ""
+	}
+
+	switch s.Type {
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return "must be an object"
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return "must be an array"
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			return fmt.Sprintf("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			return fmt.Sprintf("must have at most %d items", *s.MaxItems)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if s.MinLength != nil && len([]rune(str)) < *s.MinLength {
+			return fmt.Sprintf("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && len([]rune(str)) > *s.MaxLength {
+			return fmt.Sprintf("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" && !v.patterns[s.Pattern].MatchString(str) {
+			return "must match " + s.Pattern
+		}
+		if s.Format == "uri" {
+			if _, err := url.ParseRequestURI(str); err != nil {
+				return "must be an absolute URI"
+			}
+		}
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			return "must be a " + s.Type
+		}
+		f, err := num.Float64()
+		if err != nil || (s.Type == "integer" && strings.ContainsAny(num.String(), ".eE")) {
+			return "must be an " + s.Type
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			return fmt.Sprintf("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return fmt.Sprintf("must be at most %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				return ""
+			}
+		}
+		options := make([]string, len(s.Enum))
+		for i, allowed := range s.Enum {
+			options[i] = fmt.Sprint(allowed)
+		}
+		return "must be one of " + strings.Join(options, ", ")
+	}
+
+	return ""
+}
+
+// coerce converts a query string value to the JSON type the schema expects
+func coerce(raw string, s *schema) (interface{}, string) {
+	if s == nil {
+		return raw, ""
+	}
+
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, "must be a " + s.Type
+		}
+		return json.Number(raw), ""
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, "must be a boolean"
+		}
+		return b, ""
+	default:
+		return raw, ""
+	}
+}
+
+func (v *Validator) resolve(s *schema) *schema {
+	for s != nil && s.Ref != "" {
+		s = v.doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+func (v *Validator) resolveParameter(p *parameter) *parameter {
+	for p != nil && p.Ref != "" {
+		p = v.doc.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+	}
+	return p
+}
+
+func (v *Validator) compilePatterns(s *schema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		if _, ok := v.patterns[s.Pattern]; !ok {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				return fmt.Errorf("openapi.NewValidator: %w", err)
+			}
+			v.patterns[s.Pattern] = re
+		}
+	}
+	for _, p := range s.Properties {
+		if err := v.compilePatterns(p); err != nil {
+			return err
+		}
+	}
+	for _, sub := range s.AllOf {
+		if err := v.compilePatterns(sub); err != nil {
+			return err
+		}
+	}
+	return v.compilePatterns(s.Items)
+}
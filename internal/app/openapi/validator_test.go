@@ -0,0 +1,237 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/openapi"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+func TestValidator_Middleware(t *testing.T) {
+	validator, err := openapi.NewValidator()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantField   string
+	}{
+		{
+			name:        "valid shorten",
+			method:      http.MethodPost,
+			target:      "/api/shorten",
+			contentType: "application/json",
+			body:        `{"url":"https://example.com"}`,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "shorten without url",
+			method:      http.MethodPost,
+			target:      "/api/shorten",
+			contentType: "application/json",
+			body:        `{"link":"https://example.com"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantField:   "/url",
+		},
+		{
+			name:        "shorten with relative url",
+			method:      http.MethodPost,
+			target:      "/api/shorten",
+			contentType: "application/json",
+			body:        `{"url":"example"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantField:   "/url",
+		},
+		{
+			name:        "shorten with wrong content type",
+			method:      http.MethodPost,
+			target:      "/api/shorten",
+			contentType: "text/plain",
+			body:        `{"url":"https://example.com"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantField:   "Content-Type",
+		},
+		{
+			name:        "batch item missing correlation id",
+			method:      http.MethodPost,
+			target:      "/api/shorten/batch",
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"correlation_id":"1","original_url":"https://a.example"},{"original_url":"https://b.example"}]`,
+			wantStatus:  http.StatusBadRequest,
+			wantField:   "/1/correlation_id",
+		},
+		{
+			name:        "empty batch",
+			method:      http.MethodPost,
+			target:      "/api/shorten/batch",
+			contentType: "application/json",
+			body:        `[]`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "plain text shorten",
+			method:      http.MethodPost,
+			target:      "/",
+			contentType: "text/plain; charset=utf-8",
+			body:        "https://example.com",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "plain text without body",
+			method:      http.MethodPost,
+			target:      "/",
+			contentType: "text/plain",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:       "valid qr parameters",
+			method:     http.MethodGet,
+			target:     "/abc/qr?size=512&format=svg&fg=%23ff0000",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "qr size out of range",
+			method:     http.MethodGet,
+			target:     "/abc/qr?size=10",
+			wantStatus: http.StatusBadRequest,
+			wantField:  "size",
+		},
+		{
+			name:       "qr size not an integer",
+			method:     http.MethodGet,
+			target:     "/abc/qr?size=big",
+			wantStatus: http.StatusBadRequest,
+			wantField:  "size",
+		},
+		{
+			name:       "qr unknown format",
+			method:     http.MethodGet,
+			target:     "/abc/qr?format=gif",
+			wantStatus: http.StatusBadRequest,
+			wantField:  "format",
+		},
+		{
+			name:        "stream is passed through unbuffered",
+			method:      http.MethodPost,
+			target:      "/api/shorten/stream",
+			contentType: "application/x-ndjson",
+			body:        "{\"original_url\":\"https://example.com\"}\nnot json\n",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "stream with wrong content type",
+			method:      http.MethodPost,
+			target:      "/api/shorten/stream",
+			contentType: "application/json",
+			body:        `[]`,
+			wantStatus:  http.StatusBadRequest,
+			wantField:   "Content-Type",
+		},
+		{
+			name:       "undocumented route passes through",
+			method:     http.MethodGet,
+			target:     "/debug/pprof/",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				gotBody = string(body)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			validator.Middleware(next).ServeHTTP(w, r)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				require.Equal(t, tt.body, gotBody, "the body must reach the handler unchanged")
+				return
+			}
+
+			require.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			var resp struct {
+				Code   string               `json:"code"`
+				Errors []openapi.FieldError `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, string(problem.CodeInvalidRequest), resp.Code)
+			require.NotEmpty(t, resp.Errors)
+			if tt.wantField != "" {
+				require.Equal(t, tt.wantField, resp.Errors[0].Field)
+			}
+		})
+	}
+}
+
+func TestSpecHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	openapi.SpecHandler(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	require.Equal(t, "3.0.3", doc["openapi"])
+}
+
+func TestDocsHandler(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/api/docs", openapi.DocsHandler)
+	r.Get("/api/docs/{file}", openapi.DocsAssetHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+	// the page and everything it loads come from this server. Swagger UI itself is vendored, so only the page and the
+	// script configuring it are checked for other origins
+	external := regexp.MustCompile(`(?i)(src|href|action)\s*=\s*["']?(https?:)?//|url\(\s*["']?(https?:)?//|(fetch|import)\(\s*["'](https?:)?//|url:\s*["'](https?:)?//`)
+	page := w.Body.String()
+	require.False(t, external.MatchString(page), "docs page references %s", external.FindString(page))
+
+	assets := regexp.MustCompile(`(?:src|href)="([^"]+)"`).FindAllStringSubmatch(page, -1)
+	require.Len(t, assets, 3)
+	for _, asset := range assets {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/"+asset[1], nil))
+		require.Equal(t, http.StatusOK, w.Code, asset[1])
+		require.NotEmpty(t, w.Body.Bytes(), asset[1])
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs/docs.js", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.False(t, external.MatchString(w.Body.String()), "docs.js references %s", external.FindString(w.Body.String()))
+	require.Contains(t, w.Body.String(), `url: "/api/openapi.json"`)
+	require.Contains(t, w.Body.String(), "validatorUrl: null")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs/missing.js", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,145 @@
+// Package pagemeta extracts the title, description, OpenGraph image and favicon of an HTML page.
+package pagemeta
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the extracted texts, longer ones are cut
+const (
+	MaxTitleLength       = 300
+	MaxDescriptionLength = 1000
+	MaxURLLength         = 2048
+)
+
+// Meta is what a page tells about itself. URLs are absolute
+type Meta struct {
+	Title       string
+	Description string
+	Image       string
+	Favicon     string
+}
+
+var (
+	headEndRe = regexp.MustCompile(`(?i)</head\s*>|<body[\s>]`)
+	titleRe   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title\s*>`)
+	tagRe     = regexp.MustCompile(`(?is)<(meta|link)\b([^>]*)>`)
+	attrRe    = regexp.MustCompile(`(?is)([a-z_:.-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	spaceRe   = regexp.MustCompile(`\s+`)
+)
+
+// Parse extracts the metadata from the head of an HTML page served from base. OpenGraph and Twitter card tags are
+// preferred over the plain title and description, and the favicon falls back to /favicon.ico
+func Parse(page []byte, base *url.URL) Meta {
+	head := page
+	if loc := headEndRe.FindIndex(page); loc != nil {
+		head = page[:loc[0]]
+	}
+
+	var (
+		meta  Meta
+		title string
+		found = make(map[string]string)
+	)
+
+	if m := titleRe.FindSubmatch(head); m != nil {
+		title = string(m[1])
+	}
+
+	for _, tag := range tagRe.FindAllSubmatch(head, -1) {
+		attrs := parseAttrs(string(tag[2]))
+		switch strings.ToLower(string(tag[1])) {
+		case "meta":
+			key := strings.ToLower(attrs["property"])
+			if key == "" {
+				key = strings.ToLower(attrs["name"])
+			}
+			if _, seen := found[key]; key != "" && !seen {
+				found[key] = attrs["content"]
+			}
+		case "link":
+			if meta.Favicon == "" && isIconRel(attrs["rel"]) {
+				meta.Favicon = resolve(base, attrs["href"])
+			}
+		}
+	}
+
+	meta.Title = clean(first(found["og:title"], found["twitter:title"], title), MaxTitleLength)
+	meta.Description = clean(first(found["og:description"], found["description"], found["twitter:description"]), MaxDescriptionLength)
+	meta.Image = resolve(base, first(found["og:image:secure_url"], found["og:image"], found["og:image:url"], found["twitter:image"]))
+	if meta.Favicon == "" {
+		meta.Favicon = resolve(base, "/favicon.ico")
+	}
+
+	return meta
+}
+
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrRe.FindAllStringSubmatch(s, -1) {
+		name := strings.ToLower(m[1])
+		if _, seen := attrs[name]; !seen {
+			attrs[name] = html.UnescapeString(m[2] + m[3] + m[4])
+		}
+	}
+	return attrs
+}
+
+func isIconRel(rel string) bool {
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		if r == "icon" || r == "apple-touch-icon" {
+			return true
+		}
+	}
+	return false
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clean unescapes the text, collapses its whitespace and cuts it to limit characters
+func clean(s string, limit int) string {
+	s = strings.TrimSpace(spaceRe.ReplaceAllString(html.UnescapeString(s), " "))
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+	if utf8.RuneCountInString(s) > limit {
+		s = strings.TrimSpace(string([]rune(s)[:limit]))
+	}
+	return s
+}
+
+// resolve makes ref absolute against base, dropping anything that is not an http(s) URL
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+
+	s := u.String()
+	if len(s) > MaxURLLength {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package pagemeta
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	base, err := url.Parse("https://example.com/blog/post?id=1")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		page string
+		want Meta
+	}{
+		{
+			name: "OpenGraph",
+			page: `<!doctype html><html><head>
+				<title>Plain title</title>
+				<meta property="og:title" content="Open &amp; Graph">
+				<meta name="description" content="  Plain
+					description ">
+				<meta property='og:image' content='/img/cover.png'>
+				<link rel="shortcut icon" href="favicon.png">
+				</head><body><meta property="og:title" content="Ignored"></body></html>`,
+			want: Meta{
+				Title:       "Open & Graph",
+				Description: "Plain description",
+				Image:       "https://example.com/img/cover.png",
+				Favicon:     "https://example.com/blog/favicon.png",
+			},
+		},
+		{
+			name: "plain page",
+			page: `<html><head><TITLE>Hello,   world</TITLE></head></html>`,
+			want: Meta{Title: "Hello, world", Favicon: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "twitter card and unsafe image",
+			page: `<head><meta name=twitter:title content=Card><meta name="twitter:image" content="javascript:alert(1)"></head>`,
+			want: Meta{Title: "Card", Favicon: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "long title",
+			page: `<title>` + strings.Repeat("я", MaxTitleLength+10) + `</title>`,
+			want: Meta{Title: strings.Repeat("я", MaxTitleLength), Favicon: "https://example.com/favicon.ico"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, Parse([]byte(tc.page), base))
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// Package policy decides which destination URLs may be shortened.
+package policy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule names the check that rejected a URL
+type Rule string
+
+// Rules applied by Policy.Check, in the order they are evaluated
+const (
+	RuleFormat        Rule = "format"
+	RuleScheme        Rule = "scheme"
+	RuleSelfReference Rule = "self_reference"
+	RulePrivateHost   Rule = "private_host"
+	RuleBlocklist     Rule = "blocklist"
+	RuleAllowlist     Rule = "allowlist"
+)
+
+// reloadInterval limits how often the domain list file is checked for changes
+const reloadInterval = 5 * time.Second
+
+// internalSuffixes are name suffixes that never resolve to public hosts
+var internalSuffixes = []string{".localhost", ".local", ".internal", ".home.arpa"}
+
+// cgnat is the carrier-grade NAT range, which net.IP.IsPrivate does not cover
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Violation is returned when a URL breaks a policy rule
+type Violation struct {
+	Rule    Rule
+	Message string
+}
+
+// Error implements the error interface
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+type domainLists struct {
+	block []string
+	allow []string
+}
+
+// Policy validates destination URLs. It only allows http and https links to public hosts other than the service itself,
+// optionally restricted by a domain list file that is reloaded when it changes
+type Policy struct {
+	selfHosts []string
+	listPath  string
+
+	mu        sync.RWMutex
+	lists     domainLists
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// New creates a Policy rejecting links back to baseURL and the alias domains. listPath may be empty, otherwise it names a file with one rule per line:
+// "block example.com", "allow example.org" or a bare domain, which is blocked. A domain also matches its subdomains.
+// As soon as the file contains an allow rule, only allowed domains are accepted
+func New(baseURL, listPath string, aliases ...string) (*Policy, error) {
+	p := &Policy{listPath: listPath}
+
+	for _, self := range append([]string{baseURL}, aliases...) {
+		if !strings.Contains(self, "://") {
+			self = "http://" + self
+		}
+		if base, err := url.Parse(self); err == nil && base.Hostname() != "" {
+			p.selfHosts = append(p.selfHosts, normalizeHost(base.Hostname()))
+		}
+	}
+
+	if listPath != "" {
+		if err := p.reload(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Check returns a *Violation if rawURL may not be shortened
+func (p *Policy) Check(rawURL string) error {
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return &Violation{Rule: RuleFormat, Message: "URL must be absolute"}
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return &Violation{Rule: RuleScheme, Message: fmt.Sprintf("scheme %q is not allowed, use http or https", u.Scheme)}
+	}
+
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return &Violation{Rule: RuleFormat, Message: "URL must have a host"}
+	}
+
+	for _, self := range p.selfHosts {
+		if host == self {
+			return &Violation{Rule: RuleSelfReference, Message: "links to this shortener would create a redirect loop"}
+		}
+	}
+
+	if isPrivateHost(host) {
+		return &Violation{Rule: RulePrivateHost, Message: fmt.Sprintf("host %q is private or loopback", host)}
+	}
+
+	p.maybeReload()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if domain, ok := matchDomain(host, p.lists.block); ok {
+		return &Violation{Rule: RuleBlocklist, Message: fmt.Sprintf("domain %q is blocked", domain)}
+	}
+	if len(p.lists.allow) > 0 {
+		if _, ok := matchDomain(host, p.lists.allow); !ok {
+			return &Violation{Rule: RuleAllowlist, Message: fmt.Sprintf("domain %q is not on the allowlist", host)}
+		}
+	}
+
+	return nil
+}
+
+func (p *Policy) maybeReload() {
+	if p.listPath == "" {
+		return
+	}
+
+	p.mu.RLock()
+	due := time.Since(p.lastCheck) >= reloadInterval
+	p.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	if err := p.reload(); err != nil {
+		log.Println("Failed to reload URL policy list, keeping previous rules:", err)
+	}
+}
+
+func (p *Policy) reload() error {
+	info, err := os.Stat(p.listPath)
+
+	p.mu.Lock()
+	p.lastCheck = time.Now()
+	unchanged := err == nil && info.ModTime().Equal(p.modTime)
+	p.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("policy.reload: %w", err)
+	}
+	if unchanged {
+		return nil
+	}
+
+	f, err := os.Open(p.listPath)
+	if err != nil {
+		return fmt.Errorf("policy.reload: %w", err)
+	}
+	defer f.Close()
+
+	lists, err := parseLists(f)
+	if err != nil {
+		return fmt.Errorf("policy.reload: %s: %w", p.listPath, err)
+	}
+
+	p.mu.Lock()
+	p.lists = lists
+	p.modTime = info.ModTime()
+	p.mu.Unlock()
+
+	return nil
+}
+
+func parseLists(r io.Reader) (domainLists, error) {
+	var lists domainLists
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		action, domain := "block", fields[0]
+		if len(fields) == 2 {
+			action, domain = strings.ToLower(fields[0]), fields[1]
+		} else if len(fields) > 2 {
+			return lists, fmt.Errorf("line %d: expected \"[allow|block] domain\"", n)
+		}
+
+		domain = normalizeHost(strings.TrimPrefix(domain, "*."))
+		switch action {
+		case "block":
+			lists.block = append(lists.block, domain)
+		case "allow":
+			lists.allow = append(lists.allow, domain)
+		default:
+			return lists, fmt.Errorf("line %d: unknown action %q", n, action)
+		}
+	}
+
+	return lists, scanner.Err()
+}
+
+func matchDomain(host string, domains []string) (string, bool) {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+func isPrivateHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+			ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+			cgnat.Contains(ip)
+	}
+
+	// Browsers read hosts such as 2130706433, 0x7f.1 or 127.1 as IPv4 addresses, so anything that is not a canonical IP is refused
+	labels := strings.Split(host, ".")
+	return isNumericLabel(labels[len(labels)-1])
+}
+
+func isNumericLabel(label string) bool {
+	if label == "" {
+		return false
+	}
+	if strings.HasPrefix(label, "0x") {
+		label = label[2:]
+		return strings.Trim(label, "0123456789abcdef") == ""
+	}
+	return strings.Trim(label, "0123456789") == ""
+}
@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	p, err := New("http://short.ly:8080", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		url      string
+		wantRule Rule
+	}{
+		{url: "https://example.com/path?q=1"},
+		{url: "http://8.8.8.8/"},
+		{url: "not a url", wantRule: RuleFormat},
+		{url: "javascript:alert(1)", wantRule: RuleScheme},
+		{url: "file:///etc/passwd", wantRule: RuleScheme},
+		{url: "ftp://example.com/file", wantRule: RuleScheme},
+		{url: "http:///nohost", wantRule: RuleFormat},
+		{url: "http://localhost:3000/", wantRule: RulePrivateHost},
+		{url: "http://printer.local/", wantRule: RulePrivateHost},
+		{url: "http://127.0.0.1/", wantRule: RulePrivateHost},
+		{url: "http://10.1.2.3/", wantRule: RulePrivateHost},
+		{url: "http://192.168.0.1/", wantRule: RulePrivateHost},
+		{url: "http://169.254.169.254/latest/meta-data", wantRule: RulePrivateHost},
+		{url: "http://100.64.0.1/", wantRule: RulePrivateHost},
+		{url: "http://[::1]/", wantRule: RulePrivateHost},
+		{url: "http://[::ffff:127.0.0.1]/", wantRule: RulePrivateHost},
+		{url: "http://2130706433/", wantRule: RulePrivateHost},
+		{url: "http://0x7f.1/", wantRule: RulePrivateHost},
+		{url: "http://SHORT.LY/abc", wantRule: RuleSelfReference},
+		{url: "https://short.ly./abc", wantRule: RuleSelfReference},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := p.Check(tt.url)
+			if tt.wantRule == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var v *Violation
+			require.True(t, errors.As(err, &v), "got %v", err)
+			require.Equal(t, tt.wantRule, v.Rule)
+		})
+	}
+}
+
+func TestDomainListReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# spam\nevil.example\nblock *.tracker.test\n"), 0o600))
+
+	p, err := New("http://short.ly", path)
+	require.NoError(t, err)
+
+	require.NoError(t, p.Check("https://good.example/"))
+	requireRule(t, RuleBlocklist, p.Check("https://evil.example/"))
+	requireRule(t, RuleBlocklist, p.Check("https://cdn.evil.example/"))
+	requireRule(t, RuleBlocklist, p.Check("https://a.tracker.test/"))
+	require.NoError(t, p.Check("https://notevil.example/"))
+
+	require.NoError(t, os.WriteFile(path, []byte("allow example.org\n"), 0o600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	// Within the reload interval the old rules stay in effect
+	requireRule(t, RuleBlocklist, p.Check("https://evil.example/"))
+
+	p.lastCheck = time.Time{}
+	require.NoError(t, p.Check("https://www.example.org/"))
+	requireRule(t, RuleAllowlist, p.Check("https://evil.example/"))
+
+	// A broken file keeps the previous rules
+	require.NoError(t, os.WriteFile(path, []byte("permit too many fields\n"), 0o600))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	p.lastCheck = time.Time{}
+	require.NoError(t, p.Check("https://example.org/"))
+	requireRule(t, RuleAllowlist, p.Check("https://other.test/"))
+}
+
+func TestNewMissingList(t *testing.T) {
+	_, err := New("http://short.ly", filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
+
+func requireRule(t *testing.T, want Rule, err error) {
+	t.Helper()
+	var v *Violation
+	require.True(t, errors.As(err, &v), "got %v", err)
+	require.Equal(t, want, v.Rule)
+}
@@ -0,0 +1,268 @@
+// Package problem writes error responses as RFC 7807 problem+json documents with stable machine-readable codes.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"golang.org/x/text/language"
+
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// ContentType is the media type of problem responses
+const ContentType = "application/problem+json"
+
+// typePrefix makes problem types URIs while keeping the code readable
+const typePrefix = "urn:shorturl:problem:"
+
+// Code is a stable identifier of an error that clients can switch on
+type Code string
+
+// Problem codes returned by the API
+const (
+	CodeInvalidJSON        Code = "invalid_json"
+	CodeInvalidContentType Code = "invalid_content_type"
+	CodeInvalidBody        Code = "invalid_body"
+	CodeInvalidEncoding    Code = "invalid_encoding"
+	CodeInvalidParameter   Code = "invalid_parameter"
+	CodeInvalidID          Code = "invalid_id"
+	CodeInvalidRequest     Code = "invalid_request"
+	CodeEmptyURL           Code = "empty_url"
+	CodeEmptyBatch         Code = "empty_batch"
+	CodeTooManyLines       Code = "too_many_lines"
+	CodeURLRejected        Code = "url_rejected"
+	CodeUnknownDomain      Code = "unknown_domain"
+	CodeDomainForbidden    Code = "domain_forbidden"
+	CodeInvalidMetadata    Code = "invalid_metadata"
+	CodeInvalidVariants    Code = "invalid_variants"
+	CodeInvalidRules       Code = "invalid_rules"
+	CodeInvalidQuery       Code = "invalid_query_options"
+	CodeNotFound           Code = "not_found"
+	CodeGone               Code = "gone"
+	CodeURLExists          Code = "url_exists"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeUserExists         Code = "user_exists"
+	CodeInvalidCredentials Code = "invalid_credentials"
+	CodeWeakCredentials    Code = "weak_credentials"
+	CodeInvalidAPIKey      Code = "invalid_api_key"
+	CodeInvalidScope       Code = "invalid_scope"
+	CodeAPIKeyNotFound     Code = "api_key_not_found"
+	CodeInsufficientScope  Code = "insufficient_scope"
+	CodeInvalidWebhook     Code = "invalid_webhook"
+	CodeWebhookNotFound    Code = "webhook_not_found"
+	CodeInvalidWorkspace   Code = "invalid_workspace"
+	CodeForbidden          Code = "forbidden"
+	CodeLastOwner          Code = "last_owner"
+	CodeSessionRequired    Code = "session_required"
+	CodeInvalidReport      Code = "invalid_report"
+	CodeInvalidModeration  Code = "invalid_moderation"
+	CodeLinkDisabled       Code = "link_disabled"
+	CodeAdminRequired      Code = "admin_required"
+	CodeRateLimited        Code = "rate_limited"
+	CodeStorageUnavailable Code = "storage_unavailable"
+	CodeNotImplemented     Code = "not_implemented"
+	CodeInternal           Code = "internal_error"
+)
+
+var supportedLanguages = []language.Tag{language.English, language.Russian}
+
+var matcher = language.NewMatcher(supportedLanguages)
+
+// titles holds the human readable title of every code per language
+var titles = map[Code]map[language.Tag]string{
+	CodeInvalidJSON:        {language.English: "Invalid JSON", language.Russian: "Некорректный JSON"},
+	CodeInvalidContentType: {language.English: "Unsupported Content-Type", language.Russian: "Неподдерживаемый Content-Type"},
+	CodeInvalidBody:        {language.English: "Failed to read request body", language.Russian: "Не удалось прочитать тело запроса"},
+	CodeInvalidEncoding:    {language.English: "Failed to decompress request", language.Russian: "Не удалось распаковать запрос"},
+	CodeInvalidParameter:   {language.English: "Invalid query parameter", language.Russian: "Некорректный параметр запроса"},
+	CodeInvalidID:          {language.English: "Missing or invalid ID", language.Russian: "Идентификатор отсутствует или некорректен"},
+	CodeInvalidRequest:     {language.English: "Request does not match the API schema", language.Russian: "Запрос не соответствует схеме API"},
+	CodeEmptyURL:           {language.English: "Empty URL", language.Russian: "Пустой URL"},
+	CodeEmptyBatch:         {language.English: "Empty list of URLs", language.Russian: "Пустой список URL"},
+	CodeTooManyLines:       {language.English: "Too many lines in the stream", language.Russian: "Слишком много строк в потоке"},
+	CodeURLRejected:        {language.English: "URL rejected by policy", language.Russian: "URL отклонён политикой"},
+	CodeUnknownDomain:      {language.English: "Short domain is not registered", language.Russian: "Короткий домен не зарегистрирован"},
+	CodeDomainForbidden:    {language.English: "Short domain is reserved for its owners", language.Russian: "Короткий домен доступен только его владельцам"},
+	CodeInvalidMetadata:    {language.English: "Invalid link title, notes or tags", language.Russian: "Некорректные название, заметки или теги ссылки"},
+	CodeInvalidVariants:    {language.English: "Invalid link variants", language.Russian: "Некорректные варианты ссылки"},
+	CodeInvalidRules:       {language.English: "Invalid redirect rules", language.Russian: "Некорректные правила перенаправления"},
+	CodeInvalidQuery:       {language.English: "Invalid query string options", language.Russian: "Некорректные параметры строки запроса"},
+	CodeNotFound:           {language.English: "Not found", language.Russian: "Не найдено"},
+	CodeGone:               {language.English: "URL has been deleted", language.Russian: "URL удалён"},
+	CodeURLExists:          {language.English: "URL already exists", language.Russian: "URL уже существует"},
+	CodeUnauthorized:       {language.English: "Unauthorized", language.Russian: "Требуется авторизация"},
+	CodeUserExists:         {language.English: "Login is already taken", language.Russian: "Логин уже занят"},
+	CodeInvalidCredentials: {language.English: "Invalid login or password", language.Russian: "Неверный логин или пароль"},
+	CodeWeakCredentials: {
+		language.English: "Login must not be empty and password must be 8 to 72 bytes long",
+		language.Russian: "Логин не может быть пустым, а пароль должен содержать от 8 до 72 байт",
+	},
+	CodeInvalidAPIKey:      {language.English: "Invalid API key", language.Russian: "Недействительный API-ключ"},
+	CodeInvalidScope:       {language.English: "Scopes must be any of shorten, read, delete", language.Russian: "Области действия должны быть из списка shorten, read, delete"},
+	CodeAPIKeyNotFound:     {language.English: "API key not found", language.Russian: "API-ключ не найден"},
+	CodeInsufficientScope:  {language.English: "API key lacks the required scope", language.Russian: "У API-ключа нет нужной области действия"},
+	CodeInvalidWebhook:     {language.English: "Webhook URL must be an absolute http(s) URL and events must be any of link.created, link.clicked, link.deleted", language.Russian: "URL вебхука должен быть абсолютным http(s) URL, а события из списка link.created, link.clicked, link.deleted"},
+	CodeWebhookNotFound:    {language.English: "Webhook not found", language.Russian: "Вебхук не найден"},
+	CodeInvalidWorkspace:   {language.English: "Workspace name must not be empty, roles must be any of owner, editor, viewer and members must be registered users", language.Russian: "Название рабочего пространства не может быть пустым, роль должна быть из списка owner, editor, viewer, а участники должны быть зарегистрированы"},
+	CodeForbidden:          {language.English: "Your workspace role does not allow this", language.Russian: "Ваша роль в рабочем пространстве не позволяет это сделать"},
+	CodeLastOwner:          {language.English: "A workspace must keep at least one owner", language.Russian: "В рабочем пространстве должен остаться хотя бы один владелец"},
+	CodeSessionRequired:    {language.English: "This operation requires a cookie session", language.Russian: "Операция доступна только при входе через cookie"},
+	CodeInvalidReport:      {language.English: "Report reason must be any of phishing, malware, spam, other and details at most 1000 characters", language.Russian: "Причина жалобы должна быть из списка phishing, malware, spam, other, а описание не длиннее 1000 символов"},
+	CodeInvalidModeration:  {language.English: "Moderation action must be disable or clear and the note at most 1000 characters", language.Russian: "Действие модератора должно быть disable или clear, а заметка не длиннее 1000 символов"},
+	CodeLinkDisabled:       {language.English: "Link has been disabled after abuse reports", language.Russian: "Ссылка заблокирована по жалобам"},
+	CodeAdminRequired:      {language.English: "This operation is reserved for administrators", language.Russian: "Операция доступна только администраторам"},
+	CodeRateLimited:        {language.English: "Too many requests", language.Russian: "Слишком много запросов"},
+	CodeStorageUnavailable: {language.English: "Storage is unavailable", language.Russian: "Хранилище недоступно"},
+	CodeNotImplemented:     {language.English: "Not supported by the configured storage", language.Russian: "Не поддерживается выбранным хранилищем"},
+	CodeInternal:           {language.English: "Internal server error", language.Russian: "Внутренняя ошибка сервера"},
+}
+
+// Problem is an RFC 7807 error document. Extensions are written as additional top-level members
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Code       Code
+	Extensions map[string]interface{}
+
+	lang language.Tag
+}
+
+// New creates a problem for code with a title in the language preferred by the request's Accept-Language header
+func New(r *http.Request, status int, code Code) *Problem {
+	lang := preferredLanguage(r)
+
+	title, ok := titles[code][lang]
+	if !ok {
+		title = http.StatusText(status)
+	}
+
+	p := &Problem{
+		Type:   typePrefix + string(code),
+		Title:  title,
+		Status: status,
+		Code:   code,
+		lang:   lang,
+	}
+	if r != nil {
+		p.Instance = r.URL.Path
+	}
+	return p
+}
+
+// WithDetail sets an explanation specific to this occurrence
+func (p *Problem) WithDetail(detail string) *Problem {
+	p.Detail = detail
+	return p
+}
+
+// With adds an extension member
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// MarshalJSON implements json.Marshaler
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]interface{}, len(p.Extensions)+6)
+	for k, v := range p.Extensions {
+		doc[k] = v
+	}
+	doc["type"] = p.Type
+	doc["title"] = p.Title
+	doc["status"] = p.Status
+	doc["code"] = p.Code
+	if p.Detail != "" {
+		doc["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		doc["instance"] = p.Instance
+	}
+	return json.Marshal(doc)
+}
+
+// Write sends the problem as the response
+func (p *Problem) Write(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Content-Language", p.lang.String())
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Println("Failed to write problem response:", err)
+	}
+}
+
+// Write is a shorthand for New(r, status, code).Write(w)
+func Write(w http.ResponseWriter, r *http.Request, status int, code Code) {
+	New(r, status, code).Write(w)
+}
+
+// FromError maps application errors to problems. Errors that are not part of appErrors become internal errors
+func FromError(r *http.Request, err error) *Problem {
+	switch {
+	case errors.Is(err, appErrors.ErrNotFound):
+		return New(r, http.StatusNotFound, CodeNotFound)
+	case errors.Is(err, appErrors.ErrDeleted):
+		return New(r, http.StatusGone, CodeGone)
+	case errors.Is(err, appErrors.ErrURLExists):
+		return New(r, http.StatusConflict, CodeURLExists)
+	case errors.Is(err, appErrors.ErrTakenDown):
+		return New(r, http.StatusBadRequest, CodeURLRejected).WithDetail("the URL was taken down by an operator")
+	case errors.Is(err, appErrors.ErrUserExists):
+		return New(r, http.StatusConflict, CodeUserExists)
+	case errors.Is(err, appErrors.ErrInvalidCredentials):
+		return New(r, http.StatusUnauthorized, CodeInvalidCredentials)
+	case errors.Is(err, appErrors.ErrWeakCredentials):
+		return New(r, http.StatusBadRequest, CodeWeakCredentials)
+	case errors.Is(err, appErrors.ErrInvalidAPIKey):
+		return New(r, http.StatusUnauthorized, CodeInvalidAPIKey)
+	case errors.Is(err, appErrors.ErrInvalidScope):
+		return New(r, http.StatusBadRequest, CodeInvalidScope)
+	case errors.Is(err, appErrors.ErrInvalidWebhook):
+		return New(r, http.StatusBadRequest, CodeInvalidWebhook)
+	case errors.Is(err, appErrors.ErrInvalidWorkspace):
+		return New(r, http.StatusBadRequest, CodeInvalidWorkspace)
+	case errors.Is(err, appErrors.ErrForbidden):
+		return New(r, http.StatusForbidden, CodeForbidden)
+	case errors.Is(err, appErrors.ErrLastOwner):
+		return New(r, http.StatusConflict, CodeLastOwner)
+	case errors.Is(err, appErrors.ErrInvalidReport):
+		return New(r, http.StatusBadRequest, CodeInvalidReport)
+	case errors.Is(err, appErrors.ErrInvalidModeration):
+		return New(r, http.StatusBadRequest, CodeInvalidModeration)
+	case errors.Is(err, appErrors.ErrInvalidAuditFilter):
+		return New(r, http.StatusBadRequest, CodeInvalidParameter)
+	default:
+		return New(r, http.StatusInternalServerError, CodeInternal)
+	}
+}
+
+// WriteError writes the problem FromError picks for err
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	FromError(r, err).Write(w)
+}
+
+func preferredLanguage(r *http.Request) language.Tag {
+	if r == nil {
+		return language.English
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return language.English
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return language.English
+	}
+	return supportedLanguages[index]
+}
@@ -0,0 +1,102 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/problem"
+)
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	require.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body
+}
+
+func TestWrite_Language(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		wantLang       string
+		wantTitle      string
+	}{
+		{acceptLanguage: "", wantLang: "en", wantTitle: "Invalid JSON"},
+		{acceptLanguage: "ru-RU,ru;q=0.9,en;q=0.8", wantLang: "ru", wantTitle: "Некорректный JSON"},
+		{acceptLanguage: "de-DE, en;q=0.5", wantLang: "en", wantTitle: "Invalid JSON"},
+		{acceptLanguage: "fr", wantLang: "en", wantTitle: "Invalid JSON"},
+		{acceptLanguage: "!!!", wantLang: "en", wantTitle: "Invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/shorten", nil)
+			r.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+
+			problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Equal(t, tt.wantLang, w.Header().Get("Content-Language"))
+			body := decode(t, w)
+			require.Equal(t, tt.wantTitle, body["title"])
+			require.Equal(t, "invalid_json", body["code"])
+			require.Equal(t, "urn:shorturl:problem:invalid_json", body["type"])
+			require.Equal(t, float64(http.StatusBadRequest), body["status"])
+			require.Equal(t, "/api/shorten", body["instance"])
+		})
+	}
+}
+
+func TestProblem_Extensions(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/shorten/batch", nil)
+	w := httptest.NewRecorder()
+
+	problem.New(r, http.StatusBadRequest, problem.CodeURLRejected).
+		WithDetail("scheme is not allowed").
+		With("rule", "scheme").
+		With("code", "overridden").
+		Write(w)
+
+	body := decode(t, w)
+	require.Equal(t, "scheme is not allowed", body["detail"])
+	require.Equal(t, "scheme", body["rule"])
+	require.Equal(t, "url_rejected", body["code"], "extensions must not replace standard members")
+}
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   problem.Code
+	}{
+		{err: appErrors.ErrNotFound, wantStatus: http.StatusNotFound, wantCode: problem.CodeNotFound},
+		{err: fmt.Errorf("wrapped: %w", appErrors.ErrDeleted), wantStatus: http.StatusGone, wantCode: problem.CodeGone},
+		{err: fmt.Errorf("wrapped: %w", appErrors.ErrTakenDown), wantStatus: http.StatusBadRequest, wantCode: problem.CodeURLRejected},
+		{err: appErrors.ErrUserExists, wantStatus: http.StatusConflict, wantCode: problem.CodeUserExists},
+		{err: appErrors.ErrInvalidCredentials, wantStatus: http.StatusUnauthorized, wantCode: problem.CodeInvalidCredentials},
+		{err: appErrors.ErrWeakCredentials, wantStatus: http.StatusBadRequest, wantCode: problem.CodeWeakCredentials},
+		{err: appErrors.ErrInvalidAPIKey, wantStatus: http.StatusUnauthorized, wantCode: problem.CodeInvalidAPIKey},
+		{err: appErrors.ErrInvalidScope, wantStatus: http.StatusBadRequest, wantCode: problem.CodeInvalidScope},
+		{err: fmt.Errorf("wrapped: %w", appErrors.ErrForbidden), wantStatus: http.StatusForbidden, wantCode: problem.CodeForbidden},
+		{err: appErrors.ErrLastOwner, wantStatus: http.StatusConflict, wantCode: problem.CodeLastOwner},
+		{err: appErrors.ErrInvalidReport, wantStatus: http.StatusBadRequest, wantCode: problem.CodeInvalidReport},
+		{err: fmt.Errorf("connection reset"), wantStatus: http.StatusInternalServerError, wantCode: problem.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			p := problem.FromError(httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
+			require.Equal(t, tt.wantStatus, p.Status)
+			require.Equal(t, tt.wantCode, p.Code)
+			require.NotEmpty(t, p.Title)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// Version 1-M codewords for "HELLO WORLD" from the specification walkthrough.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	got := reedSolomonRemainder(data, reedSolomonDivisor(len(want)))
+	require.Equal(t, want, got)
+}
+
+func TestCapacity(t *testing.T) {
+	tests := []struct {
+		version int
+		level   Level
+		bytes   int
+	}{
+		{1, LevelL, 17},
+		{1, LevelM, 14},
+		{1, LevelQ, 11},
+		{1, LevelH, 7},
+		{10, LevelM, 213},
+		{40, LevelL, 2953},
+		{40, LevelH, 1273},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			capacity := (numDataCodewords(tt.version, tt.level)*8 - 4 - charCountBits(tt.version)) / 8
+			require.Equal(t, tt.bytes, capacity)
+		})
+	}
+}
+
+func TestFormatAndVersionInfo(t *testing.T) {
+	require.Equal(t, 0x5412, formatInfo(LevelM, 0))
+	require.Equal(t, 0x77C4, formatInfo(LevelL, 0))
+	require.Equal(t, 0x07C94, versionInfo(7))
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		level       Level
+		wantVersion int
+	}{
+		{name: "short link", data: "http://localhost:8080/AbCdEfGh", level: LevelM, wantVersion: 3},
+		{name: "smallest", data: "hi", level: LevelH, wantVersion: 1},
+		{name: "with version info", data: strings.Repeat("x", 200), level: LevelL, wantVersion: 9},
+		{name: "many blocks", data: strings.Repeat("y", 500), level: LevelQ, wantVersion: 21},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode([]byte(tt.data), tt.level)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantVersion, c.Version())
+			require.Equal(t, tt.wantVersion*4+17, c.Size())
+
+			for _, corner := range [][2]int{{0, 0}, {c.Size() - 7, 0}, {0, c.Size() - 7}} {
+				require.True(t, c.Dark(corner[0], corner[1]))
+				require.False(t, c.Dark(corner[0]+1, corner[1]+1))
+				require.True(t, c.Dark(corner[0]+3, corner[1]+3))
+			}
+
+			require.Equal(t, formatInfo(tt.level, c.Mask()), readFormatInfo(c))
+			require.Equal(t, tt.data, string(readData(t, c)))
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	_, err := Encode(bytes.Repeat([]byte("z"), 1274), LevelH)
+	require.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestRender(t *testing.T) {
+	c, err := Encode([]byte("http://localhost:8080/abc"), LevelM)
+	require.NoError(t, err)
+
+	opts := RenderOptions{
+		Size:       256,
+		Margin:     4,
+		Foreground: color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, c.WritePNG(&buf, opts))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, 256, img.Bounds().Dx())
+	r, g, b, _ := img.At(0, 0).RGBA()
+	require.Equal(t, [3]uint32{0xffff, 0xffff, 0xffff}, [3]uint32{r, g, b})
+
+	buf.Reset()
+	require.NoError(t, c.WriteSVG(&buf, opts))
+	require.Contains(t, buf.String(), `width="256"`)
+	require.Contains(t, buf.String(), `fill="#112233"`)
+}
+
+func TestParseColor(t *testing.T) {
+	got, err := ParseColor("#0a0B0c")
+	require.NoError(t, err)
+	require.Equal(t, color.RGBA{R: 0x0a, G: 0x0b, B: 0x0c, A: 0xff}, got)
+
+	got, err = ParseColor("f00")
+	require.NoError(t, err)
+	require.Equal(t, color.RGBA{R: 0xff, A: 0xff}, got)
+
+	_, err = ParseColor("red")
+	require.Error(t, err)
+}
+
+func readFormatInfo(c *Code) int {
+	bits := 0
+	set := func(i int, dark bool) {
+		if dark {
+			bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		set(i, c.Dark(8, i))
+	}
+	set(6, c.Dark(8, 7))
+	set(7, c.Dark(8, 8))
+	set(8, c.Dark(7, 8))
+	for i := 9; i < 15; i++ {
+		set(i, c.Dark(14-i, 8))
+	}
+	return bits
+}
+
+// readData walks the symbol like a decoder would and returns the byte-mode payload.
+func readData(t *testing.T, c *Code) []byte {
+	t.Helper()
+
+	c.applyMask(c.Mask())
+	defer c.applyMask(c.Mask())
+
+	rawCodewords := numRawDataModules(c.version) / 8
+	var bb bitBuffer
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.size - 1 - vert
+				}
+				if !c.isFunc[y][x] && len(bb) < rawCodewords*8 {
+					bb = append(bb, c.modules[y][x])
+				}
+			}
+		}
+	}
+	raw := bb.bytes()
+
+	numBlocks := numErrorCorrectionBlocks[c.level][c.version]
+	blockECCLen := eccCodewordsPerBlock[c.level][c.version]
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortBlockLen; i++ {
+		for j := range blocks {
+			if i == shortBlockLen-blockECCLen && j < numShortBlocks {
+				continue
+			}
+			blocks[j] = append(blocks[j], raw[k])
+			k++
+		}
+	}
+
+	var data []byte
+	for j, block := range blocks {
+		n := len(block) - blockECCLen
+		require.Equal(t, block[n:], reedSolomonRemainder(block[:n], reedSolomonDivisor(blockECCLen)), "block %d", j)
+		data = append(data, block[:n]...)
+	}
+
+	require.Equal(t, byte(0x4), data[0]>>4)
+	ccBits := charCountBits(c.version)
+	var bits bitBuffer
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	n := 0
+	for _, v := range bits[4 : 4+ccBits] {
+		n <<= 1
+		if v {
+			n |= 1
+		}
+	}
+	payload := bits[4+ccBits : 4+ccBits+8*n]
+	return payload.bytes()
+}
@@ -0,0 +1,88 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// RenderOptions controls how a Code is rasterised.
+type RenderOptions struct {
+	// Size is the width and height of the output in pixels.
+	Size int
+	// Margin is the width of the quiet zone in modules.
+	Margin int
+	// Foreground is the colour of dark modules.
+	Foreground color.RGBA
+	// Background is the colour of light modules and the quiet zone.
+	Background color.RGBA
+}
+
+// WritePNG renders the code as a two-colour PNG image of exactly opts.Size pixels square.
+func (c *Code) WritePNG(w io.Writer, opts RenderOptions) error {
+	total := c.size + 2*opts.Margin
+	size := opts.Size
+	if size < total {
+		size = total
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{opts.Background, opts.Foreground})
+	for py := 0; py < size; py++ {
+		y := py*total/size - opts.Margin
+		for px := 0; px < size; px++ {
+			x := px*total/size - opts.Margin
+			if c.Dark(x, y) {
+				img.SetColorIndex(px, py, 1)
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// WriteSVG renders the code as an SVG document with a viewBox in module units scaled to opts.Size pixels.
+func (c *Code) WriteSVG(w io.Writer, opts RenderOptions) error {
+	total := c.size + 2*opts.Margin
+
+	var path strings.Builder
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.Dark(x, y) {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+opts.Margin, y+opts.Margin)
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w,
+		`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n"+
+			`<rect width="100%%" height="100%%" fill="%s"/>`+"\n"+
+			`<path d="%s" fill="%s"/>`+"\n"+
+			`</svg>`+"\n",
+		opts.Size, opts.Size, total, total, hexColor(opts.Background), path.String(), hexColor(opts.Foreground))
+	return err
+}
+
+// ParseColor parses a colour in rgb or rrggbb hex notation, with or without a leading '#'.
+func ParseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	var r, g, b uint8
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("qrcode: invalid colour %q", s)
+	}
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, fmt.Errorf("qrcode: invalid colour %q", s)
+	}
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}, nil
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// uniqueViolation is the SQLSTATE of a unique constraint violation
+const uniqueViolation = "23505"
+
+// LookupLink returns the link with the given ID whether or not it is deleted
+func (r *URLRepository) LookupLink(ctx context.Context, id string) (domain.LinkRecord, error) {
+	query := `SELECT short, domain, original, user_id, workspace_id, title, notes, tags, is_deleted, deleted_at, takedown_reason
+			  FROM urlshrt WHERE short = $1;`
+
+	var link domain.LinkRecord
+	err := r.db.QueryRow(ctx, query, id).Scan(&link.ID, &link.Domain, &link.OriginalURL, &link.UserID, &link.Workspace,
+		&link.Title, &link.Notes, &link.Tags, &link.Deleted, &link.DeletedAt, &link.TakedownReason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.LinkRecord{}, appErrors.ErrNotFound
+	}
+	if err != nil {
+		return domain.LinkRecord{}, fmt.Errorf("ошибка при получении URL: %w", err)
+	}
+
+	return link, nil
+}
+
+// TakedownLink deletes a link on behalf of an operator and records why, so that it answers 410 Gone
+func (r *URLRepository) TakedownLink(ctx context.Context, id, reason string) error {
+	query := `UPDATE urlshrt SET is_deleted = true, deleted_at = COALESCE(deleted_at, NOW()), takedown_reason = $2
+			  WHERE short = $1;`
+
+	res, err := r.db.Exec(ctx, query, id, reason)
+	if err != nil {
+		return fmt.Errorf("ошибка при блокировке URL: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return appErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// refuseTakenDown fails with ErrTakenDown if one of the URLs belongs to a link taken down by an operator, on any
+// domain, so that a takedown can not be undone by shortening the URL again
+func (r *URLRepository) refuseTakenDown(ctx context.Context, urls []string) error {
+	var takenDown bool
+	query := `SELECT EXISTS(SELECT 1 FROM urlshrt WHERE original = ANY($1) AND takedown_reason <> '');`
+
+	if err := r.db.QueryRow(ctx, query, urls).Scan(&takenDown); err != nil {
+		return fmt.Errorf("ошибка при проверке заблокированных URL: %w", err)
+	}
+	if takenDown {
+		return appErrors.ErrTakenDown
+	}
+
+	return nil
+}
+
+// RestoreLink undoes the deletion or takedown of a link that has not been purged yet. It fails with ErrURLExists if
+// the URL was shortened again on the same domain after the link was deleted
+func (r *URLRepository) RestoreLink(ctx context.Context, id string) error {
+	query := `UPDATE urlshrt SET is_deleted = false, deleted_at = NULL, takedown_reason = ''
+			  WHERE short = $1;`
+
+	res, err := r.db.Exec(ctx, query, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return appErrors.ErrURLExists
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка при восстановлении URL: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return appErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted removes the links deleted before the given time together with their variants, check results and page
+// metadata, and returns how many links were removed. Links taken down by an operator are kept, so that their URLs
+// stay blocked
+func (r *URLRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rows, err := tx.Query(ctx, `DELETE FROM urlshrt WHERE is_deleted AND takedown_reason = '' AND deleted_at < $1 RETURNING short;`, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при удалении URL: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при удалении URL: %w", err)
+	}
+
+	if len(ids) > 0 {
+		for _, table := range []string{"link_variants", "link_health", "link_pages", "disabled_links"} {
+			if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE short = ANY($1);`, ids); err != nil {
+				return 0, fmt.Errorf("ошибка при удалении данных URL из %s: %w", table, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ошибка при завершении транзакции: %w", err)
+	}
+
+	return int64(len(ids)), nil
+}
+
+// Stats counts the links, the users owning them, the registered accounts and the workspaces of the instance
+func (r *URLRepository) Stats(ctx context.Context) (domain.InstanceStats, error) {
+	query := `SELECT
+			    (SELECT COUNT(*) FROM urlshrt),
+			    (SELECT COUNT(*) FROM urlshrt WHERE is_deleted),
+			    (SELECT COUNT(*) FROM urlshrt WHERE is_deleted AND takedown_reason <> ''),
+			    (SELECT COUNT(DISTINCT user_id) FROM urlshrt),
+			    (SELECT COUNT(*) FROM users),
+			    (SELECT COUNT(*) FROM workspaces);`
+
+	var stats domain.InstanceStats
+	err := r.db.QueryRow(ctx, query).Scan(&stats.Links, &stats.Deleted, &stats.TakenDown, &stats.Users, &stats.Accounts, &stats.Workspaces)
+	if err != nil {
+		return domain.InstanceStats{}, fmt.Errorf("ошибка при подсчёте статистики: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// APIKeyRepository — repository for managing hashed API keys in PostgreSQL.
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository instance with the given connection pool.
+func NewAPIKeyRepository(db *pgxpool.Pool) (*APIKeyRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &APIKeyRepository{db: db}, nil
+}
+
+// CreateAPIKey stores a new API key
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key domain.APIKey) error {
+	query := `INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7);`
+
+	_, err := r.db.Exec(ctx, query, key.ID, key.UserID, key.Name, key.Prefix, key.Hash, key.Scopes, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении API-ключа: %w", err)
+	}
+
+	return nil
+}
+
+// ListAPIKeys returns all API keys of the user, including revoked ones
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, userID int) ([]domain.APIKey, error) {
+	query := `SELECT id, user_id, name, prefix, key_hash, scopes, created_at, revoked_at
+			  FROM api_keys WHERE user_id = $1 ORDER BY created_at;`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении API-ключей: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		var key domain.APIKey
+		if err := rows.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.Hash, &key.Scopes, &key.CreatedAt, &key.RevokedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании API-ключа: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marks the user's API key as revoked
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, userID int, id string) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;`
+
+	res, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при отзыве API-ключа: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return appErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetAPIKeyByHash returns the API key with the given hash
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, hash string) (domain.APIKey, error) {
+	query := `SELECT id, user_id, name, prefix, key_hash, scopes, created_at, revoked_at
+			  FROM api_keys WHERE key_hash = $1;`
+
+	var key domain.APIKey
+	err := r.db.QueryRow(ctx, query, hash).Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.Hash, &key.Scopes, &key.CreatedAt, &key.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.APIKey{}, appErrors.ErrNotFound
+	}
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("ошибка при получении API-ключа: %w", err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// apiKeyRecord is the on-disk form of domain.APIKey, which hides the user ID and hash from JSON responses
+type apiKeyRecord struct {
+	domain.APIKey
+	UserID int    `json:"user_id"`
+	Hash   string `json:"hash"`
+}
+
+// APIKeyStore keeps API keys in memory and, when a file path is given, mirrors them to a JSON file
+type APIKeyStore struct {
+	file string
+	keys map[string]domain.APIKey
+	mu   sync.RWMutex
+}
+
+// NewAPIKeyStore creates a new API key store and loads keys from the file if it is set
+func NewAPIKeyStore(filePath string) (*APIKeyStore, error) {
+	s := &APIKeyStore{
+		file: filePath,
+		keys: make(map[string]domain.APIKey),
+	}
+
+	if filePath == "" {
+		return s, nil
+	}
+
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	if len(fileData) == 0 {
+		return s, nil
+	}
+
+	var records []apiKeyRecord
+	if err := json.Unmarshal(fileData, &records); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации данных из файла: %w", err)
+	}
+
+	for _, rec := range records {
+		key := rec.APIKey
+		key.UserID = rec.UserID
+		key.Hash = rec.Hash
+		s.keys[key.ID] = key
+	}
+
+	return s, nil
+}
+
+// CreateAPIKey stores a new API key
+func (s *APIKeyStore) CreateAPIKey(ctx context.Context, key domain.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = key
+
+	if err := s.saveToFile(); err != nil {
+		delete(s.keys, key.ID)
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// ListAPIKeys returns all API keys of the user, including revoked ones
+func (s *APIKeyStore) ListAPIKeys(ctx context.Context, userID int) ([]domain.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []domain.APIKey
+	for _, key := range s.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks the user's API key as revoked
+func (s *APIKeyStore) RevokeAPIKey(ctx context.Context, userID int, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists || key.UserID != userID || key.RevokedAt != nil {
+		return appErrors.ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	key.RevokedAt = &now
+	s.keys[id] = key
+
+	if err := s.saveToFile(); err != nil {
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPIKeyByHash returns the API key with the given hash
+func (s *APIKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (domain.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.Hash == hash {
+			return key, nil
+		}
+	}
+
+	return domain.APIKey{}, appErrors.ErrNotFound
+}
+
+func (s *APIKeyStore) saveToFile() error {
+	if s.file == "" {
+		return nil
+	}
+
+	records := make([]apiKeyRecord, 0, len(s.keys))
+	for _, key := range s.keys {
+		records = append(records, apiKeyRecord{APIKey: key, UserID: key.UserID, Hash: key.Hash})
+	}
+
+	jsonData, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации данных: %w", err)
+	}
+
+	if err := os.WriteFile(s.file, jsonData, 0600); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", s.file, err)
+	}
+
+	return nil
+}
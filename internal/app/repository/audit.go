@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// AuditRepository — repository for the audit log of link changes in PostgreSQL.
+type AuditRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAuditRepository creates a new AuditRepository instance with the given connection pool.
+func NewAuditRepository(db *pgxpool.Pool) (*AuditRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &AuditRepository{db: db}, nil
+}
+
+// SaveAuditEntries appends the entries to the audit log
+func (r *AuditRepository) SaveAuditEntries(ctx context.Context, entries []domain.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && err == nil {
+			err = fmt.Errorf("ошибка при откате транзакции: %w", rollbackErr)
+		}
+	}()
+
+	query := `INSERT INTO audit_log (id, created_at, actor_id, ip, action, short, before, after)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+
+	for _, entry := range entries {
+		_, err = tx.Exec(ctx, query, entry.ID, entry.CreatedAt, entry.ActorID, entry.IP, entry.Action, entry.LinkID,
+			entry.Before, entry.After)
+		if err != nil {
+			return fmt.Errorf("ошибка при сохранении записи журнала аудита: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при завершении транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuditEntries returns the entries matching the filter, newest first
+func (r *AuditRepository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := `SELECT id, created_at, actor_id, ip, action, short, before, after
+			  FROM audit_log
+			  WHERE ($1 = 0 OR actor_id = $1)
+			    AND ($2 = '' OR short = $2)
+			    AND ($3 = '' OR action = $3)
+			    AND ($4 = '' OR ip = $4)
+			    AND ($5::timestamptz IS NULL OR created_at >= $5)
+			    AND ($6::timestamptz IS NULL OR created_at < $6)
+			  ORDER BY created_at DESC, id
+			  LIMIT NULLIF($7, 0);`
+
+	rows, err := r.db.Query(ctx, query, filter.ActorID, filter.LinkID, filter.Action, filter.IP,
+		nullTime(filter.From), nullTime(filter.To), filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении журнала аудита: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		var entry domain.AuditEntry
+		if err := rows.Scan(&entry.ID, &entry.CreatedAt, &entry.ActorID, &entry.IP, &entry.Action, &entry.LinkID,
+			&entry.Before, &entry.After); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании записи журнала аудита: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// nullTime turns the zero time into NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
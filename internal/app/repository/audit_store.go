@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// AuditStore keeps the audit log in memory and, when a file path is given, appends it to a file with one JSON entry
+// per line. Unlike the other stores it never rewrites the file, so the log stays cheap to extend however long it gets
+type AuditStore struct {
+	file    string
+	entries []domain.AuditEntry
+	mu      sync.RWMutex
+}
+
+// NewAuditStore creates a new audit store and loads the log from the file if it is set
+func NewAuditStore(filePath string) (*AuditStore, error) {
+	s := &AuditStore{file: filePath}
+
+	if filePath == "" {
+		return s, nil
+	}
+
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(fileData))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(fileData)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry domain.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("ошибка десериализации данных из файла: %w", err)
+		}
+		s.entries = append(s.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	return s, nil
+}
+
+// SaveAuditEntries appends the entries to the audit log
+func (s *AuditStore) SaveAuditEntries(ctx context.Context, entries []domain.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.appendToFile(entries); err != nil {
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+	s.entries = append(s.entries, entries...)
+
+	return nil
+}
+
+// ListAuditEntries returns the entries matching the filter, newest first
+func (s *AuditStore) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []domain.AuditEntry
+	for _, entry := range s.entries {
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+
+	return entries, nil
+}
+
+func (s *AuditStore) appendToFile(entries []domain.AuditEntry) error {
+	if s.file == "" {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return fmt.Errorf("ошибка сериализации данных: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(s.file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла %s: %w", s.file, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", s.file, err)
+	}
+
+	return nil
+}
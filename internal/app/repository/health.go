@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// LinksToCheck returns up to limit live links that have not been checked since checkedBefore, never checked ones first
+func (r *URLRepository) LinksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]domain.Link, error) {
+	query := `SELECT u.short, u.original FROM urlshrt u LEFT JOIN link_health h ON h.short = u.short
+			  WHERE NOT u.is_deleted AND (h.checked_at IS NULL OR h.checked_at < $1)
+			  ORDER BY h.checked_at NULLS FIRST, u.id LIMIT $2;`
+
+	rows, err := r.db.Query(ctx, query, checkedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении URL для проверки: %w", err)
+	}
+	defer rows.Close()
+
+	var links []domain.Link
+	for rows.Next() {
+		var link domain.Link
+		if err := rows.Scan(&link.ID, &link.OriginalURL); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании URL: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// SaveLinkHealth stores the outcome of checks keyed by link ID, replacing earlier ones
+func (r *URLRepository) SaveLinkHealth(ctx context.Context, health map[string]domain.LinkHealth) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && err == nil {
+			err = fmt.Errorf("ошибка при откате транзакции: %w", rollbackErr)
+		}
+	}()
+
+	query := `INSERT INTO link_health (short, status, error, redirects, checked_at) VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (short) DO UPDATE
+			  SET status = EXCLUDED.status, error = EXCLUDED.error, redirects = EXCLUDED.redirects, checked_at = EXCLUDED.checked_at;`
+
+	for id, h := range health {
+		redirects := h.Redirects
+		if redirects == nil {
+			redirects = []string{}
+		}
+		if _, err := tx.Exec(ctx, query, id, h.Status, h.Error, redirects, h.CheckedAt); err != nil {
+			return fmt.Errorf("ошибка сохранения результата проверки URL в БД: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при завершении транзакции: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// linkIndex is the in-memory search index of the memory and JSON repositories. It maps the words of link titles,
+// notes and original URLs as well as link tags to link IDs. The caller must hold the repository lock
+type linkIndex struct {
+	words map[string]map[string]struct{}
+	tags  map[string]map[string]struct{}
+}
+
+func newLinkIndex() *linkIndex {
+	return &linkIndex{
+		words: make(map[string]map[string]struct{}),
+		tags:  make(map[string]map[string]struct{}),
+	}
+}
+
+func (x *linkIndex) add(data URLData) {
+	for _, word := range linkWords(data) {
+		addToSet(x.words, word, data.ID)
+	}
+	for _, tag := range data.Tags {
+		addToSet(x.tags, tag, data.ID)
+	}
+}
+
+func (x *linkIndex) remove(data URLData) {
+	for _, word := range linkWords(data) {
+		removeFromSet(x.words, word, data.ID)
+	}
+	for _, tag := range data.Tags {
+		removeFromSet(x.tags, tag, data.ID)
+	}
+}
+
+// search returns the IDs of links carrying the tag of the filter whose words start with every word of its query.
+// It returns nil if the filter is empty, i.e. every link matches
+func (x *linkIndex) search(filter domain.LinkFilter) map[string]struct{} {
+	var result map[string]struct{}
+
+	if tag := normalizeTag(filter.Tag); tag != "" {
+		result = make(map[string]struct{}, len(x.tags[tag]))
+		for id := range x.tags[tag] {
+			result[id] = struct{}{}
+		}
+	}
+
+	for _, token := range searchTokens(filter.Query) {
+		matches := make(map[string]struct{})
+		for word, ids := range x.words {
+			if !strings.HasPrefix(word, token) {
+				continue
+			}
+			for id := range ids {
+				if _, ok := result[id]; result == nil || ok {
+					matches[id] = struct{}{}
+				}
+			}
+		}
+		result = matches
+	}
+
+	return result
+}
+
+func linkWords(data URLData) []string {
+	return searchTokens(data.Title + " " + data.Notes + " " + data.OriginalURL)
+}
+
+// searchTokens splits text into lower-cased words of letters and digits, the same way for indexing and querying
+func searchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// prefixTSQuery turns a search query into a Postgres tsquery matching links with words starting with every query word
+func prefixTSQuery(query string) string {
+	tokens := searchTokens(query)
+	for i, token := range tokens {
+		tokens[i] = token + ":*"
+	}
+	return strings.Join(tokens, " & ")
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// nonNilTags keeps an empty tag list from being stored as NULL
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func addToSet(sets map[string]map[string]struct{}, key, id string) {
+	if sets[key] == nil {
+		sets[key] = make(map[string]struct{})
+	}
+	sets[key][id] = struct{}{}
+}
+
+func removeFromSet(sets map[string]map[string]struct{}, key, id string) {
+	delete(sets[key], id)
+	if len(sets[key]) == 0 {
+		delete(sets, key)
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// JobRepository — repository for background jobs in PostgreSQL.
+type JobRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewJobRepository creates a new JobRepository instance with the given connection pool.
+func NewJobRepository(db *pgxpool.Pool) (*JobRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &JobRepository{db: db}, nil
+}
+
+const jobColumns = `id, type, state, user_id, ids, results, error, created_at, started_at, finished_at`
+
+// SaveJob stores a new job or the new state of an existing one
+func (r *JobRepository) SaveJob(ctx context.Context, job domain.Job) error {
+	query := `INSERT INTO jobs (` + jobColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			  ON CONFLICT (id) DO UPDATE
+			  SET state = EXCLUDED.state, results = EXCLUDED.results, error = EXCLUDED.error,
+			      started_at = EXCLUDED.started_at, finished_at = EXCLUDED.finished_at;`
+
+	_, err := r.db.Exec(ctx, query, job.ID, job.Type, job.State, job.UserID, job.IDs, job.Results, job.Error,
+		job.CreatedAt, job.StartedAt, job.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении задачи: %w", err)
+	}
+
+	return nil
+}
+
+// GetJob returns the job with the given ID
+func (r *JobRepository) GetJob(ctx context.Context, id string) (domain.Job, error) {
+	var job domain.Job
+	err := r.db.QueryRow(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1;`, id).Scan(&job.ID, &job.Type, &job.State,
+		&job.UserID, &job.IDs, &job.Results, &job.Error, &job.CreatedAt, &job.StartedAt, &job.FinishedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Job{}, appErrors.ErrNotFound
+	}
+	if err != nil {
+		return domain.Job{}, fmt.Errorf("ошибка при получении задачи: %w", err)
+	}
+
+	return job, nil
+}
+
+// ListUnfinishedJobs returns the jobs that were queued or running, oldest first
+func (r *JobRepository) ListUnfinishedJobs(ctx context.Context) ([]domain.Job, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+jobColumns+` FROM jobs WHERE finished_at IS NULL ORDER BY created_at;`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении незавершённых задач: %w", err)
+	}
+
+	jobs, err := pgx.CollectRows(rows, scanJob)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении незавершённых задач: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// PurgeJobs removes the jobs finished before the given time and returns how many were removed
+func (r *JobRepository) PurgeJobs(ctx context.Context, finishedBefore time.Time) (int64, error) {
+	res, err := r.db.Exec(ctx, `DELETE FROM jobs WHERE finished_at < $1;`, finishedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при удалении задач: %w", err)
+	}
+
+	return res.RowsAffected(), nil
+}
+
+func scanJob(row pgx.CollectableRow) (domain.Job, error) {
+	var job domain.Job
+	err := row.Scan(&job.ID, &job.Type, &job.State, &job.UserID, &job.IDs, &job.Results, &job.Error,
+		&job.CreatedAt, &job.StartedAt, &job.FinishedAt)
+	return job, err
+}
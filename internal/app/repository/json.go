@@ -4,33 +4,91 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"math/rand"
 	"os"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
-	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
 	appErrors "github.com/Te8va/shortURL/internal/app/errors"
 )
 
 const length = 8
 
-// JSONRepository is a storage implementation that saves data in a JSON file
+// JSONRepository is a storage implementation that saves data in a JSON file. Variant clicks are only counted in
+// memory and written with the next change, by Flush or on Close, so redirects do not rewrite the file
 type JSONRepository struct {
-	file  string
-	store map[string]URLData
-	mu    sync.RWMutex
-	cfg   *config.Config
+	file    string
+	store   map[string]URLData
+	index   *linkIndex
+	members WorkspaceMembers
+	// unsaved is set while clicks counted in store are not written to the file yet
+	unsaved bool
+	mu      sync.RWMutex
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
 }
 
-// URLData represents the structure for storing URL information
+// URLData represents the structure for storing URL information. Links are keyed by their bare ID
 type URLData struct {
-	UserID      int    `json:"user_id"`
-	OriginalURL string `json:"original_url"`
-	ShortURL    string `json:"short_url"`
+	UserID      int                   `json:"user_id"`
+	OriginalURL string                `json:"original_url"`
+	ID          string                `json:"id"`
+	Domain      string                `json:"domain"`
+	Workspace   string                `json:"workspace,omitempty"`
+	Title       string                `json:"title,omitempty"`
+	Notes       string                `json:"notes,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Variants    []domain.Variant      `json:"variants,omitempty"`
+	Rules       []domain.RedirectRule `json:"rules,omitempty"`
+	Query       *domain.QueryOptions  `json:"query,omitempty"`
+	Health      *domain.LinkHealth    `json:"health,omitempty"`
+	Page        *domain.PageMeta      `json:"page,omitempty"`
+	// ShortURL is only set in files written before links were keyed by ID, see upgradeLegacyKeys
+	ShortURL string `json:"short_url,omitempty"`
+}
+
+// queryOptions returns the query string options of the link, the zero value if it has none
+func (d URLData) queryOptions() domain.QueryOptions {
+	if d.Query == nil {
+		return domain.QueryOptions{}
+	}
+	return *d.Query
+}
+
+func (d URLData) link() domain.Link {
+	return domain.Link{
+		ID:          d.ID,
+		Domain:      d.Domain,
+		OriginalURL: d.OriginalURL,
+		UserID:      d.UserID,
+		LinkMeta:    domain.LinkMeta{Workspace: d.Workspace, Title: d.Title, Notes: d.Notes, Tags: d.Tags},
+		Health:      d.Health,
+		Page:        d.Page,
+	}
+}
+
+// update applies the fields set in update to the link metadata
+func (d URLData) update(update domain.LinkUpdate) URLData {
+	if update.Title != nil {
+		d.Title = *update.Title
+	}
+	if update.Notes != nil {
+		d.Notes = *update.Notes
+	}
+	if update.Tags != nil {
+		d.Tags = *update.Tags
+	}
+	return d
 }
 
 // NewJSONRepository creates a new JSON repository and loads data from the file.
-func NewJSONRepository(filePath string, cfg *config.Config) (*JSONRepository, error) {
+func NewJSONRepository(filePath string) (*JSONRepository, error) {
 	if filePath == "" {
 		return nil, fmt.Errorf("путь к файлу не задан")
 	}
@@ -38,57 +96,128 @@ func NewJSONRepository(filePath string, cfg *config.Config) (*JSONRepository, er
 	repo := &JSONRepository{
 		file:  filePath,
 		store: make(map[string]URLData),
-		cfg:   cfg,
+		index: newLinkIndex(),
+		done:  make(chan struct{}),
 	}
 
 	if err := repo.loadFromFile(); err != nil {
 		return nil, fmt.Errorf("ошибка загрузки данных из файла: %w", err)
 	}
 
+	for _, data := range repo.store {
+		repo.index.add(data)
+	}
+
 	return repo, nil
 }
 
-// Save stores URL and returns its shortened version
-func (r *JSONRepository) Save(ctx context.Context, userID int, url string) (string, error) {
-	id := r.generateID()
-	shortenedURL := fmt.Sprintf("%s/%s", r.cfg.BaseURL, id)
+// UseWorkspaces sets where the roles of workspace members are looked up. Until it is called only the creators of
+// personal links may change them
+func (r *JSONRepository) UseWorkspaces(members WorkspaceMembers) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
+	r.members = members
+}
+
+// StartFlushing writes the clicks counted since the last write to the file every interval until Close
+func (r *JSONRepository) StartFlushing(interval time.Duration) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+				if err := r.Flush(); err != nil {
+					log.Println("Failed to write variant clicks:", err)
+				}
+			}
+		}
+	}()
+}
+
+// Flush writes the clicks counted since the last write to the file
+func (r *JSONRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.unsaved {
+		return nil
+	}
+
+	if err := r.saveToFile(); err != nil {
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// Close stops flushing and writes the clicks counted since the last write to the file
+func (r *JSONRepository) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+	r.wg.Wait()
+
+	return r.Flush()
+}
+
+// Save stores URL with its metadata on the domain and returns the ID of its short link
+func (r *JSONRepository) Save(ctx context.Context, userID int, host, url string, meta domain.LinkMeta) (string, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	for key, val := range r.store {
-		if val.OriginalURL == url && val.UserID == userID {
+		if val.OriginalURL == url && val.UserID == userID && val.Domain == host && val.Workspace == meta.Workspace {
 			return key, appErrors.ErrURLExists
 		}
 	}
 
-	r.store[shortenedURL] = URLData{
+	id := r.generateID()
+	data := URLData{
 		UserID:      userID,
 		OriginalURL: url,
-		ShortURL:    shortenedURL,
+		ID:          id,
+		Domain:      host,
+		Workspace:   meta.Workspace,
+		Title:       meta.Title,
+		Notes:       meta.Notes,
+		Tags:        meta.Tags,
 	}
+	r.store[id] = data
+	r.index.add(data)
 
 	if err := r.saveToFile(); err != nil {
 		return "", fmt.Errorf("ошибка сохранения в файл: %w", err)
 	}
 
-	return shortenedURL, nil
+	return id, nil
 }
 
-// Get returns the original URL by its shortened identifier
-func (r *JSONRepository) Get(ctx context.Context, id string) (string, bool, bool) {
+// Get returns the original URL, the redirect rules and the query string options by the domain and ID of its short link
+func (r *JSONRepository) Get(ctx context.Context, host, id string) (domain.Redirect, bool, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	url, exists := r.store[id]
-	if !exists {
-		return "", false, false
+	if !exists || url.Domain != host {
+		return domain.Redirect{}, false, false
 	}
-	return url.OriginalURL, true, false
+	return domain.Redirect{
+		OriginalURL: url.OriginalURL,
+		Rules:       append([]domain.RedirectRule(nil), url.Rules...),
+		Query:       url.queryOptions(),
+	}, true, false
 }
 
-// SaveBatch stores multiple URLs in a single call.
-func (r *JSONRepository) SaveBatch(ctx context.Context, userID int, urls map[string]string) (map[string]string, error) {
+// SaveBatch stores multiple URLs on the domain in a single call.
+func (r *JSONRepository) SaveBatch(ctx context.Context, userID int, host string, urls map[string]string) (map[string]string, error) {
 	result := make(map[string]string)
 
 	r.mu.Lock()
@@ -96,13 +225,15 @@ func (r *JSONRepository) SaveBatch(ctx context.Context, userID int, urls map[str
 
 	for correlationID, originalURL := range urls {
 		id := r.generateID()
-		shortenedURL := fmt.Sprintf("%s/%s", r.cfg.BaseURL, id)
-		r.store[shortenedURL] = URLData{
+		data := URLData{
 			UserID:      userID,
 			OriginalURL: originalURL,
-			ShortURL:    shortenedURL,
+			ID:          id,
+			Domain:      host,
 		}
-		result[correlationID] = shortenedURL
+		r.store[id] = data
+		r.index.add(data)
+		result[correlationID] = id
 	}
 
 	if err := r.saveToFile(); err != nil {
@@ -129,6 +260,7 @@ func (r *JSONRepository) saveToFile() error {
 		return fmt.Errorf("ошибка записи в файл %s: %w", r.file, err)
 	}
 
+	r.unsaved = false
 	return nil
 }
 
@@ -152,31 +284,149 @@ func (r *JSONRepository) loadFromFile() error {
 		return fmt.Errorf("ошибка десериализации данных из файла: %w", err)
 	}
 
-	return nil
+	upgraded, err := upgradeLegacyKeys(r.store)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления формата файла: %w", err)
+	}
+	if !upgraded {
+		return nil
+	}
+
+	if err := os.WriteFile(r.file+".bak", fileData, 0666); err != nil {
+		return fmt.Errorf("ошибка создания резервной копии файла: %w", err)
+	}
+	return r.saveToFile()
 }
 
-// GetUserURLs returns all URLs belonging to a specific user
-func (r *JSONRepository) GetUserURLs(ctx context.Context, userID int) ([]map[string]string, error) {
+// upgradeLegacyKeys rekeys entries of files written before links were stored by bare ID. Those were keyed by the full
+// short URL and always created on BASE_URL, so they move to its domain. Already upgraded entries are left alone, and
+// the store is only changed if every legacy entry can be upgraded. It reports whether anything was rekeyed
+func upgradeLegacyKeys(store map[string]URLData) (bool, error) {
+	upgraded := make(map[string]URLData)
+	var legacyKeys []string
+
+	for key, data := range store {
+		if data.ID != "" {
+			continue
+		}
+
+		shortURL := data.ShortURL
+		if shortURL == "" {
+			shortURL = key
+		}
+		id := shortURL[strings.LastIndex(shortURL, "/")+1:]
+		if id == "" {
+			return false, fmt.Errorf("некорректная короткая ссылка %q", shortURL)
+		}
+		if _, exists := upgraded[id]; exists {
+			return false, fmt.Errorf("идентификатор %q встречается несколько раз", id)
+		}
+
+		upgraded[id] = URLData{UserID: data.UserID, OriginalURL: data.OriginalURL, ID: id}
+		legacyKeys = append(legacyKeys, key)
+	}
+
+	for id := range upgraded {
+		if data, exists := store[id]; exists && data.ID != "" {
+			return false, fmt.Errorf("идентификатор %q встречается несколько раз", id)
+		}
+	}
+
+	for _, key := range legacyKeys {
+		delete(store, key)
+	}
+	for id, data := range upgraded {
+		store[id] = data
+	}
+
+	return len(upgraded) > 0, nil
+}
+
+// GetUserURLs returns the personal URLs of a specific user, or the URLs of the workspace if the filter names one,
+// that match the filter
+func (r *JSONRepository) GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var urls []map[string]string
-	for _, data := range r.store {
-		if data.UserID == userID {
-			urls = append(urls, map[string]string{
-				"short_url":    data.ShortURL,
-				"original_url": data.OriginalURL,
-			})
+	return userLinks(r.store, r.index, userID, filter), nil
+}
+
+// UpdateLink changes the metadata of a link the user may change and returns the updated link
+func (r *JSONRepository) UpdateLink(ctx context.Context, userID int, id string, update domain.LinkUpdate) (domain.Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return domain.Link{}, appErrors.ErrNotFound
+	}
+
+	updated := data.update(update)
+	r.store[id] = updated
+	r.index.remove(data)
+	r.index.add(updated)
+
+	if err := r.saveToFile(); err != nil {
+		return domain.Link{}, fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return updated.link(), nil
+}
+
+// WorkspaceMembers looks up the membership of a user in a workspace for the stores that keep links apart from
+// workspaces
+type WorkspaceMembers interface {
+	GetMember(ctx context.Context, workspaceID string, userID int) (domain.WorkspaceMember, error)
+}
+
+// canEdit reports whether the user may change the link: their personal links and the links of the workspaces they own
+// or edit, as editableBy does for PostgreSQL. Without members no workspace link may be changed
+func canEdit(ctx context.Context, members WorkspaceMembers, data URLData, userID int) bool {
+	if data.Workspace == "" {
+		return data.UserID == userID
+	}
+	if members == nil {
+		return false
+	}
+
+	member, err := members.GetMember(ctx, data.Workspace, userID)
+	return err == nil && (member.Role == domain.RoleOwner || member.Role == domain.RoleEditor)
+}
+
+// userLinks returns the personal links of the user or the links of the filter's workspace matching the filter,
+// using the index when the filter is set
+func userLinks(store map[string]URLData, index *linkIndex, userID int, filter domain.LinkFilter) []domain.Link {
+	var links []domain.Link
+
+	listed := func(data URLData) bool {
+		if filter.Health != "" && domain.HealthState(data.Health) != filter.Health {
+			return false
 		}
+		if filter.Workspace != "" {
+			return data.Workspace == filter.Workspace
+		}
+		return data.Workspace == "" && data.UserID == userID
 	}
 
-	if len(urls) == 0 {
-		return nil, nil
+	ids := index.search(filter)
+	if ids == nil {
+		for _, data := range store {
+			if listed(data) {
+				links = append(links, data.link())
+			}
+		}
+		return links
 	}
 
-	return urls, nil
+	for id := range ids {
+		if data, exists := store[id]; exists && listed(data) {
+			links = append(links, data.link())
+		}
+	}
+	return links
 }
 
+// generateID returns an unused ID, the caller must hold the lock
 func (r *JSONRepository) generateID() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
 
@@ -187,12 +437,306 @@ func (r *JSONRepository) generateID() string {
 		}
 		id := string(randStrBytes)
 
-		r.mu.RLock()
-		_, exists := r.store[id]
-		r.mu.RUnlock()
-
-		if !exists {
+		if _, exists := r.store[id]; !exists {
 			return id
 		}
 	}
 }
+
+// ClaimURLs transfers all URLs of one user to another
+func (r *JSONRepository) ClaimURLs(ctx context.Context, fromUserID, toUserID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, data := range r.store {
+		if data.UserID == fromUserID {
+			data.UserID = toUserID
+			r.store[key] = data
+		}
+	}
+
+	if err := r.saveToFile(); err != nil {
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// MoveLinks moves the links into the workspace, or makes them personal links of their creators if it is empty
+func (r *JSONRepository) MoveLinks(ctx context.Context, ids []string, workspaceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	moveLinks(r.store, ids, workspaceID)
+
+	if err := r.saveToFile(); err != nil {
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// moveLinks sets the workspace of the stored links with the given IDs
+func moveLinks(store map[string]URLData, ids []string, workspaceID string) {
+	for _, id := range ids {
+		if data, exists := store[id]; exists {
+			data.Workspace = workspaceID
+			store[id] = data
+		}
+	}
+}
+
+// GetVariants returns the destinations of an A/B link, none for plain links
+func (r *JSONRepository) GetVariants(ctx context.Context, id string) ([]domain.Variant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]domain.Variant(nil), r.store[id].Variants...), nil
+}
+
+// UserVariants returns the destinations of a link the user may change
+func (r *JSONRepository) UserVariants(ctx context.Context, userID int, id string) ([]domain.Variant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return nil, appErrors.ErrNotFound
+	}
+	return append([]domain.Variant(nil), data.Variants...), nil
+}
+
+// CountVariantClick counts a redirect to the variant at the given position. The count is written to the file later
+func (r *JSONRepository) CountVariantClick(ctx context.Context, id string, variant int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if countVariantClick(r.store, id, variant) {
+		r.unsaved = true
+	}
+
+	return nil
+}
+
+// SetVariants replaces the destinations of a link the user may change. Variants keeping their URL keep their click count
+func (r *JSONRepository) SetVariants(ctx context.Context, userID int, id string, variants []domain.Variant) ([]domain.Variant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return nil, appErrors.ErrNotFound
+	}
+
+	data.Variants = carryClicks(data.Variants, variants)
+	r.store[id] = data
+
+	if err := r.saveToFile(); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return append([]domain.Variant(nil), data.Variants...), nil
+}
+
+// countVariantClick increments the click count of a stored variant and reports whether it exists
+func countVariantClick(store map[string]URLData, id string, variant int) bool {
+	data, exists := store[id]
+	if !exists || variant < 0 || variant >= len(data.Variants) {
+		return false
+	}
+	data.Variants[variant].Clicks++
+	return true
+}
+
+// GetRules returns the redirect rules of a link, none for links that always go to their own destination
+func (r *JSONRepository) GetRules(ctx context.Context, id string) ([]domain.RedirectRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]domain.RedirectRule(nil), r.store[id].Rules...), nil
+}
+
+// UserRules returns the redirect rules of a link the user may change
+func (r *JSONRepository) UserRules(ctx context.Context, userID int, id string) ([]domain.RedirectRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return nil, appErrors.ErrNotFound
+	}
+	return append([]domain.RedirectRule(nil), data.Rules...), nil
+}
+
+// SetRules replaces the redirect rules of a link the user may change
+func (r *JSONRepository) SetRules(ctx context.Context, userID int, id string, rules []domain.RedirectRule) ([]domain.RedirectRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return nil, appErrors.ErrNotFound
+	}
+
+	data.Rules = append([]domain.RedirectRule(nil), rules...)
+	r.store[id] = data
+
+	if err := r.saveToFile(); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return append([]domain.RedirectRule(nil), data.Rules...), nil
+}
+
+// GetQueryOptions returns the query string options of a link, the zero value for links redirecting as is
+func (r *JSONRepository) GetQueryOptions(ctx context.Context, id string) (domain.QueryOptions, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store[id].queryOptions(), nil
+}
+
+// UserQueryOptions returns the query string options of a link the user may change
+func (r *JSONRepository) UserQueryOptions(ctx context.Context, userID int, id string) (domain.QueryOptions, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return domain.QueryOptions{}, appErrors.ErrNotFound
+	}
+	return data.queryOptions(), nil
+}
+
+// SetQueryOptions replaces the query string options of a link the user may change
+func (r *JSONRepository) SetQueryOptions(ctx context.Context, userID int, id string, opts domain.QueryOptions) (domain.QueryOptions, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return domain.QueryOptions{}, appErrors.ErrNotFound
+	}
+
+	data.Query = nil
+	if opts != (domain.QueryOptions{}) {
+		data.Query = &opts
+	}
+	r.store[id] = data
+
+	if err := r.saveToFile(); err != nil {
+		return domain.QueryOptions{}, fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return opts, nil
+}
+
+// LinksToCheck returns up to limit links that have not been checked since checkedBefore, never checked ones first
+func (r *JSONRepository) LinksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]domain.Link, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return linksToCheck(r.store, checkedBefore, limit), nil
+}
+
+// SaveLinkHealth stores the outcome of checks keyed by link ID, replacing earlier ones
+func (r *JSONRepository) SaveLinkHealth(ctx context.Context, health map[string]domain.LinkHealth) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !saveLinkHealth(r.store, health) {
+		return nil
+	}
+
+	if err := r.saveToFile(); err != nil {
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// linksToCheck returns the links checked longest ago, the caller must hold the lock
+func linksToCheck(store map[string]URLData, checkedBefore time.Time, limit int) []domain.Link {
+	var due []URLData
+	for _, data := range store {
+		if data.Health == nil || data.Health.CheckedAt.Before(checkedBefore) {
+			due = append(due, data)
+		}
+	}
+
+	checkedAt := func(data URLData) time.Time {
+		if data.Health == nil {
+			return time.Time{}
+		}
+		return data.Health.CheckedAt
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if a, b := checkedAt(due[i]), checkedAt(due[j]); !a.Equal(b) {
+			return a.Before(b)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	links := make([]domain.Link, 0, len(due))
+	for _, data := range due {
+		links = append(links, domain.Link{ID: data.ID, OriginalURL: data.OriginalURL})
+	}
+	return links
+}
+
+// saveLinkHealth stores the outcome of checks of existing links and reports whether any was stored, the caller must
+// hold the lock
+func saveLinkHealth(store map[string]URLData, health map[string]domain.LinkHealth) bool {
+	saved := false
+	for id, h := range health {
+		if data, exists := store[id]; exists {
+			h := h
+			data.Health = &h
+			store[id] = data
+			saved = true
+		}
+	}
+	return saved
+}
+
+// SavePageMeta stores the metadata of the destination page of a link, replacing earlier metadata
+func (r *JSONRepository) SavePageMeta(ctx context.Context, id string, page domain.PageMeta) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !savePageMeta(r.store, id, page) {
+		return nil
+	}
+
+	if err := r.saveToFile(); err != nil {
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// savePageMeta stores the page metadata of an existing link and reports whether it exists, the caller must hold the lock
+func savePageMeta(store map[string]URLData, id string, page domain.PageMeta) bool {
+	data, exists := store[id]
+	if !exists {
+		return false
+	}
+	data.Page = &page
+	store[id] = data
+	return true
+}
+
+// GetLink returns the short link with the given ID
+func (r *JSONRepository) GetLink(ctx context.Context, id string) (domain.Link, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.store[id]
+	if !exists {
+		return domain.Link{}, appErrors.ErrNotFound
+	}
+	return data.link(), nil
+}
@@ -0,0 +1,275 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/repository"
+)
+
+const legacyStorage = `{
+  "http://localhost:8080/abc123": {"user_id": 1, "original_url": "https://example.com", "short_url": "http://localhost:8080/abc123"},
+  "http://localhost:8080/def456": {"user_id": 2, "original_url": "https://example.org", "short_url": "http://localhost:8080/def456"}
+}`
+
+func TestJSONRepository_UpgradeLegacyFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+	require.NoError(t, os.WriteFile(path, []byte(legacyStorage), 0666))
+
+	repo, err := repository.NewJSONRepository(path)
+	require.NoError(t, err)
+
+	redirect, exists, _ := repo.Get(ctx, "", "abc123")
+	require.True(t, exists)
+	require.Equal(t, "https://example.com", redirect.OriginalURL)
+
+	links, err := repo.GetUserURLs(ctx, 2, domain.LinkFilter{})
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	require.Equal(t, "def456", links[0].ID)
+	require.Equal(t, "", links[0].Domain)
+
+	backup, err := os.ReadFile(path + ".bak")
+	require.NoError(t, err)
+	require.Equal(t, legacyStorage, string(backup))
+
+	upgraded, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(upgraded), "://localhost")
+
+	// Loading the upgraded file again changes nothing
+	require.NoError(t, os.Remove(path+".bak"))
+	repo, err = repository.NewJSONRepository(path)
+	require.NoError(t, err)
+
+	_, exists, _ = repo.Get(ctx, "", "def456")
+	require.True(t, exists)
+	_, err = os.Stat(path + ".bak")
+	require.True(t, os.IsNotExist(err))
+
+	again, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(upgraded), string(again))
+}
+
+func TestJSONRepository_UpgradeConflict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	conflicting := `{
+  "http://old.example.com/abc123": {"user_id": 1, "original_url": "https://example.com"},
+  "http://localhost:8080/abc123": {"user_id": 2, "original_url": "https://example.org"}
+}`
+	require.NoError(t, os.WriteFile(path, []byte(conflicting), 0666))
+
+	_, err := repository.NewJSONRepository(path)
+	require.Error(t, err)
+
+	untouched, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, conflicting, string(untouched))
+}
+
+func TestWorkspaceStore_Persists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.workspaces.json")
+
+	store, err := repository.NewWorkspaceStore(path)
+	require.NoError(t, err)
+
+	ws := domain.Workspace{ID: "ws", Name: "Team", Role: domain.RoleOwner}
+	require.NoError(t, store.CreateWorkspace(ctx, ws, domain.WorkspaceMember{WorkspaceID: "ws", UserID: 1000000, Role: domain.RoleOwner}))
+	require.NoError(t, store.SaveMember(ctx, domain.WorkspaceMember{WorkspaceID: "ws", UserID: 1000001, Role: domain.RoleViewer}))
+	require.ErrorIs(t, store.SaveMember(ctx, domain.WorkspaceMember{WorkspaceID: "other", UserID: 1000001}), appErrors.ErrNotFound)
+
+	store, err = repository.NewWorkspaceStore(path)
+	require.NoError(t, err)
+
+	member, err := store.GetMember(ctx, "ws", 1000001)
+	require.NoError(t, err)
+	require.Equal(t, domain.RoleViewer, member.Role)
+
+	workspaces, err := store.ListWorkspaces(ctx, 1000001)
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+	require.Equal(t, "Team", workspaces[0].Name)
+	require.Equal(t, domain.RoleViewer, workspaces[0].Role)
+
+	require.NoError(t, store.RemoveMember(ctx, "ws", 1000001))
+	_, err = store.GetMember(ctx, "ws", 1000001)
+	require.ErrorIs(t, err, appErrors.ErrNotFound)
+}
+
+func TestModerationStore_Persists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.moderation.json")
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	store, err := repository.NewModerationStore(path)
+	require.NoError(t, err)
+
+	reports := []domain.AbuseReport{
+		{ID: "r1", LinkID: "abc", Reason: domain.ReportSpam, ReporterIP: "192.0.2.1", CreatedAt: at},
+		{ID: "r2", LinkID: "abc", Reason: domain.ReportPhishing, CreatedAt: at.Add(time.Hour)},
+		{ID: "r3", LinkID: "def", Reason: domain.ReportSpam, CreatedAt: at.Add(2 * time.Hour)},
+	}
+	for _, report := range reports {
+		require.NoError(t, store.SaveReport(ctx, report))
+	}
+
+	queue, err := store.ModerationQueue(ctx, domain.QueuePending)
+	require.NoError(t, err)
+	require.Len(t, queue, 2)
+	require.Equal(t, "abc", queue[0].LinkID)
+	require.Equal(t, 2, queue[0].PendingReports)
+	require.Equal(t, []string{domain.ReportPhishing, domain.ReportSpam}, queue[0].Reasons)
+	require.Equal(t, at, *queue[0].FirstReportedAt)
+
+	disable := domain.ModerationAction{ID: "m1", LinkID: "abc", ModeratorID: 1000000, Action: domain.ModerationDisable, CreatedAt: at.Add(3 * time.Hour)}
+	require.NoError(t, store.SaveModerationAction(ctx, disable, true))
+
+	store, err = repository.NewModerationStore(path)
+	require.NoError(t, err)
+
+	disabled, err := store.IsLinkDisabled(ctx, "abc")
+	require.NoError(t, err)
+	require.True(t, disabled)
+
+	queue, err = store.ModerationQueue(ctx, domain.QueuePending)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	require.Equal(t, "def", queue[0].LinkID)
+
+	queue, err = store.ModerationQueue(ctx, domain.QueueDisabled)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	require.Equal(t, "abc", queue[0].LinkID)
+	require.Zero(t, queue[0].PendingReports)
+
+	saved, err := store.ListReports(ctx, "abc")
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+	require.Equal(t, "r2", saved[0].ID)
+	require.NotNil(t, saved[1].ResolvedAt)
+	require.Equal(t, "192.0.2.1", saved[1].ReporterIP)
+
+	clearAction := domain.ModerationAction{ID: "m2", LinkID: "abc", ModeratorID: 1000000, Action: domain.ModerationClear, CreatedAt: at.Add(4 * time.Hour)}
+	require.NoError(t, store.SaveModerationAction(ctx, clearAction, false))
+
+	disabled, err = store.IsLinkDisabled(ctx, "abc")
+	require.NoError(t, err)
+	require.False(t, disabled)
+
+	actions, err := store.ListModerationActions(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, actions, 2)
+	require.Equal(t, domain.ModerationClear, actions[0].Action)
+}
+
+func TestJSONRepository_RulesPersist(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	repo, err := repository.NewJSONRepository(path)
+	require.NoError(t, err)
+
+	id, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+
+	rules := []domain.RedirectRule{{Platforms: []string{domain.PlatformIOS}, URL: "https://apps.apple.com/app/id1"}}
+	_, err = repo.SetRules(ctx, 2, id, rules)
+	require.ErrorIs(t, err, appErrors.ErrNotFound)
+	_, err = repo.SetRules(ctx, 1, id, rules)
+	require.NoError(t, err)
+
+	repo, err = repository.NewJSONRepository(path)
+	require.NoError(t, err)
+
+	stored, err := repo.GetRules(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, rules, stored)
+
+	redirect, exists, _ := repo.Get(ctx, "", id)
+	require.True(t, exists)
+	require.Equal(t, domain.Redirect{OriginalURL: "https://example.com", Rules: rules}, redirect)
+}
+
+func TestAuditStore_Persists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.audit.json")
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	store, err := repository.NewAuditStore(path)
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveAuditEntries(ctx, []domain.AuditEntry{
+		{ID: "e1", CreatedAt: at, ActorID: 1000000, IP: "192.0.2.1", Action: domain.AuditCreate, LinkID: "abc",
+			After: &domain.AuditState{OriginalURL: "https://example.com"}},
+	}))
+	require.NoError(t, store.SaveAuditEntries(ctx, []domain.AuditEntry{
+		{ID: "e2", CreatedAt: at.Add(time.Hour), ActorID: 1000000, Action: domain.AuditUpdate, LinkID: "abc",
+			Before: &domain.AuditState{OriginalURL: "https://example.com"},
+			After:  &domain.AuditState{OriginalURL: "https://example.com", Title: "Example"}},
+		{ID: "e3", CreatedAt: at.Add(2 * time.Hour), ActorID: 1000001, Action: domain.AuditCreate, LinkID: "def"},
+	}))
+
+	store, err = repository.NewAuditStore(path)
+	require.NoError(t, err)
+
+	entries, err := store.ListAuditEntries(ctx, domain.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "e3", entries[0].ID)
+	require.Equal(t, "Example", entries[1].After.Title)
+
+	entries, err = store.ListAuditEntries(ctx, domain.AuditFilter{LinkID: "abc", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "e2", entries[0].ID)
+
+	entries, err = store.ListAuditEntries(ctx, domain.AuditFilter{Action: domain.AuditCreate, To: at.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "e1", entries[0].ID)
+}
+
+func TestJSONRepository_VariantClicksFlush(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	repo, err := repository.NewJSONRepository(path)
+	require.NoError(t, err)
+
+	id, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+	_, err = repo.SetVariants(ctx, 1, id, []domain.Variant{{URL: "https://a.example.com", Weight: 1}})
+	require.NoError(t, err)
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.CountVariantClick(ctx, id, 0))
+	require.NoError(t, repo.CountVariantClick(ctx, id, 0))
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, written, current, "clicks must not rewrite the file")
+
+	variants, err := repo.GetVariants(ctx, id)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, variants[0].Clicks)
+
+	require.NoError(t, repo.Close())
+
+	reopened, err := repository.NewJSONRepository(path)
+	require.NoError(t, err)
+	variants, err = reopened.GetVariants(ctx, id)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, variants[0].Clicks)
+}
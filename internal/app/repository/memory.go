@@ -2,58 +2,100 @@ package repository
 
 import (
 	"context"
-	"fmt"
 	"math/rand"
+	"sync"
+	"time"
 
-	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
 )
 
 // MemoryRepository is a storage implementation that keeps data in memory.
 type MemoryRepository struct {
-	store map[string]string
-	cfg   *config.Config
+	store   map[string]URLData
+	index   *linkIndex
+	members WorkspaceMembers
+	mu      sync.RWMutex
 }
 
 // NewMemoryRepository creates a new in-memory repository.
-func NewMemoryRepository(cfg *config.Config) *MemoryRepository {
+func NewMemoryRepository() *MemoryRepository {
 	return &MemoryRepository{
-		store: make(map[string]string),
-		cfg:   cfg,
+		store: make(map[string]URLData),
+		index: newLinkIndex(),
 	}
 }
 
-// Save stores URL and returns its shortened version.
-func (r *MemoryRepository) Save(ctx context.Context, userID int, url string) (string, error) {
+// UseWorkspaces sets where the roles of workspace members are looked up. Until it is called only the creators of
+// personal links may change them
+func (r *MemoryRepository) UseWorkspaces(members WorkspaceMembers) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.members = members
+}
+
+// Save stores URL with its metadata on the domain and returns the ID of its short link.
+func (r *MemoryRepository) Save(ctx context.Context, userID int, host, url string, meta domain.LinkMeta) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	id := r.generateID()
-	shortenedURL := fmt.Sprintf("%s/%s", r.cfg.BaseURL, id)
-	r.store[shortenedURL] = url
+	data := URLData{
+		UserID:      userID,
+		OriginalURL: url,
+		ID:          id,
+		Domain:      host,
+		Workspace:   meta.Workspace,
+		Title:       meta.Title,
+		Notes:       meta.Notes,
+		Tags:        meta.Tags,
+	}
+	r.store[id] = data
+	r.index.add(data)
 
-	return shortenedURL, nil
+	return id, nil
 }
 
-// Get returns the original URL by its shortened identifier
-func (r *MemoryRepository) Get(ctx context.Context, id string) (string, bool, bool) {
+// Get returns the original URL, the redirect rules and the query string options by the domain and ID of its short link
+func (r *MemoryRepository) Get(ctx context.Context, host, id string) (domain.Redirect, bool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	url, exists := r.store[id]
-	if !exists {
-		return "", false, false
+	if !exists || url.Domain != host {
+		return domain.Redirect{}, false, false
 	}
-	return url, true, false
+	return domain.Redirect{
+		OriginalURL: url.OriginalURL,
+		Rules:       append([]domain.RedirectRule(nil), url.Rules...),
+		Query:       url.queryOptions(),
+	}, true, false
 }
 
-// SaveBatch stores multiple URLs in a single call
-func (r *MemoryRepository) SaveBatch(ctx context.Context, userID int, urls map[string]string) (map[string]string, error) {
+// SaveBatch stores multiple URLs on the domain in a single call
+func (r *MemoryRepository) SaveBatch(ctx context.Context, userID int, host string, urls map[string]string) (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	result := make(map[string]string)
 	for correlationID, originalURL := range urls {
 		id := r.generateID()
-		shortenedURL := fmt.Sprintf("%s/%s", r.cfg.BaseURL, id)
-
-		r.store[shortenedURL] = originalURL
+		data := URLData{
+			UserID:      userID,
+			OriginalURL: originalURL,
+			ID:          id,
+			Domain:      host,
+		}
+		r.store[id] = data
+		r.index.add(data)
 		result[correlationID] = id
 	}
 
 	return result, nil
 }
 
+// generateID returns an unused ID, the caller must hold the lock
 func (r *MemoryRepository) generateID() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
 
@@ -64,15 +106,218 @@ func (r *MemoryRepository) generateID() string {
 		}
 		id := string(randStrBytes)
 
-		_, exists := r.store[id]
-
-		if !exists {
+		if _, exists := r.store[id]; !exists {
 			return id
 		}
 	}
 }
 
-// GetUserURLs returns all URLs belonging to a specific user
-func (r *MemoryRepository) GetUserURLs(ctx context.Context, userID int) ([]map[string]string, error) {
-	return []map[string]string{}, nil
+// GetUserURLs returns the personal URLs of a specific user, or the URLs of the workspace if the filter names one,
+// that match the filter
+func (r *MemoryRepository) GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return userLinks(r.store, r.index, userID, filter), nil
+}
+
+// UpdateLink changes the metadata of a link the user may change and returns the updated link
+func (r *MemoryRepository) UpdateLink(ctx context.Context, userID int, id string, update domain.LinkUpdate) (domain.Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return domain.Link{}, appErrors.ErrNotFound
+	}
+
+	updated := data.update(update)
+	r.store[id] = updated
+	r.index.remove(data)
+	r.index.add(updated)
+
+	return updated.link(), nil
+}
+
+// ClaimURLs transfers all URLs of one user to another
+func (r *MemoryRepository) ClaimURLs(ctx context.Context, fromUserID, toUserID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, data := range r.store {
+		if data.UserID == fromUserID {
+			data.UserID = toUserID
+			r.store[key] = data
+		}
+	}
+
+	return nil
+}
+
+// MoveLinks moves the links into the workspace, or makes them personal links of their creators if it is empty
+func (r *MemoryRepository) MoveLinks(ctx context.Context, ids []string, workspaceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	moveLinks(r.store, ids, workspaceID)
+
+	return nil
+}
+
+// GetVariants returns the destinations of an A/B link, none for plain links
+func (r *MemoryRepository) GetVariants(ctx context.Context, id string) ([]domain.Variant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]domain.Variant(nil), r.store[id].Variants...), nil
+}
+
+// UserVariants returns the destinations of a link the user may change
+func (r *MemoryRepository) UserVariants(ctx context.Context, userID int, id string) ([]domain.Variant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return nil, appErrors.ErrNotFound
+	}
+	return append([]domain.Variant(nil), data.Variants...), nil
+}
+
+// CountVariantClick counts a redirect to the variant at the given position
+func (r *MemoryRepository) CountVariantClick(ctx context.Context, id string, variant int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	countVariantClick(r.store, id, variant)
+	return nil
+}
+
+// SetVariants replaces the destinations of a link the user may change. Variants keeping their URL keep their click count
+func (r *MemoryRepository) SetVariants(ctx context.Context, userID int, id string, variants []domain.Variant) ([]domain.Variant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return nil, appErrors.ErrNotFound
+	}
+
+	data.Variants = carryClicks(data.Variants, variants)
+	r.store[id] = data
+
+	return append([]domain.Variant(nil), data.Variants...), nil
+}
+
+// GetRules returns the redirect rules of a link, none for links that always go to their own destination
+func (r *MemoryRepository) GetRules(ctx context.Context, id string) ([]domain.RedirectRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]domain.RedirectRule(nil), r.store[id].Rules...), nil
+}
+
+// UserRules returns the redirect rules of a link the user may change
+func (r *MemoryRepository) UserRules(ctx context.Context, userID int, id string) ([]domain.RedirectRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return nil, appErrors.ErrNotFound
+	}
+	return append([]domain.RedirectRule(nil), data.Rules...), nil
+}
+
+// SetRules replaces the redirect rules of a link the user may change
+func (r *MemoryRepository) SetRules(ctx context.Context, userID int, id string, rules []domain.RedirectRule) ([]domain.RedirectRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return nil, appErrors.ErrNotFound
+	}
+
+	data.Rules = append([]domain.RedirectRule(nil), rules...)
+	r.store[id] = data
+
+	return append([]domain.RedirectRule(nil), data.Rules...), nil
+}
+
+// GetQueryOptions returns the query string options of a link, the zero value for links redirecting as is
+func (r *MemoryRepository) GetQueryOptions(ctx context.Context, id string) (domain.QueryOptions, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store[id].queryOptions(), nil
+}
+
+// UserQueryOptions returns the query string options of a link the user may change
+func (r *MemoryRepository) UserQueryOptions(ctx context.Context, userID int, id string) (domain.QueryOptions, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return domain.QueryOptions{}, appErrors.ErrNotFound
+	}
+	return data.queryOptions(), nil
+}
+
+// SetQueryOptions replaces the query string options of a link the user may change
+func (r *MemoryRepository) SetQueryOptions(ctx context.Context, userID int, id string, opts domain.QueryOptions) (domain.QueryOptions, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, exists := r.store[id]
+	if !exists || !canEdit(ctx, r.members, data, userID) {
+		return domain.QueryOptions{}, appErrors.ErrNotFound
+	}
+
+	data.Query = nil
+	if opts != (domain.QueryOptions{}) {
+		data.Query = &opts
+	}
+	r.store[id] = data
+
+	return opts, nil
+}
+
+// LinksToCheck returns up to limit links that have not been checked since checkedBefore, never checked ones first
+func (r *MemoryRepository) LinksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]domain.Link, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return linksToCheck(r.store, checkedBefore, limit), nil
+}
+
+// SaveLinkHealth stores the outcome of checks keyed by link ID, replacing earlier ones
+func (r *MemoryRepository) SaveLinkHealth(ctx context.Context, health map[string]domain.LinkHealth) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saveLinkHealth(r.store, health)
+	return nil
+}
+
+// SavePageMeta stores the metadata of the destination page of a link, replacing earlier metadata
+func (r *MemoryRepository) SavePageMeta(ctx context.Context, id string, page domain.PageMeta) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	savePageMeta(r.store, id, page)
+	return nil
+}
+
+// GetLink returns the short link with the given ID
+func (r *MemoryRepository) GetLink(ctx context.Context, id string) (domain.Link, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, exists := r.store[id]
+	if !exists {
+		return domain.Link{}, appErrors.ErrNotFound
+	}
+	return data.link(), nil
 }
@@ -0,0 +1,260 @@
+package repository_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/repository"
+)
+
+func linkIDs(links []domain.Link) []string {
+	ids := make([]string, 0, len(links))
+	for _, link := range links {
+		ids = append(ids, link.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestMemoryRepository_Search(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	docs, err := repo.Save(ctx, 1, "", "https://golang.org/doc", domain.LinkMeta{Title: "Go documentation", Tags: []string{"docs", "go"}})
+	require.NoError(t, err)
+	blog, err := repo.Save(ctx, 1, "", "https://blog.example.com/post", domain.LinkMeta{Notes: "Read on the weekend", Tags: []string{"reading"}})
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, 2, "", "https://golang.org/pkg", domain.LinkMeta{Title: "Go packages", Tags: []string{"docs"}})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name   string
+		filter domain.LinkFilter
+		want   []string
+	}{
+		{name: "no filter", filter: domain.LinkFilter{}, want: []string{docs, blog}},
+		{name: "tag", filter: domain.LinkFilter{Tag: "Docs"}, want: []string{docs}},
+		{name: "title word prefix", filter: domain.LinkFilter{Query: "docu"}, want: []string{docs}},
+		{name: "original URL", filter: domain.LinkFilter{Query: "example.com"}, want: []string{blog}},
+		{name: "notes", filter: domain.LinkFilter{Query: "weekend"}, want: []string{blog}},
+		{name: "every word must match", filter: domain.LinkFilter{Query: "go weekend"}, want: []string{}},
+		{name: "tag and query", filter: domain.LinkFilter{Tag: "reading", Query: "golang"}, want: []string{}},
+		{name: "unknown tag", filter: domain.LinkFilter{Tag: "missing"}, want: []string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			links, err := repo.GetUserURLs(ctx, 1, tc.filter)
+			require.NoError(t, err)
+			want := append([]string{}, tc.want...)
+			sort.Strings(want)
+			require.Equal(t, want, linkIDs(links))
+		})
+	}
+
+	t.Run("update reindexes the link", func(t *testing.T) {
+		title := "Weekly digest"
+		tags := []string{"news"}
+		link, err := repo.UpdateLink(ctx, 1, blog, domain.LinkUpdate{Title: &title, Tags: &tags})
+		require.NoError(t, err)
+		require.Equal(t, "Read on the weekend", link.Notes)
+
+		links, err := repo.GetUserURLs(ctx, 1, domain.LinkFilter{Tag: "reading"})
+		require.NoError(t, err)
+		require.Empty(t, links)
+
+		links, err = repo.GetUserURLs(ctx, 1, domain.LinkFilter{Tag: "news", Query: "digest"})
+		require.NoError(t, err)
+		require.Equal(t, []string{blog}, linkIDs(links))
+	})
+
+	t.Run("update of someone else's link", func(t *testing.T) {
+		title := "Mine"
+		_, err := repo.UpdateLink(ctx, 2, docs, domain.LinkUpdate{Title: &title})
+		require.ErrorIs(t, err, appErrors.ErrNotFound)
+	})
+}
+
+func TestMemoryRepository_Workspaces(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	personal, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+	shared, err := repo.Save(ctx, 2, "", "https://example.org", domain.LinkMeta{Workspace: "ws", Tags: []string{"team"}})
+	require.NoError(t, err)
+
+	links, err := repo.GetUserURLs(ctx, 1, domain.LinkFilter{})
+	require.NoError(t, err)
+	require.Equal(t, []string{personal}, linkIDs(links))
+
+	links, err = repo.GetUserURLs(ctx, 1, domain.LinkFilter{Workspace: "ws", Tag: "team"})
+	require.NoError(t, err)
+	require.Equal(t, []string{shared}, linkIDs(links))
+	require.Equal(t, 2, links[0].UserID)
+
+	require.NoError(t, repo.MoveLinks(ctx, []string{personal}, "ws"))
+
+	links, err = repo.GetUserURLs(ctx, 1, domain.LinkFilter{})
+	require.NoError(t, err)
+	require.Empty(t, links)
+
+	links, err = repo.GetUserURLs(ctx, 2, domain.LinkFilter{Workspace: "ws"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{personal, shared}, linkIDs(links))
+}
+
+func TestMemoryRepository_WorkspaceEditors(t *testing.T) {
+	ctx := context.Background()
+	const owner, editor, viewer, outsider = 1, 2, 3, 4
+
+	workspaces, err := repository.NewWorkspaceStore("")
+	require.NoError(t, err)
+	require.NoError(t, workspaces.CreateWorkspace(ctx, domain.Workspace{ID: "ws", Name: "Team"},
+		domain.WorkspaceMember{WorkspaceID: "ws", UserID: owner, Role: domain.RoleOwner}))
+	require.NoError(t, workspaces.SaveMember(ctx, domain.WorkspaceMember{WorkspaceID: "ws", UserID: editor, Role: domain.RoleEditor}))
+	require.NoError(t, workspaces.SaveMember(ctx, domain.WorkspaceMember{WorkspaceID: "ws", UserID: viewer, Role: domain.RoleViewer}))
+
+	repo := repository.NewMemoryRepository()
+	repo.UseWorkspaces(workspaces)
+
+	shared, err := repo.Save(ctx, editor, "", "https://example.org", domain.LinkMeta{Workspace: "ws"})
+	require.NoError(t, err)
+	mine, err := repo.Save(ctx, owner, "", "https://example.com", domain.LinkMeta{Workspace: "ws"})
+	require.NoError(t, err)
+
+	change := func(userID int, id string) error {
+		title := "Changed"
+		if _, err := repo.UpdateLink(ctx, userID, id, domain.LinkUpdate{Title: &title}); err != nil {
+			return err
+		}
+		if _, err := repo.SetVariants(ctx, userID, id, []domain.Variant{{URL: "https://example.net", Weight: 1}}); err != nil {
+			return err
+		}
+		if _, err := repo.SetRules(ctx, userID, id, []domain.RedirectRule{}); err != nil {
+			return err
+		}
+		_, err := repo.SetQueryOptions(ctx, userID, id, domain.QueryOptions{Forward: true})
+		return err
+	}
+
+	t.Run("editor changes a teammate's link", func(t *testing.T) {
+		require.NoError(t, change(editor, mine))
+
+		link, err := repo.UpdateLink(ctx, editor, mine, domain.LinkUpdate{})
+		require.NoError(t, err)
+		require.Equal(t, owner, link.UserID, "the creator stays the same")
+	})
+
+	t.Run("owner changes an editor's link", func(t *testing.T) {
+		require.NoError(t, change(owner, shared))
+	})
+
+	t.Run("viewer and outsider", func(t *testing.T) {
+		require.ErrorIs(t, change(viewer, shared), appErrors.ErrNotFound)
+		require.ErrorIs(t, change(outsider, shared), appErrors.ErrNotFound)
+		_, err := repo.UserRules(ctx, viewer, shared)
+		require.ErrorIs(t, err, appErrors.ErrNotFound)
+	})
+
+	t.Run("removed member", func(t *testing.T) {
+		require.NoError(t, workspaces.RemoveMember(ctx, "ws", editor))
+
+		require.ErrorIs(t, change(editor, shared), appErrors.ErrNotFound, "creators lose their links with the membership")
+		_, err := repo.UserQueryOptions(ctx, editor, shared)
+		require.ErrorIs(t, err, appErrors.ErrNotFound)
+		_, err = repo.UserVariants(ctx, editor, shared)
+		require.ErrorIs(t, err, appErrors.ErrNotFound)
+	})
+}
+
+func TestMemoryRepository_QueryOptions(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	id, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+
+	opts := domain.QueryOptions{UTM: domain.UTM{Source: "newsletter"}, Forward: true}
+	_, err = repo.SetQueryOptions(ctx, 2, id, opts)
+	require.ErrorIs(t, err, appErrors.ErrNotFound)
+	_, err = repo.SetQueryOptions(ctx, 1, id, opts)
+	require.NoError(t, err)
+
+	stored, err := repo.GetQueryOptions(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, opts, stored)
+
+	redirect, exists, _ := repo.Get(ctx, "", id)
+	require.True(t, exists)
+	require.Equal(t, opts, redirect.Query)
+
+	_, err = repo.SetQueryOptions(ctx, 1, id, domain.QueryOptions{})
+	require.NoError(t, err)
+	stored, err = repo.UserQueryOptions(ctx, 1, id)
+	require.NoError(t, err)
+	require.Zero(t, stored)
+}
+
+func TestMemoryRepository_LinkHealth(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	ok, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+	broken, err := repo.Save(ctx, 1, "", "https://example.org", domain.LinkMeta{})
+	require.NoError(t, err)
+	unchecked, err := repo.Save(ctx, 1, "", "https://example.net", domain.LinkMeta{})
+	require.NoError(t, err)
+
+	due, err := repo.LinksToCheck(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{ok, broken, unchecked}, linkIDs(due))
+
+	checkedAt := time.Now().UTC()
+	require.NoError(t, repo.SaveLinkHealth(ctx, map[string]domain.LinkHealth{
+		ok:     {Status: 200, CheckedAt: checkedAt},
+		broken: {Status: 404, Redirects: []string{"https://example.org/"}, CheckedAt: checkedAt.Add(-time.Hour)},
+	}))
+
+	due, err = repo.LinksToCheck(ctx, checkedAt.Add(time.Minute), 2)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	require.Equal(t, unchecked, due[0].ID)
+	require.Equal(t, broken, due[1].ID)
+
+	links, err := repo.GetUserURLs(ctx, 1, domain.LinkFilter{Health: domain.HealthBroken})
+	require.NoError(t, err)
+	require.Equal(t, []string{broken}, linkIDs(links))
+	require.Equal(t, 404, links[0].Health.Status)
+
+	links, err = repo.GetUserURLs(ctx, 1, domain.LinkFilter{Health: domain.HealthUnchecked})
+	require.NoError(t, err)
+	require.Equal(t, []string{unchecked}, linkIDs(links))
+}
+
+func TestMemoryRepository_PageMeta(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	id, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+
+	links, err := repo.GetUserURLs(ctx, 1, domain.LinkFilter{})
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	require.Nil(t, links[0].Page)
+
+	page := domain.PageMeta{Title: "Example", Favicon: "https://example.com/favicon.ico", FetchedAt: time.Now().UTC()}
+	require.NoError(t, repo.SavePageMeta(ctx, id, page))
+	require.NoError(t, repo.SavePageMeta(ctx, "missing", page))
+
+	links, err = repo.GetUserURLs(ctx, 1, domain.LinkFilter{})
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	require.Equal(t, &page, links[0].Page)
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// ModerationRepository — repository for abuse reports, disabled links and moderator decisions in PostgreSQL.
+type ModerationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewModerationRepository creates a new ModerationRepository instance with the given connection pool.
+func NewModerationRepository(db *pgxpool.Pool) (*ModerationRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &ModerationRepository{db: db}, nil
+}
+
+// SaveReport stores a new abuse report
+func (r *ModerationRepository) SaveReport(ctx context.Context, report domain.AbuseReport) error {
+	query := `INSERT INTO abuse_reports (id, short, reason, details, reporter_ip, created_at) VALUES ($1, $2, $3, $4, $5, $6);`
+
+	_, err := r.db.Exec(ctx, query, report.ID, report.LinkID, report.Reason, report.Details, report.ReporterIP, report.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении жалобы: %w", err)
+	}
+
+	return nil
+}
+
+// ListReports returns all reports of the link, newest first
+func (r *ModerationRepository) ListReports(ctx context.Context, linkID string) ([]domain.AbuseReport, error) {
+	query := `SELECT id, short, reason, details, reporter_ip, created_at, resolved_at
+			  FROM abuse_reports WHERE short = $1 ORDER BY created_at DESC;`
+
+	rows, err := r.db.Query(ctx, query, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении жалоб: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []domain.AbuseReport
+	for rows.Next() {
+		var report domain.AbuseReport
+		if err := rows.Scan(&report.ID, &report.LinkID, &report.Reason, &report.Details, &report.ReporterIP,
+			&report.CreatedAt, &report.ResolvedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании жалобы: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// ModerationQueue returns the links with pending reports, most reported first, or the disabled links, most recently
+// disabled first. Only the moderation fields of the cases are set
+func (r *ModerationRepository) ModerationQueue(ctx context.Context, queue string) ([]domain.ModerationCase, error) {
+	query := `SELECT c.short, d.short IS NOT NULL, COALESCE(p.pending, 0), COALESCE(p.reasons, '{}'), p.first_at, p.last_at
+			  FROM (
+			    SELECT short FROM abuse_reports WHERE $1 = 'pending' AND resolved_at IS NULL
+			    UNION
+			    SELECT short FROM disabled_links WHERE $1 = 'disabled'
+			  ) c
+			  LEFT JOIN disabled_links d ON d.short = c.short
+			  LEFT JOIN (
+			    SELECT short, COUNT(*) AS pending, array_agg(DISTINCT reason) AS reasons,
+			           MIN(created_at) AS first_at, MAX(created_at) AS last_at
+			    FROM abuse_reports WHERE resolved_at IS NULL GROUP BY short
+			  ) p ON p.short = c.short
+			  ORDER BY COALESCE(p.pending, 0) DESC, p.last_at DESC NULLS LAST, d.disabled_at DESC, c.short;`
+
+	rows, err := r.db.Query(ctx, query, queue)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении очереди модерации: %w", err)
+	}
+	defer rows.Close()
+
+	var cases []domain.ModerationCase
+	for rows.Next() {
+		var c domain.ModerationCase
+		if err := rows.Scan(&c.LinkID, &c.Disabled, &c.PendingReports, &c.Reasons, &c.FirstReportedAt, &c.LastReportedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании очереди модерации: %w", err)
+		}
+		cases = append(cases, c)
+	}
+
+	return cases, rows.Err()
+}
+
+// SaveModerationAction records the decision of a moderator, resolves the pending reports of the link and disables
+// or enables it
+func (r *ModerationRepository) SaveModerationAction(ctx context.Context, action domain.ModerationAction, disabled bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && err == nil {
+			err = fmt.Errorf("ошибка при откате транзакции: %w", rollbackErr)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `INSERT INTO moderation_actions (id, short, moderator_id, action, note, created_at) VALUES ($1, $2, $3, $4, $5, $6);`,
+		action.ID, action.LinkID, action.ModeratorID, action.Action, action.Note, action.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении решения модератора: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE abuse_reports SET resolved_at = $2 WHERE short = $1 AND resolved_at IS NULL;`, action.LinkID, action.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при закрытии жалоб: %w", err)
+	}
+
+	if disabled {
+		_, err = tx.Exec(ctx, `INSERT INTO disabled_links (short, disabled_at) VALUES ($1, $2) ON CONFLICT (short) DO NOTHING;`,
+			action.LinkID, action.CreatedAt)
+	} else {
+		_, err = tx.Exec(ctx, `DELETE FROM disabled_links WHERE short = $1;`, action.LinkID)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении блокировки URL: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при завершении транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// ListModerationActions returns the decisions taken on the link, or on all links if linkID is empty, newest first
+func (r *ModerationRepository) ListModerationActions(ctx context.Context, linkID string) ([]domain.ModerationAction, error) {
+	query := `SELECT id, short, moderator_id, action, note, created_at
+			  FROM moderation_actions WHERE $1 = '' OR short = $1 ORDER BY created_at DESC;`
+
+	rows, err := r.db.Query(ctx, query, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении решений модераторов: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []domain.ModerationAction
+	for rows.Next() {
+		var action domain.ModerationAction
+		if err := rows.Scan(&action.ID, &action.LinkID, &action.ModeratorID, &action.Action, &action.Note, &action.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании решения модератора: %w", err)
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, rows.Err()
+}
+
+// IsLinkDisabled reports whether moderators disabled the link
+func (r *ModerationRepository) IsLinkDisabled(ctx context.Context, linkID string) (bool, error) {
+	var disabled bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM disabled_links WHERE short = $1);`, linkID).Scan(&disabled)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке блокировки URL: %w", err)
+	}
+
+	return disabled, nil
+}
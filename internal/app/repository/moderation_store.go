@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// reportRecord is the on-disk form of domain.AbuseReport, which hides the reporter IP from JSON responses
+type reportRecord struct {
+	domain.AbuseReport
+	ReporterIP string `json:"reporter_ip"`
+}
+
+type moderationFile struct {
+	Reports  []reportRecord            `json:"reports"`
+	Disabled map[string]time.Time      `json:"disabled"`
+	Actions  []domain.ModerationAction `json:"actions"`
+}
+
+// ModerationStore keeps abuse reports, disabled links and moderator decisions in memory and, when a file path is given,
+// mirrors them to a JSON file
+type ModerationStore struct {
+	file     string
+	reports  []domain.AbuseReport
+	disabled map[string]time.Time
+	actions  []domain.ModerationAction
+	mu       sync.RWMutex
+}
+
+// NewModerationStore creates a new moderation store and loads its data from the file if it is set
+func NewModerationStore(filePath string) (*ModerationStore, error) {
+	s := &ModerationStore{
+		file:     filePath,
+		disabled: make(map[string]time.Time),
+	}
+
+	if filePath == "" {
+		return s, nil
+	}
+
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	if len(fileData) == 0 {
+		return s, nil
+	}
+
+	var data moderationFile
+	if err := json.Unmarshal(fileData, &data); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации данных из файла: %w", err)
+	}
+
+	for _, rec := range data.Reports {
+		report := rec.AbuseReport
+		report.ReporterIP = rec.ReporterIP
+		s.reports = append(s.reports, report)
+	}
+	for id, at := range data.Disabled {
+		s.disabled[id] = at
+	}
+	s.actions = data.Actions
+
+	return s, nil
+}
+
+// SaveReport stores a new abuse report
+func (s *ModerationStore) SaveReport(ctx context.Context, report domain.AbuseReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reports = append(s.reports, report)
+
+	if err := s.saveToFile(); err != nil {
+		s.reports = s.reports[:len(s.reports)-1]
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// ListReports returns all reports of the link, newest first
+func (s *ModerationStore) ListReports(ctx context.Context, linkID string) ([]domain.AbuseReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var reports []domain.AbuseReport
+	for _, report := range s.reports {
+		if report.LinkID == linkID {
+			reports = append(reports, report)
+		}
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].CreatedAt.After(reports[j].CreatedAt)
+	})
+
+	return reports, nil
+}
+
+// ModerationQueue returns the links with pending reports, most reported first, or the disabled links, most recently
+// disabled first. Only the moderation fields of the cases are set
+func (s *ModerationStore) ModerationQueue(ctx context.Context, queue string) ([]domain.ModerationCase, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cases := make(map[string]*domain.ModerationCase)
+	caseOf := func(linkID string) *domain.ModerationCase {
+		c, ok := cases[linkID]
+		if !ok {
+			_, disabled := s.disabled[linkID]
+			c = &domain.ModerationCase{LinkID: linkID, Disabled: disabled, Reasons: []string{}}
+			cases[linkID] = c
+		}
+		return c
+	}
+
+	if queue == domain.QueueDisabled {
+		for linkID := range s.disabled {
+			caseOf(linkID)
+		}
+	}
+
+	for i := range s.reports {
+		report := s.reports[i]
+		if report.ResolvedAt != nil {
+			continue
+		}
+		c, listed := cases[report.LinkID]
+		if !listed {
+			if queue != domain.QueuePending {
+				continue
+			}
+			c = caseOf(report.LinkID)
+		}
+
+		c.PendingReports++
+		if !slices.Contains(c.Reasons, report.Reason) {
+			c.Reasons = append(c.Reasons, report.Reason)
+		}
+		if c.FirstReportedAt == nil || report.CreatedAt.Before(*c.FirstReportedAt) {
+			c.FirstReportedAt = &report.CreatedAt
+		}
+		if c.LastReportedAt == nil || report.CreatedAt.After(*c.LastReportedAt) {
+			c.LastReportedAt = &report.CreatedAt
+		}
+	}
+
+	result := make([]domain.ModerationCase, 0, len(cases))
+	for _, c := range cases {
+		sort.Strings(c.Reasons)
+		result = append(result, *c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.PendingReports != b.PendingReports {
+			return a.PendingReports > b.PendingReports
+		}
+		if queue == domain.QueueDisabled && !s.disabled[a.LinkID].Equal(s.disabled[b.LinkID]) {
+			return s.disabled[a.LinkID].After(s.disabled[b.LinkID])
+		}
+		if a.LastReportedAt != nil && b.LastReportedAt != nil && !a.LastReportedAt.Equal(*b.LastReportedAt) {
+			return a.LastReportedAt.After(*b.LastReportedAt)
+		}
+		return a.LinkID < b.LinkID
+	})
+
+	return result, nil
+}
+
+// SaveModerationAction records the decision of a moderator, resolves the pending reports of the link and disables
+// or enables it
+func (s *ModerationStore) SaveModerationAction(ctx context.Context, action domain.ModerationAction, disabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resolved []int
+	for i := range s.reports {
+		if s.reports[i].LinkID == action.LinkID && s.reports[i].ResolvedAt == nil {
+			resolvedAt := action.CreatedAt
+			s.reports[i].ResolvedAt = &resolvedAt
+			resolved = append(resolved, i)
+		}
+	}
+
+	previous, wasDisabled := s.disabled[action.LinkID]
+	if disabled {
+		if !wasDisabled {
+			s.disabled[action.LinkID] = action.CreatedAt
+		}
+	} else {
+		delete(s.disabled, action.LinkID)
+	}
+
+	s.actions = append(s.actions, action)
+
+	if err := s.saveToFile(); err != nil {
+		for _, i := range resolved {
+			s.reports[i].ResolvedAt = nil
+		}
+		if wasDisabled {
+			s.disabled[action.LinkID] = previous
+		} else {
+			delete(s.disabled, action.LinkID)
+		}
+		s.actions = s.actions[:len(s.actions)-1]
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// ListModerationActions returns the decisions taken on the link, or on all links if linkID is empty, newest first
+func (s *ModerationStore) ListModerationActions(ctx context.Context, linkID string) ([]domain.ModerationAction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var actions []domain.ModerationAction
+	for _, action := range s.actions {
+		if linkID == "" || action.LinkID == linkID {
+			actions = append(actions, action)
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].CreatedAt.After(actions[j].CreatedAt)
+	})
+
+	return actions, nil
+}
+
+// IsLinkDisabled reports whether moderators disabled the link
+func (s *ModerationStore) IsLinkDisabled(ctx context.Context, linkID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, disabled := s.disabled[linkID]
+	return disabled, nil
+}
+
+func (s *ModerationStore) saveToFile() error {
+	if s.file == "" {
+		return nil
+	}
+
+	data := moderationFile{Disabled: s.disabled, Actions: s.actions}
+	for _, report := range s.reports {
+		data.Reports = append(data.Reports, reportRecord{AbuseReport: report, ReporterIP: report.ReporterIP})
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации данных: %w", err)
+	}
+
+	if err := os.WriteFile(s.file, jsonData, 0600); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", s.file, err)
+	}
+
+	return nil
+}
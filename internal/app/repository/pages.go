@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// pageRow scans the page metadata of a link from a LEFT JOIN, where every column is NULL if it has none
+type pageRow struct {
+	title, description, image, favicon *string
+	fetchedAt                          *time.Time
+}
+
+func (p pageRow) meta() *domain.PageMeta {
+	if p.fetchedAt == nil {
+		return nil
+	}
+	return &domain.PageMeta{Title: *p.title, Description: *p.description, Image: *p.image, Favicon: *p.favicon, FetchedAt: *p.fetchedAt}
+}
+
+// SavePageMeta stores the metadata of the destination page of a link, replacing earlier metadata
+func (r *URLRepository) SavePageMeta(ctx context.Context, id string, page domain.PageMeta) error {
+	query := `INSERT INTO link_pages (short, title, description, image, favicon, fetched_at)
+			  SELECT $1, $2, $3, $4, $5, $6 WHERE EXISTS (SELECT 1 FROM urlshrt WHERE short = $1)
+			  ON CONFLICT (short) DO UPDATE
+			  SET title = EXCLUDED.title, description = EXCLUDED.description, image = EXCLUDED.image,
+			      favicon = EXCLUDED.favicon, fetched_at = EXCLUDED.fetched_at;`
+
+	_, err := r.db.Exec(ctx, query, id, page.Title, page.Description, page.Image, page.Favicon, page.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения метаданных страницы в БД: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/repository"
+)
+
+// testPool connects to the empty database in TEST_DATABASE_DSN and applies the migrations, tests using it are
+// skipped without one. The links left by other tests are removed
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	m, err := migrate.New("file://../../../migrations", dsn)
+	require.NoError(t, err)
+	require.NoError(t, repository.ApplyMigrations(m))
+
+	pool, err := repository.GetPgxPool(context.Background(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	_, err = pool.Exec(context.Background(), `TRUNCATE urlshrt CASCADE;`)
+	require.NoError(t, err)
+
+	return pool
+}
+
+func TestURLRepository_DeleteTwice(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewURLRepository(testPool(t))
+	require.NoError(t, err)
+
+	id, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+
+	links, err := repo.DeleteUserURLs(ctx, []string{id}, 1)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+
+	before, err := repo.LookupLink(ctx, id)
+	require.NoError(t, err)
+	require.True(t, before.Deleted)
+
+	links, err = repo.DeleteUserURLs(ctx, []string{id}, 1)
+	require.ErrorIs(t, err, appErrors.ErrNotFound)
+	require.Empty(t, links)
+
+	after, err := repo.LookupLink(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, before.DeletedAt, after.DeletedAt, "deleting again must not move the deletion time")
+}
+
+func TestURLRepository_AdoptBaseDomain(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewURLRepository(testPool(t))
+	require.NoError(t, err)
+
+	// links left on the BASE_URL host by 5_store_bare_short_ids
+	legacy, err := repo.Save(ctx, 1, "localhost:8080", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+	conflicting, err := repo.Save(ctx, 1, "localhost:8080", "https://example.org", domain.LinkMeta{})
+	require.NoError(t, err)
+	current, err := repo.Save(ctx, 2, "", "https://example.org", domain.LinkMeta{})
+	require.NoError(t, err)
+
+	moved, left, err := repo.AdoptBaseDomain(ctx, "localhost:8080")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, moved)
+	require.Equal(t, []string{conflicting}, left)
+
+	_, exists, _ := repo.Get(ctx, "", legacy)
+	require.True(t, exists)
+	_, exists, _ = repo.Get(ctx, "", current)
+	require.True(t, exists)
+
+	moved, left, err = repo.AdoptBaseDomain(ctx, "localhost:8080")
+	require.NoError(t, err)
+	require.Zero(t, moved)
+	require.Equal(t, []string{conflicting}, left, "conflicts are reported on every start until they are resolved")
+}
+
+func TestURLRepository_TakedownBlocksSave(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewURLRepository(testPool(t))
+	require.NoError(t, err)
+
+	id, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+	require.NoError(t, repo.TakedownLink(ctx, id, "phishing"))
+
+	_, err = repo.Save(ctx, 2, "", "https://example.com", domain.LinkMeta{})
+	require.ErrorIs(t, err, appErrors.ErrTakenDown)
+	_, err = repo.Save(ctx, 2, "go.example", "https://example.com", domain.LinkMeta{})
+	require.ErrorIs(t, err, appErrors.ErrTakenDown, "a takedown covers every domain")
+	_, err = repo.SaveBatch(ctx, 2, "", map[string]string{"a": "https://example.org", "b": "https://example.com"})
+	require.ErrorIs(t, err, appErrors.ErrTakenDown)
+
+	require.NoError(t, repo.RestoreLink(ctx, id))
+	_, err = repo.Save(ctx, 2, "go.example", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+}
+
+func TestURLRepository_PurgeKeepsTakedowns(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewURLRepository(testPool(t))
+	require.NoError(t, err)
+
+	deleted, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+	_, err = repo.DeleteUserURLs(ctx, []string{deleted}, 1)
+	require.NoError(t, err)
+	takenDown, err := repo.Save(ctx, 1, "", "https://example.org", domain.LinkMeta{})
+	require.NoError(t, err)
+	require.NoError(t, repo.TakedownLink(ctx, takenDown, "malware"))
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, purged)
+
+	_, err = repo.LookupLink(ctx, deleted)
+	require.ErrorIs(t, err, appErrors.ErrNotFound)
+	link, err := repo.LookupLink(ctx, takenDown)
+	require.NoError(t, err)
+	require.Equal(t, "malware", link.TakedownReason)
+}
+
+func TestURLRepository_GetRedirect(t *testing.T) {
+	ctx := context.Background()
+	repo, err := repository.NewURLRepository(testPool(t))
+	require.NoError(t, err)
+
+	id, err := repo.Save(ctx, 1, "", "https://example.com", domain.LinkMeta{})
+	require.NoError(t, err)
+
+	redirect, exists, _ := repo.Get(ctx, "", id)
+	require.True(t, exists)
+	require.Equal(t, "https://example.com", redirect.OriginalURL)
+	require.Empty(t, redirect.Rules)
+	require.Zero(t, redirect.Query)
+
+	rules := []domain.RedirectRule{{Platforms: []string{domain.PlatformIOS}, URL: "https://apps.apple.com/app/id1"}}
+	_, err = repo.SetRules(ctx, 1, id, rules)
+	require.NoError(t, err)
+
+	opts := domain.QueryOptions{UTM: domain.UTM{Source: "newsletter"}, Forward: true}
+	_, err = repo.SetQueryOptions(ctx, 1, id, opts)
+	require.NoError(t, err)
+
+	redirect, exists, _ = repo.Get(ctx, "", id)
+	require.True(t, exists)
+	require.Equal(t, rules, redirect.Rules)
+	require.Equal(t, opts, redirect.Query)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// GetQueryOptions returns the query string options of a link, the zero value for links redirecting as is
+func (r *URLRepository) GetQueryOptions(ctx context.Context, id string) (domain.QueryOptions, error) {
+	query := `SELECT query_options FROM urlshrt WHERE short = $1;`
+
+	var raw []byte
+	err := r.db.QueryRow(ctx, query, id).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.QueryOptions{}, nil
+	}
+	if err != nil {
+		return domain.QueryOptions{}, fmt.Errorf("ошибка при получении параметров строки запроса: %w", err)
+	}
+
+	return decodeQueryOptions(raw)
+}
+
+// UserQueryOptions returns the query string options of a link the user may change
+func (r *URLRepository) UserQueryOptions(ctx context.Context, userID int, id string) (domain.QueryOptions, error) {
+	if err := r.checkEditable(ctx, userID, id); err != nil {
+		return domain.QueryOptions{}, err
+	}
+	return r.GetQueryOptions(ctx, id)
+}
+
+// SetQueryOptions replaces the query string options of a link the user may change
+func (r *URLRepository) SetQueryOptions(ctx context.Context, userID int, id string, opts domain.QueryOptions) (domain.QueryOptions, error) {
+	var raw *string
+	if opts != (domain.QueryOptions{}) {
+		encoded, err := json.Marshal(opts)
+		if err != nil {
+			return domain.QueryOptions{}, fmt.Errorf("ошибка при кодировании параметров строки запроса: %w", err)
+		}
+		s := string(encoded)
+		raw = &s
+	}
+
+	query := `UPDATE urlshrt SET query_options = $3 WHERE short = $1 AND ` + editableBy("$2") + ` AND NOT is_deleted;`
+
+	res, err := r.db.Exec(ctx, query, id, userID, raw)
+	if err != nil {
+		return domain.QueryOptions{}, fmt.Errorf("ошибка сохранения параметров строки запроса в БД: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return domain.QueryOptions{}, appErrors.ErrNotFound
+	}
+
+	return opts, nil
+}
+
+func decodeQueryOptions(raw []byte) (domain.QueryOptions, error) {
+	var opts domain.QueryOptions
+	if len(raw) == 0 {
+		return opts, nil
+	}
+	if err := json.Unmarshal(raw, &opts); err != nil {
+		return domain.QueryOptions{}, fmt.Errorf("ошибка при разборе параметров строки запроса: %w", err)
+	}
+	return opts, nil
+}
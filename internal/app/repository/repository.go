@@ -2,32 +2,39 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
+	"time"
 
-	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
-	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
 	appErrors "github.com/Te8va/shortURL/internal/app/errors"
 )
 
 // URLRepository — repository for managing shortened URLs in PostgreSQL.
 type URLRepository struct {
-	db  *pgxpool.Pool
-	cfg *config.Config
+	db *pgxpool.Pool
 }
 
-// NewURLRepository creates a new URLRepository instance with the given connection pool and configuration.
-func NewURLRepository(db *pgxpool.Pool, cfg *config.Config) (*URLRepository, error) {
+// NewURLRepository creates a new URLRepository instance with the given connection pool.
+func NewURLRepository(db *pgxpool.Pool) (*URLRepository, error) {
 	if db == nil {
 		return nil, fmt.Errorf("database connection is nil")
 	}
-	if cfg == nil {
-		return nil, fmt.Errorf("config is nil")
-	}
-	return &URLRepository{db: db, cfg: cfg}, nil
+	return &URLRepository{db: db}, nil
+}
+
+// editableBy returns the condition on urlshrt rows the user in the query parameter param may change: their personal
+// links and the links of the workspaces they own or edit. Membership is checked on every change, so a creator who left
+// a workspace can no longer change the links they created in it
+func editableBy(param string) string {
+	return `((workspace_id = '' AND user_id = ` + param + `) OR
+			  workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ` + param + ` AND role IN ('owner', 'editor')))`
 }
 
 // PingPg checks the availability of the PostgreSQL database.
@@ -40,56 +47,77 @@ func (r *URLRepository) PingPg(ctx context.Context) error {
 	return nil
 }
 
-// Save stores URL and returns its shortened version
-func (r *URLRepository) Save(ctx context.Context, userID int, url string) (string, error) {
+// Save stores URL with its metadata on the domain and returns the ID of its short link. A deleted link of the same URL
+// does not count, so the URL gets a new link, unless it was taken down by an operator
+func (r *URLRepository) Save(ctx context.Context, userID int, host, url string, meta domain.LinkMeta) (string, error) {
+	if err := r.refuseTakenDown(ctx, []string{url}); err != nil {
+		return "", err
+	}
+
 	id := r.generateID()
-	shortenedURL := fmt.Sprintf("%s/%s", r.cfg.BaseURL, id)
 
 	query := `WITH ins AS (
-				INSERT INTO urlshrt (short, original, user_id) 
-				VALUES ($1, $2, $3)
-				ON CONFLICT (original) DO NOTHING
+				INSERT INTO urlshrt (short, domain, original, user_id, workspace_id, title, notes, tags) 
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				ON CONFLICT (domain, original) WHERE NOT is_deleted DO NOTHING
 				RETURNING short
 			  )
 			  SELECT short FROM ins
 			  UNION ALL
-			  SELECT short FROM urlshrt WHERE original = $2 LIMIT 1;`
+			  SELECT short FROM urlshrt WHERE domain = $2 AND original = $3 AND NOT is_deleted LIMIT 1;`
 
-	var existingShort string
-	err := r.db.QueryRow(ctx, query, shortenedURL, url, userID).Scan(&existingShort)
+	var existingID string
+	err := r.db.QueryRow(ctx, query, id, host, url, userID, meta.Workspace, meta.Title, meta.Notes, nonNilTags(meta.Tags)).Scan(&existingID)
 
 	if err != nil {
 		return "", fmt.Errorf("ошибка при сохранении или получении short URL: %w", err)
 	}
 
-	if existingShort != shortenedURL {
-		return existingShort, appErrors.ErrURLExists
+	if existingID != id {
+		return existingID, appErrors.ErrURLExists
 	}
 
-	return existingShort, nil
+	return existingID, nil
 }
 
-// Get returns the original URL by its shortened identifier
-func (r *URLRepository) Get(ctx context.Context, id string) (string, bool, bool) {
-	query := `SELECT original, is_deleted FROM urlshrt WHERE short = $1;`
+// Get returns the original URL, the redirect rules and the query string options by the domain and ID of its short
+// link. Rules or options that can not be decoded are left out, so the link still redirects to its original URL
+func (r *URLRepository) Get(ctx context.Context, host, id string) (domain.Redirect, bool, bool) {
+	query := `SELECT original, is_deleted, rules, query_options FROM urlshrt WHERE short = $1 AND domain = $2;`
 
-	var originalURL string
+	var redirect domain.Redirect
 	var isDeleted bool
+	var rawRules, rawQuery []byte
 
-	err := r.db.QueryRow(ctx, query, id).Scan(&originalURL, &isDeleted)
+	err := r.db.QueryRow(ctx, query, id, host).Scan(&redirect.OriginalURL, &isDeleted, &rawRules, &rawQuery)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", false, false
+			return domain.Redirect{}, false, false
 		}
 		log.Printf("Ошибка запроса в БД: %v", err)
-		return "", false, false
+		return domain.Redirect{}, false, false
 	}
 
-	return originalURL, true, isDeleted
+	if redirect.Rules, err = decodeRules(rawRules); err != nil {
+		log.Printf("Ошибка чтения правил перенаправления %s: %v", id, err)
+	}
+	if redirect.Query, err = decodeQueryOptions(rawQuery); err != nil {
+		log.Printf("Ошибка чтения параметров строки запроса %s: %v", id, err)
+	}
+
+	return redirect, true, isDeleted
 }
 
-// SaveBatch stores multiple URLs in a single call.
-func (r *URLRepository) SaveBatch(ctx context.Context, userID int, urls map[string]string) (map[string]string, error) {
+// SaveBatch stores multiple URLs on the domain in a single call.
+func (r *URLRepository) SaveBatch(ctx context.Context, userID int, host string, urls map[string]string) (map[string]string, error) {
+	originals := make([]string, 0, len(urls))
+	for _, originalURL := range urls {
+		originals = append(originals, originalURL)
+	}
+	if err := r.refuseTakenDown(ctx, originals); err != nil {
+		return nil, err
+	}
+
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
@@ -103,15 +131,14 @@ func (r *URLRepository) SaveBatch(ctx context.Context, userID int, urls map[stri
 	result := make(map[string]string)
 	for correlationID, originalURL := range urls {
 		id := r.generateID()
-		shortenedURL := fmt.Sprintf("%s/%s", r.cfg.BaseURL, id)
-		query := `INSERT INTO urlshrt (short, original, user_id) VALUES ($1, $2, $3);`
+		query := `INSERT INTO urlshrt (short, domain, original, user_id) VALUES ($1, $2, $3, $4);`
 
-		_, err := tx.Exec(ctx, query, shortenedURL, originalURL)
+		_, err := tx.Exec(ctx, query, id, host, originalURL, userID)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка сохранения URL в БД: %w", err)
 		}
 
-		result[correlationID] = shortenedURL
+		result[correlationID] = id
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -143,46 +170,290 @@ func (r *URLRepository) generateID() string {
 	}
 }
 
-// GetUserURLs returns all URLs belonging to a specific user
-func (r *URLRepository) GetUserURLs(ctx context.Context, userID int) ([]map[string]string, error) {
-	query := `SELECT short, original FROM urlshrt WHERE user_id = $1;`
-
-	rows, err := r.db.Query(ctx, query, userID)
+// GetUserURLs returns the personal URLs of a specific user, or the URLs of the workspace if the filter names one,
+// that match the filter. Access to the workspace is checked by the caller
+func (r *URLRepository) GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error) {
+	query := `SELECT u.short, u.domain, u.original, u.user_id, u.workspace_id, u.title, u.notes, u.tags,
+			         h.status, h.error, h.redirects, h.checked_at,
+			         p.title, p.description, p.image, p.favicon, p.fetched_at
+			  FROM urlshrt u
+			  LEFT JOIN link_health h ON h.short = u.short
+			  LEFT JOIN link_pages p ON p.short = u.short
+			  WHERE (($4 = '' AND u.workspace_id = '' AND u.user_id = $1) OR ($4 <> '' AND u.workspace_id = $4))
+			    AND ($2 = '' OR $2 = ANY(u.tags))
+			    AND ($3 = '' OR u.search @@ to_tsquery('simple', $3))
+			    AND ($5 = ''
+			      OR ($5 = 'unchecked' AND h.short IS NULL)
+			      OR ($5 = 'broken' AND (h.error <> '' OR h.status >= 400))
+			      OR ($5 = 'ok' AND h.error = '' AND h.status < 400))
+			  ORDER BY u.id;`
+
+	rows, err := r.db.Query(ctx, query, userID, normalizeTag(filter.Tag), prefixTSQuery(filter.Query), filter.Workspace, filter.Health)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении URL пользователя: %w", err)
 	}
 	defer rows.Close()
 
-	var urls []map[string]string
+	var links []domain.Link
 	for rows.Next() {
-		var shortURL, originalURL string
-		if err := rows.Scan(&shortURL, &originalURL); err != nil {
+		var (
+			link      domain.Link
+			status    *int
+			checkErr  *string
+			redirects []string
+			checkedAt *time.Time
+			page      pageRow
+		)
+		if err := rows.Scan(&link.ID, &link.Domain, &link.OriginalURL, &link.UserID, &link.Workspace, &link.Title, &link.Notes, &link.Tags,
+			&status, &checkErr, &redirects, &checkedAt,
+			&page.title, &page.description, &page.image, &page.favicon, &page.fetchedAt); err != nil {
 			return nil, fmt.Errorf("ошибка при сканировании URL: %w", err)
 		}
-		urls = append(urls, map[string]string{
-			"short_url":    shortURL,
-			"original_url": originalURL,
-		})
+		if checkedAt != nil {
+			link.Health = &domain.LinkHealth{Status: *status, Error: *checkErr, Redirects: redirects, CheckedAt: *checkedAt}
+		}
+		link.Page = page.meta()
+		links = append(links, link)
 	}
 
-	if len(urls) == 0 {
-		return nil, nil
+	return links, rows.Err()
+}
+
+// UpdateLink changes the metadata of a link the user may change and returns the updated link
+func (r *URLRepository) UpdateLink(ctx context.Context, userID int, id string, update domain.LinkUpdate) (domain.Link, error) {
+	query := `UPDATE urlshrt
+			  SET title = COALESCE($3, title), notes = COALESCE($4, notes), tags = COALESCE($5, tags)
+			  WHERE short = $1 AND ` + editableBy("$2") + ` AND NOT is_deleted
+			  RETURNING short, domain, original, user_id, workspace_id, title, notes, tags;`
+
+	var tags *[]string
+	if update.Tags != nil {
+		t := nonNilTags(*update.Tags)
+		tags = &t
+	}
+
+	var link domain.Link
+	err := r.db.QueryRow(ctx, query, id, userID, update.Title, update.Notes, tags).
+		Scan(&link.ID, &link.Domain, &link.OriginalURL, &link.UserID, &link.Workspace, &link.Title, &link.Notes, &link.Tags)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Link{}, appErrors.ErrNotFound
+	} else if err != nil {
+		return domain.Link{}, fmt.Errorf("ошибка при обновлении URL: %w", err)
 	}
 
-	return urls, nil
+	return link, nil
 }
 
-// DeleteUserURLs marks URLs as deleted for user.
-func (r *URLRepository) DeleteUserURLs(ctx context.Context, ids []string, userID int) error {
-	query := `UPDATE urlshrt SET is_deleted = true WHERE short = ANY($1) AND user_id = $2;`
+// DeleteUserURLs marks URLs as deleted for user and returns the links it deleted, ErrNotFound if there were none.
+// Links deleted before are left as they are and not returned again. Besides their personal links, owners and editors of a workspace may delete its links.
+func (r *URLRepository) DeleteUserURLs(ctx context.Context, ids []string, userID int) ([]domain.Link, error) {
+	query := `UPDATE urlshrt SET is_deleted = true, deleted_at = NOW()
+			  WHERE short = ANY($1) AND NOT is_deleted AND ` + editableBy("$2") + `
+			  RETURNING short, domain, original, user_id, workspace_id, title, notes, tags;`
 
-	res, err := r.db.Exec(ctx, query, ids, userID)
+	rows, err := r.db.Query(ctx, query, ids, userID)
 	if err != nil {
 		log.Printf("Ошибка удаления URL (user_id=%d): %v", userID, err)
-		return fmt.Errorf("ошибка при удалении URL: %w", err)
+		return nil, fmt.Errorf("ошибка при удалении URL: %w", err)
 	}
-	if res.RowsAffected() == 0 {
-		return fmt.Errorf("URL не найдены или не принадлежат пользователю")
+	defer rows.Close()
+
+	var links []domain.Link
+	for rows.Next() {
+		var link domain.Link
+		if err := rows.Scan(&link.ID, &link.Domain, &link.OriginalURL, &link.UserID, &link.Workspace,
+			&link.Title, &link.Notes, &link.Tags); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании удалённого URL: %w", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении URL: %w", err)
+	}
+	if len(links) == 0 {
+		return nil, appErrors.ErrNotFound
+	}
+
+	return links, nil
+}
+
+// RestoreUserURLs undeletes the links the user could delete if they were deleted after deletedAfter, a zero time
+// restores links however long ago they were deleted. It reports the outcome for every ID and returns the restored links
+func (r *URLRepository) RestoreUserURLs(ctx context.Context, ids []string, userID int, deletedAfter time.Time) ([]domain.RestoreResult, []domain.Link, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `SELECT short, domain, original, user_id, workspace_id, title, notes, tags, is_deleted, deleted_at, takedown_reason
+			  FROM urlshrt
+			  WHERE short = ANY($1) AND ` + editableBy("$2") + `
+			  FOR UPDATE;`
+
+	rows, err := tx.Query(ctx, query, ids, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка при получении удалённых URL: %w", err)
+	}
+	found := make(map[string]domain.LinkRecord, len(ids))
+	for rows.Next() {
+		var link domain.LinkRecord
+		if err := rows.Scan(&link.ID, &link.Domain, &link.OriginalURL, &link.UserID, &link.Workspace,
+			&link.Title, &link.Notes, &link.Tags, &link.Deleted, &link.DeletedAt, &link.TakedownReason); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("ошибка при сканировании удалённого URL: %w", err)
+		}
+		found[link.ID] = link
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("ошибка при получении удалённых URL: %w", err)
+	}
+
+	results := make([]domain.RestoreResult, 0, len(ids))
+	var restored []domain.Link
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		link, ok := found[id]
+		var status string
+		switch {
+		case !ok || link.TakedownReason != "":
+			status = domain.RestoreNotFound
+		case !link.Deleted:
+			status = domain.RestoreNotDeleted
+		case link.DeletedAt != nil && link.DeletedAt.Before(deletedAfter):
+			status = domain.RestoreExpired
+		default:
+			status, err = restoreLink(ctx, tx, id)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if status == domain.RestoreRestored {
+			restored = append(restored, link.Link)
+		}
+		results = append(results, domain.RestoreResult{ID: id, Status: status})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("ошибка при завершении транзакции: %w", err)
+	}
+
+	return results, restored, nil
+}
+
+// restoreLink undeletes a link within a savepoint, so that a live link of the same URL fails only this link
+func restoreLink(ctx context.Context, tx pgx.Tx, id string) (string, error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	_, err = sp.Exec(ctx, `UPDATE urlshrt SET is_deleted = false, deleted_at = NULL WHERE short = $1;`, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		if err := sp.Rollback(ctx); err != nil {
+			return "", fmt.Errorf("ошибка при откате транзакции: %w", err)
+		}
+		return domain.RestoreConflict, nil
+	}
+	if err != nil {
+		_ = sp.Rollback(ctx)
+		return "", fmt.Errorf("ошибка при восстановлении URL: %w", err)
+	}
+
+	if err := sp.Commit(ctx); err != nil {
+		return "", fmt.Errorf("ошибка при завершении транзакции: %w", err)
+	}
+
+	return domain.RestoreRestored, nil
+}
+
+// GetLink returns the short link with the given ID
+func (r *URLRepository) GetLink(ctx context.Context, id string) (domain.Link, error) {
+	query := `SELECT short, domain, original, user_id, workspace_id, title, notes, tags FROM urlshrt WHERE short = $1;`
+
+	var link domain.Link
+	err := r.db.QueryRow(ctx, query, id).Scan(&link.ID, &link.Domain, &link.OriginalURL, &link.UserID, &link.Workspace,
+		&link.Title, &link.Notes, &link.Tags)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Link{}, appErrors.ErrNotFound
+	}
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("ошибка при получении URL: %w", err)
+	}
+
+	return link, nil
+}
+
+// AdoptBaseDomain moves the links stored with the host of BASE_URL as their domain to the empty domain that stands
+// for it. Databases migrated by 5_store_bare_short_ids keep that host on legacy links, and no request resolves to it.
+// A live link whose URL is already shortened on the empty domain can not be moved without breaking one of the two
+// short links, so it stays where it is. It returns how many links were moved and the IDs of those left behind
+func (r *URLRepository) AdoptBaseDomain(ctx context.Context, host string) (int64, []string, error) {
+	if host == "" {
+		return 0, nil, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `UPDATE urlshrt u SET domain = ''
+			  WHERE u.domain = $1
+			    AND (u.is_deleted OR NOT EXISTS (
+			        SELECT 1 FROM urlshrt o WHERE o.domain = '' AND o.original = u.original AND NOT o.is_deleted));`
+
+	res, err := tx.Exec(ctx, query, host)
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка при переносе ссылок на основной домен: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT short FROM urlshrt WHERE domain = $1 ORDER BY short;`, host)
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка при получении оставшихся ссылок: %w", err)
+	}
+	left, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка при получении оставшихся ссылок: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, nil, fmt.Errorf("ошибка при фиксации транзакции: %w", err)
+	}
+
+	return res.RowsAffected(), left, nil
+}
+
+// ClaimURLs transfers all URLs of one user to another
+func (r *URLRepository) ClaimURLs(ctx context.Context, fromUserID, toUserID int) error {
+	query := `UPDATE urlshrt SET user_id = $2 WHERE user_id = $1;`
+
+	if _, err := r.db.Exec(ctx, query, fromUserID, toUserID); err != nil {
+		return fmt.Errorf("ошибка при переносе URL пользователя: %w", err)
+	}
+
+	return nil
+}
+
+// MoveLinks moves the links into the workspace, or makes them personal links of their creators if it is empty
+func (r *URLRepository) MoveLinks(ctx context.Context, ids []string, workspaceID string) error {
+	query := `UPDATE urlshrt SET workspace_id = $2 WHERE short = ANY($1);`
+
+	if _, err := r.db.Exec(ctx, query, ids, workspaceID); err != nil {
+		return fmt.Errorf("ошибка при переносе URL в рабочее пространство: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// GetRules returns the redirect rules of a link, none for links that always go to their own destination
+func (r *URLRepository) GetRules(ctx context.Context, id string) ([]domain.RedirectRule, error) {
+	query := `SELECT rules FROM urlshrt WHERE short = $1;`
+
+	var raw []byte
+	err := r.db.QueryRow(ctx, query, id).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил перенаправления: %w", err)
+	}
+
+	return decodeRules(raw)
+}
+
+// UserRules returns the redirect rules of a link the user may change
+func (r *URLRepository) UserRules(ctx context.Context, userID int, id string) ([]domain.RedirectRule, error) {
+	if err := r.checkEditable(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return r.GetRules(ctx, id)
+}
+
+// SetRules replaces the redirect rules of a link the user may change
+func (r *URLRepository) SetRules(ctx context.Context, userID int, id string, rules []domain.RedirectRule) ([]domain.RedirectRule, error) {
+	if rules == nil {
+		rules = []domain.RedirectRule{}
+	}
+
+	raw, err := json.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при кодировании правил перенаправления: %w", err)
+	}
+
+	query := `UPDATE urlshrt SET rules = $3 WHERE short = $1 AND ` + editableBy("$2") + ` AND NOT is_deleted;`
+
+	res, err := r.db.Exec(ctx, query, id, userID, string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения правил перенаправления в БД: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return nil, appErrors.ErrNotFound
+	}
+
+	return rules, nil
+}
+
+func decodeRules(raw []byte) ([]domain.RedirectRule, error) {
+	var rules []domain.RedirectRule
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("ошибка при разборе правил перенаправления: %w", err)
+	}
+	return rules, nil
+}
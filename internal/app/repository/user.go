@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// UserRepository — repository for managing user accounts in PostgreSQL.
+type UserRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewUserRepository creates a new UserRepository instance with the given connection pool.
+func NewUserRepository(db *pgxpool.Pool) (*UserRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &UserRepository{db: db}, nil
+}
+
+// CreateUser stores a new account and returns its ID
+func (r *UserRepository) CreateUser(ctx context.Context, login, passwordHash string) (int, error) {
+	query := `INSERT INTO users (login, password_hash)
+			  VALUES ($1, $2)
+			  ON CONFLICT (login) DO NOTHING
+			  RETURNING id;`
+
+	var id int
+	err := r.db.QueryRow(ctx, query, login, passwordHash).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, appErrors.ErrUserExists
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании пользователя: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetUserByLogin returns the account with the given login
+func (r *UserRepository) GetUserByLogin(ctx context.Context, login string) (domain.User, error) {
+	query := `SELECT id, login, password_hash, created_at FROM users WHERE login = $1;`
+
+	var user domain.User
+	err := r.db.QueryRow(ctx, query, login).Scan(&user.ID, &user.Login, &user.PasswordHash, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.User{}, appErrors.ErrNotFound
+	}
+	if err != nil {
+		return domain.User{}, fmt.Errorf("ошибка при получении пользователя: %w", err)
+	}
+
+	return user, nil
+}
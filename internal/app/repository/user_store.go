@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// UserStore keeps user accounts in memory and, when a file path is given, mirrors them to a JSON file
+type UserStore struct {
+	file   string
+	users  map[string]domain.User
+	nextID int
+	mu     sync.RWMutex
+}
+
+// NewUserStore creates a new user store and loads accounts from the file if it is set
+func NewUserStore(filePath string) (*UserStore, error) {
+	s := &UserStore{
+		file:   filePath,
+		users:  make(map[string]domain.User),
+		nextID: domain.FirstRegisteredUserID,
+	}
+
+	if filePath == "" {
+		return s, nil
+	}
+
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	if len(fileData) == 0 {
+		return s, nil
+	}
+
+	var users []domain.User
+	if err := json.Unmarshal(fileData, &users); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации данных из файла: %w", err)
+	}
+
+	for _, user := range users {
+		s.users[user.Login] = user
+		if user.ID >= s.nextID {
+			s.nextID = user.ID + 1
+		}
+	}
+
+	return s, nil
+}
+
+// CreateUser stores a new account and returns its ID
+func (s *UserStore) CreateUser(ctx context.Context, login, passwordHash string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[login]; exists {
+		return 0, appErrors.ErrUserExists
+	}
+
+	user := domain.User{
+		ID:           s.nextID,
+		Login:        login,
+		PasswordHash: passwordHash,
+		CreatedAt:    time.Now().UTC(),
+	}
+	s.users[login] = user
+	s.nextID++
+
+	if err := s.saveToFile(); err != nil {
+		delete(s.users, login)
+		s.nextID--
+		return 0, fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return user.ID, nil
+}
+
+// GetUserByLogin returns the account with the given login
+func (s *UserStore) GetUserByLogin(ctx context.Context, login string) (domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, exists := s.users[login]
+	if !exists {
+		return domain.User{}, appErrors.ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *UserStore) saveToFile() error {
+	if s.file == "" {
+		return nil
+	}
+
+	users := make([]domain.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+
+	jsonData, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации данных: %w", err)
+	}
+
+	if err := os.WriteFile(s.file, jsonData, 0600); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", s.file, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// GetVariants returns the destinations of an A/B link, none for plain links
+func (r *URLRepository) GetVariants(ctx context.Context, id string) ([]domain.Variant, error) {
+	query := `SELECT url, weight, clicks FROM link_variants WHERE short = $1 ORDER BY position;`
+
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении вариантов URL: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []domain.Variant
+	for rows.Next() {
+		var variant domain.Variant
+		if err := rows.Scan(&variant.URL, &variant.Weight, &variant.Clicks); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании варианта URL: %w", err)
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, rows.Err()
+}
+
+// UserVariants returns the destinations of a link the user may change
+func (r *URLRepository) UserVariants(ctx context.Context, userID int, id string) ([]domain.Variant, error) {
+	if err := r.checkEditable(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return r.GetVariants(ctx, id)
+}
+
+// CountVariantClick counts a redirect to the variant at the given position
+func (r *URLRepository) CountVariantClick(ctx context.Context, id string, variant int) error {
+	query := `UPDATE link_variants SET clicks = clicks + 1 WHERE short = $1 AND position = $2;`
+
+	if _, err := r.db.Exec(ctx, query, id, variant); err != nil {
+		return fmt.Errorf("ошибка при подсчёте перехода: %w", err)
+	}
+
+	return nil
+}
+
+// SetVariants replaces the destinations of a link the user may change. Variants keeping their URL keep their click count
+func (r *URLRepository) SetVariants(ctx context.Context, userID int, id string, variants []domain.Variant) ([]domain.Variant, error) {
+	if err := r.checkEditable(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	previous, err := r.GetVariants(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	variants = carryClicks(previous, variants)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && err == nil {
+			err = fmt.Errorf("ошибка при откате транзакции: %w", rollbackErr)
+		}
+	}()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM link_variants WHERE short = $1;`, id); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении вариантов URL: %w", err)
+	}
+
+	for i, variant := range variants {
+		_, err := tx.Exec(ctx, `INSERT INTO link_variants (short, position, url, weight, clicks) VALUES ($1, $2, $3, $4, $5);`,
+			id, i, variant.URL, variant.Weight, variant.Clicks)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сохранения варианта URL в БД: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка при завершении транзакции: %w", err)
+	}
+
+	return variants, nil
+}
+
+// checkEditable returns ErrNotFound unless the link exists, is not deleted and the user may change it
+func (r *URLRepository) checkEditable(ctx context.Context, userID int, id string) error {
+	query := `SELECT EXISTS(SELECT 1 FROM urlshrt WHERE short = $1 AND ` + editableBy("$2") + ` AND NOT is_deleted);`
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, id, userID).Scan(&exists); err != nil {
+		return fmt.Errorf("ошибка при проверке владельца URL: %w", err)
+	}
+	if !exists {
+		return appErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// carryClicks copies the click counts of previous variants to new variants with the same URL
+func carryClicks(previous, variants []domain.Variant) []domain.Variant {
+	clicks := make(map[string]int64, len(previous))
+	for _, variant := range previous {
+		clicks[variant.URL] += variant.Clicks
+	}
+
+	carried := make([]domain.Variant, len(variants))
+	for i, variant := range variants {
+		variant.Clicks = clicks[variant.URL]
+		delete(clicks, variant.URL)
+		carried[i] = variant
+	}
+	return carried
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// WebhookRepository — repository for managing webhook subscriptions and failed deliveries in PostgreSQL.
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewWebhookRepository creates a new WebhookRepository instance with the given connection pool.
+func NewWebhookRepository(db *pgxpool.Pool) (*WebhookRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &WebhookRepository{db: db}, nil
+}
+
+// CreateWebhook stores a new webhook
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, hook domain.Webhook) error {
+	query := `INSERT INTO webhooks (id, user_id, url, secret, events, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6);`
+
+	_, err := r.db.Exec(ctx, query, hook.ID, hook.UserID, hook.URL, hook.Secret, hook.Events, hook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении вебхука: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhooks returns all webhooks of the user
+func (r *WebhookRepository) ListWebhooks(ctx context.Context, userID int) ([]domain.Webhook, error) {
+	query := `SELECT id, user_id, url, secret, events, created_at
+			  FROM webhooks WHERE user_id = $1 ORDER BY created_at;`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении вебхуков: %w", err)
+	}
+	defer rows.Close()
+
+	var hooks []domain.Webhook
+	for rows.Next() {
+		var hook domain.Webhook
+		if err := rows.Scan(&hook.ID, &hook.UserID, &hook.URL, &hook.Secret, &hook.Events, &hook.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании вебхука: %w", err)
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+// DeleteWebhook removes the user's webhook
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, userID int, id string) error {
+	query := `DELETE FROM webhooks WHERE id = $1 AND user_id = $2;`
+
+	res, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении вебхука: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return appErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// AddDeadLetter stores a delivery that failed after every retry
+func (r *WebhookRepository) AddDeadLetter(ctx context.Context, letter domain.WebhookDeadLetter) error {
+	query := `INSERT INTO webhook_dead_letters (id, webhook_id, delivery_id, user_id, event, payload, attempts, last_error, failed_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
+
+	_, err := r.db.Exec(ctx, query, letter.ID, letter.WebhookID, letter.DeliveryID, letter.UserID, letter.Event,
+		string(letter.Payload), letter.Attempts, letter.LastError, letter.FailedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении недоставленного события: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeadLetters returns the failed deliveries of the user, newest first
+func (r *WebhookRepository) ListDeadLetters(ctx context.Context, userID int) ([]domain.WebhookDeadLetter, error) {
+	query := `SELECT id, webhook_id, delivery_id, user_id, event, payload, attempts, last_error, failed_at
+			  FROM webhook_dead_letters WHERE user_id = $1 ORDER BY failed_at DESC;`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении недоставленных событий: %w", err)
+	}
+	defer rows.Close()
+
+	var letters []domain.WebhookDeadLetter
+	for rows.Next() {
+		var letter domain.WebhookDeadLetter
+		var payload string
+		if err := rows.Scan(&letter.ID, &letter.WebhookID, &letter.DeliveryID, &letter.UserID, &letter.Event,
+			&payload, &letter.Attempts, &letter.LastError, &letter.FailedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании недоставленного события: %w", err)
+		}
+		letter.Payload = []byte(payload)
+		letters = append(letters, letter)
+	}
+
+	return letters, rows.Err()
+}
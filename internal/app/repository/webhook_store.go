@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// maxDeadLettersPerUser bounds how many failed deliveries the store keeps for each user, oldest are dropped first
+const maxDeadLettersPerUser = 100
+
+// webhookRecord is the on-disk form of domain.Webhook, which hides the user ID and secret from JSON responses
+type webhookRecord struct {
+	domain.Webhook
+	UserID int    `json:"user_id"`
+	Secret string `json:"secret"`
+}
+
+// deadLetterRecord is the on-disk form of domain.WebhookDeadLetter
+type deadLetterRecord struct {
+	domain.WebhookDeadLetter
+	UserID int `json:"user_id"`
+}
+
+type webhookFile struct {
+	Webhooks    []webhookRecord    `json:"webhooks"`
+	DeadLetters []deadLetterRecord `json:"dead_letters"`
+}
+
+// WebhookStore keeps webhooks and failed deliveries in memory and, when a file path is given, mirrors them to a JSON file
+type WebhookStore struct {
+	file        string
+	hooks       map[string]domain.Webhook
+	deadLetters map[int][]domain.WebhookDeadLetter
+	mu          sync.RWMutex
+}
+
+// NewWebhookStore creates a new webhook store and loads its data from the file if it is set
+func NewWebhookStore(filePath string) (*WebhookStore, error) {
+	s := &WebhookStore{
+		file:        filePath,
+		hooks:       make(map[string]domain.Webhook),
+		deadLetters: make(map[int][]domain.WebhookDeadLetter),
+	}
+
+	if filePath == "" {
+		return s, nil
+	}
+
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	if len(fileData) == 0 {
+		return s, nil
+	}
+
+	var data webhookFile
+	if err := json.Unmarshal(fileData, &data); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации данных из файла: %w", err)
+	}
+
+	for _, rec := range data.Webhooks {
+		hook := rec.Webhook
+		hook.UserID = rec.UserID
+		hook.Secret = rec.Secret
+		s.hooks[hook.ID] = hook
+	}
+	for _, rec := range data.DeadLetters {
+		letter := rec.WebhookDeadLetter
+		letter.UserID = rec.UserID
+		s.deadLetters[letter.UserID] = append(s.deadLetters[letter.UserID], letter)
+	}
+
+	return s, nil
+}
+
+// CreateWebhook stores a new webhook
+func (s *WebhookStore) CreateWebhook(ctx context.Context, hook domain.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks[hook.ID] = hook
+
+	if err := s.saveToFile(); err != nil {
+		delete(s.hooks, hook.ID)
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhooks returns all webhooks of the user
+func (s *WebhookStore) ListWebhooks(ctx context.Context, userID int) ([]domain.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var hooks []domain.Webhook
+	for _, hook := range s.hooks {
+		if hook.UserID == userID {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].CreatedAt.Before(hooks[j].CreatedAt)
+	})
+
+	return hooks, nil
+}
+
+// DeleteWebhook removes the user's webhook
+func (s *WebhookStore) DeleteWebhook(ctx context.Context, userID int, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hook, exists := s.hooks[id]
+	if !exists || hook.UserID != userID {
+		return appErrors.ErrNotFound
+	}
+
+	delete(s.hooks, id)
+
+	if err := s.saveToFile(); err != nil {
+		s.hooks[id] = hook
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// AddDeadLetter stores a delivery that failed after every retry
+func (s *WebhookStore) AddDeadLetter(ctx context.Context, letter domain.WebhookDeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letters := append(s.deadLetters[letter.UserID], letter)
+	if len(letters) > maxDeadLettersPerUser {
+		letters = letters[len(letters)-maxDeadLettersPerUser:]
+	}
+	s.deadLetters[letter.UserID] = letters
+
+	if err := s.saveToFile(); err != nil {
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeadLetters returns the failed deliveries of the user, newest first
+func (s *WebhookStore) ListDeadLetters(ctx context.Context, userID int) ([]domain.WebhookDeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored := s.deadLetters[userID]
+	letters := make([]domain.WebhookDeadLetter, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		letters = append(letters, stored[i])
+	}
+
+	return letters, nil
+}
+
+func (s *WebhookStore) saveToFile() error {
+	if s.file == "" {
+		return nil
+	}
+
+	var data webhookFile
+	for _, hook := range s.hooks {
+		data.Webhooks = append(data.Webhooks, webhookRecord{Webhook: hook, UserID: hook.UserID, Secret: hook.Secret})
+	}
+	for _, letters := range s.deadLetters {
+		for _, letter := range letters {
+			data.DeadLetters = append(data.DeadLetters, deadLetterRecord{WebhookDeadLetter: letter, UserID: letter.UserID})
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации данных: %w", err)
+	}
+
+	if err := os.WriteFile(s.file, jsonData, 0600); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", s.file, err)
+	}
+
+	return nil
+}
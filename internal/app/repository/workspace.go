@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// WorkspaceRepository — repository for managing workspaces and their members in PostgreSQL.
+type WorkspaceRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewWorkspaceRepository creates a new WorkspaceRepository instance with the given connection pool.
+func NewWorkspaceRepository(db *pgxpool.Pool) (*WorkspaceRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &WorkspaceRepository{db: db}, nil
+}
+
+// CreateWorkspace stores a new workspace together with its first owner
+func (r *WorkspaceRepository) CreateWorkspace(ctx context.Context, ws domain.Workspace, owner domain.WorkspaceMember) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && err == nil {
+			err = fmt.Errorf("ошибка при откате транзакции: %w", rollbackErr)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `INSERT INTO workspaces (id, name, created_at) VALUES ($1, $2, $3);`, ws.ID, ws.Name, ws.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении рабочего пространства: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role, added_at) VALUES ($1, $2, $3, $4);`,
+		ws.ID, owner.UserID, owner.Role, owner.AddedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении участника рабочего пространства: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при завершении транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// ListWorkspaces returns the workspaces the user is a member of together with the user's role in each
+func (r *WorkspaceRepository) ListWorkspaces(ctx context.Context, userID int) ([]domain.Workspace, error) {
+	query := `SELECT w.id, w.name, m.role, w.created_at
+			  FROM workspaces w JOIN workspace_members m ON m.workspace_id = w.id
+			  WHERE m.user_id = $1 ORDER BY w.created_at;`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении рабочих пространств: %w", err)
+	}
+	defer rows.Close()
+
+	var workspaces []domain.Workspace
+	for rows.Next() {
+		var ws domain.Workspace
+		if err := rows.Scan(&ws.ID, &ws.Name, &ws.Role, &ws.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании рабочего пространства: %w", err)
+		}
+		workspaces = append(workspaces, ws)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// GetMember returns the membership of the user in the workspace
+func (r *WorkspaceRepository) GetMember(ctx context.Context, workspaceID string, userID int) (domain.WorkspaceMember, error) {
+	query := `SELECT workspace_id, user_id, role, added_at FROM workspace_members WHERE workspace_id = $1 AND user_id = $2;`
+
+	var member domain.WorkspaceMember
+	err := r.db.QueryRow(ctx, query, workspaceID, userID).Scan(&member.WorkspaceID, &member.UserID, &member.Role, &member.AddedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.WorkspaceMember{}, appErrors.ErrNotFound
+	}
+	if err != nil {
+		return domain.WorkspaceMember{}, fmt.Errorf("ошибка при получении участника рабочего пространства: %w", err)
+	}
+
+	return member, nil
+}
+
+// ListMembers returns the members of the workspace in the order they were added
+func (r *WorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]domain.WorkspaceMember, error) {
+	query := `SELECT workspace_id, user_id, role, added_at FROM workspace_members WHERE workspace_id = $1 ORDER BY added_at;`
+
+	rows, err := r.db.Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении участников рабочего пространства: %w", err)
+	}
+	defer rows.Close()
+
+	var members []domain.WorkspaceMember
+	for rows.Next() {
+		var member domain.WorkspaceMember
+		if err := rows.Scan(&member.WorkspaceID, &member.UserID, &member.Role, &member.AddedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании участника рабочего пространства: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// SaveMember adds the member to the workspace or changes the role of an existing member
+func (r *WorkspaceRepository) SaveMember(ctx context.Context, member domain.WorkspaceMember) error {
+	query := `INSERT INTO workspace_members (workspace_id, user_id, role, added_at)
+			  SELECT $1, $2, $3, $4 WHERE EXISTS (SELECT 1 FROM workspaces WHERE id = $1)
+			  ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role;`
+
+	res, err := r.db.Exec(ctx, query, member.WorkspaceID, member.UserID, member.Role, member.AddedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении участника рабочего пространства: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return appErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// RemoveMember removes the user from the workspace
+func (r *WorkspaceRepository) RemoveMember(ctx context.Context, workspaceID string, userID int) error {
+	query := `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2;`
+
+	res, err := r.db.Exec(ctx, query, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении участника рабочего пространства: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return appErrors.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+// memberRecord is the on-disk form of domain.WorkspaceMember, which hides the workspace ID from JSON responses
+type memberRecord struct {
+	domain.WorkspaceMember
+	WorkspaceID string `json:"workspace_id"`
+}
+
+type workspaceFile struct {
+	Workspaces []domain.Workspace `json:"workspaces"`
+	Members    []memberRecord     `json:"members"`
+}
+
+// WorkspaceStore keeps workspaces and their members in memory and, when a file path is given, mirrors them to a JSON file
+type WorkspaceStore struct {
+	file       string
+	workspaces map[string]domain.Workspace
+	members    map[string]map[int]domain.WorkspaceMember
+	mu         sync.RWMutex
+}
+
+// NewWorkspaceStore creates a new workspace store and loads its data from the file if it is set
+func NewWorkspaceStore(filePath string) (*WorkspaceStore, error) {
+	s := &WorkspaceStore{
+		file:       filePath,
+		workspaces: make(map[string]domain.Workspace),
+		members:    make(map[string]map[int]domain.WorkspaceMember),
+	}
+
+	if filePath == "" {
+		return s, nil
+	}
+
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	if len(fileData) == 0 {
+		return s, nil
+	}
+
+	var data workspaceFile
+	if err := json.Unmarshal(fileData, &data); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации данных из файла: %w", err)
+	}
+
+	for _, ws := range data.Workspaces {
+		s.workspaces[ws.ID] = ws
+		s.members[ws.ID] = make(map[int]domain.WorkspaceMember)
+	}
+	for _, rec := range data.Members {
+		member := rec.WorkspaceMember
+		member.WorkspaceID = rec.WorkspaceID
+		if members, exists := s.members[member.WorkspaceID]; exists {
+			members[member.UserID] = member
+		}
+	}
+
+	return s, nil
+}
+
+// CreateWorkspace stores a new workspace together with its first owner
+func (s *WorkspaceStore) CreateWorkspace(ctx context.Context, ws domain.Workspace, owner domain.WorkspaceMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ws.Role = ""
+	s.workspaces[ws.ID] = ws
+	s.members[ws.ID] = map[int]domain.WorkspaceMember{owner.UserID: owner}
+
+	if err := s.saveToFile(); err != nil {
+		delete(s.workspaces, ws.ID)
+		delete(s.members, ws.ID)
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// ListWorkspaces returns the workspaces the user is a member of together with the user's role in each
+func (s *WorkspaceStore) ListWorkspaces(ctx context.Context, userID int) ([]domain.Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var workspaces []domain.Workspace
+	for id, members := range s.members {
+		if member, ok := members[userID]; ok {
+			ws := s.workspaces[id]
+			ws.Role = member.Role
+			workspaces = append(workspaces, ws)
+		}
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].CreatedAt.Before(workspaces[j].CreatedAt)
+	})
+
+	return workspaces, nil
+}
+
+// GetMember returns the membership of the user in the workspace
+func (s *WorkspaceStore) GetMember(ctx context.Context, workspaceID string, userID int) (domain.WorkspaceMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	member, exists := s.members[workspaceID][userID]
+	if !exists {
+		return domain.WorkspaceMember{}, appErrors.ErrNotFound
+	}
+	return member, nil
+}
+
+// ListMembers returns the members of the workspace in the order they were added
+func (s *WorkspaceStore) ListMembers(ctx context.Context, workspaceID string) ([]domain.WorkspaceMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := make([]domain.WorkspaceMember, 0, len(s.members[workspaceID]))
+	for _, member := range s.members[workspaceID] {
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].AddedAt.Before(members[j].AddedAt)
+	})
+
+	return members, nil
+}
+
+// SaveMember adds the member to the workspace or changes the role of an existing member
+func (s *WorkspaceStore) SaveMember(ctx context.Context, member domain.WorkspaceMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members, exists := s.members[member.WorkspaceID]
+	if !exists {
+		return appErrors.ErrNotFound
+	}
+
+	previous, existed := members[member.UserID]
+	members[member.UserID] = member
+
+	if err := s.saveToFile(); err != nil {
+		if existed {
+			members[member.UserID] = previous
+		} else {
+			delete(members, member.UserID)
+		}
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember removes the user from the workspace
+func (s *WorkspaceStore) RemoveMember(ctx context.Context, workspaceID string, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, exists := s.members[workspaceID][userID]
+	if !exists {
+		return appErrors.ErrNotFound
+	}
+
+	delete(s.members[workspaceID], userID)
+
+	if err := s.saveToFile(); err != nil {
+		s.members[workspaceID][userID] = member
+		return fmt.Errorf("ошибка сохранения в файл: %w", err)
+	}
+
+	return nil
+}
+
+func (s *WorkspaceStore) saveToFile() error {
+	if s.file == "" {
+		return nil
+	}
+
+	var data workspaceFile
+	for _, ws := range s.workspaces {
+		data.Workspaces = append(data.Workspaces, ws)
+	}
+	for _, members := range s.members {
+		for _, member := range members {
+			data.Members = append(data.Members, memberRecord{WorkspaceMember: member, WorkspaceID: member.WorkspaceID})
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации данных: %w", err)
+	}
+
+	if err := os.WriteFile(s.file, jsonData, 0600); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", s.file, err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"log"
+	"net/http"
 
 	_ "net/http/pprof"
 
@@ -11,62 +12,210 @@ import (
 	mdlwr "github.com/go-chi/chi/v5/middleware"
 
 	"github.com/Te8va/shortURL/internal/app/config"
+	"github.com/Te8va/shortURL/internal/app/domain"
 	"github.com/Te8va/shortURL/internal/app/handler"
 	"github.com/Te8va/shortURL/internal/app/middleware"
+	"github.com/Te8va/shortURL/internal/app/openapi"
+	"github.com/Te8va/shortURL/internal/app/problem"
 	"github.com/Te8va/shortURL/internal/app/service"
 )
 
+// Deps holds the services the router wires into handlers
+type Deps struct {
+	Tokens  *middleware.TokenManager
+	Saver   service.URLSaverServ
+	Getter  service.URLGetterServ
+	Pinger  service.PingerServ
+	Updater service.URLUpdaterServ
+	Auth    service.AuthServ
+	Keys    service.APIKeyServ
+	// Deleter deletes and restores links, both answer 501 if nil
+	Deleter service.URLDeleteServ
+	// Limits stores rate limit buckets, in memory if nil
+	Limits middleware.RateLimitStore
+	Policy handler.URLChecker
+	// Webhooks receives link events and manages subscriptions, events are not published if nil
+	Webhooks service.WebhookServ
+	// Workspaces manages shared workspaces, links can only be personal if nil
+	Workspaces service.WorkspaceServ
+	// Variants splits the traffic of A/B links, links always redirect to their original URL if nil
+	Variants service.URLVariantServ
+	// Rules manages the rules routing visitors of links by device, language and time of day
+	Rules service.URLRuleServ
+	// Query manages the UTM parameters and query string forwarding of links
+	Query service.URLQueryServ
+	// Pages fetches the title, description and images of the destinations of new links, nothing is fetched if nil
+	Pages service.PageFetchServ
+	// Moderation takes abuse reports and disables links, links can not be reported if nil
+	Moderation service.ModerationServ
+	// Audit records who changed which links, changes are not audited if nil
+	Audit service.AuditServ
+	// Jobs runs bulk deletions in the background and reports their outcome, deletions answer 501 if nil
+	Jobs service.JobServ
+}
+
 // NewRouter creates and configures the main HTTP router for the application
-func NewRouter(cfg *config.Config, saver service.URLSaverServ, getter service.URLGetterServ, pinger service.PingerServ, deleter service.URLDeleteServ) chi.Router {
+func NewRouter(cfg *config.Config, deps Deps) chi.Router {
 	r := chi.NewRouter()
 
 	if err := middleware.Initialize("info"); err != nil {
 		log.Println("Failed to initialize middleware:", err)
 	}
 
-	r.Use(middleware.AuthMiddleware(cfg.JWTKey))
+	if deps.Limits == nil {
+		deps.Limits = middleware.NewMemoryRateLimitStore()
+	}
+	limiter := middleware.NewRateLimiter(deps.Limits, cfg.TrustProxyHeaders)
+
+	r.Use(middleware.AuthMiddleware(deps.Tokens, deps.Keys))
 	r.Use(middleware.WithLogging)
 
-	r.Mount("/", newRootRouter(cfg, saver, getter))
-	r.Mount("/api", newAPIRouter(cfg, saver, getter, deleter))
-	r.Mount("/ping", newPingRouter(pinger))
+	if cfg.ValidateRequests {
+		validator, err := openapi.NewValidator()
+		if err != nil {
+			log.Println("Failed to load OpenAPI specification:", err)
+		} else {
+			r.Use(validator.Middleware)
+		}
+	}
+
+	r.Mount("/", newRootRouter(cfg, deps, limiter))
+	r.Mount("/api", newAPIRouter(cfg, deps, limiter))
+	r.Mount("/ping", newPingRouter(deps.Pinger))
 	r.Mount("/debug", mdlwr.Profiler())
 
 	return r
 }
 
-func newRootRouter(cfg *config.Config, saver service.URLSaverServ, getter service.URLGetterServ) chi.Router {
+func newRootRouter(cfg *config.Config, deps Deps, limiter *middleware.RateLimiter) chi.Router {
 	r := chi.NewRouter()
 
-	saveHandler := handler.NewSaveHandler(saver)
-	getHandler := handler.NewGetterHandler(getter, cfg)
+	saveHandler := handler.NewSaveHandler(deps.Saver, deps.Policy, cfg, handler.SaveOptions{Events: deps.Webhooks, Workspaces: deps.Workspaces, Pages: deps.Pages, Audit: deps.Audit})
+	getHandler := handler.NewGetterHandler(deps.Getter, cfg, handler.GetterOptions{Events: deps.Webhooks, Workspaces: deps.Workspaces, Variants: deps.Variants, Moderation: deps.Moderation})
+	qrHandler := handler.NewQRHandler(deps.Getter, cfg, deps.Moderation)
+
+	shortenLimit := limiter.Limit("shorten", cfg.RateLimitShorten)
+	redirectLimit := limiter.Limit("redirect", cfg.RateLimitRedirect)
 
-	r.Post("/", saveHandler.PostHandler)
-	r.Get("/{id}", getHandler.GetHandler)
+	r.With(middleware.RequireScope(domain.ScopeShorten), shortenLimit).Post("/", saveHandler.PostHandler)
+	r.With(redirectLimit).Get("/{id}", getHandler.GetHandler)
+	r.With(redirectLimit).Get("/{id}/qr", qrHandler.QRHandler)
+
+	if deps.Moderation != nil {
+		moderationHandler := handler.NewModerationHandler(deps.Moderation, cfg, deps.Audit)
+		r.With(limiter.Limit("report", cfg.RateLimitReport)).Post("/{id}/report", moderationHandler.ReportHandler)
+	}
 
 	return r
 }
 
-func newAPIRouter(cfg *config.Config, saver service.URLSaverServ, getter service.URLGetterServ, deleter service.URLDeleteServ) chi.Router {
+func newAPIRouter(cfg *config.Config, deps Deps, limiter *middleware.RateLimiter) chi.Router {
 	r := chi.NewRouter()
 
-	saveHandler := handler.NewSaveHandler(saver)
-	getHandler := handler.NewGetterHandler(getter, cfg)
-	deleteHandler := handler.NewDeleteHandler(deleter, cfg)
+	saveHandler := handler.NewSaveHandler(deps.Saver, deps.Policy, cfg, handler.SaveOptions{Events: deps.Webhooks, Workspaces: deps.Workspaces, Pages: deps.Pages, Audit: deps.Audit})
+	getHandler := handler.NewGetterHandler(deps.Getter, cfg, handler.GetterOptions{Events: deps.Webhooks, Workspaces: deps.Workspaces, Variants: deps.Variants, Moderation: deps.Moderation})
+	updateHandler := handler.NewUpdateHandler(deps.Updater, cfg, deps.Audit)
+	authHandler := handler.NewAuthHandler(deps.Auth, deps.Tokens)
+	apiKeyHandler := handler.NewAPIKeyHandler(deps.Keys)
+	webhookHandler := handler.NewWebhookHandler(deps.Webhooks)
+	workspaceHandler := handler.NewWorkspaceHandler(deps.Workspaces, cfg, deps.Audit)
+	variantHandler := handler.NewVariantHandler(deps.Variants, deps.Policy, cfg, deps.Audit)
+	ruleHandler := handler.NewRuleHandler(deps.Rules, deps.Policy, cfg, deps.Audit)
+	queryHandler := handler.NewQueryHandler(deps.Query, cfg, deps.Audit)
+
+	r.Get("/openapi.json", openapi.SpecHandler)
+	r.Get("/docs", openapi.DocsHandler)
+	r.Get("/docs/{file}", openapi.DocsAssetHandler)
 
 	r.Route("/shorten", func(r chi.Router) {
-		r.Post("/", saveHandler.PostHandlerJSON)
-		r.Post("/batch", saveHandler.PostHandlerBatch)
+		r.Use(middleware.RequireScope(domain.ScopeShorten))
+		r.With(limiter.Limit("shorten", cfg.RateLimitShorten)).Post("/", saveHandler.PostHandlerJSON)
+		r.With(limiter.Limit("batch", cfg.RateLimitBatch)).Post("/batch", saveHandler.PostHandlerBatch)
+		r.With(limiter.Limit("batch", cfg.RateLimitBatch)).Post("/stream", saveHandler.PostHandlerStream)
+	})
+
+	r.Route("/auth", func(r chi.Router) {
+		authLimit := limiter.Limit("auth", cfg.RateLimitAuth)
+		r.With(authLimit).Post("/register", authHandler.RegisterHandler)
+		r.With(authLimit).Post("/login", authHandler.LoginHandler)
+		r.Post("/logout", authHandler.LogoutHandler)
 	})
 
 	r.Route("/user", func(r chi.Router) {
-		r.Get("/urls", getHandler.GetUserURLsHandler)
-		r.Delete("/urls", deleteHandler.DeleteUserURLsHandler)
+		r.With(middleware.RequireScope(domain.ScopeRead)).Get("/urls", getHandler.GetUserURLsHandler)
+		if deps.Deleter != nil {
+			deleteHandler := handler.NewDeleteHandler(deps.Deleter, cfg, handler.DeleteOptions{Events: deps.Webhooks, Audit: deps.Audit, Jobs: deps.Jobs})
+			r.With(middleware.RequireScope(domain.ScopeDelete), limiter.Limit("delete", cfg.RateLimitDelete)).Delete("/urls", deleteHandler.DeleteUserURLsHandler)
+			r.With(middleware.RequireScope(domain.ScopeDelete), limiter.Limit("delete", cfg.RateLimitDelete)).Post("/urls/restore", deleteHandler.RestoreUserURLsHandler)
+		} else {
+			r.Delete("/urls", notImplemented)
+			r.Post("/urls/restore", notImplemented)
+		}
+		r.With(middleware.RequireScope(domain.ScopeShorten)).Patch("/urls/{id}", updateHandler.UpdateLinkHandler)
+		r.With(middleware.RequireScope(domain.ScopeRead)).Get("/urls/{id}/variants", variantHandler.ListVariantsHandler)
+		r.With(middleware.RequireScope(domain.ScopeShorten)).Put("/urls/{id}/variants", variantHandler.SetVariantsHandler)
+		r.With(middleware.RequireScope(domain.ScopeRead)).Get("/urls/{id}/rules", ruleHandler.ListRulesHandler)
+		r.With(middleware.RequireScope(domain.ScopeShorten)).Put("/urls/{id}/rules", ruleHandler.SetRulesHandler)
+		r.With(middleware.RequireScope(domain.ScopeRead)).Get("/urls/{id}/query", queryHandler.GetQueryOptionsHandler)
+		r.With(middleware.RequireScope(domain.ScopeShorten)).Put("/urls/{id}/query", queryHandler.SetQueryOptionsHandler)
+
+		r.Route("/keys", func(r chi.Router) {
+			r.Use(middleware.RequireSession)
+			r.Get("/", apiKeyHandler.ListAPIKeysHandler)
+			r.Post("/", apiKeyHandler.CreateAPIKeyHandler)
+			r.Delete("/{id}", apiKeyHandler.RevokeAPIKeyHandler)
+		})
+
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(middleware.RequireSession)
+			r.Get("/", webhookHandler.ListWebhooksHandler)
+			r.Post("/", webhookHandler.CreateWebhookHandler)
+			r.Delete("/{id}", webhookHandler.DeleteWebhookHandler)
+			r.Get("/dead-letters", webhookHandler.ListDeadLettersHandler)
+		})
+
+		r.Route("/workspaces", func(r chi.Router) {
+			r.Use(middleware.RequireSession)
+			r.Get("/", workspaceHandler.ListWorkspacesHandler)
+			r.Post("/", workspaceHandler.CreateWorkspaceHandler)
+			r.Get("/{id}/members", workspaceHandler.ListMembersHandler)
+			r.Put("/{id}/members/{userID}", workspaceHandler.SetMemberHandler)
+			r.Delete("/{id}/members/{userID}", workspaceHandler.RemoveMemberHandler)
+			r.Post("/{id}/urls", workspaceHandler.TransferLinksHandler)
+		})
+	})
+
+	if deps.Jobs != nil {
+		jobHandler := handler.NewJobHandler(deps.Jobs)
+		r.Get("/jobs/{id}", jobHandler.GetJobHandler)
+	}
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(middleware.RequireAdmin(cfg.AdminUserIDs))
+
+		if deps.Moderation != nil {
+			moderationHandler := handler.NewModerationHandler(deps.Moderation, cfg, deps.Audit)
+			r.Get("/moderation", moderationHandler.QueueHandler)
+			r.Get("/moderation/{id}", moderationHandler.ReviewHandler)
+			r.Post("/moderation/{id}", moderationHandler.ModerateHandler)
+			r.Get("/moderation-actions", moderationHandler.ActionsHandler)
+		}
+
+		if deps.Audit != nil {
+			auditHandler := handler.NewAuditHandler(deps.Audit)
+			r.Get("/audit", auditHandler.QueryHandler)
+			r.Get("/audit/export", auditHandler.ExportHandler)
+		}
 	})
 
 	return r
 }
 
+// notImplemented answers routes the configured storage has no support for
+func notImplemented(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, http.StatusNotImplemented, problem.CodeNotImplemented)
+}
+
 func newPingRouter(pinger service.PingerServ) chi.Router {
 	r := chi.NewRouter()
 
@@ -0,0 +1,119 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/config"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/middleware"
+	"github.com/Te8va/shortURL/internal/app/openapi"
+	"github.com/Te8va/shortURL/internal/app/router"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+// TestRoutesDocumented fails when a route is registered without being described in the OpenAPI document or the other way round
+func TestRoutesDocumented(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	cfg := &config.Config{BaseURL: "http://localhost:8080"}
+	r := router.NewRouter(cfg, router.Deps{
+		Tokens:     middleware.NewTokenManager(middleware.NewHMACKey("test", "secret"), time.Hour, false),
+		Pinger:     mocks.NewMockPingerServ(ctrl),
+		Moderation: mocks.NewMockModerationServ(ctrl),
+		Audit:      mocks.NewMockAuditServ(ctrl),
+		Jobs:       mocks.NewMockJobServ(ctrl),
+	})
+
+	registered := make(map[openapi.Route]bool)
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if strings.HasPrefix(route, "/debug/") {
+			return nil
+		}
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		registered[openapi.Route{Method: method, Path: route}] = true
+		return nil
+	})
+	require.NoError(t, err)
+
+	documented, err := openapi.Routes()
+	require.NoError(t, err)
+
+	for _, route := range documented {
+		require.True(t, registered[route], "%s %s is documented but not routed", route.Method, route.Path)
+		delete(registered, route)
+	}
+	for route := range registered {
+		require.Failf(t, "route is not documented", "%s %s is missing from openapi.json", route.Method, route.Path)
+	}
+}
+
+// TestRouter_DeleteWithoutDeleter checks that storages which can not delete links answer 501 instead of panicking
+func TestRouter_DeleteWithoutDeleter(t *testing.T) {
+	cfg := &config.Config{BaseURL: "http://localhost:8080"}
+	r := router.NewRouter(cfg, router.Deps{
+		Tokens: middleware.NewTokenManager(middleware.NewHMACKey("test", "secret"), time.Hour, false),
+	})
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodPost, path: "/api/user/urls/restore"},
+		{method: http.MethodDelete, path: "/api/user/urls"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`["abc"]`))
+			w := httptest.NewRecorder()
+
+			require.NotPanics(t, func() { r.ServeHTTP(w, req) })
+			require.Equal(t, http.StatusNotImplemented, w.Code)
+			require.Contains(t, w.Body.String(), `"code":"not_implemented"`)
+		})
+	}
+}
+
+// TestRouter_AuthRateLimited checks that login and register share one bucket, so passwords can not be guessed
+// by alternating between them
+func TestRouter_AuthRateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	auth := mocks.NewMockAuthServ(ctrl)
+	auth.EXPECT().Login(gomock.Any(), "alice", "password1").Return(0, appErrors.ErrInvalidCredentials).Times(1)
+	auth.EXPECT().Register(gomock.Any(), "alice", "password1").Return(0, appErrors.ErrUserExists).Times(1)
+
+	cfg := &config.Config{BaseURL: "http://localhost:8080", RateLimitAuth: config.RateLimit{Requests: 2, Period: time.Minute}}
+	r := router.NewRouter(cfg, router.Deps{
+		Tokens: middleware.NewTokenManager(middleware.NewHMACKey("test", "secret"), time.Hour, false),
+		Auth:   auth,
+	})
+
+	tests := []struct {
+		path     string
+		wantCode int
+	}{
+		{path: "/api/auth/login", wantCode: http.StatusUnauthorized},
+		{path: "/api/auth/register", wantCode: http.StatusConflict},
+		{path: "/api/auth/login", wantCode: http.StatusTooManyRequests},
+		{path: "/api/auth/register", wantCode: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`{"login":"alice","password":"password1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, tt.wantCode, w.Code, tt.path)
+	}
+}
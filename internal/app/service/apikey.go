@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+const (
+	apiKeyPrefix      = "sk_"
+	apiKeySecretBytes = 24
+	apiKeyIDBytes     = 8
+	apiKeyShownChars  = 8
+)
+
+// APIKeyStorage defines the interface for a storage of hashed API keys
+//
+//go:generate mockgen -source=apikey.go -destination=mocks/apikey_mock.gen.go -package=mocks
+type APIKeyStorage interface {
+	CreateAPIKey(ctx context.Context, key domain.APIKey) error
+	ListAPIKeys(ctx context.Context, userID int) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID int, id string) error
+	GetAPIKeyByHash(ctx context.Context, hash string) (domain.APIKey, error)
+}
+
+// APIKeyServ defines the interface for a service that manages and authenticates API keys
+type APIKeyServ interface {
+	CreateAPIKey(ctx context.Context, userID int, name string, scopes []string) (domain.APIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, userID int) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID int, id string) error
+	AuthenticateAPIKey(ctx context.Context, key string) (domain.APIKey, error)
+}
+
+// APIKeyService issues personal API keys and resolves them back to users
+type APIKeyService struct {
+	keys APIKeyStorage
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService with the given storage
+func NewAPIKeyService(keys APIKeyStorage) *APIKeyService {
+	return &APIKeyService{keys: keys}
+}
+
+// CreateAPIKey generates a new key for the user. The plain key is returned once and only its hash is stored
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, userID int, name string, scopes []string) (domain.APIKeyResponse, error) {
+	for _, scope := range scopes {
+		if scope != domain.ScopeShorten && scope != domain.ScopeRead && scope != domain.ScopeDelete {
+			return domain.APIKeyResponse{}, appErrors.ErrInvalidScope
+		}
+	}
+
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return domain.APIKeyResponse{}, fmt.Errorf("service.CreateAPIKey: %w", err)
+	}
+	id, err := randomHex(apiKeyIDBytes)
+	if err != nil {
+		return domain.APIKeyResponse{}, fmt.Errorf("service.CreateAPIKey: %w", err)
+	}
+
+	plain := apiKeyPrefix + secret
+	if scopes == nil {
+		scopes = []string{}
+	}
+	key := domain.APIKey{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plain[:len(apiKeyPrefix)+apiKeyShownChars],
+		Hash:      HashAPIKey(plain),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.keys.CreateAPIKey(ctx, key); err != nil {
+		return domain.APIKeyResponse{}, fmt.Errorf("service.CreateAPIKey: %w", err)
+	}
+
+	return domain.APIKeyResponse{APIKey: key, Key: plain}, nil
+}
+
+// ListAPIKeys delegates listing the user's API keys to repository
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID int) ([]domain.APIKey, error) {
+	return s.keys.ListAPIKeys(ctx, userID)
+}
+
+// RevokeAPIKey delegates revoking the user's API key to repository
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID int, id string) error {
+	return s.keys.RevokeAPIKey(ctx, userID, id)
+}
+
+// AuthenticateAPIKey returns the stored key matching the plain key if it exists and is not revoked
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (domain.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return domain.APIKey{}, appErrors.ErrInvalidAPIKey
+	}
+
+	stored, err := s.keys.GetAPIKeyByHash(ctx, HashAPIKey(key))
+	if errors.Is(err, appErrors.ErrNotFound) {
+		return domain.APIKey{}, appErrors.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("service.AuthenticateAPIKey: %w", err)
+	}
+
+	if stored.RevokedAt != nil {
+		return domain.APIKey{}, appErrors.ErrInvalidAPIKey
+	}
+
+	return stored, nil
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 of the plain key. Keys carry enough entropy that a slow hash is unnecessary
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
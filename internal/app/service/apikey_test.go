@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeys := mocks.NewMockAPIKeyStorage(ctrl)
+	svc := service.NewAPIKeyService(mockKeys)
+
+	var stored domain.APIKey
+	mockKeys.EXPECT().
+		CreateAPIKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, key domain.APIKey) error {
+			stored = key
+			return nil
+		})
+
+	resp, err := svc.CreateAPIKey(context.Background(), 7, "ci", []string{domain.ScopeShorten})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(resp.Key, "sk_"))
+	assert.True(t, strings.HasPrefix(resp.Key, resp.Prefix))
+	assert.Equal(t, service.HashAPIKey(resp.Key), stored.Hash)
+	assert.NotContains(t, stored.Hash, resp.Key)
+	assert.Equal(t, 7, stored.UserID)
+	assert.Equal(t, []string{domain.ScopeShorten}, stored.Scopes)
+
+	_, err = svc.CreateAPIKey(context.Background(), 7, "ci", []string{"admin"})
+	assert.ErrorIs(t, err, appErrors.ErrInvalidScope)
+}
+
+func TestAPIKeyService_AuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeys := mocks.NewMockAPIKeyStorage(ctrl)
+	svc := service.NewAPIKeyService(mockKeys)
+
+	revokedAt := time.Now()
+	active := domain.APIKey{ID: "a", UserID: 7}
+	revoked := domain.APIKey{ID: "b", UserID: 7, RevokedAt: &revokedAt}
+
+	tests := []struct {
+		name      string
+		key       string
+		mockSetup func()
+		wantErr   error
+	}{
+		{
+			name: "active key",
+			key:  "sk_active",
+			mockSetup: func() {
+				mockKeys.EXPECT().GetAPIKeyByHash(gomock.Any(), service.HashAPIKey("sk_active")).Return(active, nil)
+			},
+		},
+		{
+			name: "revoked key",
+			key:  "sk_revoked",
+			mockSetup: func() {
+				mockKeys.EXPECT().GetAPIKeyByHash(gomock.Any(), service.HashAPIKey("sk_revoked")).Return(revoked, nil)
+			},
+			wantErr: appErrors.ErrInvalidAPIKey,
+		},
+		{
+			name: "unknown key",
+			key:  "sk_unknown",
+			mockSetup: func() {
+				mockKeys.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Return(domain.APIKey{}, appErrors.ErrNotFound)
+			},
+			wantErr: appErrors.ErrInvalidAPIKey,
+		},
+		{
+			name:      "wrong prefix",
+			key:       "pk_whatever",
+			mockSetup: func() {},
+			wantErr:   appErrors.ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			key, err := svc.AuthenticateAPIKey(context.Background(), tt.key)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, active, key)
+		})
+	}
+}
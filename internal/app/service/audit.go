@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+const (
+	auditIDBytes      = 8
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+var auditActions = map[string]bool{
+	domain.AuditCreate:      true,
+	domain.AuditBatchCreate: true,
+	domain.AuditUpdate:      true,
+	domain.AuditDelete:      true,
+	domain.AuditRestore:     true,
+	domain.AuditVariants:    true,
+	domain.AuditRules:       true,
+	domain.AuditQuery:       true,
+	domain.AuditTransfer:    true,
+	domain.AuditModerate:    true,
+	domain.AuditTakedown:    true,
+}
+
+// AuditStorage defines the interface for an append-only storage of the audit log
+//
+//go:generate mockgen -source=audit.go -destination=mocks/audit_mock.gen.go -package=mocks
+type AuditStorage interface {
+	SaveAuditEntries(ctx context.Context, entries []domain.AuditEntry) error
+	ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// AuditServ defines the interface for a service that records changes of links and lets administrators search them
+type AuditServ interface {
+	Snapshot(ctx context.Context, linkID string) (*domain.AuditState, error)
+	Record(ctx context.Context, entries ...domain.AuditEntry) error
+	Query(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	Export(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// AuditService keeps an audit log of who created, changed and deleted which links, from which address and when
+type AuditService struct {
+	audit AuditStorage
+	links LinkStorage
+}
+
+// NewAuditService creates a new instance of AuditService with the given storages
+func NewAuditService(audit AuditStorage, links LinkStorage) *AuditService {
+	return &AuditService{audit: audit, links: links}
+}
+
+// Snapshot returns the current state of a link to be recorded as the state before a change, nil if it does not exist
+func (s *AuditService) Snapshot(ctx context.Context, linkID string) (*domain.AuditState, error) {
+	link, err := s.links.GetLink(ctx, linkID)
+	if errors.Is(err, appErrors.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service.Snapshot: %w", err)
+	}
+
+	return domain.NewAuditState(link), nil
+}
+
+// Record appends the entries to the audit log, giving them IDs and the current time
+func (s *AuditService) Record(ctx context.Context, entries ...domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	for i := range entries {
+		id, err := randomHex(auditIDBytes)
+		if err != nil {
+			return fmt.Errorf("service.Record: %w", err)
+		}
+		entries[i].ID = id
+		if entries[i].CreatedAt.IsZero() {
+			entries[i].CreatedAt = now
+		}
+	}
+
+	if err := s.audit.SaveAuditEntries(ctx, entries); err != nil {
+		return fmt.Errorf("service.Record: %w", err)
+	}
+
+	return nil
+}
+
+// Query returns the newest entries matching the filter, at most 100 unless the filter asks for up to 1000
+func (s *AuditService) Query(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if err := validateAuditFilter(filter); err != nil {
+		return nil, err
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		return nil, appErrors.ErrInvalidAuditFilter
+	}
+
+	entries, err := s.audit.ListAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("service.Query: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Export returns all entries matching the filter, newest first
+func (s *AuditService) Export(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if err := validateAuditFilter(filter); err != nil {
+		return nil, err
+	}
+	filter.Limit = 0
+
+	entries, err := s.audit.ListAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("service.Export: %w", err)
+	}
+
+	return entries, nil
+}
+
+func validateAuditFilter(filter domain.AuditFilter) error {
+	if filter.Action != "" && !auditActions[filter.Action] {
+		return appErrors.ErrInvalidAuditFilter
+	}
+	if filter.Limit < 0 || (!filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To)) {
+		return appErrors.ErrInvalidAuditFilter
+	}
+	return nil
+}
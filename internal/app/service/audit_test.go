@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestAuditService_Snapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	links := mocks.NewMockLinkStorage(ctrl)
+	svc := service.NewAuditService(mocks.NewMockAuditStorage(ctrl), links)
+
+	links.EXPECT().GetLink(gomock.Any(), "abc").Return(domain.Link{ID: "abc", OriginalURL: "https://example.com", LinkMeta: domain.LinkMeta{Title: "Example"}}, nil)
+	links.EXPECT().GetLink(gomock.Any(), "missing").Return(domain.Link{}, appErrors.ErrNotFound)
+
+	state, err := svc.Snapshot(context.Background(), "abc")
+	require.NoError(t, err)
+	require.Equal(t, &domain.AuditState{OriginalURL: "https://example.com", Title: "Example"}, state)
+
+	state, err = svc.Snapshot(context.Background(), "missing")
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
+
+func TestAuditService_Record(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockAuditStorage(ctrl)
+	svc := service.NewAuditService(storage, mocks.NewMockLinkStorage(ctrl))
+
+	storage.EXPECT().SaveAuditEntries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, entries []domain.AuditEntry) error {
+			require.Len(t, entries, 2)
+			require.NotEmpty(t, entries[0].ID)
+			require.NotEqual(t, entries[0].ID, entries[1].ID)
+			require.False(t, entries[0].CreatedAt.IsZero())
+			require.Equal(t, entries[0].CreatedAt, entries[1].CreatedAt)
+			return nil
+		})
+
+	require.NoError(t, svc.Record(context.Background(),
+		domain.AuditEntry{Action: domain.AuditBatchCreate, LinkID: "a"},
+		domain.AuditEntry{Action: domain.AuditBatchCreate, LinkID: "b"},
+	))
+	require.NoError(t, svc.Record(context.Background()))
+}
+
+func TestAuditService_Query(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockAuditStorage(ctrl)
+	svc := service.NewAuditService(storage, mocks.NewMockLinkStorage(ctrl))
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, filter := range []domain.AuditFilter{
+			{Action: "rename"},
+			{Limit: -1},
+			{Limit: 1001},
+			{From: at, To: at},
+		} {
+			_, err := svc.Query(context.Background(), filter)
+			require.ErrorIs(t, err, appErrors.ErrInvalidAuditFilter)
+		}
+	})
+
+	t.Run("default limit", func(t *testing.T) {
+		storage.EXPECT().ListAuditEntries(gomock.Any(), domain.AuditFilter{LinkID: "abc", Limit: 100}).
+			Return([]domain.AuditEntry{{ID: "e1"}}, nil)
+
+		entries, err := svc.Query(context.Background(), domain.AuditFilter{LinkID: "abc"})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+
+	t.Run("export is not limited", func(t *testing.T) {
+		storage.EXPECT().ListAuditEntries(gomock.Any(), domain.AuditFilter{Action: domain.AuditDelete}).Return(nil, nil)
+
+		_, err := svc.Export(context.Background(), domain.AuditFilter{Action: domain.AuditDelete, Limit: 5})
+		require.NoError(t, err)
+	})
+}
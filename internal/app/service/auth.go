@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+const (
+	minPasswordLength = 8
+	// maxPasswordLength is the most bcrypt can hash, longer passwords are rejected by it
+	maxPasswordLength = 72
+)
+
+// UserStorage defines the interface for a storage of user accounts
+//
+//go:generate mockgen -source=auth.go -destination=mocks/auth_mock.gen.go -package=mocks
+type UserStorage interface {
+	CreateUser(ctx context.Context, login, passwordHash string) (int, error)
+	GetUserByLogin(ctx context.Context, login string) (domain.User, error)
+}
+
+// URLClaimer defines the interface for moving links from one user ID to another
+type URLClaimer interface {
+	ClaimURLs(ctx context.Context, fromUserID, toUserID int) error
+}
+
+// AuthServ defines the interface for a service that registers and authenticates users
+type AuthServ interface {
+	Register(ctx context.Context, login, password string) (int, error)
+	Login(ctx context.Context, login, password string) (int, error)
+	ClaimURLs(ctx context.Context, fromUserID, toUserID int) error
+}
+
+// AuthService registers users, verifies their passwords and hands anonymous links over to accounts
+type AuthService struct {
+	users   UserStorage
+	claimer URLClaimer
+}
+
+// NewAuthService creates a new instance of AuthService with the given dependencies
+func NewAuthService(users UserStorage, claimer URLClaimer) *AuthService {
+	return &AuthService{users: users, claimer: claimer}
+}
+
+// Register creates an account with a bcrypt hash of the password and returns its user ID
+func (s *AuthService) Register(ctx context.Context, login, password string) (int, error) {
+	if login == "" || len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return 0, appErrors.ErrWeakCredentials
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, fmt.Errorf("service.Register: %w", err)
+	}
+
+	id, err := s.users.CreateUser(ctx, login, string(hash))
+	if err != nil {
+		return 0, fmt.Errorf("service.Register: %w", err)
+	}
+
+	return id, nil
+}
+
+// Login checks the password against the stored hash and returns the user ID of the account
+func (s *AuthService) Login(ctx context.Context, login, password string) (int, error) {
+	user, err := s.users.GetUserByLogin(ctx, login)
+	if errors.Is(err, appErrors.ErrNotFound) {
+		return 0, appErrors.ErrInvalidCredentials
+	}
+	if err != nil {
+		return 0, fmt.Errorf("service.Login: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return 0, appErrors.ErrInvalidCredentials
+	}
+
+	return user.ID, nil
+}
+
+// ClaimURLs delegates moving links of an anonymous user to an account to repository
+func (s *AuthService) ClaimURLs(ctx context.Context, fromUserID, toUserID int) error {
+	if fromUserID == toUserID {
+		return nil
+	}
+	return s.claimer.ClaimURLs(ctx, fromUserID, toUserID)
+}
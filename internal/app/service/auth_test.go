@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestAuthService_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsers := mocks.NewMockUserStorage(ctrl)
+	svc := service.NewAuthService(mockUsers, nil)
+
+	t.Run("success stores bcrypt hash", func(t *testing.T) {
+		mockUsers.EXPECT().
+			CreateUser(gomock.Any(), "alice", gomock.Any()).
+			DoAndReturn(func(ctx context.Context, login, hash string) (int, error) {
+				assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("password123")))
+				return domain.FirstRegisteredUserID, nil
+			})
+
+		id, err := svc.Register(context.Background(), "alice", "password123")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.FirstRegisteredUserID, id)
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		_, err := svc.Register(context.Background(), "alice", "short")
+		assert.ErrorIs(t, err, appErrors.ErrWeakCredentials)
+	})
+
+	t.Run("password longer than bcrypt accepts", func(t *testing.T) {
+		_, err := svc.Register(context.Background(), "alice", strings.Repeat("a", 73))
+		assert.ErrorIs(t, err, appErrors.ErrWeakCredentials)
+	})
+
+	t.Run("login taken", func(t *testing.T) {
+		mockUsers.EXPECT().
+			CreateUser(gomock.Any(), "bob", gomock.Any()).
+			Return(0, appErrors.ErrUserExists)
+
+		_, err := svc.Register(context.Background(), "bob", "password123")
+		assert.ErrorIs(t, err, appErrors.ErrUserExists)
+	})
+}
+
+func TestAuthService_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsers := mocks.NewMockUserStorage(ctrl)
+	svc := service.NewAuthService(mockUsers, nil)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	user := domain.User{ID: 1000001, Login: "alice", PasswordHash: string(hash)}
+
+	tests := []struct {
+		name      string
+		login     string
+		password  string
+		mockSetup func()
+		wantID    int
+		wantErr   error
+	}{
+		{
+			name:     "success",
+			login:    "alice",
+			password: "password123",
+			mockSetup: func() {
+				mockUsers.EXPECT().GetUserByLogin(gomock.Any(), "alice").Return(user, nil)
+			},
+			wantID: 1000001,
+		},
+		{
+			name:     "wrong password",
+			login:    "alice",
+			password: "wrongpassword",
+			mockSetup: func() {
+				mockUsers.EXPECT().GetUserByLogin(gomock.Any(), "alice").Return(user, nil)
+			},
+			wantErr: appErrors.ErrInvalidCredentials,
+		},
+		{
+			name:     "unknown login",
+			login:    "nobody",
+			password: "password123",
+			mockSetup: func() {
+				mockUsers.EXPECT().GetUserByLogin(gomock.Any(), "nobody").Return(domain.User{}, appErrors.ErrNotFound)
+			},
+			wantErr: appErrors.ErrInvalidCredentials,
+		},
+		{
+			name:     "storage error",
+			login:    "alice",
+			password: "password123",
+			mockSetup: func() {
+				mockUsers.EXPECT().GetUserByLogin(gomock.Any(), "alice").Return(domain.User{}, errors.New("db error"))
+			},
+			wantErr: errors.New("service.Login: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			id, err := svc.Login(context.Background(), tt.login, tt.password)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantID, id)
+		})
+	}
+}
+
+func TestAuthService_ClaimURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClaimer := mocks.NewMockURLClaimer(ctrl)
+	svc := service.NewAuthService(nil, mockClaimer)
+
+	mockClaimer.EXPECT().ClaimURLs(gomock.Any(), 42, 1000001).Return(nil).Times(1)
+
+	assert.NoError(t, svc.ClaimURLs(context.Background(), 42, 1000001))
+	assert.NoError(t, svc.ClaimURLs(context.Background(), 1000001, 1000001))
+}
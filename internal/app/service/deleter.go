@@ -1,15 +1,26 @@
 package service
 
-import "context"
+import (
+	"context"
+	"time"
 
-// URLDeleteServ defines the interface for service that deletes user URLs
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// URLDeleteServ defines the interface for service that deletes and restores user URLs
 //
 //go:generate mockgen -source=deleter.go -destination=mocks/delete_mock.gen.go -package=mocks
 type URLDeleteServ interface {
-	DeleteUserURLs(ctx context.Context, ids []string, userID int) error
+	DeleteUserURLs(ctx context.Context, ids []string, userID int) ([]domain.Link, error)
+	RestoreUserURLs(ctx context.Context, ids []string, userID int, deletedAfter time.Time) ([]domain.RestoreResult, []domain.Link, error)
 }
 
-// DeleteUserURLs delegates the delete operation to repository
-func (s *URLService) DeleteUserURLs(ctx context.Context, ids []string, userID int) error {
+// DeleteUserURLs delegates the delete operation to repository and returns the links it deleted
+func (s *URLService) DeleteUserURLs(ctx context.Context, ids []string, userID int) ([]domain.Link, error) {
 	return s.deleter.DeleteUserURLs(ctx, ids, userID)
 }
+
+// RestoreUserURLs delegates the restore operation to repository and returns the outcome per ID and the restored links
+func (s *URLService) RestoreUserURLs(ctx context.Context, ids []string, userID int, deletedAfter time.Time) ([]domain.RestoreResult, []domain.Link, error) {
+	return s.deleter.RestoreUserURLs(ctx, ids, userID, deletedAfter)
+}
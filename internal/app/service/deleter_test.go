@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Te8va/shortURL/internal/app/domain"
 	"github.com/Te8va/shortURL/internal/app/service"
 	"github.com/Te8va/shortURL/internal/app/service/mocks"
 )
@@ -33,7 +35,7 @@ func TestURLService_DeleteUserURLs(t *testing.T) {
 				mockDeleter.
 					EXPECT().
 					DeleteUserURLs(gomock.Any(), []string{"abc123", "xyz789"}, 1).
-					Return(nil)
+					Return([]domain.Link{{ID: "abc123"}, {ID: "xyz789"}}, nil)
 			},
 			expectedErr: nil,
 		},
@@ -45,7 +47,7 @@ func TestURLService_DeleteUserURLs(t *testing.T) {
 				mockDeleter.
 					EXPECT().
 					DeleteUserURLs(gomock.Any(), []string{"badid"}, 2).
-					Return(errors.New("delete failed"))
+					Return(nil, errors.New("delete failed"))
 			},
 			expectedErr: errors.New("delete failed"),
 		},
@@ -55,14 +57,35 @@ func TestURLService_DeleteUserURLs(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mockSetup()
 
-			svc := service.NewURLService(nil, nil, nil, mockDeleter)
-			err := svc.DeleteUserURLs(context.Background(), tc.ids, tc.userID)
+			svc := service.NewURLService(nil, nil, nil, mockDeleter, nil, nil, nil, nil)
+			links, err := svc.DeleteUserURLs(context.Background(), tc.ids, tc.userID)
 
 			if tc.expectedErr != nil {
 				assert.EqualError(t, err, tc.expectedErr.Error())
 			} else {
 				assert.NoError(t, err)
+				assert.Len(t, links, len(tc.ids))
 			}
 		})
 	}
 }
+
+func TestURLService_RestoreUserURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDeleter := mocks.NewMockURLDeleteServ(ctrl)
+	deletedAfter := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	mockDeleter.EXPECT().RestoreUserURLs(gomock.Any(), []string{"abc123", "gone"}, 1, deletedAfter).Return(
+		[]domain.RestoreResult{{ID: "abc123", Status: domain.RestoreRestored}, {ID: "gone", Status: domain.RestoreExpired}},
+		[]domain.Link{{ID: "abc123"}}, nil)
+
+	svc := service.NewURLService(nil, nil, nil, mockDeleter, nil, nil, nil, nil)
+	results, links, err := svc.RestoreUserURLs(context.Background(), []string{"abc123", "gone"}, 1, deletedAfter)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, domain.RestoreExpired, results[1].Status)
+	assert.Len(t, links, 1)
+}
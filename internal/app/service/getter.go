@@ -1,21 +1,25 @@
 package service
 
-import "context"
+import (
+	"context"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
 
 // URLGetterServ defines the interface for a service that retrieves URLs
 //
 //go:generate mockgen -source=getter.go -destination=mocks/getter_mock.gen.go -package=mocks
 type URLGetterServ interface {
-	Get(ctx context.Context, id string) (string, bool, bool)
-	GetUserURLs(ctx context.Context, userID int) ([]map[string]string, error)
+	Get(ctx context.Context, host, id string) (domain.Redirect, bool, bool)
+	GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error)
 }
 
 // Get delegates the retrieval operation to repository
-func (s *URLService) Get(ctx context.Context, id string) (string, bool, bool) {
-	return s.getter.Get(ctx, id)
+func (s *URLService) Get(ctx context.Context, host, id string) (domain.Redirect, bool, bool) {
+	return s.getter.Get(ctx, host, id)
 }
 
-// GetUserURLs delegates the retrieval of the user's URLs to repository
-func (s *URLService) GetUserURLs(ctx context.Context, userID int) ([]map[string]string, error) {
-	return s.getter.GetUserURLs(ctx, userID)
+// GetUserURLs delegates the retrieval and search of the user's URLs to repository
+func (s *URLService) GetUserURLs(ctx context.Context, userID int, filter domain.LinkFilter) ([]domain.Link, error) {
+	return s.getter.GetUserURLs(ctx, userID, filter)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Te8va/shortURL/internal/app/domain"
 	"github.com/Te8va/shortURL/internal/app/service"
 	"github.com/Te8va/shortURL/internal/app/service/mocks"
 )
@@ -17,7 +18,7 @@ func TestURLService_Get(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockGetter := mocks.NewMockURLGetterServ(ctrl)
-	svc := service.NewURLService(nil, mockGetter, nil, nil)
+	svc := service.NewURLService(nil, mockGetter, nil, nil, nil, nil, nil, nil)
 
 	testCases := []struct {
 		name           string
@@ -29,12 +30,12 @@ func TestURLService_Get(t *testing.T) {
 	}{
 		{
 			name: "found and not deleted",
-			id:   "http://localhost/abc123",
+			id:   "abc123",
 			mockSetup: func() {
 				mockGetter.
 					EXPECT().
-					Get(gomock.Any(), "http://localhost/abc123").
-					Return("https://example.com", true, false)
+					Get(gomock.Any(), "localhost", "abc123").
+					Return(domain.Redirect{OriginalURL: "https://example.com"}, true, false)
 			},
 			expectedURL:    "https://example.com",
 			expectedExists: true,
@@ -42,12 +43,12 @@ func TestURLService_Get(t *testing.T) {
 		},
 		{
 			name: "not found",
-			id:   "http://localhost/404",
+			id:   "404",
 			mockSetup: func() {
 				mockGetter.
 					EXPECT().
-					Get(gomock.Any(), "http://localhost/404").
-					Return("", false, false)
+					Get(gomock.Any(), "localhost", "404").
+					Return(domain.Redirect{}, false, false)
 			},
 			expectedURL:    "",
 			expectedExists: false,
@@ -55,12 +56,12 @@ func TestURLService_Get(t *testing.T) {
 		},
 		{
 			name: "found but deleted",
-			id:   "http://localhost/deleted",
+			id:   "deleted",
 			mockSetup: func() {
 				mockGetter.
 					EXPECT().
-					Get(gomock.Any(), "http://localhost/deleted").
-					Return("", true, true)
+					Get(gomock.Any(), "localhost", "deleted").
+					Return(domain.Redirect{}, true, true)
 			},
 			expectedURL:    "",
 			expectedExists: true,
@@ -71,8 +72,8 @@ func TestURLService_Get(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mockSetup()
-			url, exists, deleted := svc.Get(context.Background(), tc.id)
-			assert.Equal(t, tc.expectedURL, url)
+			redirect, exists, deleted := svc.Get(context.Background(), "localhost", tc.id)
+			assert.Equal(t, tc.expectedURL, redirect.OriginalURL)
 			assert.Equal(t, tc.expectedExists, exists)
 			assert.Equal(t, tc.expectedDel, deleted)
 		})
@@ -84,18 +85,18 @@ func TestURLService_GetUserURLs(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockGetter := mocks.NewMockURLGetterServ(ctrl)
-	svc := service.NewURLService(nil, mockGetter, nil, nil)
+	svc := service.NewURLService(nil, mockGetter, nil, nil, nil, nil, nil, nil)
 
 	t.Run("success", func(t *testing.T) {
-		expected := []map[string]string{
-			{"short": "abc123", "original": "https://google.com"},
+		expected := []domain.Link{
+			{ID: "abc123", Domain: "localhost", OriginalURL: "https://google.com", UserID: 1},
 		}
 		mockGetter.
 			EXPECT().
-			GetUserURLs(gomock.Any(), 1).
+			GetUserURLs(gomock.Any(), 1, domain.LinkFilter{Tag: "docs", Query: "google"}).
 			Return(expected, nil)
 
-		result, err := svc.GetUserURLs(context.Background(), 1)
+		result, err := svc.GetUserURLs(context.Background(), 1, domain.LinkFilter{Tag: "docs", Query: "google"})
 		assert.NoError(t, err)
 		assert.Equal(t, expected, result)
 	})
@@ -103,10 +104,10 @@ func TestURLService_GetUserURLs(t *testing.T) {
 	t.Run("error from getter", func(t *testing.T) {
 		mockGetter.
 			EXPECT().
-			GetUserURLs(gomock.Any(), 2).
+			GetUserURLs(gomock.Any(), 2, domain.LinkFilter{}).
 			Return(nil, errors.New("db error"))
 
-		result, err := svc.GetUserURLs(context.Background(), 2)
+		result, err := svc.GetUserURLs(context.Background(), 2, domain.LinkFilter{})
 		assert.Error(t, err)
 		assert.Nil(t, result)
 	})
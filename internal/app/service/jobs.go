@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+const jobIDBytes = 8
+
+// jobFailure is what users see of an error that stopped a job, the error itself is only logged
+const jobFailure = "deletion failed, please try again"
+
+// JobStorage defines the interface for a storage of background jobs
+//
+//go:generate mockgen -source=jobs.go -destination=mocks/jobs_mock.gen.go -package=mocks
+type JobStorage interface {
+	SaveJob(ctx context.Context, job domain.Job) error
+	GetJob(ctx context.Context, id string) (domain.Job, error)
+	ListUnfinishedJobs(ctx context.Context) ([]domain.Job, error)
+	PurgeJobs(ctx context.Context, finishedBefore time.Time) (int64, error)
+}
+
+// LinkDeleter defines the interface for a storage that deletes links and tells why a link could not be deleted
+type LinkDeleter interface {
+	DeleteUserURLs(ctx context.Context, ids []string, userID int) ([]domain.Link, error)
+	LookupLink(ctx context.Context, id string) (domain.LinkRecord, error)
+}
+
+// JobServ defines the interface for a service that runs bulk deletions in the background and reports their outcome
+type JobServ interface {
+	SubmitDelete(ctx context.Context, userID int, ids []string, deleted func(links []domain.Link)) (domain.Job, error)
+	Job(ctx context.Context, userID int, id string) (domain.Job, error)
+}
+
+// JobOptions tunes the job service. Zero values are replaced with defaults
+type JobOptions struct {
+	// Retention is how long a finished job can be looked up
+	Retention time.Duration
+	// Workers bounds how many jobs run at once
+	Workers int
+	// QueueSize bounds how many jobs wait for a worker before submitting blocks
+	QueueSize int
+	// Resumed, if set, is called with the links a job resumed by Start deleted. It stands in for the callback passed
+	// to SubmitDelete, which does not survive a restart
+	Resumed func(job domain.Job, links []domain.Link)
+}
+
+func (o JobOptions) withDefaults() JobOptions {
+	if o.Retention <= 0 {
+		o.Retention = 24 * time.Hour
+	}
+	if o.Workers <= 0 {
+		o.Workers = 2
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 100
+	}
+	return o
+}
+
+type queuedJob struct {
+	job     domain.Job
+	deleted func(links []domain.Link)
+}
+
+// JobService runs the bulk deletions users request in the background and keeps their state and per-link results
+// for Retention after they finish. Jobs left unfinished by a restart are run again on Start
+type JobService struct {
+	jobs      JobStorage
+	links     LinkDeleter
+	opts      JobOptions
+	queue     chan queuedJob
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewJobService creates a new instance of JobService, Start runs its workers
+func NewJobService(jobs JobStorage, links LinkDeleter, opts JobOptions) *JobService {
+	opts = opts.withDefaults()
+	return &JobService{
+		jobs:  jobs,
+		links: links,
+		opts:  opts,
+		queue: make(chan queuedJob, opts.QueueSize),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start runs the workers, queues the jobs left unfinished and purges expired jobs every hour until Close is called
+func (s *JobService) Start() {
+	s.wg.Add(s.opts.Workers)
+	for i := 0; i < s.opts.Workers; i++ {
+		go func() {
+			defer s.wg.Done()
+			for {
+				select {
+				case <-s.done:
+					return
+				case q := <-s.queue:
+					s.run(q)
+				}
+			}
+		}()
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		jobs, err := s.jobs.ListUnfinishedJobs(context.Background())
+		if err != nil {
+			log.Println("Failed to resume jobs:", err)
+		}
+		for _, job := range jobs {
+			select {
+			case <-s.done:
+				return
+			case s.queue <- s.resume(job):
+			}
+		}
+
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.jobs.PurgeJobs(context.Background(), time.Now().Add(-s.opts.Retention)); err != nil {
+				log.Println("Failed to purge jobs:", err)
+			}
+
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops the workers once the jobs in flight finish. Queued jobs stay queued and run after the next Start
+func (s *JobService) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+}
+
+// SubmitDelete queues the deletion of the user's links and returns the queued job. deleted, if set, is called with the
+// links the job deleted once it is done
+func (s *JobService) SubmitDelete(ctx context.Context, userID int, ids []string, deleted func(links []domain.Link)) (domain.Job, error) {
+	id, err := randomHex(jobIDBytes)
+	if err != nil {
+		return domain.Job{}, fmt.Errorf("service.SubmitDelete: %w", err)
+	}
+
+	job := domain.Job{
+		ID:        id,
+		Type:      domain.JobDelete,
+		State:     domain.JobQueued,
+		UserID:    userID,
+		IDs:       ids,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.jobs.SaveJob(ctx, job); err != nil {
+		return domain.Job{}, fmt.Errorf("service.SubmitDelete: %w", err)
+	}
+
+	select {
+	case s.queue <- queuedJob{job: job, deleted: deleted}:
+	case <-ctx.Done():
+		// the job is saved as queued, so it runs after the next restart
+		return domain.Job{}, fmt.Errorf("service.SubmitDelete: %w", ctx.Err())
+	}
+
+	return job, nil
+}
+
+// Job returns the job with the given ID if the user submitted it
+func (s *JobService) Job(ctx context.Context, userID int, id string) (domain.Job, error) {
+	job, err := s.jobs.GetJob(ctx, id)
+	if err != nil {
+		return domain.Job{}, fmt.Errorf("service.Job: %w", err)
+	}
+	if job.UserID != userID {
+		return domain.Job{}, appErrors.ErrNotFound
+	}
+
+	return job, nil
+}
+
+// resume queues a job left unfinished by a restart with the Resumed callback in place of the one it was submitted with
+func (s *JobService) resume(job domain.Job) queuedJob {
+	q := queuedJob{job: job}
+	if resumed := s.opts.Resumed; resumed != nil {
+		q.deleted = func(links []domain.Link) {
+			resumed(job, links)
+		}
+	}
+	return q
+}
+
+// run deletes the links of the job and records the outcome for each of them
+func (s *JobService) run(q queuedJob) {
+	ctx := context.Background()
+	job := q.job
+
+	started := time.Now().UTC()
+	job.State = domain.JobRunning
+	job.StartedAt = &started
+	if err := s.jobs.SaveJob(ctx, job); err != nil {
+		log.Println("Failed to save job:", err)
+	}
+
+	links, err := s.deleteLinks(ctx, &job)
+	finished := time.Now().UTC()
+	job.FinishedAt = &finished
+	if err != nil {
+		log.Printf("Job %s failed: %v", job.ID, err)
+		job.State = domain.JobFailed
+		job.Error = jobFailure
+	} else {
+		job.State = domain.JobDone
+	}
+
+	if err := s.jobs.SaveJob(ctx, job); err != nil {
+		log.Println("Failed to save job:", err)
+	}
+
+	if q.deleted != nil && len(links) > 0 {
+		q.deleted(links)
+	}
+}
+
+// deleteLinks deletes the links of the job and fills in its results, telling links that do not exist or are already
+// deleted from links the user may not delete
+func (s *JobService) deleteLinks(ctx context.Context, job *domain.Job) ([]domain.Link, error) {
+	links, err := s.links.DeleteUserURLs(ctx, job.IDs, job.UserID)
+	if err != nil && !errors.Is(err, appErrors.ErrNotFound) {
+		return nil, err
+	}
+
+	deleted := make(map[string]bool, len(links))
+	for _, link := range links {
+		deleted[link.ID] = true
+	}
+
+	job.Results = make([]domain.JobResult, 0, len(job.IDs))
+	seen := make(map[string]bool, len(job.IDs))
+	for _, id := range job.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		status := domain.JobResultDeleted
+		if !deleted[id] {
+			link, err := s.links.LookupLink(ctx, id)
+			switch {
+			case errors.Is(err, appErrors.ErrNotFound) || (err == nil && link.Deleted):
+				status = domain.JobResultNotFound
+			case err != nil:
+				return links, err
+			default:
+				status = domain.JobResultNotOwned
+			}
+		}
+		job.Results = append(job.Results, domain.JobResult{ID: id, Status: status})
+	}
+
+	return links, nil
+}
@@ -0,0 +1,216 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+// runJob submits a deletion and returns the job as it was saved when it finished
+func runJob(t *testing.T, storage *mocks.MockJobStorage, links *mocks.MockLinkDeleter, ids []string) (domain.Job, []domain.Link) {
+	t.Helper()
+
+	finished := make(chan domain.Job, 1)
+	storage.EXPECT().SaveJob(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, job domain.Job) error {
+			if job.FinishedAt != nil {
+				finished <- job
+			}
+			return nil
+		}).AnyTimes()
+
+	svc := service.NewJobService(storage, links, service.JobOptions{})
+	svc.Start()
+	defer svc.Close()
+
+	deleted := make(chan []domain.Link, 1)
+	job, err := svc.SubmitDelete(context.Background(), 7, ids, func(links []domain.Link) { deleted <- links })
+	require.NoError(t, err)
+	require.Equal(t, domain.JobQueued, job.State)
+	require.NotEmpty(t, job.ID)
+
+	select {
+	case job = <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not finish")
+	}
+	// the links are handed over after the job is saved, Close waits for that
+	svc.Close()
+
+	select {
+	case links := <-deleted:
+		return job, links
+	default:
+		return job, nil
+	}
+}
+
+func TestJobService_Delete(t *testing.T) {
+	t.Run("done", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		storage := mocks.NewMockJobStorage(ctrl)
+		links := mocks.NewMockLinkDeleter(ctrl)
+		storage.EXPECT().ListUnfinishedJobs(gomock.Any()).Return(nil, nil)
+		storage.EXPECT().PurgeJobs(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+
+		links.EXPECT().DeleteUserURLs(gomock.Any(), []string{"abc", "gone", "theirs", "abc"}, 7).
+			Return([]domain.Link{{ID: "abc", UserID: 7}}, nil)
+		links.EXPECT().LookupLink(gomock.Any(), "gone").Return(domain.LinkRecord{}, appErrors.ErrNotFound)
+		links.EXPECT().LookupLink(gomock.Any(), "theirs").Return(domain.LinkRecord{Link: domain.Link{ID: "theirs", UserID: 8}}, nil)
+
+		job, deleted := runJob(t, storage, links, []string{"abc", "gone", "theirs", "abc"})
+		require.Equal(t, domain.JobDone, job.State)
+		require.NotNil(t, job.StartedAt)
+		require.Equal(t, []domain.JobResult{
+			{ID: "abc", Status: domain.JobResultDeleted},
+			{ID: "gone", Status: domain.JobResultNotFound},
+			{ID: "theirs", Status: domain.JobResultNotOwned},
+		}, job.Results)
+		require.Len(t, deleted, 1)
+	})
+
+	t.Run("nothing deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		storage := mocks.NewMockJobStorage(ctrl)
+		links := mocks.NewMockLinkDeleter(ctrl)
+		storage.EXPECT().ListUnfinishedJobs(gomock.Any()).Return(nil, nil)
+		storage.EXPECT().PurgeJobs(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+
+		links.EXPECT().DeleteUserURLs(gomock.Any(), []string{"gone"}, 7).Return(nil, appErrors.ErrNotFound)
+		links.EXPECT().LookupLink(gomock.Any(), "gone").Return(domain.LinkRecord{}, appErrors.ErrNotFound)
+
+		job, deleted := runJob(t, storage, links, []string{"gone"})
+		require.Equal(t, domain.JobDone, job.State)
+		require.Equal(t, []domain.JobResult{{ID: "gone", Status: domain.JobResultNotFound}}, job.Results)
+		require.Empty(t, deleted)
+	})
+
+	t.Run("submitted again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		links := mocks.NewMockLinkDeleter(ctrl)
+		storage := func() *mocks.MockJobStorage {
+			storage := mocks.NewMockJobStorage(ctrl)
+			storage.EXPECT().ListUnfinishedJobs(gomock.Any()).Return(nil, nil)
+			storage.EXPECT().PurgeJobs(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+			return storage
+		}
+
+		gomock.InOrder(
+			links.EXPECT().DeleteUserURLs(gomock.Any(), []string{"abc"}, 7).Return([]domain.Link{{ID: "abc", UserID: 7}}, nil),
+			links.EXPECT().DeleteUserURLs(gomock.Any(), []string{"abc"}, 7).Return(nil, appErrors.ErrNotFound),
+		)
+		links.EXPECT().LookupLink(gomock.Any(), "abc").Return(domain.LinkRecord{Link: domain.Link{ID: "abc", UserID: 7}, Deleted: true}, nil)
+
+		job, deleted := runJob(t, storage(), links, []string{"abc"})
+		require.Equal(t, []domain.JobResult{{ID: "abc", Status: domain.JobResultDeleted}}, job.Results)
+		require.Len(t, deleted, 1)
+
+		job, deleted = runJob(t, storage(), links, []string{"abc"})
+		require.Equal(t, []domain.JobResult{{ID: "abc", Status: domain.JobResultNotFound}}, job.Results)
+		require.Empty(t, deleted, "links deleted before must not be reported, audited or published again")
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		storage := mocks.NewMockJobStorage(ctrl)
+		links := mocks.NewMockLinkDeleter(ctrl)
+		storage.EXPECT().ListUnfinishedJobs(gomock.Any()).Return(nil, nil)
+		storage.EXPECT().PurgeJobs(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+
+		links.EXPECT().DeleteUserURLs(gomock.Any(), []string{"abc"}, 7).Return(nil, errors.New("connection refused"))
+
+		job, _ := runJob(t, storage, links, []string{"abc"})
+		require.Equal(t, domain.JobFailed, job.State)
+		require.NotEmpty(t, job.Error)
+		require.NotContains(t, job.Error, "connection refused")
+	})
+}
+
+func TestJobService_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockJobStorage(ctrl)
+	links := mocks.NewMockLinkDeleter(ctrl)
+
+	unfinished := domain.Job{ID: "j1", Type: domain.JobDelete, State: domain.JobRunning, UserID: 7, IDs: []string{"abc"}}
+	storage.EXPECT().ListUnfinishedJobs(gomock.Any()).Return([]domain.Job{unfinished}, nil)
+	storage.EXPECT().PurgeJobs(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	links.EXPECT().DeleteUserURLs(gomock.Any(), []string{"abc"}, 7).Return([]domain.Link{{ID: "abc"}}, nil)
+
+	finished := make(chan domain.Job, 1)
+	storage.EXPECT().SaveJob(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, job domain.Job) error {
+			if job.FinishedAt != nil {
+				finished <- job
+			}
+			return nil
+		}).AnyTimes()
+
+	type resumed struct {
+		job   domain.Job
+		links []domain.Link
+	}
+	deleted := make(chan resumed, 1)
+	svc := service.NewJobService(storage, links, service.JobOptions{
+		Resumed: func(job domain.Job, links []domain.Link) { deleted <- resumed{job: job, links: links} },
+	})
+	svc.Start()
+	defer svc.Close()
+
+	select {
+	case job := <-finished:
+		require.Equal(t, "j1", job.ID)
+		require.Equal(t, domain.JobDone, job.State)
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not resumed")
+	}
+	// the links are handed over after the job is saved, Close waits for that
+	svc.Close()
+
+	select {
+	case got := <-deleted:
+		require.Equal(t, "j1", got.job.ID)
+		require.Equal(t, []domain.Link{{ID: "abc"}}, got.links)
+	default:
+		t.Fatal("deleted links of the resumed job were not handed over")
+	}
+}
+
+func TestJobService_Job(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockJobStorage(ctrl)
+	svc := service.NewJobService(storage, mocks.NewMockLinkDeleter(ctrl), service.JobOptions{})
+
+	storage.EXPECT().GetJob(gomock.Any(), "j1").Return(domain.Job{ID: "j1", UserID: 7, State: domain.JobDone}, nil).Times(2)
+	storage.EXPECT().GetJob(gomock.Any(), "missing").Return(domain.Job{}, appErrors.ErrNotFound)
+
+	job, err := svc.Job(context.Background(), 7, "j1")
+	require.NoError(t, err)
+	require.Equal(t, domain.JobDone, job.State)
+
+	_, err = svc.Job(context.Background(), 8, "j1")
+	require.ErrorIs(t, err, appErrors.ErrNotFound)
+
+	_, err = svc.Job(context.Background(), 7, "missing")
+	require.ErrorIs(t, err, appErrors.ErrNotFound)
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// LinkCheckUserAgent is sent with every request of the dead-link checker
+const LinkCheckUserAgent = "shortURL-link-checker/1.0"
+
+// errCheckRefused is recorded for links and redirects the checker does not request. The refused URL and address are
+// left out, health results are shown to users and must not tell them about the internal network
+var errCheckRefused = errors.New("not checked: the URL is rejected by the URL policy or is not publicly routable")
+
+// LinkHealthStorage defines the interface for a storage of links due for a check and the outcome of checks
+//
+//go:generate mockgen -source=linkcheck.go -destination=mocks/linkcheck_mock.gen.go -package=mocks
+type LinkHealthStorage interface {
+	LinksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]domain.Link, error)
+	SaveLinkHealth(ctx context.Context, health map[string]domain.LinkHealth) error
+}
+
+// LinkCheckOptions tunes the dead-link checker. Zero values are replaced with defaults
+type LinkCheckOptions struct {
+	// Interval is how long a check stays fresh before the link is checked again
+	Interval time.Duration
+	// Poll is how often the checker looks for links due for a check
+	Poll time.Duration
+	// Timeout bounds a single request
+	Timeout time.Duration
+	// Workers bounds how many links are checked at once
+	Workers int
+	// HostDelay is the minimum time between two requests to the same host
+	HostDelay    time.Duration
+	BatchSize    int
+	MaxRedirects int
+	// Policy is checked for every link before it is requested and for every redirect, nothing is checked if nil
+	Policy URLChecker
+	// AllowPrivate allows connecting to loopback and private addresses, which are refused by default so that
+	// a public host name resolving to an internal address can not be used to probe the internal network
+	AllowPrivate bool
+}
+
+func (o LinkCheckOptions) withDefaults() LinkCheckOptions {
+	if o.Interval <= 0 {
+		o.Interval = 24 * time.Hour
+	}
+	if o.Poll <= 0 {
+		o.Poll = time.Minute
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.Workers <= 0 {
+		o.Workers = 8
+	}
+	if o.HostDelay < 0 {
+		o.HostDelay = 0
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.MaxRedirects <= 0 {
+		o.MaxRedirects = 10
+	}
+	return o
+}
+
+// LinkChecker periodically requests the original URLs of links and records their status code, the redirects they went
+// through and when they were checked. At most Workers links are checked at once and requests to the same host are
+// spaced by HostDelay
+type LinkChecker struct {
+	links     LinkHealthStorage
+	opts      LinkCheckOptions
+	client    *http.Client
+	hosts     *hostGate
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewLinkChecker creates a new instance of LinkChecker, Start runs it in the background
+func NewLinkChecker(links LinkHealthStorage, opts LinkCheckOptions) *LinkChecker {
+	opts = opts.withDefaults()
+	return &LinkChecker{
+		links:  links,
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout, Transport: outboundTransport(opts.Timeout, opts.AllowPrivate)},
+		hosts:  newHostGate(opts.HostDelay),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start checks the links that are due every Poll until Close is called
+func (c *LinkChecker) Start() {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.opts.Poll)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-c.done:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			if _, err := c.CheckLinks(ctx); err != nil {
+				log.Println("Failed to check links:", err)
+			}
+			cancel()
+
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops the checker and waits for the checks in flight
+func (c *LinkChecker) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	c.wg.Wait()
+}
+
+// CheckLinks checks all links not checked within Interval and returns how many were checked
+func (c *LinkChecker) CheckLinks(ctx context.Context) (int, error) {
+	checkedBefore := time.Now().Add(-c.opts.Interval)
+	checked := 0
+
+	for ctx.Err() == nil {
+		links, err := c.links.LinksToCheck(ctx, checkedBefore, c.opts.BatchSize)
+		if err != nil {
+			return checked, fmt.Errorf("service.CheckLinks: %w", err)
+		}
+		if len(links) == 0 {
+			break
+		}
+
+		health := c.checkBatch(ctx, links)
+		if ctx.Err() != nil {
+			break
+		}
+		if err := c.links.SaveLinkHealth(ctx, health); err != nil {
+			return checked, fmt.Errorf("service.CheckLinks: %w", err)
+		}
+		checked += len(health)
+
+		if len(links) < c.opts.BatchSize {
+			break
+		}
+	}
+
+	return checked, nil
+}
+
+func (c *LinkChecker) checkBatch(ctx context.Context, links []domain.Link) map[string]domain.LinkHealth {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		health = make(map[string]domain.LinkHealth, len(links))
+		queue  = make(chan domain.Link)
+	)
+
+	workers := c.opts.Workers
+	if workers > len(links) {
+		workers = len(links)
+	}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for link := range queue {
+				h := c.check(ctx, link.OriginalURL)
+				mu.Lock()
+				health[link.ID] = h
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, link := range links {
+		queue <- link
+	}
+	close(queue)
+	wg.Wait()
+
+	return health
+}
+
+// check requests the URL with HEAD, falling back to GET for servers that do not support HEAD
+func (c *LinkChecker) check(ctx context.Context, rawURL string) domain.LinkHealth {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return domain.LinkHealth{Error: "invalid URL", CheckedAt: time.Now().UTC()}
+	}
+	if c.policyCheck(rawURL) != nil {
+		return domain.LinkHealth{Error: errCheckRefused.Error(), CheckedAt: time.Now().UTC()}
+	}
+
+	if err := c.hosts.wait(ctx, u.Host); err != nil {
+		return domain.LinkHealth{Error: err.Error(), CheckedAt: time.Now().UTC()}
+	}
+	health := c.request(ctx, http.MethodHead, rawURL)
+
+	if health.Status == http.StatusMethodNotAllowed || health.Status == http.StatusNotImplemented {
+		if err := c.hosts.wait(ctx, u.Host); err != nil {
+			return domain.LinkHealth{Error: err.Error(), CheckedAt: time.Now().UTC()}
+		}
+		health = c.request(ctx, http.MethodGet, rawURL)
+	}
+
+	return health
+}
+
+func (c *LinkChecker) request(ctx context.Context, method, rawURL string) domain.LinkHealth {
+	var health domain.LinkHealth
+
+	client := *c.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > c.opts.MaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", c.opts.MaxRedirects)
+		}
+		if c.policyCheck(req.URL.String()) != nil {
+			return errCheckRefused
+		}
+		health.Redirects = append(health.Redirects, req.URL.String())
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		health.Error = err.Error()
+		health.CheckedAt = time.Now().UTC()
+		return health
+	}
+	req.Header.Set("User-Agent", LinkCheckUserAgent)
+
+	resp, err := client.Do(req)
+	health.CheckedAt = time.Now().UTC()
+	if errors.Is(err, errCheckRefused) || errors.Is(err, errNonPublicAddress) {
+		health.Error = errCheckRefused.Error()
+		return health
+	} else if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	defer resp.Body.Close()
+
+	// drain a little of the body so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	health.Status = resp.StatusCode
+	return health
+}
+
+func (c *LinkChecker) policyCheck(rawURL string) error {
+	if c.opts.Policy == nil {
+		return nil
+	}
+	return c.opts.Policy.Check(rawURL)
+}
+
+// maxGateHosts is how many hosts hostGate remembers before forgetting those it may send to right away
+const maxGateHosts = 1024
+
+// hostGate spaces requests to the same host by a fixed delay
+type hostGate struct {
+	delay time.Duration
+	mu    sync.Mutex
+	next  map[string]time.Time
+}
+
+func newHostGate(delay time.Duration) *hostGate {
+	return &hostGate{delay: delay, next: make(map[string]time.Time)}
+}
+
+// wait reserves the next slot for a request to host and blocks until it comes
+func (g *hostGate) wait(ctx context.Context, host string) error {
+	g.mu.Lock()
+	now := time.Now()
+	if len(g.next) > maxGateHosts {
+		for h, next := range g.next {
+			if next.Before(now) {
+				delete(g.next, h)
+			}
+		}
+	}
+	slot := g.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	g.next[host] = slot.Add(g.delay)
+	g.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,215 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestLinkChecker_CheckLinks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, service.LinkCheckUserAgent, r.UserAgent())
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved-again", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved-again", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockLinkHealthStorage(ctrl)
+	links := []domain.Link{
+		{ID: "ok", OriginalURL: srv.URL + "/ok"},
+		{ID: "gone", OriginalURL: srv.URL + "/gone"},
+		{ID: "moved", OriginalURL: srv.URL + "/moved"},
+		{ID: "loop", OriginalURL: srv.URL + "/loop"},
+		{ID: "get-only", OriginalURL: srv.URL + "/get-only"},
+		{ID: "down", OriginalURL: "http://127.0.0.1:1/"},
+	}
+
+	var saved map[string]domain.LinkHealth
+	gomock.InOrder(
+		storage.EXPECT().LinksToCheck(gomock.Any(), gomock.Any(), 100).Return(links, nil),
+		storage.EXPECT().SaveLinkHealth(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, health map[string]domain.LinkHealth) error {
+				saved = health
+				return nil
+			}),
+	)
+
+	checker := service.NewLinkChecker(storage, service.LinkCheckOptions{Timeout: 2 * time.Second, MaxRedirects: 3, AllowPrivate: true})
+	checked, err := checker.CheckLinks(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, len(links), checked)
+
+	require.Equal(t, http.StatusOK, saved["ok"].Status)
+	require.Equal(t, domain.HealthOK, domain.HealthState(ptr(saved["ok"])))
+	require.False(t, saved["ok"].CheckedAt.IsZero())
+
+	require.Equal(t, http.StatusNotFound, saved["gone"].Status)
+	require.Equal(t, domain.HealthBroken, domain.HealthState(ptr(saved["gone"])))
+
+	require.Equal(t, http.StatusOK, saved["moved"].Status)
+	require.Equal(t, []string{srv.URL + "/moved-again", srv.URL + "/ok"}, saved["moved"].Redirects)
+
+	require.Contains(t, saved["loop"].Error, "stopped after 3 redirects")
+	require.Equal(t, domain.HealthBroken, domain.HealthState(ptr(saved["loop"])))
+
+	require.Equal(t, http.StatusOK, saved["get-only"].Status)
+
+	require.NotEmpty(t, saved["down"].Error)
+	require.Zero(t, saved["down"].Status)
+}
+
+func TestLinkChecker_Politeness(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []time.Time
+		inFlight atomic.Int32
+		maxSeen  atomic.Int32
+	)
+	polite := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, time.Now())
+		mu.Unlock()
+	}))
+	defer polite.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxSeen.Load()
+			if n <= seen || maxSeen.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var links []domain.Link
+	for _, id := range []string{"a", "b", "c"} {
+		links = append(links, domain.Link{ID: "polite-" + id, OriginalURL: polite.URL + "/" + id})
+	}
+	var slowLinks []domain.Link
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		// without a host delay only the worker limit bounds how many of these are requested at once
+		slowLinks = append(slowLinks, domain.Link{ID: "slow-" + id, OriginalURL: slow.URL + "/" + id})
+	}
+
+	storage := mocks.NewMockLinkHealthStorage(ctrl)
+	storage.EXPECT().LinksToCheck(gomock.Any(), gomock.Any(), gomock.Any()).Return(links, nil)
+	storage.EXPECT().SaveLinkHealth(gomock.Any(), gomock.Len(3)).Return(nil)
+
+	checker := service.NewLinkChecker(storage, service.LinkCheckOptions{Workers: 3, HostDelay: 50 * time.Millisecond, AllowPrivate: true})
+	_, err := checker.CheckLinks(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, requests, 3)
+	for i := 1; i < len(requests); i++ {
+		require.GreaterOrEqual(t, requests[i].Sub(requests[i-1]), 40*time.Millisecond)
+	}
+
+	storage.EXPECT().LinksToCheck(gomock.Any(), gomock.Any(), gomock.Any()).Return(slowLinks, nil)
+	storage.EXPECT().SaveLinkHealth(gomock.Any(), gomock.Len(6)).Return(nil)
+
+	checker = service.NewLinkChecker(storage, service.LinkCheckOptions{Workers: 2, AllowPrivate: true})
+	_, err = checker.CheckLinks(context.Background())
+	require.NoError(t, err)
+	require.LessOrEqual(t, maxSeen.Load(), int32(2))
+}
+
+func TestLinkChecker_RefusesInternal(t *testing.T) {
+	var hits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/internal", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	})
+	mux.HandleFunc("/to-internal", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/internal", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	check := func(t *testing.T, opts service.LinkCheckOptions, links ...domain.Link) map[string]domain.LinkHealth {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var saved map[string]domain.LinkHealth
+		storage := mocks.NewMockLinkHealthStorage(ctrl)
+		storage.EXPECT().LinksToCheck(gomock.Any(), gomock.Any(), gomock.Any()).Return(links, nil)
+		storage.EXPECT().SaveLinkHealth(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, health map[string]domain.LinkHealth) error {
+				saved = health
+				return nil
+			})
+
+		_, err := service.NewLinkChecker(storage, opts).CheckLinks(context.Background())
+		require.NoError(t, err)
+		return saved
+	}
+
+	t.Run("private addresses", func(t *testing.T) {
+		saved := check(t, service.LinkCheckOptions{}, domain.Link{ID: "a", OriginalURL: srv.URL + "/internal"})
+
+		require.Zero(t, saved["a"].Status)
+		require.Contains(t, saved["a"].Error, "not checked")
+		require.NotContains(t, saved["a"].Error, "127.0.0.1", "results must not reveal internal addresses")
+	})
+
+	t.Run("policy", func(t *testing.T) {
+		opts := service.LinkCheckOptions{Policy: blockList{"/internal"}, AllowPrivate: true}
+		saved := check(t, opts,
+			domain.Link{ID: "legacy", OriginalURL: srv.URL + "/internal"},
+			domain.Link{ID: "redirect", OriginalURL: srv.URL + "/to-internal"},
+		)
+
+		require.Contains(t, saved["legacy"].Error, "not checked")
+		require.Zero(t, saved["redirect"].Status)
+		require.Contains(t, saved["redirect"].Error, "not checked")
+		require.Empty(t, saved["redirect"].Redirects, "refused redirects are not recorded")
+	})
+
+	require.Zero(t, hits.Load())
+}
+
+func ptr(h domain.LinkHealth) *domain.LinkHealth {
+	return &h
+}
@@ -0,0 +1,170 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: auth.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserStorage is a mock of UserStorage interface.
+type MockUserStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserStorageMockRecorder
+}
+
+// MockUserStorageMockRecorder is the mock recorder for MockUserStorage.
+type MockUserStorageMockRecorder struct {
+	mock *MockUserStorage
+}
+
+// NewMockUserStorage creates a new mock instance.
+func NewMockUserStorage(ctrl *gomock.Controller) *MockUserStorage {
+	mock := &MockUserStorage{ctrl: ctrl}
+	mock.recorder = &MockUserStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserStorage) EXPECT() *MockUserStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method.
+func (m *MockUserStorage) CreateUser(ctx context.Context, login, passwordHash string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, login, passwordHash)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserStorageMockRecorder) CreateUser(ctx, login, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserStorage)(nil).CreateUser), ctx, login, passwordHash)
+}
+
+// GetUserByLogin mocks base method.
+func (m *MockUserStorage) GetUserByLogin(ctx context.Context, login string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByLogin", ctx, login)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByLogin indicates an expected call of GetUserByLogin.
+func (mr *MockUserStorageMockRecorder) GetUserByLogin(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockUserStorage)(nil).GetUserByLogin), ctx, login)
+}
+
+// MockURLClaimer is a mock of URLClaimer interface.
+type MockURLClaimer struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLClaimerMockRecorder
+}
+
+// MockURLClaimerMockRecorder is the mock recorder for MockURLClaimer.
+type MockURLClaimerMockRecorder struct {
+	mock *MockURLClaimer
+}
+
+// NewMockURLClaimer creates a new mock instance.
+func NewMockURLClaimer(ctrl *gomock.Controller) *MockURLClaimer {
+	mock := &MockURLClaimer{ctrl: ctrl}
+	mock.recorder = &MockURLClaimerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLClaimer) EXPECT() *MockURLClaimerMockRecorder {
+	return m.recorder
+}
+
+// ClaimURLs mocks base method.
+func (m *MockURLClaimer) ClaimURLs(ctx context.Context, fromUserID, toUserID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimURLs", ctx, fromUserID, toUserID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimURLs indicates an expected call of ClaimURLs.
+func (mr *MockURLClaimerMockRecorder) ClaimURLs(ctx, fromUserID, toUserID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimURLs", reflect.TypeOf((*MockURLClaimer)(nil).ClaimURLs), ctx, fromUserID, toUserID)
+}
+
+// MockAuthServ is a mock of AuthServ interface.
+type MockAuthServ struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthServMockRecorder
+}
+
+// MockAuthServMockRecorder is the mock recorder for MockAuthServ.
+type MockAuthServMockRecorder struct {
+	mock *MockAuthServ
+}
+
+// NewMockAuthServ creates a new mock instance.
+func NewMockAuthServ(ctrl *gomock.Controller) *MockAuthServ {
+	mock := &MockAuthServ{ctrl: ctrl}
+	mock.recorder = &MockAuthServMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthServ) EXPECT() *MockAuthServMockRecorder {
+	return m.recorder
+}
+
+// ClaimURLs mocks base method.
+func (m *MockAuthServ) ClaimURLs(ctx context.Context, fromUserID, toUserID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimURLs", ctx, fromUserID, toUserID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimURLs indicates an expected call of ClaimURLs.
+func (mr *MockAuthServMockRecorder) ClaimURLs(ctx, fromUserID, toUserID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimURLs", reflect.TypeOf((*MockAuthServ)(nil).ClaimURLs), ctx, fromUserID, toUserID)
+}
+
+// Login mocks base method.
+func (m *MockAuthServ) Login(ctx context.Context, login, password string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, login, password)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockAuthServMockRecorder) Login(ctx, login, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthServ)(nil).Login), ctx, login, password)
+}
+
+// Register mocks base method.
+func (m *MockAuthServ) Register(ctx context.Context, login, password string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, login, password)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockAuthServMockRecorder) Register(ctx, login, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthServ)(nil).Register), ctx, login, password)
+}
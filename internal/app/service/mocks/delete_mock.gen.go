@@ -7,7 +7,9 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	domain "github.com/Te8va/shortURL/internal/app/domain"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,11 +37,12 @@ func (m *MockURLDeleteServ) EXPECT() *MockURLDeleteServMockRecorder {
 }
 
 // DeleteUserURLs mocks base method.
-func (m *MockURLDeleteServ) DeleteUserURLs(ctx context.Context, ids []string, userID int) error {
+func (m *MockURLDeleteServ) DeleteUserURLs(ctx context.Context, ids []string, userID int) ([]domain.Link, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteUserURLs", ctx, ids, userID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteUserURLs indicates an expected call of DeleteUserURLs.
@@ -47,3 +50,19 @@ func (mr *MockURLDeleteServMockRecorder) DeleteUserURLs(ctx, ids, userID interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserURLs", reflect.TypeOf((*MockURLDeleteServ)(nil).DeleteUserURLs), ctx, ids, userID)
 }
+
+// RestoreUserURLs mocks base method.
+func (m *MockURLDeleteServ) RestoreUserURLs(ctx context.Context, ids []string, userID int, deletedAfter time.Time) ([]domain.RestoreResult, []domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUserURLs", ctx, ids, userID, deletedAfter)
+	ret0, _ := ret[0].([]domain.RestoreResult)
+	ret1, _ := ret[1].([]domain.Link)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RestoreUserURLs indicates an expected call of RestoreUserURLs.
+func (mr *MockURLDeleteServMockRecorder) RestoreUserURLs(ctx, ids, userID, deletedAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUserURLs", reflect.TypeOf((*MockURLDeleteServ)(nil).RestoreUserURLs), ctx, ids, userID, deletedAfter)
+}
@@ -0,0 +1,202 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: jobs.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockJobStorage is a mock of JobStorage interface.
+type MockJobStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobStorageMockRecorder
+}
+
+// MockJobStorageMockRecorder is the mock recorder for MockJobStorage.
+type MockJobStorageMockRecorder struct {
+	mock *MockJobStorage
+}
+
+// NewMockJobStorage creates a new mock instance.
+func NewMockJobStorage(ctrl *gomock.Controller) *MockJobStorage {
+	mock := &MockJobStorage{ctrl: ctrl}
+	mock.recorder = &MockJobStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobStorage) EXPECT() *MockJobStorageMockRecorder {
+	return m.recorder
+}
+
+// GetJob mocks base method.
+func (m *MockJobStorage) GetJob(ctx context.Context, id string) (domain.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(domain.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockJobStorageMockRecorder) GetJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockJobStorage)(nil).GetJob), ctx, id)
+}
+
+// ListUnfinishedJobs mocks base method.
+func (m *MockJobStorage) ListUnfinishedJobs(ctx context.Context) ([]domain.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnfinishedJobs", ctx)
+	ret0, _ := ret[0].([]domain.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnfinishedJobs indicates an expected call of ListUnfinishedJobs.
+func (mr *MockJobStorageMockRecorder) ListUnfinishedJobs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnfinishedJobs", reflect.TypeOf((*MockJobStorage)(nil).ListUnfinishedJobs), ctx)
+}
+
+// PurgeJobs mocks base method.
+func (m *MockJobStorage) PurgeJobs(ctx context.Context, finishedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeJobs", ctx, finishedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeJobs indicates an expected call of PurgeJobs.
+func (mr *MockJobStorageMockRecorder) PurgeJobs(ctx, finishedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeJobs", reflect.TypeOf((*MockJobStorage)(nil).PurgeJobs), ctx, finishedBefore)
+}
+
+// SaveJob mocks base method.
+func (m *MockJobStorage) SaveJob(ctx context.Context, job domain.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveJob indicates an expected call of SaveJob.
+func (mr *MockJobStorageMockRecorder) SaveJob(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveJob", reflect.TypeOf((*MockJobStorage)(nil).SaveJob), ctx, job)
+}
+
+// MockLinkDeleter is a mock of LinkDeleter interface.
+type MockLinkDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinkDeleterMockRecorder
+}
+
+// MockLinkDeleterMockRecorder is the mock recorder for MockLinkDeleter.
+type MockLinkDeleterMockRecorder struct {
+	mock *MockLinkDeleter
+}
+
+// NewMockLinkDeleter creates a new mock instance.
+func NewMockLinkDeleter(ctrl *gomock.Controller) *MockLinkDeleter {
+	mock := &MockLinkDeleter{ctrl: ctrl}
+	mock.recorder = &MockLinkDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinkDeleter) EXPECT() *MockLinkDeleterMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserURLs mocks base method.
+func (m *MockLinkDeleter) DeleteUserURLs(ctx context.Context, ids []string, userID int) ([]domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserURLs", ctx, ids, userID)
+	ret0, _ := ret[0].([]domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserURLs indicates an expected call of DeleteUserURLs.
+func (mr *MockLinkDeleterMockRecorder) DeleteUserURLs(ctx, ids, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserURLs", reflect.TypeOf((*MockLinkDeleter)(nil).DeleteUserURLs), ctx, ids, userID)
+}
+
+// LookupLink mocks base method.
+func (m *MockLinkDeleter) LookupLink(ctx context.Context, id string) (domain.LinkRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupLink", ctx, id)
+	ret0, _ := ret[0].(domain.LinkRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupLink indicates an expected call of LookupLink.
+func (mr *MockLinkDeleterMockRecorder) LookupLink(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupLink", reflect.TypeOf((*MockLinkDeleter)(nil).LookupLink), ctx, id)
+}
+
+// MockJobServ is a mock of JobServ interface.
+type MockJobServ struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobServMockRecorder
+}
+
+// MockJobServMockRecorder is the mock recorder for MockJobServ.
+type MockJobServMockRecorder struct {
+	mock *MockJobServ
+}
+
+// NewMockJobServ creates a new mock instance.
+func NewMockJobServ(ctrl *gomock.Controller) *MockJobServ {
+	mock := &MockJobServ{ctrl: ctrl}
+	mock.recorder = &MockJobServMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobServ) EXPECT() *MockJobServMockRecorder {
+	return m.recorder
+}
+
+// Job mocks base method.
+func (m *MockJobServ) Job(ctx context.Context, userID int, id string) (domain.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Job", ctx, userID, id)
+	ret0, _ := ret[0].(domain.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Job indicates an expected call of Job.
+func (mr *MockJobServMockRecorder) Job(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Job", reflect.TypeOf((*MockJobServ)(nil).Job), ctx, userID, id)
+}
+
+// SubmitDelete mocks base method.
+func (m *MockJobServ) SubmitDelete(ctx context.Context, userID int, ids []string, deleted func([]domain.Link)) (domain.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitDelete", ctx, userID, ids, deleted)
+	ret0, _ := ret[0].(domain.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitDelete indicates an expected call of SubmitDelete.
+func (mr *MockJobServMockRecorder) SubmitDelete(ctx, userID, ids, deleted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitDelete", reflect.TypeOf((*MockJobServ)(nil).SubmitDelete), ctx, userID, ids, deleted)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: linkcheck.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLinkHealthStorage is a mock of LinkHealthStorage interface.
+type MockLinkHealthStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinkHealthStorageMockRecorder
+}
+
+// MockLinkHealthStorageMockRecorder is the mock recorder for MockLinkHealthStorage.
+type MockLinkHealthStorageMockRecorder struct {
+	mock *MockLinkHealthStorage
+}
+
+// NewMockLinkHealthStorage creates a new mock instance.
+func NewMockLinkHealthStorage(ctrl *gomock.Controller) *MockLinkHealthStorage {
+	mock := &MockLinkHealthStorage{ctrl: ctrl}
+	mock.recorder = &MockLinkHealthStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinkHealthStorage) EXPECT() *MockLinkHealthStorageMockRecorder {
+	return m.recorder
+}
+
+// LinksToCheck mocks base method.
+func (m *MockLinkHealthStorage) LinksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinksToCheck", ctx, checkedBefore, limit)
+	ret0, _ := ret[0].([]domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LinksToCheck indicates an expected call of LinksToCheck.
+func (mr *MockLinkHealthStorageMockRecorder) LinksToCheck(ctx, checkedBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinksToCheck", reflect.TypeOf((*MockLinkHealthStorage)(nil).LinksToCheck), ctx, checkedBefore, limit)
+}
+
+// SaveLinkHealth mocks base method.
+func (m *MockLinkHealthStorage) SaveLinkHealth(ctx context.Context, health map[string]domain.LinkHealth) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLinkHealth", ctx, health)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLinkHealth indicates an expected call of SaveLinkHealth.
+func (mr *MockLinkHealthStorageMockRecorder) SaveLinkHealth(ctx, health interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLinkHealth", reflect.TypeOf((*MockLinkHealthStorage)(nil).SaveLinkHealth), ctx, health)
+}
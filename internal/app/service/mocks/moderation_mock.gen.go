@@ -0,0 +1,237 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: moderation.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockModerationStorage is a mock of ModerationStorage interface.
+type MockModerationStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationStorageMockRecorder
+}
+
+// MockModerationStorageMockRecorder is the mock recorder for MockModerationStorage.
+type MockModerationStorageMockRecorder struct {
+	mock *MockModerationStorage
+}
+
+// NewMockModerationStorage creates a new mock instance.
+func NewMockModerationStorage(ctrl *gomock.Controller) *MockModerationStorage {
+	mock := &MockModerationStorage{ctrl: ctrl}
+	mock.recorder = &MockModerationStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationStorage) EXPECT() *MockModerationStorageMockRecorder {
+	return m.recorder
+}
+
+// IsLinkDisabled mocks base method.
+func (m *MockModerationStorage) IsLinkDisabled(ctx context.Context, linkID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsLinkDisabled", ctx, linkID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsLinkDisabled indicates an expected call of IsLinkDisabled.
+func (mr *MockModerationStorageMockRecorder) IsLinkDisabled(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLinkDisabled", reflect.TypeOf((*MockModerationStorage)(nil).IsLinkDisabled), ctx, linkID)
+}
+
+// ListModerationActions mocks base method.
+func (m *MockModerationStorage) ListModerationActions(ctx context.Context, linkID string) ([]domain.ModerationAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListModerationActions", ctx, linkID)
+	ret0, _ := ret[0].([]domain.ModerationAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListModerationActions indicates an expected call of ListModerationActions.
+func (mr *MockModerationStorageMockRecorder) ListModerationActions(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListModerationActions", reflect.TypeOf((*MockModerationStorage)(nil).ListModerationActions), ctx, linkID)
+}
+
+// ListReports mocks base method.
+func (m *MockModerationStorage) ListReports(ctx context.Context, linkID string) ([]domain.AbuseReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReports", ctx, linkID)
+	ret0, _ := ret[0].([]domain.AbuseReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReports indicates an expected call of ListReports.
+func (mr *MockModerationStorageMockRecorder) ListReports(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReports", reflect.TypeOf((*MockModerationStorage)(nil).ListReports), ctx, linkID)
+}
+
+// ModerationQueue mocks base method.
+func (m *MockModerationStorage) ModerationQueue(ctx context.Context, queue string) ([]domain.ModerationCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModerationQueue", ctx, queue)
+	ret0, _ := ret[0].([]domain.ModerationCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ModerationQueue indicates an expected call of ModerationQueue.
+func (mr *MockModerationStorageMockRecorder) ModerationQueue(ctx, queue interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModerationQueue", reflect.TypeOf((*MockModerationStorage)(nil).ModerationQueue), ctx, queue)
+}
+
+// SaveModerationAction mocks base method.
+func (m *MockModerationStorage) SaveModerationAction(ctx context.Context, action domain.ModerationAction, disabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveModerationAction", ctx, action, disabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveModerationAction indicates an expected call of SaveModerationAction.
+func (mr *MockModerationStorageMockRecorder) SaveModerationAction(ctx, action, disabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveModerationAction", reflect.TypeOf((*MockModerationStorage)(nil).SaveModerationAction), ctx, action, disabled)
+}
+
+// SaveReport mocks base method.
+func (m *MockModerationStorage) SaveReport(ctx context.Context, report domain.AbuseReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReport", ctx, report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReport indicates an expected call of SaveReport.
+func (mr *MockModerationStorageMockRecorder) SaveReport(ctx, report interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReport", reflect.TypeOf((*MockModerationStorage)(nil).SaveReport), ctx, report)
+}
+
+// MockModerationServ is a mock of ModerationServ interface.
+type MockModerationServ struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationServMockRecorder
+}
+
+// MockModerationServMockRecorder is the mock recorder for MockModerationServ.
+type MockModerationServMockRecorder struct {
+	mock *MockModerationServ
+}
+
+// NewMockModerationServ creates a new mock instance.
+func NewMockModerationServ(ctrl *gomock.Controller) *MockModerationServ {
+	mock := &MockModerationServ{ctrl: ctrl}
+	mock.recorder = &MockModerationServMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationServ) EXPECT() *MockModerationServMockRecorder {
+	return m.recorder
+}
+
+// Actions mocks base method.
+func (m *MockModerationServ) Actions(ctx context.Context, linkID string) ([]domain.ModerationAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Actions", ctx, linkID)
+	ret0, _ := ret[0].([]domain.ModerationAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Actions indicates an expected call of Actions.
+func (mr *MockModerationServMockRecorder) Actions(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Actions", reflect.TypeOf((*MockModerationServ)(nil).Actions), ctx, linkID)
+}
+
+// Disabled mocks base method.
+func (m *MockModerationServ) Disabled(ctx context.Context, linkID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disabled", ctx, linkID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Disabled indicates an expected call of Disabled.
+func (mr *MockModerationServMockRecorder) Disabled(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disabled", reflect.TypeOf((*MockModerationServ)(nil).Disabled), ctx, linkID)
+}
+
+// Moderate mocks base method.
+func (m *MockModerationServ) Moderate(ctx context.Context, moderatorID int, linkID string, req domain.ModerationRequest) (domain.ModerationAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Moderate", ctx, moderatorID, linkID, req)
+	ret0, _ := ret[0].(domain.ModerationAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Moderate indicates an expected call of Moderate.
+func (mr *MockModerationServMockRecorder) Moderate(ctx, moderatorID, linkID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Moderate", reflect.TypeOf((*MockModerationServ)(nil).Moderate), ctx, moderatorID, linkID, req)
+}
+
+// Queue mocks base method.
+func (m *MockModerationServ) Queue(ctx context.Context, queue string) ([]domain.ModerationCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Queue", ctx, queue)
+	ret0, _ := ret[0].([]domain.ModerationCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Queue indicates an expected call of Queue.
+func (mr *MockModerationServMockRecorder) Queue(ctx, queue interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queue", reflect.TypeOf((*MockModerationServ)(nil).Queue), ctx, queue)
+}
+
+// Report mocks base method.
+func (m *MockModerationServ) Report(ctx context.Context, linkID, reporterIP string, req domain.AbuseReportRequest) (domain.AbuseReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", ctx, linkID, reporterIP, req)
+	ret0, _ := ret[0].(domain.AbuseReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockModerationServMockRecorder) Report(ctx, linkID, reporterIP, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockModerationServ)(nil).Report), ctx, linkID, reporterIP, req)
+}
+
+// Review mocks base method.
+func (m *MockModerationServ) Review(ctx context.Context, linkID string) (domain.ModerationReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Review", ctx, linkID)
+	ret0, _ := ret[0].(domain.ModerationReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Review indicates an expected call of Review.
+func (mr *MockModerationServMockRecorder) Review(ctx, linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Review", reflect.TypeOf((*MockModerationServ)(nil).Review), ctx, linkID)
+}
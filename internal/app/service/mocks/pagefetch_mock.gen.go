@@ -0,0 +1,122 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pagefetch.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPageMetaStorage is a mock of PageMetaStorage interface.
+type MockPageMetaStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockPageMetaStorageMockRecorder
+}
+
+// MockPageMetaStorageMockRecorder is the mock recorder for MockPageMetaStorage.
+type MockPageMetaStorageMockRecorder struct {
+	mock *MockPageMetaStorage
+}
+
+// NewMockPageMetaStorage creates a new mock instance.
+func NewMockPageMetaStorage(ctrl *gomock.Controller) *MockPageMetaStorage {
+	mock := &MockPageMetaStorage{ctrl: ctrl}
+	mock.recorder = &MockPageMetaStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPageMetaStorage) EXPECT() *MockPageMetaStorageMockRecorder {
+	return m.recorder
+}
+
+// SavePageMeta mocks base method.
+func (m *MockPageMetaStorage) SavePageMeta(ctx context.Context, id string, page domain.PageMeta) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePageMeta", ctx, id, page)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePageMeta indicates an expected call of SavePageMeta.
+func (mr *MockPageMetaStorageMockRecorder) SavePageMeta(ctx, id, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePageMeta", reflect.TypeOf((*MockPageMetaStorage)(nil).SavePageMeta), ctx, id, page)
+}
+
+// MockURLChecker is a mock of URLChecker interface.
+type MockURLChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLCheckerMockRecorder
+}
+
+// MockURLCheckerMockRecorder is the mock recorder for MockURLChecker.
+type MockURLCheckerMockRecorder struct {
+	mock *MockURLChecker
+}
+
+// NewMockURLChecker creates a new mock instance.
+func NewMockURLChecker(ctrl *gomock.Controller) *MockURLChecker {
+	mock := &MockURLChecker{ctrl: ctrl}
+	mock.recorder = &MockURLCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLChecker) EXPECT() *MockURLCheckerMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockURLChecker) Check(rawURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", rawURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockURLCheckerMockRecorder) Check(rawURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockURLChecker)(nil).Check), rawURL)
+}
+
+// MockPageFetchServ is a mock of PageFetchServ interface.
+type MockPageFetchServ struct {
+	ctrl     *gomock.Controller
+	recorder *MockPageFetchServMockRecorder
+}
+
+// MockPageFetchServMockRecorder is the mock recorder for MockPageFetchServ.
+type MockPageFetchServMockRecorder struct {
+	mock *MockPageFetchServ
+}
+
+// NewMockPageFetchServ creates a new mock instance.
+func NewMockPageFetchServ(ctrl *gomock.Controller) *MockPageFetchServ {
+	mock := &MockPageFetchServ{ctrl: ctrl}
+	mock.recorder = &MockPageFetchServMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPageFetchServ) EXPECT() *MockPageFetchServMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockPageFetchServ) Enqueue(id, url string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Enqueue", id, url)
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockPageFetchServMockRecorder) Enqueue(id, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockPageFetchServ)(nil).Enqueue), id, url)
+}
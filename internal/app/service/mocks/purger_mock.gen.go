@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: purger.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDeletedLinkStorage is a mock of DeletedLinkStorage interface.
+type MockDeletedLinkStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeletedLinkStorageMockRecorder
+}
+
+// MockDeletedLinkStorageMockRecorder is the mock recorder for MockDeletedLinkStorage.
+type MockDeletedLinkStorageMockRecorder struct {
+	mock *MockDeletedLinkStorage
+}
+
+// NewMockDeletedLinkStorage creates a new mock instance.
+func NewMockDeletedLinkStorage(ctrl *gomock.Controller) *MockDeletedLinkStorage {
+	mock := &MockDeletedLinkStorage{ctrl: ctrl}
+	mock.recorder = &MockDeletedLinkStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeletedLinkStorage) EXPECT() *MockDeletedLinkStorageMockRecorder {
+	return m.recorder
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockDeletedLinkStorage) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockDeletedLinkStorageMockRecorder) PurgeDeleted(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockDeletedLinkStorage)(nil).PurgeDeleted), ctx, deletedBefore)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: query.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockURLQueryServ is a mock of URLQueryServ interface.
+type MockURLQueryServ struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLQueryServMockRecorder
+}
+
+// MockURLQueryServMockRecorder is the mock recorder for MockURLQueryServ.
+type MockURLQueryServMockRecorder struct {
+	mock *MockURLQueryServ
+}
+
+// NewMockURLQueryServ creates a new mock instance.
+func NewMockURLQueryServ(ctrl *gomock.Controller) *MockURLQueryServ {
+	mock := &MockURLQueryServ{ctrl: ctrl}
+	mock.recorder = &MockURLQueryServMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLQueryServ) EXPECT() *MockURLQueryServMockRecorder {
+	return m.recorder
+}
+
+// SetQueryOptions mocks base method.
+func (m *MockURLQueryServ) SetQueryOptions(ctx context.Context, userID int, id string, opts domain.QueryOptions) (domain.QueryOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQueryOptions", ctx, userID, id, opts)
+	ret0, _ := ret[0].(domain.QueryOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetQueryOptions indicates an expected call of SetQueryOptions.
+func (mr *MockURLQueryServMockRecorder) SetQueryOptions(ctx, userID, id, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQueryOptions", reflect.TypeOf((*MockURLQueryServ)(nil).SetQueryOptions), ctx, userID, id, opts)
+}
+
+// UserQueryOptions mocks base method.
+func (m *MockURLQueryServ) UserQueryOptions(ctx context.Context, userID int, id string) (domain.QueryOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserQueryOptions", ctx, userID, id)
+	ret0, _ := ret[0].(domain.QueryOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserQueryOptions indicates an expected call of UserQueryOptions.
+func (mr *MockURLQueryServMockRecorder) UserQueryOptions(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserQueryOptions", reflect.TypeOf((*MockURLQueryServ)(nil).UserQueryOptions), ctx, userID, id)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rules.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockURLRuleServ is a mock of URLRuleServ interface.
+type MockURLRuleServ struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLRuleServMockRecorder
+}
+
+// MockURLRuleServMockRecorder is the mock recorder for MockURLRuleServ.
+type MockURLRuleServMockRecorder struct {
+	mock *MockURLRuleServ
+}
+
+// NewMockURLRuleServ creates a new mock instance.
+func NewMockURLRuleServ(ctrl *gomock.Controller) *MockURLRuleServ {
+	mock := &MockURLRuleServ{ctrl: ctrl}
+	mock.recorder = &MockURLRuleServMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLRuleServ) EXPECT() *MockURLRuleServMockRecorder {
+	return m.recorder
+}
+
+// SetRules mocks base method.
+func (m *MockURLRuleServ) SetRules(ctx context.Context, userID int, id string, rules []domain.RedirectRule) ([]domain.RedirectRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRules", ctx, userID, id, rules)
+	ret0, _ := ret[0].([]domain.RedirectRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRules indicates an expected call of SetRules.
+func (mr *MockURLRuleServMockRecorder) SetRules(ctx, userID, id, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRules", reflect.TypeOf((*MockURLRuleServ)(nil).SetRules), ctx, userID, id, rules)
+}
+
+// UserRules mocks base method.
+func (m *MockURLRuleServ) UserRules(ctx context.Context, userID int, id string) ([]domain.RedirectRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserRules", ctx, userID, id)
+	ret0, _ := ret[0].([]domain.RedirectRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserRules indicates an expected call of UserRules.
+func (mr *MockURLRuleServMockRecorder) UserRules(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRules", reflect.TypeOf((*MockURLRuleServ)(nil).UserRules), ctx, userID, id)
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	domain "github.com/Te8va/shortURL/internal/app/domain"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,31 +36,31 @@ func (m *MockURLSaverServ) EXPECT() *MockURLSaverServMockRecorder {
 }
 
 // Save mocks base method.
-func (m *MockURLSaverServ) Save(ctx context.Context, userID int, url string) (string, error) {
+func (m *MockURLSaverServ) Save(ctx context.Context, userID int, host, url string, meta domain.LinkMeta) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", ctx, userID, url)
+	ret := m.ctrl.Call(m, "Save", ctx, userID, host, url, meta)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockURLSaverServMockRecorder) Save(ctx, userID, url interface{}) *gomock.Call {
+func (mr *MockURLSaverServMockRecorder) Save(ctx, userID, host, url, meta interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockURLSaverServ)(nil).Save), ctx, userID, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockURLSaverServ)(nil).Save), ctx, userID, host, url, meta)
 }
 
 // SaveBatch mocks base method.
-func (m *MockURLSaverServ) SaveBatch(ctx context.Context, userID int, urls map[string]string) (map[string]string, error) {
+func (m *MockURLSaverServ) SaveBatch(ctx context.Context, userID int, host string, urls map[string]string) (map[string]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveBatch", ctx, userID, urls)
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, userID, host, urls)
 	ret0, _ := ret[0].(map[string]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveBatch indicates an expected call of SaveBatch.
-func (mr *MockURLSaverServMockRecorder) SaveBatch(ctx, userID, urls interface{}) *gomock.Call {
+func (mr *MockURLSaverServMockRecorder) SaveBatch(ctx, userID, host, urls interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockURLSaverServ)(nil).SaveBatch), ctx, userID, urls)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockURLSaverServ)(nil).SaveBatch), ctx, userID, host, urls)
 }
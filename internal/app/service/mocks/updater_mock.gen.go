@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: updater.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockURLUpdaterServ is a mock of URLUpdaterServ interface.
+type MockURLUpdaterServ struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLUpdaterServMockRecorder
+}
+
+// MockURLUpdaterServMockRecorder is the mock recorder for MockURLUpdaterServ.
+type MockURLUpdaterServMockRecorder struct {
+	mock *MockURLUpdaterServ
+}
+
+// NewMockURLUpdaterServ creates a new mock instance.
+func NewMockURLUpdaterServ(ctrl *gomock.Controller) *MockURLUpdaterServ {
+	mock := &MockURLUpdaterServ{ctrl: ctrl}
+	mock.recorder = &MockURLUpdaterServMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLUpdaterServ) EXPECT() *MockURLUpdaterServMockRecorder {
+	return m.recorder
+}
+
+// UpdateLink mocks base method.
+func (m *MockURLUpdaterServ) UpdateLink(ctx context.Context, userID int, id string, update domain.LinkUpdate) (domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLink", ctx, userID, id, update)
+	ret0, _ := ret[0].(domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLink indicates an expected call of UpdateLink.
+func (mr *MockURLUpdaterServMockRecorder) UpdateLink(ctx, userID, id, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLink", reflect.TypeOf((*MockURLUpdaterServ)(nil).UpdateLink), ctx, userID, id, update)
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: variants.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockURLVariantServ is a mock of URLVariantServ interface.
+type MockURLVariantServ struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLVariantServMockRecorder
+}
+
+// MockURLVariantServMockRecorder is the mock recorder for MockURLVariantServ.
+type MockURLVariantServMockRecorder struct {
+	mock *MockURLVariantServ
+}
+
+// NewMockURLVariantServ creates a new mock instance.
+func NewMockURLVariantServ(ctrl *gomock.Controller) *MockURLVariantServ {
+	mock := &MockURLVariantServ{ctrl: ctrl}
+	mock.recorder = &MockURLVariantServMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLVariantServ) EXPECT() *MockURLVariantServMockRecorder {
+	return m.recorder
+}
+
+// CountVariantClick mocks base method.
+func (m *MockURLVariantServ) CountVariantClick(ctx context.Context, id string, variant int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountVariantClick", ctx, id, variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CountVariantClick indicates an expected call of CountVariantClick.
+func (mr *MockURLVariantServMockRecorder) CountVariantClick(ctx, id, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountVariantClick", reflect.TypeOf((*MockURLVariantServ)(nil).CountVariantClick), ctx, id, variant)
+}
+
+// GetVariants mocks base method.
+func (m *MockURLVariantServ) GetVariants(ctx context.Context, id string) ([]domain.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariants", ctx, id)
+	ret0, _ := ret[0].([]domain.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariants indicates an expected call of GetVariants.
+func (mr *MockURLVariantServMockRecorder) GetVariants(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariants", reflect.TypeOf((*MockURLVariantServ)(nil).GetVariants), ctx, id)
+}
+
+// SetVariants mocks base method.
+func (m *MockURLVariantServ) SetVariants(ctx context.Context, userID int, id string, variants []domain.Variant) ([]domain.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVariants", ctx, userID, id, variants)
+	ret0, _ := ret[0].([]domain.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVariants indicates an expected call of SetVariants.
+func (mr *MockURLVariantServMockRecorder) SetVariants(ctx, userID, id, variants interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVariants", reflect.TypeOf((*MockURLVariantServ)(nil).SetVariants), ctx, userID, id, variants)
+}
+
+// UserVariants mocks base method.
+func (m *MockURLVariantServ) UserVariants(ctx context.Context, userID int, id string) ([]domain.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserVariants", ctx, userID, id)
+	ret0, _ := ret[0].([]domain.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserVariants indicates an expected call of UserVariants.
+func (mr *MockURLVariantServMockRecorder) UserVariants(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserVariants", reflect.TypeOf((*MockURLVariantServ)(nil).UserVariants), ctx, userID, id)
+}
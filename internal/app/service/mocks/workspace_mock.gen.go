@@ -0,0 +1,300 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: workspace.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/Te8va/shortURL/internal/app/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWorkspaceStorage is a mock of WorkspaceStorage interface.
+type MockWorkspaceStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceStorageMockRecorder
+}
+
+// MockWorkspaceStorageMockRecorder is the mock recorder for MockWorkspaceStorage.
+type MockWorkspaceStorageMockRecorder struct {
+	mock *MockWorkspaceStorage
+}
+
+// NewMockWorkspaceStorage creates a new mock instance.
+func NewMockWorkspaceStorage(ctrl *gomock.Controller) *MockWorkspaceStorage {
+	mock := &MockWorkspaceStorage{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceStorage) EXPECT() *MockWorkspaceStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateWorkspace mocks base method.
+func (m *MockWorkspaceStorage) CreateWorkspace(ctx context.Context, ws domain.Workspace, owner domain.WorkspaceMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", ctx, ws, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MockWorkspaceStorageMockRecorder) CreateWorkspace(ctx, ws, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockWorkspaceStorage)(nil).CreateWorkspace), ctx, ws, owner)
+}
+
+// GetMember mocks base method.
+func (m *MockWorkspaceStorage) GetMember(ctx context.Context, workspaceID string, userID int) (domain.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMember", ctx, workspaceID, userID)
+	ret0, _ := ret[0].(domain.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMember indicates an expected call of GetMember.
+func (mr *MockWorkspaceStorageMockRecorder) GetMember(ctx, workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMember", reflect.TypeOf((*MockWorkspaceStorage)(nil).GetMember), ctx, workspaceID, userID)
+}
+
+// ListMembers mocks base method.
+func (m *MockWorkspaceStorage) ListMembers(ctx context.Context, workspaceID string) ([]domain.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, workspaceID)
+	ret0, _ := ret[0].([]domain.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockWorkspaceStorageMockRecorder) ListMembers(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockWorkspaceStorage)(nil).ListMembers), ctx, workspaceID)
+}
+
+// ListWorkspaces mocks base method.
+func (m *MockWorkspaceStorage) ListWorkspaces(ctx context.Context, userID int) ([]domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaces", ctx, userID)
+	ret0, _ := ret[0].([]domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaces indicates an expected call of ListWorkspaces.
+func (mr *MockWorkspaceStorageMockRecorder) ListWorkspaces(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaces", reflect.TypeOf((*MockWorkspaceStorage)(nil).ListWorkspaces), ctx, userID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockWorkspaceStorage) RemoveMember(ctx context.Context, workspaceID string, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, workspaceID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockWorkspaceStorageMockRecorder) RemoveMember(ctx, workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockWorkspaceStorage)(nil).RemoveMember), ctx, workspaceID, userID)
+}
+
+// SaveMember mocks base method.
+func (m *MockWorkspaceStorage) SaveMember(ctx context.Context, member domain.WorkspaceMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMember indicates an expected call of SaveMember.
+func (mr *MockWorkspaceStorageMockRecorder) SaveMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMember", reflect.TypeOf((*MockWorkspaceStorage)(nil).SaveMember), ctx, member)
+}
+
+// MockWorkspaceLinkStorage is a mock of WorkspaceLinkStorage interface.
+type MockWorkspaceLinkStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceLinkStorageMockRecorder
+}
+
+// MockWorkspaceLinkStorageMockRecorder is the mock recorder for MockWorkspaceLinkStorage.
+type MockWorkspaceLinkStorageMockRecorder struct {
+	mock *MockWorkspaceLinkStorage
+}
+
+// NewMockWorkspaceLinkStorage creates a new mock instance.
+func NewMockWorkspaceLinkStorage(ctrl *gomock.Controller) *MockWorkspaceLinkStorage {
+	mock := &MockWorkspaceLinkStorage{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceLinkStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceLinkStorage) EXPECT() *MockWorkspaceLinkStorageMockRecorder {
+	return m.recorder
+}
+
+// GetLink mocks base method.
+func (m *MockWorkspaceLinkStorage) GetLink(ctx context.Context, id string) (domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLink", ctx, id)
+	ret0, _ := ret[0].(domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLink indicates an expected call of GetLink.
+func (mr *MockWorkspaceLinkStorageMockRecorder) GetLink(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLink", reflect.TypeOf((*MockWorkspaceLinkStorage)(nil).GetLink), ctx, id)
+}
+
+// MoveLinks mocks base method.
+func (m *MockWorkspaceLinkStorage) MoveLinks(ctx context.Context, ids []string, workspaceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveLinks", ctx, ids, workspaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveLinks indicates an expected call of MoveLinks.
+func (mr *MockWorkspaceLinkStorageMockRecorder) MoveLinks(ctx, ids, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveLinks", reflect.TypeOf((*MockWorkspaceLinkStorage)(nil).MoveLinks), ctx, ids, workspaceID)
+}
+
+// MockWorkspaceServ is a mock of WorkspaceServ interface.
+type MockWorkspaceServ struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceServMockRecorder
+}
+
+// MockWorkspaceServMockRecorder is the mock recorder for MockWorkspaceServ.
+type MockWorkspaceServMockRecorder struct {
+	mock *MockWorkspaceServ
+}
+
+// NewMockWorkspaceServ creates a new mock instance.
+func NewMockWorkspaceServ(ctrl *gomock.Controller) *MockWorkspaceServ {
+	mock := &MockWorkspaceServ{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceServMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceServ) EXPECT() *MockWorkspaceServMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockWorkspaceServ) Authorize(ctx context.Context, userID int, workspaceID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, userID, workspaceID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockWorkspaceServMockRecorder) Authorize(ctx, userID, workspaceID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockWorkspaceServ)(nil).Authorize), ctx, userID, workspaceID, role)
+}
+
+// CreateWorkspace mocks base method.
+func (m *MockWorkspaceServ) CreateWorkspace(ctx context.Context, userID int, name string) (domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", ctx, userID, name)
+	ret0, _ := ret[0].(domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MockWorkspaceServMockRecorder) CreateWorkspace(ctx, userID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockWorkspaceServ)(nil).CreateWorkspace), ctx, userID, name)
+}
+
+// ListMembers mocks base method.
+func (m *MockWorkspaceServ) ListMembers(ctx context.Context, userID int, workspaceID string) ([]domain.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, userID, workspaceID)
+	ret0, _ := ret[0].([]domain.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockWorkspaceServMockRecorder) ListMembers(ctx, userID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockWorkspaceServ)(nil).ListMembers), ctx, userID, workspaceID)
+}
+
+// ListWorkspaces mocks base method.
+func (m *MockWorkspaceServ) ListWorkspaces(ctx context.Context, userID int) ([]domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaces", ctx, userID)
+	ret0, _ := ret[0].([]domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaces indicates an expected call of ListWorkspaces.
+func (mr *MockWorkspaceServMockRecorder) ListWorkspaces(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaces", reflect.TypeOf((*MockWorkspaceServ)(nil).ListWorkspaces), ctx, userID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockWorkspaceServ) RemoveMember(ctx context.Context, userID int, workspaceID string, memberID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, userID, workspaceID, memberID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockWorkspaceServMockRecorder) RemoveMember(ctx, userID, workspaceID, memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockWorkspaceServ)(nil).RemoveMember), ctx, userID, workspaceID, memberID)
+}
+
+// SetMember mocks base method.
+func (m *MockWorkspaceServ) SetMember(ctx context.Context, userID int, workspaceID string, memberID int, role string) (domain.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", ctx, userID, workspaceID, memberID, role)
+	ret0, _ := ret[0].(domain.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockWorkspaceServMockRecorder) SetMember(ctx, userID, workspaceID, memberID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockWorkspaceServ)(nil).SetMember), ctx, userID, workspaceID, memberID, role)
+}
+
+// TransferLinks mocks base method.
+func (m *MockWorkspaceServ) TransferLinks(ctx context.Context, userID int, workspaceID string, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferLinks", ctx, userID, workspaceID, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferLinks indicates an expected call of TransferLinks.
+func (mr *MockWorkspaceServMockRecorder) TransferLinks(ctx, userID, workspaceID, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferLinks", reflect.TypeOf((*MockWorkspaceServ)(nil).TransferLinks), ctx, userID, workspaceID, ids)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+const (
+	reportIDBytes           = 8
+	moderationIDBytes       = 8
+	maxReportDetailsLength  = 1000
+	maxModerationNoteLength = 1000
+)
+
+var reportReasons = map[string]bool{
+	domain.ReportPhishing: true,
+	domain.ReportMalware:  true,
+	domain.ReportSpam:     true,
+	domain.ReportOther:    true,
+}
+
+// ModerationStorage defines the interface for a storage of abuse reports, disabled links and the audit trail of
+// moderator decisions
+//
+//go:generate mockgen -source=moderation.go -destination=mocks/moderation_mock.gen.go -package=mocks
+type ModerationStorage interface {
+	SaveReport(ctx context.Context, report domain.AbuseReport) error
+	ListReports(ctx context.Context, linkID string) ([]domain.AbuseReport, error)
+	ModerationQueue(ctx context.Context, queue string) ([]domain.ModerationCase, error)
+	SaveModerationAction(ctx context.Context, action domain.ModerationAction, disabled bool) error
+	ListModerationActions(ctx context.Context, linkID string) ([]domain.ModerationAction, error)
+	IsLinkDisabled(ctx context.Context, linkID string) (bool, error)
+}
+
+// ModerationServ defines the interface for a service that takes abuse reports and lets moderators act on them
+type ModerationServ interface {
+	Report(ctx context.Context, linkID, reporterIP string, req domain.AbuseReportRequest) (domain.AbuseReport, error)
+	Queue(ctx context.Context, queue string) ([]domain.ModerationCase, error)
+	Review(ctx context.Context, linkID string) (domain.ModerationReview, error)
+	Moderate(ctx context.Context, moderatorID int, linkID string, req domain.ModerationRequest) (domain.ModerationAction, error)
+	Actions(ctx context.Context, linkID string) ([]domain.ModerationAction, error)
+	Disabled(ctx context.Context, linkID string) (bool, error)
+}
+
+// ModerationService lets visitors report short links and moderators review the reports and disable or clear the links.
+// Every decision is kept in an audit trail
+type ModerationService struct {
+	moderation ModerationStorage
+	links      LinkStorage
+}
+
+// NewModerationService creates a new instance of ModerationService with the given storages
+func NewModerationService(moderation ModerationStorage, links LinkStorage) *ModerationService {
+	return &ModerationService{moderation: moderation, links: links}
+}
+
+// Report files an abuse report of an existing link
+func (s *ModerationService) Report(ctx context.Context, linkID, reporterIP string, req domain.AbuseReportRequest) (domain.AbuseReport, error) {
+	details := strings.TrimSpace(req.Details)
+	if !reportReasons[req.Reason] || utf8.RuneCountInString(details) > maxReportDetailsLength {
+		return domain.AbuseReport{}, appErrors.ErrInvalidReport
+	}
+
+	if _, err := s.links.GetLink(ctx, linkID); err != nil {
+		return domain.AbuseReport{}, err
+	}
+
+	id, err := randomHex(reportIDBytes)
+	if err != nil {
+		return domain.AbuseReport{}, fmt.Errorf("service.Report: %w", err)
+	}
+
+	report := domain.AbuseReport{
+		ID:         id,
+		LinkID:     linkID,
+		Reason:     req.Reason,
+		Details:    details,
+		ReporterIP: reporterIP,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.moderation.SaveReport(ctx, report); err != nil {
+		return domain.AbuseReport{}, fmt.Errorf("service.Report: %w", err)
+	}
+
+	return report, nil
+}
+
+// Queue lists the links with pending reports, most reported first, or the disabled links
+func (s *ModerationService) Queue(ctx context.Context, queue string) ([]domain.ModerationCase, error) {
+	cases, err := s.moderation.ModerationQueue(ctx, queue)
+	if err != nil {
+		return nil, fmt.Errorf("service.Queue: %w", err)
+	}
+
+	listed := cases[:0]
+	for _, c := range cases {
+		link, err := s.links.GetLink(ctx, c.LinkID)
+		if errors.Is(err, appErrors.ErrNotFound) {
+			// the link was purged, its reports and decisions stay for the record
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("service.Queue: %w", err)
+		}
+		listed = append(listed, withLink(c, link))
+	}
+
+	return listed, nil
+}
+
+// Review returns the case of a link together with all of its reports and the decisions taken on it
+func (s *ModerationService) Review(ctx context.Context, linkID string) (domain.ModerationReview, error) {
+	link, err := s.links.GetLink(ctx, linkID)
+	if err != nil {
+		return domain.ModerationReview{}, err
+	}
+
+	reports, err := s.moderation.ListReports(ctx, linkID)
+	if err != nil {
+		return domain.ModerationReview{}, fmt.Errorf("service.Review: %w", err)
+	}
+	actions, err := s.moderation.ListModerationActions(ctx, linkID)
+	if err != nil {
+		return domain.ModerationReview{}, fmt.Errorf("service.Review: %w", err)
+	}
+	disabled, err := s.moderation.IsLinkDisabled(ctx, linkID)
+	if err != nil {
+		return domain.ModerationReview{}, fmt.Errorf("service.Review: %w", err)
+	}
+
+	c := domain.ModerationCase{LinkID: linkID, Disabled: disabled, Reasons: []string{}}
+	seen := make(map[string]bool)
+	for _, report := range reports {
+		if report.ResolvedAt != nil {
+			continue
+		}
+		c.PendingReports++
+		if !seen[report.Reason] {
+			seen[report.Reason] = true
+			c.Reasons = append(c.Reasons, report.Reason)
+		}
+		if c.FirstReportedAt == nil || report.CreatedAt.Before(*c.FirstReportedAt) {
+			c.FirstReportedAt = &report.CreatedAt
+		}
+		if c.LastReportedAt == nil || report.CreatedAt.After(*c.LastReportedAt) {
+			c.LastReportedAt = &report.CreatedAt
+		}
+	}
+
+	if reports == nil {
+		reports = []domain.AbuseReport{}
+	}
+	if actions == nil {
+		actions = []domain.ModerationAction{}
+	}
+
+	return domain.ModerationReview{ModerationCase: withLink(c, link), Reports: reports, Actions: actions}, nil
+}
+
+// Moderate disables or clears a link on behalf of a moderator, resolves its pending reports and records the decision
+func (s *ModerationService) Moderate(ctx context.Context, moderatorID int, linkID string, req domain.ModerationRequest) (domain.ModerationAction, error) {
+	note := strings.TrimSpace(req.Note)
+	if (req.Action != domain.ModerationDisable && req.Action != domain.ModerationClear) ||
+		utf8.RuneCountInString(note) > maxModerationNoteLength {
+		return domain.ModerationAction{}, appErrors.ErrInvalidModeration
+	}
+
+	if _, err := s.links.GetLink(ctx, linkID); err != nil {
+		return domain.ModerationAction{}, err
+	}
+
+	id, err := randomHex(moderationIDBytes)
+	if err != nil {
+		return domain.ModerationAction{}, fmt.Errorf("service.Moderate: %w", err)
+	}
+
+	action := domain.ModerationAction{
+		ID:          id,
+		LinkID:      linkID,
+		ModeratorID: moderatorID,
+		Action:      req.Action,
+		Note:        note,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.moderation.SaveModerationAction(ctx, action, req.Action == domain.ModerationDisable); err != nil {
+		return domain.ModerationAction{}, fmt.Errorf("service.Moderate: %w", err)
+	}
+
+	return action, nil
+}
+
+// Actions returns the audit trail of moderator decisions on a link, or on all links if linkID is empty, newest first
+func (s *ModerationService) Actions(ctx context.Context, linkID string) ([]domain.ModerationAction, error) {
+	return s.moderation.ListModerationActions(ctx, linkID)
+}
+
+// Disabled reports whether moderators disabled the link
+func (s *ModerationService) Disabled(ctx context.Context, linkID string) (bool, error) {
+	return s.moderation.IsLinkDisabled(ctx, linkID)
+}
+
+func withLink(c domain.ModerationCase, link domain.Link) domain.ModerationCase {
+	c.Domain = link.Domain
+	c.OriginalURL = link.OriginalURL
+	c.UserID = link.UserID
+	if c.Reasons == nil {
+		c.Reasons = []string{}
+	}
+	return c
+}
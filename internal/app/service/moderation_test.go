@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestModerationService_Report(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockModerationStorage(ctrl)
+	links := mocks.NewMockLinkStorage(ctrl)
+	svc := service.NewModerationService(storage, links)
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := svc.Report(context.Background(), "abc", "203.0.113.7", domain.AbuseReportRequest{Reason: "boring"})
+		require.ErrorIs(t, err, appErrors.ErrInvalidReport)
+
+		_, err = svc.Report(context.Background(), "abc", "203.0.113.7", domain.AbuseReportRequest{
+			Reason:  domain.ReportSpam,
+			Details: strings.Repeat("x", 1001),
+		})
+		require.ErrorIs(t, err, appErrors.ErrInvalidReport)
+	})
+
+	t.Run("unknown link", func(t *testing.T) {
+		links.EXPECT().GetLink(gomock.Any(), "missing").Return(domain.Link{}, appErrors.ErrNotFound)
+
+		_, err := svc.Report(context.Background(), "missing", "203.0.113.7", domain.AbuseReportRequest{Reason: domain.ReportSpam})
+		require.ErrorIs(t, err, appErrors.ErrNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		links.EXPECT().GetLink(gomock.Any(), "abc").Return(domain.Link{ID: "abc"}, nil)
+		storage.EXPECT().SaveReport(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, report domain.AbuseReport) error {
+				require.Equal(t, "abc", report.LinkID)
+				require.Equal(t, "203.0.113.7", report.ReporterIP)
+				require.Equal(t, "fake bank login", report.Details)
+				require.NotEmpty(t, report.ID)
+				return nil
+			})
+
+		report, err := svc.Report(context.Background(), "abc", "203.0.113.7", domain.AbuseReportRequest{
+			Reason:  domain.ReportPhishing,
+			Details: "  fake bank login ",
+		})
+		require.NoError(t, err)
+		require.Equal(t, domain.ReportPhishing, report.Reason)
+	})
+}
+
+func TestModerationService_Queue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockModerationStorage(ctrl)
+	links := mocks.NewMockLinkStorage(ctrl)
+	svc := service.NewModerationService(storage, links)
+
+	storage.EXPECT().ModerationQueue(gomock.Any(), domain.QueuePending).Return([]domain.ModerationCase{
+		{LinkID: "abc", PendingReports: 3, Reasons: []string{domain.ReportPhishing}},
+		{LinkID: "gone", PendingReports: 2},
+	}, nil)
+	links.EXPECT().GetLink(gomock.Any(), "abc").Return(domain.Link{ID: "abc", OriginalURL: "https://example.com", UserID: 7}, nil)
+	links.EXPECT().GetLink(gomock.Any(), "gone").Return(domain.Link{}, appErrors.ErrNotFound)
+
+	cases, err := svc.Queue(context.Background(), domain.QueuePending)
+	require.NoError(t, err)
+	require.Len(t, cases, 1)
+	require.Equal(t, "https://example.com", cases[0].OriginalURL)
+	require.Equal(t, 7, cases[0].UserID)
+	require.Equal(t, 3, cases[0].PendingReports)
+}
+
+func TestModerationService_Review(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockModerationStorage(ctrl)
+	links := mocks.NewMockLinkStorage(ctrl)
+	svc := service.NewModerationService(storage, links)
+
+	first := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	resolved := first.Add(-time.Hour)
+	links.EXPECT().GetLink(gomock.Any(), "abc").Return(domain.Link{ID: "abc", OriginalURL: "https://example.com"}, nil)
+	storage.EXPECT().ListReports(gomock.Any(), "abc").Return([]domain.AbuseReport{
+		{ID: "3", LinkID: "abc", Reason: domain.ReportSpam, CreatedAt: first.Add(2 * time.Hour)},
+		{ID: "2", LinkID: "abc", Reason: domain.ReportPhishing, CreatedAt: first},
+		{ID: "1", LinkID: "abc", Reason: domain.ReportMalware, CreatedAt: resolved, ResolvedAt: &first},
+	}, nil)
+	storage.EXPECT().ListModerationActions(gomock.Any(), "abc").Return(nil, nil)
+	storage.EXPECT().IsLinkDisabled(gomock.Any(), "abc").Return(false, nil)
+
+	review, err := svc.Review(context.Background(), "abc")
+	require.NoError(t, err)
+	require.Equal(t, 2, review.PendingReports)
+	require.Equal(t, []string{domain.ReportSpam, domain.ReportPhishing}, review.Reasons)
+	require.Equal(t, first, *review.FirstReportedAt)
+	require.Equal(t, first.Add(2*time.Hour), *review.LastReportedAt)
+	require.Len(t, review.Reports, 3)
+	require.NotNil(t, review.Actions)
+}
+
+func TestModerationService_Moderate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockModerationStorage(ctrl)
+	links := mocks.NewMockLinkStorage(ctrl)
+	svc := service.NewModerationService(storage, links)
+
+	_, err := svc.Moderate(context.Background(), ownerID, "abc", domain.ModerationRequest{Action: "delete"})
+	require.ErrorIs(t, err, appErrors.ErrInvalidModeration)
+
+	tests := []struct {
+		action   string
+		disabled bool
+	}{
+		{action: domain.ModerationDisable, disabled: true},
+		{action: domain.ModerationClear, disabled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			links.EXPECT().GetLink(gomock.Any(), "abc").Return(domain.Link{ID: "abc"}, nil)
+			storage.EXPECT().SaveModerationAction(gomock.Any(), gomock.Any(), tt.disabled).Return(nil)
+
+			action, err := svc.Moderate(context.Background(), ownerID, "abc", domain.ModerationRequest{Action: tt.action, Note: " checked "})
+			require.NoError(t, err)
+			require.Equal(t, ownerID, action.ModeratorID)
+			require.Equal(t, tt.action, action.Action)
+			require.Equal(t, "checked", action.Note)
+		})
+	}
+}
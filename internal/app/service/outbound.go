@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// errNonPublicAddress is returned for connections refused by refusePrivate
+var errNonPublicAddress = errors.New("refusing to connect to non-public address")
+
+// outboundTransport returns the transport for requests to user-supplied URLs. Unless allowPrivate is set it refuses
+// to connect to addresses that are not publicly routable, which holds for every redirect and whatever a host name
+// resolves to at the time of the request
+func outboundTransport(timeout time.Duration, allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = refusePrivate
+	}
+	return &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout}
+}
+
+// refusePrivate is a dialer control refusing connections to addresses that are not publicly routable
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w %s", errNonPublicAddress, host)
+	}
+	return nil
+}
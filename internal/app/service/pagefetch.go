@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/pagemeta"
+)
+
+// PageFetchUserAgent is sent with every request for the metadata of a destination page
+const PageFetchUserAgent = "shortURL-page-fetcher/1.0"
+
+// PageMetaStorage defines the interface for a storage of the metadata of destination pages
+//
+//go:generate mockgen -source=pagefetch.go -destination=mocks/pagefetch_mock.gen.go -package=mocks
+type PageMetaStorage interface {
+	SavePageMeta(ctx context.Context, id string, page domain.PageMeta) error
+}
+
+// URLChecker defines the interface for validating URLs against the URL policy
+type URLChecker interface {
+	Check(rawURL string) error
+}
+
+// PageFetchServ defines the interface for a service that fetches the metadata of destination pages in the background
+type PageFetchServ interface {
+	Enqueue(id, url string)
+}
+
+// PageFetchOptions tunes page metadata fetching. Zero values are replaced with defaults
+type PageFetchOptions struct {
+	// Policy is checked for the page, every redirect and the image and favicon URLs, nothing is checked if nil
+	Policy URLChecker
+	// Timeout bounds fetching a single page including redirects
+	Timeout time.Duration
+	// MaxBytes is how much of a page is read, metadata is expected in its head
+	MaxBytes     int64
+	MaxRedirects int
+	Workers      int
+	QueueSize    int
+	// AllowPrivate allows connecting to loopback and private addresses, which are refused by default so that
+	// a public host name resolving to an internal address can not be used to probe the internal network
+	AllowPrivate bool
+}
+
+func (o PageFetchOptions) withDefaults() PageFetchOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = 5 * time.Second
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = 512 << 10
+	}
+	if o.MaxRedirects <= 0 {
+		o.MaxRedirects = 5
+	}
+	if o.Workers <= 0 {
+		o.Workers = 2
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1024
+	}
+	return o
+}
+
+type pageJob struct {
+	id  string
+	url string
+}
+
+// PageFetcher fetches the title, description, OpenGraph image and favicon of the destination pages of new links
+// in the background and stores them with the link
+type PageFetcher struct {
+	pages     PageMetaStorage
+	opts      PageFetchOptions
+	client    *http.Client
+	jobs      chan pageJob
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewPageFetcher creates a new instance of PageFetcher and starts its workers
+func NewPageFetcher(pages PageMetaStorage, opts PageFetchOptions) *PageFetcher {
+	opts = opts.withDefaults()
+	f := &PageFetcher{
+		pages: pages,
+		opts:  opts,
+		jobs:  make(chan pageJob, opts.QueueSize),
+		done:  make(chan struct{}),
+	}
+
+	f.client = &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: outboundTransport(opts.Timeout, opts.AllowPrivate),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > opts.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", opts.MaxRedirects)
+			}
+			return f.check(req.URL.String())
+		},
+	}
+
+	f.wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go f.work()
+	}
+
+	return f
+}
+
+// Enqueue queues fetching the metadata of the destination of a link without blocking the caller.
+// Links are skipped when the queue is full
+func (f *PageFetcher) Enqueue(id, url string) {
+	select {
+	case <-f.done:
+	case f.jobs <- pageJob{id: id, url: url}:
+	default:
+		log.Printf("Page fetch queue is full, skipping %s", id)
+	}
+}
+
+// Close stops the workers. Queued links are not fetched
+func (f *PageFetcher) Close() {
+	f.closeOnce.Do(func() {
+		close(f.done)
+	})
+	f.wg.Wait()
+}
+
+func (f *PageFetcher) work() {
+	defer f.wg.Done()
+
+	for {
+		select {
+		case <-f.done:
+			return
+		case job := <-f.jobs:
+			if err := f.FetchPage(context.Background(), job.id, job.url); err != nil {
+				log.Printf("Failed to fetch page metadata of %s: %v", job.id, err)
+			}
+		}
+	}
+}
+
+// FetchPage fetches the metadata of the page at rawURL and stores it for the link
+func (f *PageFetcher) FetchPage(ctx context.Context, id, rawURL string) error {
+	if err := f.check(rawURL); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", PageFetchUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return fmt.Errorf("not an HTML page: %s", mediaType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.opts.MaxBytes))
+	if err != nil {
+		return err
+	}
+
+	meta := pagemeta.Parse(body, resp.Request.URL)
+	page := domain.PageMeta{
+		Title:       meta.Title,
+		Description: meta.Description,
+		Image:       f.allowed(meta.Image),
+		Favicon:     f.allowed(meta.Favicon),
+		FetchedAt:   time.Now().UTC(),
+	}
+
+	if err := f.pages.SavePageMeta(ctx, id, page); err != nil {
+		return fmt.Errorf("service.FetchPage: %w", err)
+	}
+
+	return nil
+}
+
+func (f *PageFetcher) check(rawURL string) error {
+	if f.opts.Policy == nil {
+		return nil
+	}
+	return f.opts.Policy.Check(rawURL)
+}
+
+// allowed drops URLs rejected by the policy
+func (f *PageFetcher) allowed(rawURL string) string {
+	if rawURL == "" || f.check(rawURL) != nil {
+		return ""
+	}
+	return rawURL
+}
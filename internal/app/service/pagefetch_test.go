@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+// blockList rejects URLs containing any of its entries
+type blockList []string
+
+func (b blockList) Check(rawURL string) error {
+	for _, blocked := range b {
+		if strings.Contains(rawURL, blocked) {
+			return errors.New("blocked")
+		}
+	}
+	return nil
+}
+
+func TestPageFetcher_FetchPage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, service.PageFetchUserAgent, r.UserAgent())
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>Plain</title>
+			<meta property="og:title" content="Open &amp; Graph">
+			<meta name="description" content="About the page">
+			<meta property="og:image" content="http://blocked.test/image.png">
+			<link rel="icon" href="/static/icon.png">
+			</head><body>` + strings.Repeat("x", 1<<20) + `</body></html>`))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/to-blocked", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/blocked", http.StatusFound)
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockPageMetaStorage(ctrl)
+	fetcher := service.NewPageFetcher(storage, service.PageFetchOptions{
+		Policy:       blockList{"blocked"},
+		Timeout:      200 * time.Millisecond,
+		MaxBytes:     4096,
+		AllowPrivate: true,
+	})
+	defer fetcher.Close()
+
+	ctx := context.Background()
+
+	t.Run("metadata", func(t *testing.T) {
+		storage.EXPECT().SavePageMeta(gomock.Any(), "abc", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, page domain.PageMeta) error {
+				require.Equal(t, "Open & Graph", page.Title)
+				require.Equal(t, "About the page", page.Description)
+				require.Empty(t, page.Image)
+				require.Equal(t, srv.URL+"/static/icon.png", page.Favicon)
+				require.False(t, page.FetchedAt.IsZero())
+				return nil
+			})
+
+		require.NoError(t, fetcher.FetchPage(ctx, "abc", srv.URL+"/moved"))
+	})
+
+	t.Run("rejected by policy", func(t *testing.T) {
+		require.Error(t, fetcher.FetchPage(ctx, "abc", srv.URL+"/blocked"))
+		require.Error(t, fetcher.FetchPage(ctx, "abc", srv.URL+"/to-blocked"))
+	})
+
+	t.Run("not HTML", func(t *testing.T) {
+		require.Error(t, fetcher.FetchPage(ctx, "abc", srv.URL+"/image.png"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		require.Error(t, fetcher.FetchPage(ctx, "abc", srv.URL+"/missing"))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		require.Error(t, fetcher.FetchPage(ctx, "abc", srv.URL+"/slow"))
+	})
+}
+
+func TestPageFetcher_PrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("private address must not be requested")
+	}))
+	defer srv.Close()
+
+	fetcher := service.NewPageFetcher(nil, service.PageFetchOptions{})
+	defer fetcher.Close()
+
+	err := fetcher.FetchPage(context.Background(), "abc", srv.URL)
+	require.ErrorContains(t, err, "non-public address")
+}
+
+func TestPageFetcher_Enqueue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<title>Queued</title>`))
+	}))
+	defer srv.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	saved := make(chan domain.PageMeta, 1)
+	storage := mocks.NewMockPageMetaStorage(ctrl)
+	storage.EXPECT().SavePageMeta(gomock.Any(), "abc", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, page domain.PageMeta) error {
+			saved <- page
+			return nil
+		})
+
+	fetcher := service.NewPageFetcher(storage, service.PageFetchOptions{AllowPrivate: true})
+	defer fetcher.Close()
+
+	fetcher.Enqueue("abc", srv.URL)
+
+	select {
+	case page := <-saved:
+		require.Equal(t, "Queued", page.Title)
+	case <-time.After(5 * time.Second):
+		t.Fatal("page was not fetched")
+	}
+}
@@ -13,15 +13,19 @@ type PingerServ interface {
 
 // URLService is an aggregate service for working with URLs
 type URLService struct {
-	saver   URLSaverServ
-	getter  URLGetterServ
-	pinger  PingerServ
-	deleter URLDeleteServ
+	saver    URLSaverServ
+	getter   URLGetterServ
+	pinger   PingerServ
+	deleter  URLDeleteServ
+	updater  URLUpdaterServ
+	variants URLVariantServ
+	rules    URLRuleServ
+	query    URLQueryServ
 }
 
 // NewURLService creates a new instance of URLService with the given dependencies
-func NewURLService(saver URLSaverServ, getter URLGetterServ, pinger PingerServ, deleter URLDeleteServ) *URLService {
-	return &URLService{saver: saver, getter: getter, pinger: pinger, deleter: deleter}
+func NewURLService(saver URLSaverServ, getter URLGetterServ, pinger PingerServ, deleter URLDeleteServ, updater URLUpdaterServ, variants URLVariantServ, rules URLRuleServ, query URLQueryServ) *URLService {
+	return &URLService{saver: saver, getter: getter, pinger: pinger, deleter: deleter, updater: updater, variants: variants, rules: rules, query: query}
 }
 
 // PingPg delegates the database connectivity check to repository
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DeletedLinkStorage defines the interface for a storage that removes deleted links for good
+//
+//go:generate mockgen -source=purger.go -destination=mocks/purger_mock.gen.go -package=mocks
+type DeletedLinkStorage interface {
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// PurgeOptions tunes the purge of deleted links. Zero values are replaced with defaults
+type PurgeOptions struct {
+	// Retention is how long a deleted link is kept, and can be restored, before it is purged
+	Retention time.Duration
+	// Interval is how often the purger looks for links past Retention
+	Interval time.Duration
+}
+
+func (o PurgeOptions) withDefaults() PurgeOptions {
+	if o.Retention <= 0 {
+		o.Retention = 30 * 24 * time.Hour
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Hour
+	}
+	return o
+}
+
+// Purger periodically removes the links deleted longer than Retention ago together with their data
+type Purger struct {
+	links     DeletedLinkStorage
+	opts      PurgeOptions
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewPurger creates a new instance of Purger, Start runs it in the background
+func NewPurger(links DeletedLinkStorage, opts PurgeOptions) *Purger {
+	return &Purger{
+		links: links,
+		opts:  opts.withDefaults(),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start purges the links past retention every Interval until Close is called
+func (p *Purger) Start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.opts.Interval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-p.done:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			if purged, err := p.Purge(ctx); err != nil {
+				log.Println("Failed to purge deleted links:", err)
+			} else if purged > 0 {
+				log.Printf("Purged %d deleted links", purged)
+			}
+			cancel()
+
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops the purger and waits for the purge in flight
+func (p *Purger) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+	p.wg.Wait()
+}
+
+// Purge removes the links deleted longer than Retention ago and returns how many were removed
+func (p *Purger) Purge(ctx context.Context) (int64, error) {
+	purged, err := p.links.PurgeDeleted(ctx, time.Now().Add(-p.opts.Retention))
+	if err != nil {
+		return 0, fmt.Errorf("service.Purge: %w", err)
+	}
+
+	return purged, nil
+}
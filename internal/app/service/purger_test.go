@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestPurger_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockDeletedLinkStorage(ctrl)
+	purger := service.NewPurger(storage, service.PurgeOptions{Retention: 7 * 24 * time.Hour})
+
+	storage.EXPECT().PurgeDeleted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, deletedBefore time.Time) (int64, error) {
+			require.WithinDuration(t, time.Now().Add(-7*24*time.Hour), deletedBefore, time.Minute)
+			return 3, nil
+		})
+
+	purged, err := purger.Purge(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 3, purged)
+
+	storage.EXPECT().PurgeDeleted(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("connection refused"))
+
+	_, err = purger.Purge(context.Background())
+	require.Error(t, err)
+}
+
+func TestPurger_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockDeletedLinkStorage(ctrl)
+	purger := service.NewPurger(storage, service.PurgeOptions{Interval: 10 * time.Millisecond})
+
+	purged := make(chan struct{}, 1)
+	storage.EXPECT().PurgeDeleted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, deletedBefore time.Time) (int64, error) {
+			select {
+			case purged <- struct{}{}:
+			default:
+			}
+			return 0, nil
+		}).MinTimes(2)
+
+	purger.Start()
+	<-purged
+	<-purged
+	purger.Close()
+}
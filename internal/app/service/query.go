@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// URLQueryServ defines the interface for a service that manages the UTM parameters and query string forwarding of links
+//
+//go:generate mockgen -source=query.go -destination=mocks/query_mock.gen.go -package=mocks
+type URLQueryServ interface {
+	UserQueryOptions(ctx context.Context, userID int, id string) (domain.QueryOptions, error)
+	SetQueryOptions(ctx context.Context, userID int, id string, opts domain.QueryOptions) (domain.QueryOptions, error)
+}
+
+// UserQueryOptions delegates looking up the query string options of the user's link to repository
+func (s *URLService) UserQueryOptions(ctx context.Context, userID int, id string) (domain.QueryOptions, error) {
+	return s.query.UserQueryOptions(ctx, userID, id)
+}
+
+// SetQueryOptions delegates replacing the query string options of the user's link to repository
+func (s *URLService) SetQueryOptions(ctx context.Context, userID int, id string, opts domain.QueryOptions) (domain.QueryOptions, error) {
+	return s.query.SetQueryOptions(ctx, userID, id, opts)
+}
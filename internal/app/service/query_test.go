@@ -0,0 +1,39 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestURLService_QueryOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuery := mocks.NewMockURLQueryServ(ctrl)
+	svc := service.NewURLService(nil, nil, nil, nil, nil, nil, nil, mockQuery)
+
+	opts := domain.QueryOptions{UTM: domain.UTM{Source: "newsletter", Medium: "email"}, Forward: true}
+
+	t.Run("set", func(t *testing.T) {
+		mockQuery.EXPECT().SetQueryOptions(gomock.Any(), 1, "abc123", opts).Return(opts, nil)
+
+		stored, err := svc.SetQueryOptions(context.Background(), 1, "abc123", opts)
+		assert.NoError(t, err)
+		assert.Equal(t, opts, stored)
+	})
+
+	t.Run("someone else's link", func(t *testing.T) {
+		mockQuery.EXPECT().UserQueryOptions(gomock.Any(), 2, "abc123").Return(domain.QueryOptions{}, appErrors.ErrNotFound)
+
+		_, err := svc.UserQueryOptions(context.Background(), 2, "abc123")
+		assert.ErrorIs(t, err, appErrors.ErrNotFound)
+	})
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// URLRuleServ defines the interface for a service that manages the conditional redirect rules of links
+//
+//go:generate mockgen -source=rules.go -destination=mocks/rules_mock.gen.go -package=mocks
+type URLRuleServ interface {
+	UserRules(ctx context.Context, userID int, id string) ([]domain.RedirectRule, error)
+	SetRules(ctx context.Context, userID int, id string, rules []domain.RedirectRule) ([]domain.RedirectRule, error)
+}
+
+// UserRules delegates looking up the redirect rules of the user's link to repository
+func (s *URLService) UserRules(ctx context.Context, userID int, id string) ([]domain.RedirectRule, error) {
+	return s.rules.UserRules(ctx, userID, id)
+}
+
+// SetRules delegates replacing the redirect rules of the user's link to repository
+func (s *URLService) SetRules(ctx context.Context, userID int, id string, rules []domain.RedirectRule) ([]domain.RedirectRule, error) {
+	return s.rules.SetRules(ctx, userID, id, rules)
+}
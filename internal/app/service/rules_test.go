@@ -0,0 +1,42 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestURLService_Rules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRules := mocks.NewMockURLRuleServ(ctrl)
+	svc := service.NewURLService(nil, nil, nil, nil, nil, nil, mockRules, nil)
+
+	rules := []domain.RedirectRule{
+		{Platforms: []string{domain.PlatformIOS}, URL: "https://apps.apple.com/app/id1"},
+		{Platforms: []string{domain.PlatformAndroid}, URL: "https://play.google.com/store/apps/details?id=app"},
+	}
+
+	t.Run("set", func(t *testing.T) {
+		mockRules.EXPECT().SetRules(gomock.Any(), 1, "abc123", rules).Return(rules, nil)
+
+		stored, err := svc.SetRules(context.Background(), 1, "abc123", rules)
+		assert.NoError(t, err)
+		assert.Equal(t, rules, stored)
+	})
+
+	t.Run("someone else's link", func(t *testing.T) {
+		mockRules.EXPECT().UserRules(gomock.Any(), 2, "abc123").Return(nil, appErrors.ErrNotFound)
+
+		_, err := svc.UserRules(context.Background(), 2, "abc123")
+		assert.ErrorIs(t, err, appErrors.ErrNotFound)
+	})
+}
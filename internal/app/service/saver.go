@@ -1,21 +1,25 @@
 package service
 
-import "context"
+import (
+	"context"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
 
 // URLSaverServ defines the interface for a service that saves URLs
 //
 //go:generate mockgen -source=saver.go -destination=mocks/saver_mock.gen.go -package=mocks
 type URLSaverServ interface {
-	Save(ctx context.Context, userID int, url string) (string, error)
-	SaveBatch(ctx context.Context, userID int, urls map[string]string) (map[string]string, error)
+	Save(ctx context.Context, userID int, host, url string, meta domain.LinkMeta) (string, error)
+	SaveBatch(ctx context.Context, userID int, host string, urls map[string]string) (map[string]string, error)
 }
 
 // Save delegates the save operation to repository
-func (s *URLService) Save(ctx context.Context, userID int, url string) (string, error) {
-	return s.saver.Save(ctx, userID, url)
+func (s *URLService) Save(ctx context.Context, userID int, host, url string, meta domain.LinkMeta) (string, error) {
+	return s.saver.Save(ctx, userID, host, url, meta)
 }
 
 // SaveBatch delegates the batch save operation to repository
-func (s *URLService) SaveBatch(ctx context.Context, userID int, urls map[string]string) (map[string]string, error) {
-	return s.saver.SaveBatch(ctx, userID, urls)
+func (s *URLService) SaveBatch(ctx context.Context, userID int, host string, urls map[string]string) (map[string]string, error) {
+	return s.saver.SaveBatch(ctx, userID, host, urls)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Te8va/shortURL/internal/app/domain"
 	"github.com/Te8va/shortURL/internal/app/service"
 	"github.com/Te8va/shortURL/internal/app/service/mocks"
 )
@@ -18,7 +19,7 @@ func TestURLService_Save(t *testing.T) {
 
 	mockSaver := mocks.NewMockURLSaverServ(ctrl)
 
-	svc := service.NewURLService(mockSaver, nil, nil, nil)
+	svc := service.NewURLService(mockSaver, nil, nil, nil, nil, nil, nil, nil)
 
 	tests := []struct {
 		name      string
@@ -34,7 +35,7 @@ func TestURLService_Save(t *testing.T) {
 			url:    "https://example.com",
 			mockSetup: func() {
 				mockSaver.EXPECT().
-					Save(gomock.Any(), 1, "https://example.com").
+					Save(gomock.Any(), 1, "short.ly", "https://example.com", domain.LinkMeta{}).
 					Return("short1234", nil)
 			},
 			wantID:  "short1234",
@@ -46,7 +47,7 @@ func TestURLService_Save(t *testing.T) {
 			url:    "https://fail.com",
 			mockSetup: func() {
 				mockSaver.EXPECT().
-					Save(gomock.Any(), 2, "https://fail.com").
+					Save(gomock.Any(), 2, "short.ly", "https://fail.com", domain.LinkMeta{}).
 					Return("", errors.New("save failed"))
 			},
 			wantID:  "",
@@ -58,7 +59,7 @@ func TestURLService_Save(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			gotID, err := svc.Save(context.Background(), tt.userID, tt.url)
+			gotID, err := svc.Save(context.Background(), tt.userID, "short.ly", tt.url, domain.LinkMeta{})
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Empty(t, gotID)
@@ -76,7 +77,7 @@ func TestURLService_SaveBatch(t *testing.T) {
 
 	mockSaver := mocks.NewMockURLSaverServ(ctrl)
 
-	svc := service.NewURLService(mockSaver, nil, nil, nil)
+	svc := service.NewURLService(mockSaver, nil, nil, nil, nil, nil, nil, nil)
 
 	batchInput := map[string]string{
 		"corr1": "https://example1.com",
@@ -102,7 +103,7 @@ func TestURLService_SaveBatch(t *testing.T) {
 			urls:   batchInput,
 			mockSetup: func() {
 				mockSaver.EXPECT().
-					SaveBatch(gomock.Any(), 1, batchInput).
+					SaveBatch(gomock.Any(), 1, "short.ly", batchInput).
 					Return(batchOutput, nil)
 			},
 			wantMap: batchOutput,
@@ -114,7 +115,7 @@ func TestURLService_SaveBatch(t *testing.T) {
 			urls:   batchInput,
 			mockSetup: func() {
 				mockSaver.EXPECT().
-					SaveBatch(gomock.Any(), 1, batchInput).
+					SaveBatch(gomock.Any(), 1, "short.ly", batchInput).
 					Return(nil, errors.New("batch save failed"))
 			},
 			wantMap: nil,
@@ -126,7 +127,7 @@ func TestURLService_SaveBatch(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			gotMap, err := svc.SaveBatch(context.Background(), tt.userID, tt.urls)
+			gotMap, err := svc.SaveBatch(context.Background(), tt.userID, "short.ly", tt.urls)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, gotMap)
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// URLUpdaterServ defines the interface for a service that updates link metadata
+//
+//go:generate mockgen -source=updater.go -destination=mocks/updater_mock.gen.go -package=mocks
+type URLUpdaterServ interface {
+	UpdateLink(ctx context.Context, userID int, id string, update domain.LinkUpdate) (domain.Link, error)
+}
+
+// UpdateLink delegates the metadata update to repository
+func (s *URLService) UpdateLink(ctx context.Context, userID int, id string, update domain.LinkUpdate) (domain.Link, error) {
+	return s.updater.UpdateLink(ctx, userID, id, update)
+}
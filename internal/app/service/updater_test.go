@@ -0,0 +1,41 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestURLService_UpdateLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mocks.NewMockURLUpdaterServ(ctrl)
+	svc := service.NewURLService(nil, nil, nil, nil, mockUpdater, nil, nil, nil)
+
+	title := "Docs"
+	update := domain.LinkUpdate{Title: &title}
+
+	t.Run("success", func(t *testing.T) {
+		expected := domain.Link{ID: "abc123", OriginalURL: "https://example.com", LinkMeta: domain.LinkMeta{Title: title}}
+		mockUpdater.EXPECT().UpdateLink(gomock.Any(), 1, "abc123", update).Return(expected, nil)
+
+		link, err := svc.UpdateLink(context.Background(), 1, "abc123", update)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, link)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockUpdater.EXPECT().UpdateLink(gomock.Any(), 2, "abc123", update).Return(domain.Link{}, appErrors.ErrNotFound)
+
+		_, err := svc.UpdateLink(context.Background(), 2, "abc123", update)
+		assert.ErrorIs(t, err, appErrors.ErrNotFound)
+	})
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+)
+
+// URLVariantServ defines the interface for a service that manages the destinations of A/B links and counts their clicks
+//
+//go:generate mockgen -source=variants.go -destination=mocks/variants_mock.gen.go -package=mocks
+type URLVariantServ interface {
+	GetVariants(ctx context.Context, id string) ([]domain.Variant, error)
+	UserVariants(ctx context.Context, userID int, id string) ([]domain.Variant, error)
+	CountVariantClick(ctx context.Context, id string, variant int) error
+	SetVariants(ctx context.Context, userID int, id string, variants []domain.Variant) ([]domain.Variant, error)
+}
+
+// GetVariants delegates looking up the destinations of a link to repository
+func (s *URLService) GetVariants(ctx context.Context, id string) ([]domain.Variant, error) {
+	return s.variants.GetVariants(ctx, id)
+}
+
+// UserVariants delegates looking up the destinations of the user's link to repository
+func (s *URLService) UserVariants(ctx context.Context, userID int, id string) ([]domain.Variant, error) {
+	return s.variants.UserVariants(ctx, userID, id)
+}
+
+// CountVariantClick delegates counting a redirect to a variant to repository
+func (s *URLService) CountVariantClick(ctx context.Context, id string, variant int) error {
+	return s.variants.CountVariantClick(ctx, id, variant)
+}
+
+// SetVariants delegates replacing the destinations of the user's link to repository
+func (s *URLService) SetVariants(ctx context.Context, userID int, id string, variants []domain.Variant) ([]domain.Variant, error) {
+	return s.variants.SetVariants(ctx, userID, id, variants)
+}
@@ -0,0 +1,49 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+func TestURLService_Variants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockVariants := mocks.NewMockURLVariantServ(ctrl)
+	svc := service.NewURLService(nil, nil, nil, nil, nil, mockVariants, nil, nil)
+
+	variants := []domain.Variant{{URL: "https://a.example.com", Weight: 3}, {URL: "https://b.example.com", Weight: 1}}
+
+	t.Run("set", func(t *testing.T) {
+		mockVariants.EXPECT().SetVariants(gomock.Any(), 1, "abc123", variants).Return(variants, nil)
+
+		stored, err := svc.SetVariants(context.Background(), 1, "abc123", variants)
+		assert.NoError(t, err)
+		assert.Equal(t, variants, stored)
+	})
+
+	t.Run("someone else's link", func(t *testing.T) {
+		mockVariants.EXPECT().UserVariants(gomock.Any(), 2, "abc123").Return(nil, appErrors.ErrNotFound)
+
+		_, err := svc.UserVariants(context.Background(), 2, "abc123")
+		assert.ErrorIs(t, err, appErrors.ErrNotFound)
+	})
+
+	t.Run("count click", func(t *testing.T) {
+		mockVariants.EXPECT().GetVariants(gomock.Any(), "abc123").Return(variants, nil)
+		mockVariants.EXPECT().CountVariantClick(gomock.Any(), "abc123", 1).Return(nil)
+
+		got, err := svc.GetVariants(context.Background(), "abc123")
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.NoError(t, svc.CountVariantClick(context.Background(), "abc123", 1))
+	})
+}
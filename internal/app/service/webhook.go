@@ -0,0 +1,441 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	webhookSecretBytes  = 24
+	webhookIDBytes      = 8
+)
+
+// Headers sent with every webhook delivery. The delivery ID is unique to an event and webhook and stays the same when
+// the delivery is retried. The signature is "sha256=" followed by the hex HMAC of "<timestamp>.<body>"
+const (
+	WebhookEventHeader     = "X-Shorturl-Event"
+	WebhookDeliveryHeader  = "X-Shorturl-Delivery"
+	WebhookTimestampHeader = "X-Shorturl-Timestamp"
+	WebhookSignatureHeader = "X-Shorturl-Signature"
+)
+
+// WebhookStorage defines the interface for a storage of webhooks and failed deliveries
+//
+//go:generate mockgen -source=webhook.go -destination=mocks/webhook_mock.gen.go -package=mocks
+type WebhookStorage interface {
+	CreateWebhook(ctx context.Context, hook domain.Webhook) error
+	ListWebhooks(ctx context.Context, userID int) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID int, id string) error
+	AddDeadLetter(ctx context.Context, letter domain.WebhookDeadLetter) error
+	ListDeadLetters(ctx context.Context, userID int) ([]domain.WebhookDeadLetter, error)
+}
+
+// LinkStorage defines the interface for looking up a short link by its ID
+type LinkStorage interface {
+	GetLink(ctx context.Context, id string) (domain.Link, error)
+}
+
+// WebhookServ defines the interface for a service that manages webhooks and delivers link events to them
+type WebhookServ interface {
+	CreateWebhook(ctx context.Context, userID int, url string, events []string) (domain.WebhookResponse, error)
+	ListWebhooks(ctx context.Context, userID int) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID int, id string) error
+	ListDeadLetters(ctx context.Context, userID int) ([]domain.WebhookDeadLetter, error)
+	Publish(event domain.LinkEvent)
+}
+
+// WebhookOptions tunes webhook delivery. Zero values are replaced with defaults
+type WebhookOptions struct {
+	// MaxAttempts is how many times a delivery is tried before it is dead-lettered
+	MaxAttempts int
+	// Backoff is the delay before the first retry, it doubles with every further attempt up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout bounds a single delivery attempt
+	Timeout   time.Duration
+	Workers   int
+	QueueSize int
+	// ShortURL composes the public short URL of a link for events that only carry its ID
+	ShortURL func(domain, id string) string
+	// Policy is checked for webhook URLs when they are created and for every redirect of a delivery,
+	// nothing is checked if nil
+	Policy       URLChecker
+	MaxRedirects int
+	// AllowPrivate allows delivering to loopback and private addresses, which are refused by default so that
+	// a public host name resolving to an internal address can not be used to reach the internal network
+	AllowPrivate bool
+}
+
+func (o WebhookOptions) withDefaults() WebhookOptions {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 5 * time.Minute
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1024
+	}
+	if o.MaxRedirects <= 0 {
+		o.MaxRedirects = 5
+	}
+	return o
+}
+
+type webhookDelivery struct {
+	id       string
+	hook     domain.Webhook
+	event    string
+	body     []byte
+	attempts int
+	lastErr  string
+}
+
+// WebhookService stores webhook subscriptions and delivers signed link events to them in the background.
+// Failed deliveries are retried with exponential backoff and dead-lettered once attempts run out
+type WebhookService struct {
+	hooks      WebhookStorage
+	links      LinkStorage
+	opts       WebhookOptions
+	client     *http.Client
+	events     chan domain.LinkEvent
+	deliveries chan *webhookDelivery
+	done       chan struct{}
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+}
+
+// NewWebhookService creates a new instance of WebhookService and starts its delivery workers
+func NewWebhookService(hooks WebhookStorage, links LinkStorage, opts WebhookOptions) *WebhookService {
+	opts = opts.withDefaults()
+	s := &WebhookService{
+		hooks:      hooks,
+		links:      links,
+		opts:       opts,
+		events:     make(chan domain.LinkEvent, opts.QueueSize),
+		deliveries: make(chan *webhookDelivery, opts.QueueSize),
+		done:       make(chan struct{}),
+	}
+	s.client = &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: outboundTransport(opts.Timeout, opts.AllowPrivate),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > opts.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", opts.MaxRedirects)
+			}
+			return s.check(req.URL.String())
+		},
+	}
+
+	s.wg.Add(1 + opts.Workers)
+	go s.dispatch()
+	for i := 0; i < opts.Workers; i++ {
+		go s.work()
+	}
+
+	return s
+}
+
+// CreateWebhook subscribes the user to link events. The signing secret is returned once
+func (s *WebhookService) CreateWebhook(ctx context.Context, userID int, rawURL string, events []string) (domain.WebhookResponse, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return domain.WebhookResponse{}, appErrors.ErrInvalidWebhook
+	}
+	if err := s.check(rawURL); err != nil {
+		return domain.WebhookResponse{}, fmt.Errorf("%w: %w", appErrors.ErrInvalidWebhook, err)
+	}
+	for _, event := range events {
+		if event != domain.EventLinkCreated && event != domain.EventLinkClicked && event != domain.EventLinkDeleted {
+			return domain.WebhookResponse{}, appErrors.ErrInvalidWebhook
+		}
+	}
+
+	secret, err := randomHex(webhookSecretBytes)
+	if err != nil {
+		return domain.WebhookResponse{}, fmt.Errorf("service.CreateWebhook: %w", err)
+	}
+	id, err := randomHex(webhookIDBytes)
+	if err != nil {
+		return domain.WebhookResponse{}, fmt.Errorf("service.CreateWebhook: %w", err)
+	}
+
+	if events == nil {
+		events = []string{}
+	}
+	hook := domain.Webhook{
+		ID:        id,
+		UserID:    userID,
+		URL:       rawURL,
+		Secret:    webhookSecretPrefix + secret,
+		Events:    events,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.hooks.CreateWebhook(ctx, hook); err != nil {
+		return domain.WebhookResponse{}, fmt.Errorf("service.CreateWebhook: %w", err)
+	}
+
+	return domain.WebhookResponse{Webhook: hook, Secret: hook.Secret}, nil
+}
+
+// ListWebhooks delegates listing the user's webhooks to repository
+func (s *WebhookService) ListWebhooks(ctx context.Context, userID int) ([]domain.Webhook, error) {
+	return s.hooks.ListWebhooks(ctx, userID)
+}
+
+// DeleteWebhook delegates removing the user's webhook to repository
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID int, id string) error {
+	return s.hooks.DeleteWebhook(ctx, userID, id)
+}
+
+// ListDeadLetters delegates listing the user's failed deliveries to repository
+func (s *WebhookService) ListDeadLetters(ctx context.Context, userID int) ([]domain.WebhookDeadLetter, error) {
+	return s.hooks.ListDeadLetters(ctx, userID)
+}
+
+// Publish queues the event for delivery without blocking the caller. Events are dropped when the queue is full.
+// For clicks the owner of the link is looked up, for deletions the event is only delivered if event.UserID owns the link
+func (s *WebhookService) Publish(event domain.LinkEvent) {
+	select {
+	case <-s.done:
+	case s.events <- event:
+	default:
+		log.Printf("Webhook event queue is full, dropping %s for %s", event.Type, event.ShortURL)
+	}
+}
+
+// Close stops the workers. Deliveries still waiting for a retry are abandoned
+func (s *WebhookService) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+}
+
+// SignWebhook returns the signature header value for a delivery body sent at timestamp (Unix seconds)
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookService) dispatch() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case event := <-s.events:
+			s.fanOut(event)
+		}
+	}
+}
+
+func (s *WebhookService) fanOut(event domain.LinkEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+
+	if event.Type == domain.EventLinkClicked || event.Type == domain.EventLinkDeleted {
+		link, err := s.links.GetLink(ctx, event.ID)
+		if err != nil {
+			return
+		}
+		// events go to the webhooks of the link owner, whoever caused them, e.g. a workspace editor deleting the link
+		event.UserID = link.UserID
+		if event.ShortURL == "" && s.opts.ShortURL != nil {
+			event.ShortURL = s.opts.ShortURL(link.Domain, link.ID)
+		}
+		if event.OriginalURL == "" {
+			event.OriginalURL = link.OriginalURL
+		}
+	}
+
+	hooks, err := s.hooks.ListWebhooks(ctx, event.UserID)
+	if err != nil {
+		log.Println("Failed to list webhooks:", err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	id, err := randomHex(webhookIDBytes)
+	if err != nil {
+		log.Println("Failed to generate webhook event ID:", err)
+		return
+	}
+	body, err := json.Marshal(domain.WebhookPayload{
+		ID:        id,
+		Type:      event.Type,
+		CreatedAt: time.Now().UTC(),
+		Data:      event,
+	})
+	if err != nil {
+		log.Println("Failed to encode webhook payload:", err)
+		return
+	}
+
+	for _, hook := range hooks {
+		if !subscribed(hook, event.Type) {
+			continue
+		}
+		deliveryID, err := randomHex(webhookIDBytes)
+		if err != nil {
+			log.Println("Failed to generate webhook delivery ID:", err)
+			return
+		}
+		s.enqueue(&webhookDelivery{id: deliveryID, hook: hook, event: event.Type, body: body})
+	}
+}
+
+func (s *WebhookService) enqueue(d *webhookDelivery) {
+	select {
+	case <-s.done:
+	case s.deliveries <- d:
+	default:
+		d.lastErr = "delivery queue is full"
+		s.deadLetter(d)
+	}
+}
+
+func (s *WebhookService) work() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case d := <-s.deliveries:
+			s.deliver(d)
+		}
+	}
+}
+
+func (s *WebhookService) deliver(d *webhookDelivery) {
+	d.attempts++
+	err := s.send(d)
+	if err == nil {
+		return
+	}
+
+	d.lastErr = err.Error()
+	if d.attempts >= s.opts.MaxAttempts {
+		s.deadLetter(d)
+		return
+	}
+
+	time.AfterFunc(s.backoff(d.attempts), func() {
+		s.enqueue(d)
+	})
+}
+
+func (s *WebhookService) send(d *webhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.hook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, d.event)
+	req.Header.Set(WebhookDeliveryHeader, d.id)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(d.hook.Secret, timestamp, d.body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *WebhookService) check(rawURL string) error {
+	if s.opts.Policy == nil {
+		return nil
+	}
+	return s.opts.Policy.Check(rawURL)
+}
+
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.opts.Backoff
+	for i := 1; i < attempts && delay < s.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.opts.MaxBackoff {
+		delay = s.opts.MaxBackoff
+	}
+	return delay
+}
+
+func (s *WebhookService) deadLetter(d *webhookDelivery) {
+	id, err := randomHex(webhookIDBytes)
+	if err != nil {
+		log.Println("Failed to generate dead letter ID:", err)
+		return
+	}
+
+	letter := domain.WebhookDeadLetter{
+		ID:         id,
+		WebhookID:  d.hook.ID,
+		DeliveryID: d.id,
+		UserID:     d.hook.UserID,
+		Event:      d.event,
+		Payload:    d.body,
+		Attempts:   d.attempts,
+		LastError:  d.lastErr,
+		FailedAt:   time.Now().UTC(),
+	}
+	if err := s.hooks.AddDeadLetter(context.Background(), letter); err != nil {
+		log.Println("Failed to store webhook dead letter:", err)
+	}
+}
+
+func subscribed(hook domain.Webhook, event string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, e := range hook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
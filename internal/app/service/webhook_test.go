@@ -0,0 +1,327 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/policy"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a stand-in webhook endpoint that answers with the given statuses in turn and then with 200
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, chan receivedWebhook) {
+	t.Helper()
+
+	received := make(chan receivedWebhook, 16)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedWebhook{header: r.Header.Clone(), body: body}
+
+		n := int(atomic.AddInt32(&calls, 1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, received
+}
+
+func waitWebhook(t *testing.T, received chan receivedWebhook) receivedWebhook {
+	t.Helper()
+
+	select {
+	case r := <-received:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+		return receivedWebhook{}
+	}
+}
+
+func fastWebhookOptions(attempts int) service.WebhookOptions {
+	return service.WebhookOptions{MaxAttempts: attempts, Backoff: 10 * time.Millisecond, Timeout: time.Second, Workers: 1, AllowPrivate: true}
+}
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHooks := mocks.NewMockWebhookStorage(ctrl)
+	svc := service.NewWebhookService(mockHooks, mocks.NewMockLinkStorage(ctrl), service.WebhookOptions{})
+	defer svc.Close()
+
+	var stored domain.Webhook
+	mockHooks.EXPECT().
+		CreateWebhook(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, hook domain.Webhook) error {
+			stored = hook
+			return nil
+		})
+
+	resp, err := svc.CreateWebhook(context.Background(), 7, "https://hooks.example.com/in", []string{domain.EventLinkCreated})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.Secret, "whsec_"))
+	assert.Equal(t, resp.Secret, stored.Secret)
+	assert.Equal(t, 7, stored.UserID)
+
+	for _, tc := range []struct {
+		url    string
+		events []string
+	}{
+		{url: "ftp://hooks.example.com", events: nil},
+		{url: "/relative", events: nil},
+		{url: "https://hooks.example.com", events: []string{"link.renamed"}},
+	} {
+		_, err := svc.CreateWebhook(context.Background(), 7, tc.url, tc.events)
+		assert.ErrorIs(t, err, appErrors.ErrInvalidWebhook, tc.url)
+	}
+}
+
+func TestWebhookService_CreateWebhook_Policy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	urlPolicy, err := policy.New("http://localhost:8080", "")
+	require.NoError(t, err)
+
+	svc := service.NewWebhookService(mocks.NewMockWebhookStorage(ctrl), mocks.NewMockLinkStorage(ctrl), service.WebhookOptions{Policy: urlPolicy})
+	defer svc.Close()
+
+	for _, rawURL := range []string{
+		"http://127.0.0.1:9000/in",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.5/in",
+		"http://metadata.internal/in",
+		"http://localhost:8080/hook",
+	} {
+		_, err := svc.CreateWebhook(context.Background(), 7, rawURL, nil)
+		assert.ErrorIs(t, err, appErrors.ErrInvalidWebhook, rawURL)
+
+		var violation *policy.Violation
+		assert.ErrorAs(t, err, &violation, rawURL)
+	}
+}
+
+func TestWebhookService_PrivateAddresses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv, received := newReceiver(t)
+
+	mockHooks := mocks.NewMockWebhookStorage(ctrl)
+	opts := fastWebhookOptions(1)
+	opts.AllowPrivate = false
+	svc := service.NewWebhookService(mockHooks, mocks.NewMockLinkStorage(ctrl), opts)
+	defer svc.Close()
+
+	// the URL was accepted when it was created, the address it reaches now is checked on every delivery
+	mockHooks.EXPECT().ListWebhooks(gomock.Any(), 7).Return([]domain.Webhook{{ID: "h1", UserID: 7, URL: srv.URL, Secret: "s"}}, nil)
+
+	letters := make(chan domain.WebhookDeadLetter, 1)
+	mockHooks.EXPECT().
+		AddDeadLetter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, letter domain.WebhookDeadLetter) error {
+			letters <- letter
+			return nil
+		})
+
+	svc.Publish(domain.LinkEvent{Type: domain.EventLinkCreated, UserID: 7, ShortURL: "http://localhost:8080/abc"})
+
+	select {
+	case letter := <-letters:
+		assert.Contains(t, letter.LastError, "non-public address")
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery to a private address was not refused")
+	}
+	select {
+	case <-received:
+		t.Fatal("private address must not be requested")
+	default:
+	}
+}
+
+func TestWebhookService_Redirects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusTemporaryRedirect)
+	}))
+	defer redirector.Close()
+
+	urlPolicy, err := policy.New("http://localhost:8080", "")
+	require.NoError(t, err)
+
+	mockHooks := mocks.NewMockWebhookStorage(ctrl)
+	opts := fastWebhookOptions(1)
+	opts.Policy = urlPolicy
+	svc := service.NewWebhookService(mockHooks, mocks.NewMockLinkStorage(ctrl), opts)
+	defer svc.Close()
+
+	mockHooks.EXPECT().ListWebhooks(gomock.Any(), 7).Return([]domain.Webhook{{ID: "h1", UserID: 7, URL: redirector.URL, Secret: "s"}}, nil)
+
+	letters := make(chan domain.WebhookDeadLetter, 1)
+	mockHooks.EXPECT().
+		AddDeadLetter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, letter domain.WebhookDeadLetter) error {
+			letters <- letter
+			return nil
+		})
+
+	svc.Publish(domain.LinkEvent{Type: domain.EventLinkCreated, UserID: 7, ShortURL: "http://localhost:8080/abc"})
+
+	select {
+	case letter := <-letters:
+		assert.Contains(t, letter.LastError, string(policy.RulePrivateHost))
+	case <-time.After(5 * time.Second):
+		t.Fatal("redirect to a private host was followed")
+	}
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv, received := newReceiver(t)
+
+	mockHooks := mocks.NewMockWebhookStorage(ctrl)
+	mockLinks := mocks.NewMockLinkStorage(ctrl)
+	opts := fastWebhookOptions(3)
+	opts.ShortURL = func(host, id string) string { return "http://" + host + "/" + id }
+	svc := service.NewWebhookService(mockHooks, mockLinks, opts)
+	defer svc.Close()
+
+	hooks := []domain.Webhook{
+		{ID: "all", UserID: 7, URL: srv.URL, Secret: "whsec_a"},
+		{ID: "deletions", UserID: 7, URL: srv.URL, Secret: "whsec_b", Events: []string{domain.EventLinkDeleted}},
+	}
+	mockHooks.EXPECT().ListWebhooks(gomock.Any(), 7).Return(hooks, nil).AnyTimes()
+	mockLinks.EXPECT().
+		GetLink(gomock.Any(), "abc").
+		Return(domain.Link{ID: "abc", Domain: "localhost:8080", OriginalURL: "https://example.com", UserID: 7}, nil).
+		AnyTimes()
+
+	svc.Publish(domain.LinkEvent{Type: domain.EventLinkClicked, ID: "abc", ShortURL: "http://localhost:8080/abc", OriginalURL: "https://example.com"})
+
+	got := waitWebhook(t, received)
+	require.Equal(t, domain.EventLinkClicked, got.header.Get(service.WebhookEventHeader))
+	require.NotEmpty(t, got.header.Get(service.WebhookDeliveryHeader))
+
+	timestamp, err := strconv.ParseInt(got.header.Get(service.WebhookTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	require.Equal(t, service.SignWebhook("whsec_a", timestamp, got.body), got.header.Get(service.WebhookSignatureHeader))
+
+	var payload domain.WebhookPayload
+	require.NoError(t, json.Unmarshal(got.body, &payload))
+	require.Equal(t, domain.EventLinkClicked, payload.Type)
+	require.Equal(t, "http://localhost:8080/abc", payload.Data.ShortURL)
+	require.Equal(t, "https://example.com", payload.Data.OriginalURL)
+
+	select {
+	case extra := <-received:
+		t.Fatalf("webhook subscribed only to deletions received %s", extra.header.Get(service.WebhookEventHeader))
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// a workspace editor deleting the link announces it to the webhooks of the link owner, as the owner deleting it does
+	svc.Publish(domain.LinkEvent{Type: domain.EventLinkDeleted, UserID: 8, ID: "abc"})
+	svc.Publish(domain.LinkEvent{Type: domain.EventLinkDeleted, UserID: 7, ID: "abc"})
+
+	delivered := map[string]bool{got.header.Get(service.WebhookDeliveryHeader): true}
+	for i := 0; i < 4; i++ {
+		got := waitWebhook(t, received)
+		require.Equal(t, domain.EventLinkDeleted, got.header.Get(service.WebhookEventHeader))
+		require.NoError(t, json.Unmarshal(got.body, &payload))
+		require.Equal(t, "http://localhost:8080/abc", payload.Data.ShortURL, "deleted links are looked up by ID")
+		delivered[got.header.Get(service.WebhookDeliveryHeader)] = true
+	}
+	require.Len(t, delivered, 5, "every webhook gets its own delivery ID for every event")
+}
+
+func TestWebhookService_Retry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv, received := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+
+	mockHooks := mocks.NewMockWebhookStorage(ctrl)
+	svc := service.NewWebhookService(mockHooks, mocks.NewMockLinkStorage(ctrl), fastWebhookOptions(3))
+	defer svc.Close()
+
+	mockHooks.EXPECT().ListWebhooks(gomock.Any(), 7).Return([]domain.Webhook{{ID: "h1", UserID: 7, URL: srv.URL, Secret: "s"}}, nil)
+
+	svc.Publish(domain.LinkEvent{Type: domain.EventLinkCreated, UserID: 7, ShortURL: "http://localhost:8080/abc"})
+
+	first := waitWebhook(t, received)
+	waitWebhook(t, received)
+	third := waitWebhook(t, received)
+	require.Equal(t, first.body, third.body, "retries must resend the same event")
+	require.NotEmpty(t, first.header.Get(service.WebhookDeliveryHeader))
+	require.Equal(t, first.header.Get(service.WebhookDeliveryHeader), third.header.Get(service.WebhookDeliveryHeader),
+		"retries must keep the delivery ID")
+}
+
+func TestWebhookService_DeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv, received := newReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+
+	mockHooks := mocks.NewMockWebhookStorage(ctrl)
+	svc := service.NewWebhookService(mockHooks, mocks.NewMockLinkStorage(ctrl), fastWebhookOptions(3))
+	defer svc.Close()
+
+	mockHooks.EXPECT().ListWebhooks(gomock.Any(), 7).Return([]domain.Webhook{{ID: "h1", UserID: 7, URL: srv.URL, Secret: "s"}}, nil)
+
+	letters := make(chan domain.WebhookDeadLetter, 1)
+	mockHooks.EXPECT().
+		AddDeadLetter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, letter domain.WebhookDeadLetter) error {
+			letters <- letter
+			return nil
+		})
+
+	svc.Publish(domain.LinkEvent{Type: domain.EventLinkCreated, UserID: 7, ShortURL: "http://localhost:8080/abc"})
+
+	var deliveryID string
+	for i := 0; i < 3; i++ {
+		deliveryID = waitWebhook(t, received).header.Get(service.WebhookDeliveryHeader)
+	}
+
+	select {
+	case letter := <-letters:
+		assert.Equal(t, "h1", letter.WebhookID)
+		assert.Equal(t, deliveryID, letter.DeliveryID)
+		assert.Equal(t, 7, letter.UserID)
+		assert.Equal(t, domain.EventLinkCreated, letter.Event)
+		assert.Equal(t, 3, letter.Attempts)
+		assert.Contains(t, letter.LastError, "500")
+		assert.Contains(t, string(letter.Payload), `"short_url":"http://localhost:8080/abc"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("failed delivery was not dead-lettered")
+	}
+}
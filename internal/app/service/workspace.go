@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+)
+
+const (
+	workspaceIDBytes       = 8
+	maxWorkspaceNameLength = 100
+)
+
+// roleRanks orders workspace roles so that a higher role is allowed everything a lower one is
+var roleRanks = map[string]int{
+	domain.RoleViewer: 1,
+	domain.RoleEditor: 2,
+	domain.RoleOwner:  3,
+}
+
+// WorkspaceStorage defines the interface for a storage of workspaces and their members
+//
+//go:generate mockgen -source=workspace.go -destination=mocks/workspace_mock.gen.go -package=mocks
+type WorkspaceStorage interface {
+	CreateWorkspace(ctx context.Context, ws domain.Workspace, owner domain.WorkspaceMember) error
+	ListWorkspaces(ctx context.Context, userID int) ([]domain.Workspace, error)
+	GetMember(ctx context.Context, workspaceID string, userID int) (domain.WorkspaceMember, error)
+	ListMembers(ctx context.Context, workspaceID string) ([]domain.WorkspaceMember, error)
+	SaveMember(ctx context.Context, member domain.WorkspaceMember) error
+	RemoveMember(ctx context.Context, workspaceID string, userID int) error
+}
+
+// WorkspaceLinkStorage defines the interface for looking up short links and moving them between workspaces
+type WorkspaceLinkStorage interface {
+	GetLink(ctx context.Context, id string) (domain.Link, error)
+	MoveLinks(ctx context.Context, ids []string, workspaceID string) error
+}
+
+// WorkspaceServ defines the interface for a service that manages workspaces, their members and the links they own
+type WorkspaceServ interface {
+	CreateWorkspace(ctx context.Context, userID int, name string) (domain.Workspace, error)
+	ListWorkspaces(ctx context.Context, userID int) ([]domain.Workspace, error)
+	ListMembers(ctx context.Context, userID int, workspaceID string) ([]domain.WorkspaceMember, error)
+	SetMember(ctx context.Context, userID int, workspaceID string, memberID int, role string) (domain.WorkspaceMember, error)
+	RemoveMember(ctx context.Context, userID int, workspaceID string, memberID int) error
+	TransferLinks(ctx context.Context, userID int, workspaceID string, ids []string) error
+	Authorize(ctx context.Context, userID int, workspaceID, role string) error
+}
+
+// WorkspaceService lets registered users share links in workspaces. Owners manage members, editors create, move
+// and delete links and viewers list them
+type WorkspaceService struct {
+	workspaces WorkspaceStorage
+	links      WorkspaceLinkStorage
+}
+
+// NewWorkspaceService creates a new instance of WorkspaceService with the given storages
+func NewWorkspaceService(workspaces WorkspaceStorage, links WorkspaceLinkStorage) *WorkspaceService {
+	return &WorkspaceService{workspaces: workspaces, links: links}
+}
+
+// CreateWorkspace creates a workspace owned by the user
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, userID int, name string) (domain.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxWorkspaceNameLength || userID < domain.FirstRegisteredUserID {
+		return domain.Workspace{}, appErrors.ErrInvalidWorkspace
+	}
+
+	id, err := randomHex(workspaceIDBytes)
+	if err != nil {
+		return domain.Workspace{}, fmt.Errorf("service.CreateWorkspace: %w", err)
+	}
+
+	now := time.Now().UTC()
+	ws := domain.Workspace{ID: id, Name: name, Role: domain.RoleOwner, CreatedAt: now}
+	owner := domain.WorkspaceMember{WorkspaceID: id, UserID: userID, Role: domain.RoleOwner, AddedAt: now}
+
+	if err := s.workspaces.CreateWorkspace(ctx, ws, owner); err != nil {
+		return domain.Workspace{}, fmt.Errorf("service.CreateWorkspace: %w", err)
+	}
+
+	return ws, nil
+}
+
+// ListWorkspaces delegates listing the workspaces the user is a member of to repository
+func (s *WorkspaceService) ListWorkspaces(ctx context.Context, userID int) ([]domain.Workspace, error) {
+	return s.workspaces.ListWorkspaces(ctx, userID)
+}
+
+// ListMembers returns the members of a workspace the user is a member of
+func (s *WorkspaceService) ListMembers(ctx context.Context, userID int, workspaceID string) ([]domain.WorkspaceMember, error) {
+	if err := s.Authorize(ctx, userID, workspaceID, domain.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.workspaces.ListMembers(ctx, workspaceID)
+}
+
+// SetMember adds a registered user to the workspace or changes their role. Only owners may do so, and the last owner
+// can not step down
+func (s *WorkspaceService) SetMember(ctx context.Context, userID int, workspaceID string, memberID int, role string) (domain.WorkspaceMember, error) {
+	if _, ok := roleRanks[role]; !ok || memberID < domain.FirstRegisteredUserID {
+		return domain.WorkspaceMember{}, appErrors.ErrInvalidWorkspace
+	}
+
+	if err := s.Authorize(ctx, userID, workspaceID, domain.RoleOwner); err != nil {
+		return domain.WorkspaceMember{}, err
+	}
+
+	member, err := s.workspaces.GetMember(ctx, workspaceID, memberID)
+	switch {
+	case errors.Is(err, appErrors.ErrNotFound):
+		member = domain.WorkspaceMember{WorkspaceID: workspaceID, UserID: memberID, AddedAt: time.Now().UTC()}
+	case err != nil:
+		return domain.WorkspaceMember{}, fmt.Errorf("service.SetMember: %w", err)
+	case member.Role == domain.RoleOwner && role != domain.RoleOwner:
+		if err := s.keepOwner(ctx, workspaceID); err != nil {
+			return domain.WorkspaceMember{}, err
+		}
+	}
+
+	member.Role = role
+	if err := s.workspaces.SaveMember(ctx, member); err != nil {
+		return domain.WorkspaceMember{}, fmt.Errorf("service.SetMember: %w", err)
+	}
+
+	return member, nil
+}
+
+// RemoveMember removes a member from the workspace. Owners may remove anyone, other members only themselves, and the
+// last owner can not leave
+func (s *WorkspaceService) RemoveMember(ctx context.Context, userID int, workspaceID string, memberID int) error {
+	required := domain.RoleOwner
+	if memberID == userID {
+		required = domain.RoleViewer
+	}
+	if err := s.Authorize(ctx, userID, workspaceID, required); err != nil {
+		return err
+	}
+
+	member, err := s.workspaces.GetMember(ctx, workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == domain.RoleOwner {
+		if err := s.keepOwner(ctx, workspaceID); err != nil {
+			return err
+		}
+	}
+
+	return s.workspaces.RemoveMember(ctx, workspaceID, memberID)
+}
+
+// TransferLinks moves links into the workspace. The user must be an editor of the workspace and able to manage
+// every link: personal links must be their own and workspace links must belong to a workspace they edit
+func (s *WorkspaceService) TransferLinks(ctx context.Context, userID int, workspaceID string, ids []string) error {
+	if err := s.Authorize(ctx, userID, workspaceID, domain.RoleEditor); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		link, err := s.links.GetLink(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if link.Workspace == "" {
+			if link.UserID != userID {
+				return appErrors.ErrNotFound
+			}
+			continue
+		}
+		if err := s.Authorize(ctx, userID, link.Workspace, domain.RoleEditor); err != nil {
+			return err
+		}
+	}
+
+	if err := s.links.MoveLinks(ctx, ids, workspaceID); err != nil {
+		return fmt.Errorf("service.TransferLinks: %w", err)
+	}
+
+	return nil
+}
+
+// Authorize checks that the user holds at least the role in the workspace. It returns ErrNotFound if the user is not
+// a member, so that workspaces of others are not revealed, and ErrForbidden if the user's role is lower
+func (s *WorkspaceService) Authorize(ctx context.Context, userID int, workspaceID, role string) error {
+	member, err := s.workspaces.GetMember(ctx, workspaceID, userID)
+	if errors.Is(err, appErrors.ErrNotFound) {
+		return appErrors.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("service.Authorize: %w", err)
+	}
+
+	if roleRanks[member.Role] < roleRanks[role] {
+		return appErrors.ErrForbidden
+	}
+
+	return nil
+}
+
+// keepOwner returns ErrLastOwner if the workspace has a single owner left
+func (s *WorkspaceService) keepOwner(ctx context.Context, workspaceID string) error {
+	members, err := s.workspaces.ListMembers(ctx, workspaceID)
+	if err != nil {
+		return fmt.Errorf("service.keepOwner: %w", err)
+	}
+
+	owners := 0
+	for _, member := range members {
+		if member.Role == domain.RoleOwner {
+			owners++
+		}
+	}
+	if owners <= 1 {
+		return appErrors.ErrLastOwner
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Te8va/shortURL/internal/app/domain"
+	appErrors "github.com/Te8va/shortURL/internal/app/errors"
+	"github.com/Te8va/shortURL/internal/app/service"
+	"github.com/Te8va/shortURL/internal/app/service/mocks"
+)
+
+const (
+	ownerID  = domain.FirstRegisteredUserID
+	editorID = domain.FirstRegisteredUserID + 1
+	viewerID = domain.FirstRegisteredUserID + 2
+)
+
+// expectMembers makes the storage answer membership lookups of workspace "ws" with the given roles
+func expectMembers(storage *mocks.MockWorkspaceStorage, roles map[int]string) {
+	storage.EXPECT().GetMember(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, workspaceID string, userID int) (domain.WorkspaceMember, error) {
+			role, ok := roles[userID]
+			if workspaceID != "ws" || !ok {
+				return domain.WorkspaceMember{}, appErrors.ErrNotFound
+			}
+			return domain.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role}, nil
+		}).AnyTimes()
+
+	var members []domain.WorkspaceMember
+	for userID, role := range roles {
+		members = append(members, domain.WorkspaceMember{WorkspaceID: "ws", UserID: userID, Role: role})
+	}
+	storage.EXPECT().ListMembers(gomock.Any(), "ws").Return(members, nil).AnyTimes()
+}
+
+func TestWorkspaceService_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockWorkspaceStorage(ctrl)
+	expectMembers(storage, map[int]string{ownerID: domain.RoleOwner, editorID: domain.RoleEditor, viewerID: domain.RoleViewer})
+	svc := service.NewWorkspaceService(storage, mocks.NewMockWorkspaceLinkStorage(ctrl))
+
+	tests := []struct {
+		name    string
+		userID  int
+		role    string
+		wantErr error
+	}{
+		{name: "owner may edit", userID: ownerID, role: domain.RoleEditor},
+		{name: "editor may edit", userID: editorID, role: domain.RoleEditor},
+		{name: "viewer may view", userID: viewerID, role: domain.RoleViewer},
+		{name: "viewer may not edit", userID: viewerID, role: domain.RoleEditor, wantErr: appErrors.ErrForbidden},
+		{name: "editor may not manage members", userID: editorID, role: domain.RoleOwner, wantErr: appErrors.ErrForbidden},
+		{name: "stranger", userID: 42, role: domain.RoleViewer, wantErr: appErrors.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.Authorize(context.Background(), tt.userID, "ws", tt.role)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWorkspaceService_CreateWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockWorkspaceStorage(ctrl)
+	svc := service.NewWorkspaceService(storage, mocks.NewMockWorkspaceLinkStorage(ctrl))
+
+	storage.EXPECT().CreateWorkspace(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, ws domain.Workspace, owner domain.WorkspaceMember) error {
+			require.Equal(t, "Marketing", ws.Name)
+			require.Equal(t, ws.ID, owner.WorkspaceID)
+			require.Equal(t, ownerID, owner.UserID)
+			require.Equal(t, domain.RoleOwner, owner.Role)
+			return nil
+		})
+
+	ws, err := svc.CreateWorkspace(context.Background(), ownerID, "  Marketing ")
+	require.NoError(t, err)
+	require.NotEmpty(t, ws.ID)
+	require.Equal(t, domain.RoleOwner, ws.Role)
+
+	_, err = svc.CreateWorkspace(context.Background(), ownerID, " ")
+	require.ErrorIs(t, err, appErrors.ErrInvalidWorkspace)
+
+	_, err = svc.CreateWorkspace(context.Background(), 42, "Anonymous")
+	require.ErrorIs(t, err, appErrors.ErrInvalidWorkspace)
+}
+
+func TestWorkspaceService_SetMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockWorkspaceStorage(ctrl)
+	expectMembers(storage, map[int]string{ownerID: domain.RoleOwner, editorID: domain.RoleEditor})
+	svc := service.NewWorkspaceService(storage, mocks.NewMockWorkspaceLinkStorage(ctrl))
+	ctx := context.Background()
+
+	storage.EXPECT().SaveMember(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, member domain.WorkspaceMember) error {
+			require.Equal(t, viewerID, member.UserID)
+			require.Equal(t, domain.RoleViewer, member.Role)
+			require.False(t, member.AddedAt.IsZero())
+			return nil
+		})
+
+	member, err := svc.SetMember(ctx, ownerID, "ws", viewerID, domain.RoleViewer)
+	require.NoError(t, err)
+	require.Equal(t, "ws", member.WorkspaceID)
+
+	_, err = svc.SetMember(ctx, ownerID, "ws", viewerID, "admin")
+	require.ErrorIs(t, err, appErrors.ErrInvalidWorkspace)
+
+	_, err = svc.SetMember(ctx, ownerID, "ws", 42, domain.RoleViewer)
+	require.ErrorIs(t, err, appErrors.ErrInvalidWorkspace)
+
+	_, err = svc.SetMember(ctx, editorID, "ws", viewerID, domain.RoleViewer)
+	require.ErrorIs(t, err, appErrors.ErrForbidden)
+
+	_, err = svc.SetMember(ctx, ownerID, "ws", ownerID, domain.RoleEditor)
+	require.ErrorIs(t, err, appErrors.ErrLastOwner)
+}
+
+func TestWorkspaceService_RemoveMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockWorkspaceStorage(ctrl)
+	expectMembers(storage, map[int]string{ownerID: domain.RoleOwner, editorID: domain.RoleEditor, viewerID: domain.RoleViewer})
+	svc := service.NewWorkspaceService(storage, mocks.NewMockWorkspaceLinkStorage(ctrl))
+	ctx := context.Background()
+
+	storage.EXPECT().RemoveMember(gomock.Any(), "ws", viewerID).Return(nil).Times(2)
+
+	require.NoError(t, svc.RemoveMember(ctx, viewerID, "ws", viewerID))
+	require.NoError(t, svc.RemoveMember(ctx, ownerID, "ws", viewerID))
+	require.ErrorIs(t, svc.RemoveMember(ctx, editorID, "ws", viewerID), appErrors.ErrForbidden)
+	require.ErrorIs(t, svc.RemoveMember(ctx, ownerID, "ws", ownerID), appErrors.ErrLastOwner)
+}
+
+func TestWorkspaceService_TransferLinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := mocks.NewMockWorkspaceStorage(ctrl)
+	expectMembers(storage, map[int]string{editorID: domain.RoleEditor, viewerID: domain.RoleViewer})
+	links := mocks.NewMockWorkspaceLinkStorage(ctrl)
+	svc := service.NewWorkspaceService(storage, links)
+	ctx := context.Background()
+
+	links.EXPECT().GetLink(gomock.Any(), "mine").Return(domain.Link{ID: "mine", UserID: editorID}, nil).AnyTimes()
+	links.EXPECT().GetLink(gomock.Any(), "theirs").Return(domain.Link{ID: "theirs", UserID: ownerID}, nil).AnyTimes()
+	links.EXPECT().MoveLinks(gomock.Any(), []string{"mine"}, "ws").Return(nil)
+
+	require.NoError(t, svc.TransferLinks(ctx, editorID, "ws", []string{"mine"}))
+	require.ErrorIs(t, svc.TransferLinks(ctx, editorID, "ws", []string{"mine", "theirs"}), appErrors.ErrNotFound)
+	require.ErrorIs(t, svc.TransferLinks(ctx, viewerID, "ws", []string{"mine"}), appErrors.ErrForbidden)
+	require.ErrorIs(t, svc.TransferLinks(ctx, editorID, "other", []string{"mine"}), appErrors.ErrNotFound)
+}
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE urlshrt DROP COLUMN IF EXISTS query_options;
+
+COMMIT;
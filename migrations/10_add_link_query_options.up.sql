@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Default UTM parameters and query string forwarding of a link, NULL for links redirecting to their destination as is
+ALTER TABLE urlshrt ADD COLUMN IF NOT EXISTS query_options JSONB;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Outcome of the last check of the original URL of every link. Links without a row have not been checked yet
+CREATE TABLE IF NOT EXISTS link_health (
+    short VARCHAR(255) PRIMARY KEY,
+    status INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    redirects TEXT[] NOT NULL DEFAULT '{}',
+    checked_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS link_health_checked_at_idx ON link_health (checked_at);
+
+COMMIT;
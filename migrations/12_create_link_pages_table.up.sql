@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Title, description, OpenGraph image and favicon of the destination page of a link, fetched after it is created
+CREATE TABLE IF NOT EXISTS link_pages (
+    short VARCHAR(255) PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image TEXT NOT NULL DEFAULT '',
+    favicon TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMPTZ NOT NULL
+);
+
+COMMIT;
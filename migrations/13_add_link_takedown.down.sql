@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS urlshrt_deleted_at_idx;
+ALTER TABLE urlshrt
+    DROP COLUMN IF EXISTS takedown_reason,
+    DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
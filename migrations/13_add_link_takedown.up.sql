@@ -0,0 +1,13 @@
+BEGIN;
+
+-- When a link was deleted, so that deleted links can be purged after a while, and why an operator took it down.
+-- Links deleted before deleted_at existed count as deleted now
+ALTER TABLE urlshrt
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS takedown_reason TEXT NOT NULL DEFAULT '';
+
+UPDATE urlshrt SET deleted_at = NOW() WHERE is_deleted AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS urlshrt_deleted_at_idx ON urlshrt (deleted_at) WHERE is_deleted;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS moderation_actions;
+DROP TABLE IF EXISTS disabled_links;
+DROP TABLE IF EXISTS abuse_reports;
+
+COMMIT;
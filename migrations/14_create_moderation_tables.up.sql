@@ -0,0 +1,35 @@
+BEGIN;
+
+-- Abuse reports filed by visitors, pending until a moderator disables or clears the link
+CREATE TABLE IF NOT EXISTS abuse_reports (
+    id VARCHAR(32) PRIMARY KEY,
+    short VARCHAR(255) NOT NULL,
+    reason VARCHAR(16) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    reporter_ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS abuse_reports_short_idx ON abuse_reports (short);
+CREATE INDEX IF NOT EXISTS abuse_reports_pending_idx ON abuse_reports (short) WHERE resolved_at IS NULL;
+
+-- Links disabled by moderators answer 451 instead of redirecting
+CREATE TABLE IF NOT EXISTS disabled_links (
+    short VARCHAR(255) PRIMARY KEY,
+    disabled_at TIMESTAMPTZ NOT NULL
+);
+
+-- Audit trail of moderator decisions, kept after the links are purged
+CREATE TABLE IF NOT EXISTS moderation_actions (
+    id VARCHAR(32) PRIMARY KEY,
+    short VARCHAR(255) NOT NULL,
+    moderator_id INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS moderation_actions_short_idx ON moderation_actions (short);
+
+COMMIT;
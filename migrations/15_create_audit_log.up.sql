@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Append-only log of who created, updated and deleted which links, with the link before and after the change
+CREATE TABLE IF NOT EXISTS audit_log (
+    id VARCHAR(32) PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL,
+    actor_id INTEGER NOT NULL,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL,
+    short VARCHAR(255) NOT NULL,
+    before JSONB,
+    after JSONB
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at);
+CREATE INDEX IF NOT EXISTS audit_log_short_idx ON audit_log (short);
+CREATE INDEX IF NOT EXISTS audit_log_actor_id_idx ON audit_log (actor_id);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Fails while a deleted link and a live one share a URL on the same domain; purge the deleted links first
+DROP INDEX IF EXISTS urlshrt_domain_original_key;
+CREATE UNIQUE INDEX IF NOT EXISTS urlshrt_domain_original_key ON urlshrt (domain, original);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- A deleted link no longer reserves its URL: shortening it again creates a new link while the deleted one waits to be
+-- purged
+DROP INDEX IF EXISTS urlshrt_domain_original_key;
+CREATE UNIQUE INDEX IF NOT EXISTS urlshrt_domain_original_key ON urlshrt (domain, original) WHERE NOT is_deleted;
+
+COMMIT;
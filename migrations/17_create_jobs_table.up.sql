@@ -0,0 +1,20 @@
+BEGIN;
+
+-- Bulk operations running in the background, kept for a while after they finish so that users can check the outcome
+CREATE TABLE IF NOT EXISTS jobs (
+    id VARCHAR(32) PRIMARY KEY,
+    type VARCHAR(16) NOT NULL,
+    state VARCHAR(16) NOT NULL,
+    user_id INTEGER NOT NULL,
+    ids TEXT[] NOT NULL,
+    results JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS jobs_unfinished_idx ON jobs (created_at) WHERE finished_at IS NULL;
+CREATE INDEX IF NOT EXISTS jobs_finished_at_idx ON jobs (finished_at);
+
+COMMIT;
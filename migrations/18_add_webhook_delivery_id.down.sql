@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE webhook_dead_letters DROP COLUMN IF EXISTS delivery_id;
+
+COMMIT;
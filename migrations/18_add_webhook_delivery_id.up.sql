@@ -0,0 +1,6 @@
+BEGIN;
+
+-- The X-Shorturl-Delivery header of a failed delivery, letters stored before it was recorded have none
+ALTER TABLE webhook_dead_letters ADD COLUMN IF NOT EXISTS delivery_id VARCHAR(32) NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    login VARCHAR(255) UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Anonymous cookie users get random IDs below 1000000, so accounts start above that range.
+ALTER SEQUENCE users_id_seq RESTART WITH 1000000;
+
+COMMIT;
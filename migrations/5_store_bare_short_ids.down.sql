@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Short IDs stay bare: BASE_URL is not known to the database, so the legacy full short URLs can not be rebuilt.
+-- Restoring the global UNIQUE constraint fails if the same URL was shortened on several domains
+DROP INDEX IF EXISTS urlshrt_domain_original_key;
+ALTER TABLE urlshrt ADD CONSTRAINT urlshrt_original_key UNIQUE (original);
+ALTER TABLE urlshrt DROP COLUMN IF EXISTS domain;
+
+COMMIT;
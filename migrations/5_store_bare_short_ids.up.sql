@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE urlshrt ADD COLUMN IF NOT EXISTS domain VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Split legacy "scheme://host[:port]/id" values into the host and the bare ID. Rows that already hold a bare ID are left alone
+UPDATE urlshrt
+SET domain = lower(split_part(substring(short FROM '://(.*)$'), '/', 1)),
+    short = substring(short FROM '[^/]*$')
+WHERE short LIKE '%://%';
+
+-- The same URL may be shortened once per domain
+ALTER TABLE urlshrt DROP CONSTRAINT IF EXISTS urlshrt_original_key;
+CREATE UNIQUE INDEX IF NOT EXISTS urlshrt_domain_original_key ON urlshrt (domain, original);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX IF EXISTS urlshrt_tags_idx;
+DROP INDEX IF EXISTS urlshrt_search_idx;
+ALTER TABLE urlshrt
+    DROP COLUMN IF EXISTS search,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS notes,
+    DROP COLUMN IF EXISTS title;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+ALTER TABLE urlshrt
+    ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Punctuation in URLs is turned into spaces so that hosts and path segments are searchable as separate words
+ALTER TABLE urlshrt ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', title || ' ' || notes || ' ' || translate(original, '/:.?&=#_-', '         '))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS urlshrt_search_idx ON urlshrt USING GIN (search);
+CREATE INDEX IF NOT EXISTS urlshrt_tags_idx ON urlshrt USING GIN (tags);
+
+COMMIT;
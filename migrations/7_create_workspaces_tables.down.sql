@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS urlshrt_workspace_id_idx;
+ALTER TABLE urlshrt DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Destinations of A/B links in the order they were given. Links without variants redirect to their original URL
+CREATE TABLE IF NOT EXISTS link_variants (
+    short VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    weight INTEGER NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (short, position)
+);
+
+COMMIT;
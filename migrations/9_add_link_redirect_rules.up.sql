@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Conditional redirect rules of a link, evaluated in order before falling back to its own destination
+ALTER TABLE urlshrt ADD COLUMN IF NOT EXISTS rules JSONB NOT NULL DEFAULT '[]';
+
+COMMIT;